@@ -34,9 +34,9 @@ type FakeAlerts struct {
 	ns   string
 }
 
-var alertsResource = schema.GroupVersionResource{Group: "synopsys", Version: "v1", Resource: "alerts"}
+var alertsResource = schema.GroupVersionResource{Group: "synopsys.com", Version: "v1", Resource: "alerts"}
 
-var alertsKind = schema.GroupVersionKind{Group: "synopsys", Version: "v1", Kind: "Alert"}
+var alertsKind = schema.GroupVersionKind{Group: "synopsys.com", Version: "v1", Kind: "Alert"}
 
 // Get takes name of the alert, and returns the corresponding alert object, and an error if there is any.
 func (c *FakeAlerts) Get(name string, options v1.GetOptions) (result *alertv1.Alert, err error) {
@@ -22,8 +22,14 @@ under the License.
 package alert
 
 import (
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // GetAlertCustomCertificateSecret ...
@@ -62,3 +68,72 @@ func GetAlertJavaKeystoreSecret(namespace, secretName, javaKeystore string) core
 		Type: corev1.SecretTypeOpaque,
 	}
 }
+
+// GetSecretsFromFlagsAndSetHelmValue returns the custom certificate and Java keystore secrets of the synopsysctl file flags
+// and sets the Helm values that refer to them
+func GetSecretsFromFlagsAndSetHelmValue(namespace string, flagset *pflag.FlagSet, helmVal map[string]interface{}) ([]corev1.Secret, error) {
+	var secrets []corev1.Secret
+	if flagset.Lookup("certificate-file-path").Changed && flagset.Lookup("certificate-key-file-path").Changed {
+		certificateData, err := util.ReadFileData(flagset.Lookup("certificate-file-path").Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate file: %+v", err)
+		}
+		certificateKeyData, err := util.ReadFileData(flagset.Lookup("certificate-key-file-path").Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate key file: %+v", err)
+		}
+		customCertificateSecretName := "alert-custom-certificate"
+		secrets = append(secrets, GetAlertCustomCertificateSecret(namespace, customCertificateSecretName, certificateData, certificateKeyData))
+		util.SetHelmValueInMap(helmVal, []string{"webserverCustomCertificatesSecretName"}, customCertificateSecretName)
+	}
+
+	if flagset.Lookup("java-keystore-file-path").Changed {
+		javaKeystoreData, err := util.ReadFileData(flagset.Lookup("java-keystore-file-path").Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Java Keystore file: %+v", err)
+		}
+		javaKeystoreSecretName := "alert-java-keystore"
+		secrets = append(secrets, GetAlertJavaKeystoreSecret(namespace, javaKeystoreSecretName, javaKeystoreData))
+		util.SetHelmValueInMap(helmVal, []string{"javaKeystoreSecretName"}, javaKeystoreSecretName)
+	}
+	return secrets, nil
+}
+
+// GetAlertIngress returns the ingress that exposes the Alert user interface
+func GetAlertIngress(namespace string, name string, helmValues map[string]interface{}) *networkingv1beta1.Ingress {
+	port := int32(8443)
+	switch value := util.GetHelmValueFromMap(helmValues, []string{"alert", "port"}).(type) {
+	case int32:
+		port = value
+	case int:
+		port = int32(value)
+	case float64:
+		port = int32(value)
+	}
+	return util.GetKubeIngress(
+		namespace,
+		util.GetResourceName(name, util.AlertName, "ingress"),
+		map[string]string{
+			"app":       util.AlertName,
+			"component": "ingress",
+			"name":      name,
+		},
+		util.GetIngressConfigFromHelmValues(helmValues, "ingress"),
+		util.GetResourceName(name, util.AlertName, ""),
+		port,
+	)
+}
+
+// CRUDIngress creates or updates the Alert ingress if it's enabled in the Helm values, otherwise it deletes it
+func CRUDIngress(kubeClient kubernetes.Interface, namespace string, name string, helmValues map[string]interface{}) error {
+	if enabled, ok := util.GetHelmValueFromMap(helmValues, []string{"ingress", "enabled"}).(bool); ok && enabled {
+		if _, err := util.CreateOrUpdateIngress(kubeClient, namespace, GetAlertIngress(namespace, name, helmValues)); err != nil {
+			return fmt.Errorf("failed to create Alert ingress due to %+v", err)
+		}
+		return nil
+	}
+	if err := util.DeleteIngressIfExists(kubeClient, namespace, util.GetResourceName(name, util.AlertName, "ingress")); err != nil {
+		return fmt.Errorf("unable to delete Alert ingress due to %+v", err)
+	}
+	return nil
+}
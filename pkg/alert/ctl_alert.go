@@ -62,6 +62,11 @@ type FlagTree struct {
 	CertificateFilePath    string
 	CertificateKeyFilePath string
 	JavaKeyStoreFilePath   string
+	CertManagerIssuer      string
+	IngressHost            string
+	IngressClass           string
+	IngressTLSSecretName   string
+	IngressAnnotations     map[string]string
 	// SecurityContextFilePath string
 }
 
@@ -96,10 +101,18 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 
 	cmd.Flags().StringVar(&ctl.flagTree.StandAlone, "standalone", "true", "If true, Alert runs in standalone mode [true|false]")
 	if master {
-		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", util.NONE, "Service type to expose Alert's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]")
+		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", util.NONE, "Service type to expose Alert's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|INGRESS|NONE]")
 	} else {
-		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", ctl.flagTree.ExposeService, "Service type to expose Alert's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]")
+		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", ctl.flagTree.ExposeService, "Service type to expose Alert's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|INGRESS|NONE]")
 	}
+	cmd.Flags().StringVar(&ctl.flagTree.IngressHost, "ingress-host", ctl.flagTree.IngressHost, "Host name of the ingress when --expose-ui is INGRESS")
+	if master {
+		cmd.Flags().StringVar(&ctl.flagTree.IngressClass, "ingress-class", "nginx", "Class of the ingress controller when --expose-ui is INGRESS")
+	} else {
+		cmd.Flags().StringVar(&ctl.flagTree.IngressClass, "ingress-class", ctl.flagTree.IngressClass, "Class of the ingress controller when --expose-ui is INGRESS")
+	}
+	cmd.Flags().StringVar(&ctl.flagTree.IngressTLSSecretName, "ingress-tls-secret", ctl.flagTree.IngressTLSSecretName, "Name of the TLS secret of the ingress when --expose-ui is INGRESS")
+	cmd.Flags().StringToStringVar(&ctl.flagTree.IngressAnnotations, "ingress-annotations", ctl.flagTree.IngressAnnotations, "Annotations of the ingress when --expose-ui is INGRESS [KEY=VALUE,...]")
 	cmd.Flags().StringVar(&ctl.flagTree.EncryptionPassword, "encryption-password", ctl.flagTree.EncryptionPassword, "Encryption Password for Alert")
 	cmd.Flags().StringVar(&ctl.flagTree.EncryptionGlobalSalt, "encryption-global-salt", ctl.flagTree.EncryptionGlobalSalt, "Encryption Global Salt for Alert")
 	cmd.Flags().StringVar(&ctl.flagTree.PersistentStorage, "persistent-storage", "true", "If true, Alert has persistent storage [true|false]")
@@ -114,8 +127,15 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 	cmd.Flags().StringVar(&ctl.flagTree.CertificateFilePath, "certificate-file-path", ctl.flagTree.CertificateFilePath, "Absolute path to the PEM certificate to use for Alert")
 	cmd.Flags().StringVar(&ctl.flagTree.CertificateKeyFilePath, "certificate-key-file-path", ctl.flagTree.CertificateKeyFilePath, "Absolute path to the PEM certificate key for Alert")
 	cmd.Flags().StringVar(&ctl.flagTree.JavaKeyStoreFilePath, "java-keystore-file-path", ctl.flagTree.JavaKeyStoreFilePath, "Absolute path to the Java Keystore to use for Alert")
+	if master {
+		cmd.Flags().StringVar(&ctl.flagTree.CertManagerIssuer, "cert-manager-issuer", ctl.flagTree.CertManagerIssuer, "Name of the cert-manager issuer that signs the Alert certificate for the ALERT_HOSTNAME environ [NAME[:Issuer|ClusterIssuer]]")
+	}
 	// cmd.Flags().StringVar(&ctl.flagTree.SecurityContextFilePath, "security-context-file-path", ctl.flagTree.SecurityContextFilePath, "Absolute path to a file containing a map of pod names to security contexts runAsUser, fsGroup, and runAsGroup")
 
+	for _, name := range []string{"encryption-password"} {
+		util.MarkFlagSecret(cmd.Flags(), name)
+	}
+
 	cmd.Flags().Int32Var(&ctl.flagTree.Port, "port", ctl.flagTree.Port, "Port of Alert") // only for devs
 	cmd.Flags().MarkHidden("port")
 }
@@ -136,14 +156,25 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 		}
 	}
 	if FlagWasSet(flagset, "expose-ui") {
-		isValid := util.IsExposeServiceValid(ctl.flagTree.ExposeService)
+		isValid := util.IsExposeServiceValid(ctl.flagTree.ExposeService) || ctl.flagTree.ExposeService == util.INGRESS
 		if !isValid {
-			return fmt.Errorf("expose ui must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
+			return fmt.Errorf("expose ui must be '%s', '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.INGRESS, util.NONE)
+		}
+		if ctl.flagTree.ExposeService == util.INGRESS && len(ctl.flagTree.IngressHost) == 0 && util.GetHelmValueFromMap(ctl.args, []string{"ingress", "host"}) == nil {
+			return fmt.Errorf("ingress host must be set to expose the user interface with an ingress")
 		}
 	}
 	if (FlagWasSet(flagset, "certificate-file-path") || FlagWasSet(flagset, "certificate-key-file-path")) && !(FlagWasSet(flagset, "certificate-file-path") && FlagWasSet(flagset, "certificate-key-file-path")) {
 		return fmt.Errorf("must set both certificate-file-path and certificate-key-file-path")
 	}
+	if FlagWasSet(flagset, "cert-manager-issuer") {
+		if FlagWasSet(flagset, "certificate-file-path") || FlagWasSet(flagset, "certificate-key-file-path") {
+			return fmt.Errorf("cannot set cert-manager-issuer with certificate-file-path or certificate-key-file-path")
+		}
+		if _, _, err := util.ParseCertManagerIssuer(ctl.flagTree.CertManagerIssuer); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -189,6 +220,27 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 				util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, true)
 				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, "LoadBalancer")
 			}
+			// the ingress isn't part of the chart, synopsysctl creates it
+			if ctl.flagTree.ExposeService == util.INGRESS {
+				util.SetHelmValueInMap(ctl.args, []string{"ingress", "enabled"}, true)
+				if util.GetHelmValueFromMap(ctl.args, []string{"ingress", "class"}) == nil {
+					util.SetHelmValueInMap(ctl.args, []string{"ingress", "class"}, ctl.flagTree.IngressClass)
+				}
+			} else if util.GetHelmValueFromMap(ctl.args, []string{"ingress"}) != nil {
+				util.SetHelmValueInMap(ctl.args, []string{"ingress", "enabled"}, false)
+			}
+		case "ingress-host":
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "host"}, ctl.flagTree.IngressHost)
+		case "ingress-class":
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "class"}, ctl.flagTree.IngressClass)
+		case "ingress-tls-secret":
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "tlsSecretName"}, ctl.flagTree.IngressTLSSecretName)
+		case "ingress-annotations":
+			annotations := map[string]interface{}{}
+			for k, v := range ctl.flagTree.IngressAnnotations {
+				annotations[k] = v
+			}
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "annotations"}, annotations)
 		case "port":
 			util.SetHelmValueInMap(ctl.args, []string{"alert", "port"}, ctl.flagTree.Port)
 		case "encryption-password":
@@ -62,6 +62,23 @@ func TestGenerateHelmFlagsFromCobraFlags(t *testing.T) {
 
 }
 
+func TestCheckValuesFromFlagsCertManagerIssuer(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, flagName := range []string{"certificate-file-path", "certificate-key-file-path"} {
+		alertCobraHelper := NewHelmValuesFromCobraFlags()
+		cmd := &cobra.Command{}
+		alertCobraHelper.AddCobraFlagsToCommand(cmd, true)
+		flagset := cmd.Flags()
+		assert.Nil(flagset.Set("cert-manager-issuer", "letsencrypt"))
+		assert.Nil(alertCobraHelper.CheckValuesFromFlags(flagset))
+
+		// the certificate of cert-manager can't be combined with a certificate file
+		assert.Nil(flagset.Set(flagName, "/tmp/tls.crt"))
+		assert.NotNil(alertCobraHelper.CheckValuesFromFlags(flagset), flagName)
+	}
+}
+
 func TestSetCRSpecFieldByFlag(t *testing.T) {
 	assert := assert.New(t)
 
@@ -20,6 +20,6 @@ under the License.
 */
 
 // +k8s:deepcopy-gen=package
-// +groupName=synopsys
+// +groupName=synopsys.com
 
 package v1
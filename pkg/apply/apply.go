@@ -0,0 +1,292 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package apply creates or updates the instances listed in a manifest in-process, with the flags of the create and
+// update commands of their products
+package apply
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestAPIVersion is the version of the instance manifest format supported by apply
+const ManifestAPIVersion = "synopsysctl/v1"
+
+// Manifest lists the instances that are managed by apply
+type Manifest struct {
+	APIVersion string     `json:"apiVersion"`
+	Instances  []Instance `json:"instances"`
+}
+
+// Instance is an instance of a Synopsys product. The settings are the flags of the create and update commands of the product
+type Instance struct {
+	Product   string                 `json:"product"`
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Version   string                 `json:"version,omitempty"`
+	Settings  map[string]interface{} `json:"settings,omitempty"`
+}
+
+// ValuesGenerator returns the Helm values of the flags of a command. The current values are the values of the release
+// on update and nil on create
+type ValuesGenerator func(flagset *pflag.FlagSet, currentValues map[string]interface{}) (map[string]interface{}, error)
+
+// Product binds the create and update flags of a product to apply
+type Product struct {
+	// TakesName is true if there can be several instances of the product in a namespace
+	TakesName bool
+	// AddFlags adds the flags of the create or update command of the product to the command and returns the generator of their values
+	AddFlags func(cmd *cobra.Command, create bool) ValuesGenerator
+	// RequireCreateFlags marks the flags that the create command requires, which can depend on the other flags
+	RequireCreateFlags func(flagset *pflag.FlagSet)
+	// Create creates the instance with the values
+	Create func(ctx context.Context, c *client.Client, instance Instance, flagset *pflag.FlagSet, values map[string]interface{}) error
+	// Update updates the instance with the values. The previous revision is the revision of the release before the update
+	Update func(ctx context.Context, c *client.Client, instance Instance, flagset *pflag.FlagSet, values map[string]interface{}, previousRevision int) error
+}
+
+// Applier creates or updates the instances of a manifest
+type Applier struct {
+	// Products are the products that can be managed by apply
+	Products map[string]Product
+	// NewClient returns a client for the instances in the namespace
+	NewClient func(namespace string) (*client.Client, error)
+	// ConfigureCreate is called with the create command of an instance after its settings are set, e.g. to set the flags of a profile
+	ConfigureCreate func(cmd *cobra.Command, args []string) error
+	// DryRun only logs the instances that would be created or updated
+	DryRun bool
+}
+
+// ParseManifest reads and validates an instance manifest
+func (a *Applier) ParseManifest(data []byte) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := yaml.UnmarshalStrict(data, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.APIVersion != ManifestAPIVersion {
+		return nil, fmt.Errorf("unsupported apiVersion '%s', must be '%s'", manifest.APIVersion, ManifestAPIVersion)
+	}
+	productNames := []string{}
+	for name := range a.Products {
+		productNames = append(productNames, name)
+	}
+	sort.Strings(productNames)
+	seen := make(map[string]bool)
+	for i, instance := range manifest.Instances {
+		product, ok := a.Products[instance.Product]
+		if !ok {
+			return nil, fmt.Errorf("instance %d has an unsupported product '%s', must be one of [%s]", i, instance.Product, strings.Join(productNames, "|"))
+		}
+		if !product.TakesName && len(instance.Name) == 0 {
+			manifest.Instances[i].Name = instance.Product
+		}
+		if len(manifest.Instances[i].Name) == 0 || len(instance.Namespace) == 0 {
+			return nil, fmt.Errorf("instance %d must have a name and a namespace", i)
+		}
+		key := fmt.Sprintf("%s/%s/%s", instance.Product, instance.Namespace, manifest.Instances[i].Name)
+		if seen[key] {
+			return nil, fmt.Errorf("%s '%s' in namespace '%s' is listed more than once", instance.Product, manifest.Instances[i].Name, instance.Namespace)
+		}
+		seen[key] = true
+	}
+	return manifest, nil
+}
+
+// Apply creates the instance if its release doesn't exist, updates it if its settings changed and leaves it alone otherwise.
+// The settings are set on the flags of a new command, so the instances don't share any state
+func (a *Applier) Apply(ctx context.Context, instance Instance) error {
+	product, ok := a.Products[instance.Product]
+	if !ok {
+		return fmt.Errorf("unsupported product '%s'", instance.Product)
+	}
+	settings := make(map[string]interface{})
+	for key, value := range instance.Settings {
+		settings[key] = value
+	}
+	if len(instance.Version) > 0 {
+		settings["version"] = instance.Version
+	}
+
+	c, err := a.NewClient(instance.Namespace)
+	if err != nil {
+		return err
+	}
+	name := instance.Name
+	if !product.TakesName {
+		name = ""
+	}
+	helmRelease, err := c.GetRelease(instance.Product, name)
+	if err != nil {
+		return err
+	}
+
+	if helmRelease == nil {
+		cmd := newCommand("create", instance.Product)
+		generateValues := product.AddFlags(cmd, true)
+		if err := resolveSecrets(cmd.Flags(), settings); err != nil {
+			return err
+		}
+		if err := SetFlags(cmd.Flags(), settings, false); err != nil {
+			return err
+		}
+		if a.ConfigureCreate != nil {
+			if err := a.ConfigureCreate(cmd, getArgs(product, instance)); err != nil {
+				return err
+			}
+		}
+		if product.RequireCreateFlags != nil {
+			product.RequireCreateFlags(cmd.Flags())
+		}
+		if err := CheckRequiredFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		values, err := generateValues(cmd.Flags(), nil)
+		if err != nil {
+			return err
+		}
+		log.Infof("creating %s '%s' in namespace '%s'", instance.Product, instance.Name, instance.Namespace)
+		if a.DryRun {
+			return nil
+		}
+		return product.Create(ctx, c, instance, cmd.Flags(), values)
+	}
+
+	cmd := newCommand("update", instance.Product)
+	generateValues := product.AddFlags(cmd, false)
+	if err := resolveSecrets(cmd.Flags(), settings); err != nil {
+		return err
+	}
+	if err := SetFlags(cmd.Flags(), settings, true); err != nil {
+		return err
+	}
+	values, err := generateValues(cmd.Flags(), util.CopyHelmValues(helmRelease.Config))
+	if err != nil {
+		return err
+	}
+	changed, err := ValuesChanged(helmRelease.Config, values)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		log.Infof("%s '%s' in namespace '%s' is unchanged", instance.Product, instance.Name, instance.Namespace)
+		return nil
+	}
+	log.Infof("updating %s '%s' in namespace '%s'", instance.Product, instance.Name, instance.Namespace)
+	if a.DryRun {
+		return nil
+	}
+	return product.Update(ctx, c, instance, cmd.Flags(), values, helmRelease.Version)
+}
+
+// newCommand returns a command for the flags of the create or update command of a product. It has the path of the
+// command, e.g. synopsysctl create blackduck, which selects the settings of a profile
+func newCommand(verb string, product string) *cobra.Command {
+	root := &cobra.Command{Use: "synopsysctl"}
+	verbCmd := &cobra.Command{Use: verb}
+	cmd := &cobra.Command{Use: product}
+	root.AddCommand(verbCmd)
+	verbCmd.AddCommand(cmd)
+	return cmd
+}
+
+// getArgs returns the arguments of the create and update commands of an instance
+func getArgs(product Product, instance Instance) []string {
+	if !product.TakesName {
+		return []string{}
+	}
+	return []string{instance.Name}
+}
+
+// resolveSecrets replaces the settings of the secret flags with their secrets, which are read from files, stdin,
+// environment variables or secret stores
+func resolveSecrets(flagset *pflag.FlagSet, settings map[string]interface{}) error {
+	for name, value := range settings {
+		flag := flagset.Lookup(name)
+		if flag == nil || !util.IsSecretFlag(flag) {
+			continue
+		}
+		secret, err := util.ReadSecretValue(fmt.Sprintf("%v", value), os.Stdin)
+		if err != nil {
+			return fmt.Errorf("invalid value for setting '%s': %+v", name, err)
+		}
+		util.RegisterSecret(secret)
+		settings[name] = secret
+	}
+	return nil
+}
+
+// SetFlags sets the flags from the settings of an instance. If ignoreUnknown is true, the settings that aren't flags
+// of the command are skipped, e.g. the settings that can only be set on create
+func SetFlags(flagset *pflag.FlagSet, settings map[string]interface{}, ignoreUnknown bool) error {
+	for name, value := range settings {
+		if flagset.Lookup(name) == nil {
+			if ignoreUnknown {
+				log.Debugf("setting '%s' can't be updated, skipping it", name)
+				continue
+			}
+			return fmt.Errorf("unknown setting '%s'", name)
+		}
+		values := []interface{}{value}
+		if list, ok := value.([]interface{}); ok {
+			values = list
+		}
+		for _, v := range values {
+			if err := flagset.Set(name, fmt.Sprintf("%v", v)); err != nil {
+				return fmt.Errorf("invalid value '%v' for setting '%s': %+v", v, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRequiredFlags returns an error that lists the required flags that aren't set, as cobra does for the commands it runs
+func CheckRequiredFlags(flagset *pflag.FlagSet) error {
+	missing := []string{}
+	flagset.VisitAll(func(flag *pflag.Flag) {
+		if required, ok := flag.Annotations[cobra.BashCompOneRequiredFlag]; ok && len(required) > 0 && required[0] == "true" && !flag.Changed {
+			missing = append(missing, flag.Name)
+		}
+	})
+	if len(missing) > 0 {
+		return fmt.Errorf("required setting(s) \"%s\" not set", strings.Join(missing, "\", \""))
+	}
+	return nil
+}
+
+// ValuesChanged returns true if the new Helm values are different from the current values
+func ValuesChanged(currentValues map[string]interface{}, newValues map[string]interface{}) (bool, error) {
+	changes, err := util.DiffHelmValues(currentValues, newValues)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare the Helm values: %+v", err)
+	}
+	return len(changes) > 0, nil
+}
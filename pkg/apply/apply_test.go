@@ -0,0 +1,232 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// testChartFiles are the files of a chart with a single Deployment, which is installed in the simulated cluster
+var testChartFiles = map[string]string{
+	"Chart.yaml":  "apiVersion: v2\nname: bdba\nversion: 1.0.0\ntype: application\n",
+	"values.yaml": "worker:\n  replicas: 1\n",
+	"templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-worker
+  labels:
+    component: worker
+spec:
+  replicas: {{ .Values.worker.replicas }}
+  selector:
+    matchLabels:
+      component: worker
+  template:
+    metadata:
+      labels:
+        component: worker
+    spec:
+      containers:
+      - name: worker
+        image: worker
+`,
+}
+
+// newTestApplier returns an applier of a product with a single instance per namespace, which is installed from the test chart
+// in the simulated cluster. The license flag is only a flag of create, where it is required
+func newTestApplier(t *testing.T, dir string) (*Applier, *util.SimulatedCluster) {
+	chartPath := filepath.Join(dir, "bdba")
+	for name, content := range testChartFiles {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(chartPath, name)), 0755))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(chartPath, name), []byte(content), 0644))
+	}
+	simulation, err := util.NewSimulatedCluster(filepath.Join(dir, "simulate.json"))
+	assert.Nil(t, err)
+
+	product := Product{
+		AddFlags: func(cmd *cobra.Command, create bool) ValuesGenerator {
+			replicas := cmd.Flags().Int("worker-replicas", 1, "Replicas of the worker")
+			password := cmd.Flags().String("password", "", "Password of the instance")
+			util.MarkFlagSecret(cmd.Flags(), "password")
+			if create {
+				cmd.Flags().String("license", "", "License of the instance")
+			}
+			return func(flagset *pflag.FlagSet, currentValues map[string]interface{}) (map[string]interface{}, error) {
+				values := currentValues
+				if values == nil {
+					values = map[string]interface{}{}
+				}
+				if flagset.Changed("worker-replicas") || currentValues == nil {
+					util.SetHelmValueInMap(values, []string{"worker", "replicas"}, *replicas)
+				}
+				if flagset.Changed("password") {
+					values["password"] = *password
+				}
+				return values, nil
+			}
+		},
+		RequireCreateFlags: func(flagset *pflag.FlagSet) {
+			cobra.MarkFlagRequired(flagset, "license")
+		},
+		Create: func(ctx context.Context, c *client.Client, instance Instance, flagset *pflag.FlagSet, values map[string]interface{}) error {
+			return c.CreateInstance(ctx, client.BDBA, client.InstanceValues{ChartURL: chartPath, Values: values})
+		},
+		Update: func(ctx context.Context, c *client.Client, instance Instance, flagset *pflag.FlagSet, values map[string]interface{}, previousRevision int) error {
+			return c.UpdateInstance(ctx, client.BDBA, client.InstanceValues{ChartURL: chartPath, Values: values})
+		},
+	}
+	return &Applier{
+		Products: map[string]Product{client.BDBA: product},
+		NewClient: func(namespace string) (*client.Client, error) {
+			return client.NewClient(client.Options{Namespace: namespace, Simulation: simulation})
+		},
+	}, simulation
+}
+
+func TestParseManifest(t *testing.T) {
+	assert := assert.New(t)
+	applier := &Applier{Products: map[string]Product{client.BDBA: {}, client.BlackDuck: {TakesName: true}}}
+
+	manifest, err := applier.ParseManifest([]byte("apiVersion: synopsysctl/v1\ninstances:\n- product: bdba\n  namespace: bdba\n- product: blackduck\n  name: hub\n  namespace: hub\n  version: 2020.6.0\n  settings:\n    size: small\n"))
+	assert.Nil(err)
+	assert.Equal([]Instance{
+		{Product: "bdba", Name: "bdba", Namespace: "bdba"},
+		{Product: "blackduck", Name: "hub", Namespace: "hub", Version: "2020.6.0", Settings: map[string]interface{}{"size": "small"}},
+	}, manifest.Instances)
+
+	for _, data := range []string{
+		"apiVersion: synopsysctl/v2\n",
+		"apiVersion: synopsysctl/v1\nunknown: true\n",
+		"apiVersion: synopsysctl/v1\ninstances:\n- product: opssight\n  name: ops\n  namespace: ops\n",
+		"apiVersion: synopsysctl/v1\ninstances:\n- product: blackduck\n  namespace: hub\n",
+		"apiVersion: synopsysctl/v1\ninstances:\n- product: bdba\n  namespace: bdba\n- product: bdba\n  namespace: bdba\n",
+	} {
+		_, err := applier.ParseManifest([]byte(data))
+		assert.NotNil(err, data)
+	}
+}
+
+func TestApply(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "synopsysctl-apply")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	applier, simulation := newTestApplier(t, dir)
+	ctx := context.Background()
+	commandPaths := []string{}
+	applier.ConfigureCreate = func(cmd *cobra.Command, args []string) error {
+		commandPaths = append(commandPaths, cmd.CommandPath())
+		return nil
+	}
+	getRelease := func(namespace string) map[string]interface{} {
+		c, err := applier.NewClient(namespace)
+		if !assert.Nil(err) {
+			return nil
+		}
+		helmRelease, err := c.GetRelease(client.BDBA, "")
+		if !assert.Nil(err) || helmRelease == nil {
+			return nil
+		}
+		return map[string]interface{}{"revision": helmRelease.Version, "values": helmRelease.Config}
+	}
+
+	// the create settings are validated and nothing is created by a dry run
+	assert.NotNil(applier.Apply(ctx, Instance{Product: client.BDBA, Name: client.BDBA, Namespace: "ns"}))
+	assert.NotNil(applier.Apply(ctx, Instance{Product: client.BDBA, Name: client.BDBA, Namespace: "ns", Settings: map[string]interface{}{"license": "l", "unknown": true}}))
+	applier.DryRun = true
+	assert.Nil(applier.Apply(ctx, Instance{Product: client.BDBA, Name: client.BDBA, Namespace: "ns", Settings: map[string]interface{}{"license": "l"}}))
+	assert.Nil(getRelease("ns"))
+	applier.DryRun = false
+
+	// the secret settings are read from their source
+	os.Setenv("SYNOPSYSCTL_APPLY_TEST_PASSWORD", "secret")
+	defer os.Unsetenv("SYNOPSYSCTL_APPLY_TEST_PASSWORD")
+	settings := map[string]interface{}{"license": "l", "worker-replicas": 2, "password": "env:SYNOPSYSCTL_APPLY_TEST_PASSWORD"}
+	assert.Nil(applier.Apply(ctx, Instance{Product: client.BDBA, Name: client.BDBA, Namespace: "ns", Settings: settings}))
+	assert.Equal("synopsysctl create bdba", commandPaths[len(commandPaths)-1])
+	release := getRelease("ns")
+	if assert.NotNil(release) {
+		assert.Equal(1, release["revision"])
+		assert.Equal("secret", release["values"].(map[string]interface{})["password"])
+	}
+	deployment, err := simulation.KubeClient.AppsV1().Deployments("ns").Get("bdba-worker", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(int32(2), *deployment.Spec.Replicas)
+
+	// an unchanged instance isn't updated, and the create settings are skipped on update
+	assert.Nil(applier.Apply(ctx, Instance{Product: client.BDBA, Name: client.BDBA, Namespace: "ns", Settings: settings}))
+	assert.Equal(1, getRelease("ns")["revision"])
+	settings["worker-replicas"] = 3
+	assert.Nil(applier.Apply(ctx, Instance{Product: client.BDBA, Name: client.BDBA, Namespace: "ns", Settings: settings}))
+	assert.Equal(2, getRelease("ns")["revision"])
+	deployment, err = simulation.KubeClient.AppsV1().Deployments("ns").Get("bdba-worker", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(int32(3), *deployment.Spec.Replicas)
+
+	// the instances of other namespaces are applied with clients of their own
+	assert.Nil(applier.Apply(ctx, Instance{Product: client.BDBA, Name: client.BDBA, Namespace: "other", Settings: map[string]interface{}{"license": "l"}}))
+	assert.Equal(1, getRelease("other")["revision"])
+	assert.Equal(2, getRelease("ns")["revision"])
+}
+
+func TestSetFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	flagset := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flagset.StringSlice("environs", []string{}, "")
+	flagset.Int("replicas", 1, "")
+	assert.Nil(SetFlags(flagset, map[string]interface{}{"environs": []interface{}{"A:1", "B:2"}, "replicas": 3}, false))
+	environs, err := flagset.GetStringSlice("environs")
+	assert.Nil(err)
+	assert.Equal([]string{"A:1", "B:2"}, environs)
+	replicas, err := flagset.GetInt("replicas")
+	assert.Nil(err)
+	assert.Equal(3, replicas)
+
+	assert.NotNil(SetFlags(flagset, map[string]interface{}{"unknown": 1}, false))
+	assert.Nil(SetFlags(flagset, map[string]interface{}{"unknown": 1}, true))
+	assert.NotNil(SetFlags(flagset, map[string]interface{}{"replicas": "many"}, false))
+}
+
+func TestCheckRequiredFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	flagset := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flagset.String("license", "", "")
+	flagset.String("size", "", "")
+	assert.Nil(CheckRequiredFlags(flagset))
+	cobra.MarkFlagRequired(flagset, "license")
+	assert.EqualError(CheckRequiredFlags(flagset), "required setting(s) \"license\" not set")
+	assert.Nil(flagset.Set("license", "l"))
+	assert.Nil(CheckRequiredFlags(flagset))
+}
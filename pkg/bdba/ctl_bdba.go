@@ -23,6 +23,7 @@ package bdba
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
@@ -30,6 +31,9 @@ import (
 	"github.com/spf13/pflag"
 )
 
+// AutoscaleComponents are the components of BDBA that can be autoscaled with a HorizontalPodAutoscaler
+var AutoscaleComponents = []string{"worker"}
+
 // HelmValuesFromCobraFlags is a type for converting synopsysctl flags
 // to Helm Chart fields and values
 // args: map of helm chart field to value
@@ -103,8 +107,9 @@ type FlagTree struct {
 	DisableWorkerLogging   bool `json:"disableWorkerLogging"`
 
 	// Worker scaling
-	WorkerReplicas    int `json:"workerReplicas"`
-	WorkerConcurrency int `json:"workerConcurrency"` // TODO: Patcher
+	WorkerReplicas    int               `json:"workerReplicas"`
+	WorkerConcurrency int               `json:"workerConcurrency"` // TODO: Patcher
+	Autoscale         map[string]string `json:"autoscale"`
 
 	// Networking and security
 	RootCASecret string `json:"rootCASecret"`
@@ -119,6 +124,7 @@ type FlagTree struct {
 	IngressHost          string `json:"ingressHost"`
 	IngressTLSEnabled    bool   `json:"ingressTLSEnabled"`
 	IngressTLSSecretName string `json:"ingressTLSSecretName"`
+	CertManagerIssuer    string `json:"certManagerIssuer"`
 
 	// External PostgreSQL
 	ExternalPG             bool   `json:"ExternalPg"`
@@ -227,6 +233,7 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 	// Worker scaling
 	cmd.Flags().IntVar(&ctl.flagTree.WorkerReplicas, "worker-replicas", 1, "Number of worker replicas")
 	cmd.Flags().IntVar(&ctl.flagTree.WorkerConcurrency, "worker-concurrency", 1, "Amount of concurrent workers per pod")
+	cmd.Flags().StringToStringVar(&ctl.flagTree.Autoscale, "autoscale", ctl.flagTree.Autoscale, fmt.Sprintf("Autoscale the components with a HorizontalPodAutoscaler, or turn it off [%s=MIN:MAX:CPU_PERCENT|off,...]", strings.Join(AutoscaleComponents, "|")))
 
 	// Minio
 	cmd.Flags().StringVar(&ctl.flagTree.MinioMode, "minio-mode", "standalone", "Minio mode [standalone|distributed]")
@@ -241,6 +248,9 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 	cmd.Flags().StringVar(&ctl.flagTree.IngressHost, "ingress-host", ctl.flagTree.IngressHost, "Hostname for ingress")
 	cmd.Flags().BoolVar(&ctl.flagTree.IngressTLSEnabled, "enable-ingress-tls", false, "Enable TLS for ingress")
 	cmd.Flags().StringVar(&ctl.flagTree.IngressTLSSecretName, "ingress-tls-secret", ctl.flagTree.IngressTLSSecretName, "TLS Secret to use for ingress")
+	if master {
+		cmd.Flags().StringVar(&ctl.flagTree.CertManagerIssuer, "cert-manager-issuer", ctl.flagTree.CertManagerIssuer, "Name of the cert-manager issuer that signs the ingress certificate for the ingress host [NAME[:Issuer|ClusterIssuer]]")
+	}
 
 	// External PG
 	cmd.Flags().BoolVar(&ctl.flagTree.ExternalPG,
@@ -266,6 +276,15 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 	cmd.Flags().StringVar(&ctl.flagTree.PGPassword, "postgres-password", "default", "PostgreSQL password")
 	cmd.Flags().StringVar(&ctl.flagTree.PGExistingSecret, "postgres-secret", ctl.flagTree.PGExistingSecret, "Existing secret for PostgreSQL")
 
+	for _, name := range []string{"license-password", "email-smtp-password", "ldap-bind-password", "external-postgres-password", "postgres-password"} {
+		util.MarkFlagSecret(cmd.Flags(), name)
+	}
+	if master {
+		util.MarkSecretFlagNeeded(cmd.Flags(), "license-password", "enable-offline-mode=false")
+		util.MarkSecretFlagNeeded(cmd.Flags(), "postgres-password", "external-postgres=false", "postgres-secret=")
+		util.MarkSecretFlagNeeded(cmd.Flags(), "external-postgres-password", "external-postgres=true", "external-postgres-client-secret=")
+	}
+
 	cmd.Flags().SortFlags = false
 }
 
@@ -326,7 +345,7 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 			}
 
 			if flagset.Lookup("enable-ingress-tls").Value.String() == "true" {
-				if !flagset.Lookup("ingress-tls-secret").Changed {
+				if !flagset.Lookup("ingress-tls-secret").Changed && len(ctl.flagTree.CertManagerIssuer) == 0 {
 					return fmt.Errorf("--ingress-tls-secret must be set for TLS-enabled ingress")
 				}
 			}
@@ -360,6 +379,24 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 
 	}
 
+	if flagset.Lookup("autoscale").Changed {
+		if err := util.ValidateAutoscaleFlag(ctl.flagTree.Autoscale, AutoscaleComponents); err != nil {
+			return err
+		}
+	}
+
+	if len(ctl.flagTree.CertManagerIssuer) > 0 {
+		if flagset.Lookup("enable-ingress").Value.String() != "true" || !flagset.Lookup("ingress-host").Changed {
+			return fmt.Errorf("--enable-ingress and --ingress-host must be set for --cert-manager-issuer")
+		}
+		if flagset.Lookup("ingress-tls-secret").Changed {
+			return fmt.Errorf("--ingress-tls-secret and --cert-manager-issuer are mutually exclusive")
+		}
+		if _, _, err := util.ParseCertManagerIssuer(ctl.flagTree.CertManagerIssuer); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -474,6 +511,8 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 			util.SetHelmValueInMap(ctl.args, []string{"worker", "replicas"}, ctl.flagTree.WorkerReplicas)
 		case "worker-concurrency":
 			util.SetHelmValueInMap(ctl.args, []string{"worker", "concurrency"}, ctl.flagTree.WorkerConcurrency)
+		case "autoscale":
+			util.SetAutoscaleHelmValues(ctl.args, ctl.flagTree.Autoscale)
 		case "minio-mode":
 			util.SetHelmValueInMap(ctl.args, []string{"minio", "mode"}, ctl.flagTree.MinioMode)
 		case "root-ca-secret":
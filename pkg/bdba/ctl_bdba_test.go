@@ -91,6 +91,24 @@ func TestSetCRSpecFieldByFlag(t *testing.T) {
 				},
 			},
 		},
+		// case
+		{
+			flagName: "autoscale",
+			changedCtl: &HelmValuesFromCobraFlags{
+				flagTree: FlagTree{
+					Autoscale: map[string]string{"worker": "1:5:70"},
+				},
+			},
+			changedArgs: map[string]interface{}{
+				"autoscale": map[string]interface{}{
+					"worker": map[string]interface{}{
+						"minReplicas":                    int32(1),
+						"maxReplicas":                    int32(5),
+						"targetCPUUtilizationPercentage": int32(70),
+					},
+				},
+			},
+		},
 		// TODO: More test cases ...
 	}
 
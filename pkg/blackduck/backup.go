@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// BackupDatabases are the Black Duck databases that are dumped during a backup
+var BackupDatabases = []string{"bds_hub", "bds_hub_report", "bdio"}
+
+// BackupCertificateSecrets are the suffixes of the Black Duck certificate secrets that are stored in a backup
+var BackupCertificateSecrets = []string{"webserver-certificate", "proxy-certificate", "auth-custom-ca"}
+
+// GetPostgresPod returns the blackduck-postgres pod of the Black Duck instance
+func GetPostgresPod(kubeClient kubernetes.Interface, namespace string, name string) (*corev1.Pod, error) {
+	return util.FilterPodByNamePrefixInNamespace(kubeClient, namespace, util.GetResourceName(name, util.BlackDuckName, "postgres"))
+}
+
+// GetPostgresAdminUser returns the Postgres admin user from the Helm values of the Black Duck instance
+func GetPostgresAdminUser(helmValues map[string]interface{}) string {
+	if adminUser, ok := util.GetHelmValueFromMap(helmValues, []string{"postgres", "adminUserName"}).(string); ok && len(adminUser) > 0 {
+		return adminUser
+	}
+	return "postgres"
+}
+
+// IsExternalPostgres returns true if the Black Duck instance is configured to use an external Postgres database
+func IsExternalPostgres(helmValues map[string]interface{}) bool {
+	isExternal, ok := util.GetHelmValueFromMap(helmValues, []string{"postgres", "isExternal"}).(bool)
+	return ok && isExternal
+}
+
+// DumpDatabase runs pg_dump in the blackduck-postgres pod and streams the dump in the Postgres custom format to the writer
+func DumpDatabase(restConfig *rest.Config, kubeClient kubernetes.Interface, pod *corev1.Pod, adminUser string, database string, dump io.Writer) error {
+	req := util.CreateExecContainerRequest(kubeClient, pod, "pg_dump", "-U", adminUser, "-Fc", database)
+	if err := util.ExecContainerWithStreams(restConfig, req, bytes.NewReader(nil), dump); err != nil {
+		return fmt.Errorf("unable to dump database '%s' in pod '%s' due to %+v", database, pod.Name, err)
+	}
+	return nil
+}
+
+// RestoreDatabase streams a dump created by DumpDatabase to pg_restore in the blackduck-postgres pod
+func RestoreDatabase(restConfig *rest.Config, kubeClient kubernetes.Interface, pod *corev1.Pod, adminUser string, database string, dump io.Reader) error {
+	req := util.CreateExecContainerRequest(kubeClient, pod, "pg_restore", "-U", adminUser, "-d", database, "--clean", "--if-exists")
+	if _, err := util.ExecContainerWithStdin(restConfig, req, dump); err != nil {
+		return fmt.Errorf("unable to restore database '%s' in pod '%s' due to %+v", database, pod.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package blackduck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPostgresAdminUser(t *testing.T) {
+	assert.Equal(t, "postgres", GetPostgresAdminUser(map[string]interface{}{}))
+	assert.Equal(t, "postgres", GetPostgresAdminUser(map[string]interface{}{"postgres": map[string]interface{}{"adminUserName": ""}}))
+	assert.Equal(t, "blackduck", GetPostgresAdminUser(map[string]interface{}{"postgres": map[string]interface{}{"adminUserName": "blackduck"}}))
+}
+
+func TestIsExternalPostgres(t *testing.T) {
+	assert.False(t, IsExternalPostgres(map[string]interface{}{}))
+	assert.False(t, IsExternalPostgres(map[string]interface{}{"postgres": map[string]interface{}{"isExternal": false}}))
+	assert.True(t, IsExternalPostgres(map[string]interface{}{"postgres": map[string]interface{}{"isExternal": true}}))
+}
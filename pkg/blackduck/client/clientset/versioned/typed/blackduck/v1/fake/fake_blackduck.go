@@ -34,9 +34,9 @@ type FakeBlackducks struct {
 	ns   string
 }
 
-var blackducksResource = schema.GroupVersionResource{Group: "synopsys", Version: "v1", Resource: "blackducks"}
+var blackducksResource = schema.GroupVersionResource{Group: "synopsys.com", Version: "v1", Resource: "blackducks"}
 
-var blackducksKind = schema.GroupVersionKind{Group: "synopsys", Version: "v1", Kind: "Blackduck"}
+var blackducksKind = schema.GroupVersionKind{Group: "synopsys.com", Version: "v1", Kind: "Blackduck"}
 
 // Get takes name of the blackduck, and returns the corresponding blackduck object, and an error if there is any.
 func (c *FakeBlackducks) Get(name string, options v1.GetOptions) (result *blackduckv1.Blackduck, err error) {
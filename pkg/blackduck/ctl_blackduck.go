@@ -65,6 +65,12 @@ type FlagTree struct {
 	CertificateKeyFilePath        string
 	ProxyCertificateFilePath      string
 	AuthCustomCAFilePath          string
+	CertManagerIssuer             string
+	IngressHost                   string
+	IngressClass                  string
+	IngressTLSSecretName          string
+	IngressAnnotations            map[string]string
+	Autoscale                     map[string]string
 	MigrationMode                 bool
 	Environs                      []string
 	AdminPassword                 string
@@ -105,6 +111,9 @@ func (ctl *HelmValuesFromCobraFlags) SetArgs(args map[string]interface{}) {
 	}
 }
 
+// AutoscaleComponents are the components of Black Duck that can be autoscaled with a HorizontalPodAutoscaler
+var AutoscaleComponents = []string{"jobrunner", "scan"}
+
 // Constants for predefined specs
 const (
 	EmptySpec                           string = "empty"
@@ -131,10 +140,19 @@ func (ctl *HelmValuesFromCobraFlags) AddCRSpecFlagsToCommand(cmd *cobra.Command,
 	cmd.Flags().StringVar(&ctl.flagTree.Size, "size", ctl.flagTree.Size, "Size of Black Duck [small|medium|large|x-large]")
 	cmd.Flags().StringVar(&ctl.flagTree.Version, "version", "2020.4.0", "Version of Black Duck")
 	if master {
-		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", util.NONE, "Service type of Black Duck webserver's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]")
+		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", util.NONE, "Service type of Black Duck webserver's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|INGRESS|NONE]")
+	} else {
+		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", ctl.flagTree.ExposeService, "Service type of Black Duck webserver's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|INGRESS|NONE]")
+	}
+	cmd.Flags().StringVar(&ctl.flagTree.IngressHost, "ingress-host", ctl.flagTree.IngressHost, "Host name of the ingress when --expose-ui is INGRESS")
+	if master {
+		cmd.Flags().StringVar(&ctl.flagTree.IngressClass, "ingress-class", "nginx", "Class of the ingress controller when --expose-ui is INGRESS")
 	} else {
-		cmd.Flags().StringVar(&ctl.flagTree.ExposeService, "expose-ui", ctl.flagTree.ExposeService, "Service type of Black Duck webserver's user interface [NODEPORT|LOADBALANCER|OPENSHIFT|NONE]")
+		cmd.Flags().StringVar(&ctl.flagTree.IngressClass, "ingress-class", ctl.flagTree.IngressClass, "Class of the ingress controller when --expose-ui is INGRESS")
 	}
+	cmd.Flags().StringVar(&ctl.flagTree.IngressTLSSecretName, "ingress-tls-secret", ctl.flagTree.IngressTLSSecretName, "Name of the TLS secret of the ingress when --expose-ui is INGRESS")
+	cmd.Flags().StringToStringVar(&ctl.flagTree.IngressAnnotations, "ingress-annotations", ctl.flagTree.IngressAnnotations, "Annotations of the ingress when --expose-ui is INGRESS [KEY=VALUE,...]")
+	cmd.Flags().StringToStringVar(&ctl.flagTree.Autoscale, "autoscale", ctl.flagTree.Autoscale, fmt.Sprintf("Autoscale the components with a HorizontalPodAutoscaler, or turn it off [%s=MIN:MAX:CPU_PERCENT|off,...]", strings.Join(AutoscaleComponents, "|")))
 
 	cmd.Flags().StringVar(&ctl.flagTree.ExternalPostgresHost, "external-postgres-host", ctl.flagTree.ExternalPostgresHost, "Host of external Postgres")
 	cmd.Flags().IntVar(&ctl.flagTree.ExternalPostgresPort, "external-postgres-port", 5432, "Port of external Postgres")
@@ -150,6 +168,9 @@ func (ctl *HelmValuesFromCobraFlags) AddCRSpecFlagsToCommand(cmd *cobra.Command,
 	cmd.Flags().StringVar(&ctl.flagTree.CertificateKeyFilePath, "certificate-key-file-path", ctl.flagTree.CertificateKeyFilePath, "Absolute path to a file for the Black Duck nginx certificate key")
 	cmd.Flags().StringVar(&ctl.flagTree.ProxyCertificateFilePath, "proxy-certificate-file-path", ctl.flagTree.ProxyCertificateFilePath, "Absolute path to a file for the Black Duck proxy server’s Certificate Authority (CA)")
 	cmd.Flags().StringVar(&ctl.flagTree.AuthCustomCAFilePath, "auth-custom-ca-file-path", ctl.flagTree.AuthCustomCAFilePath, "Absolute path to a file for the Custom Auth CA for Black Duck")
+	if master {
+		cmd.Flags().StringVar(&ctl.flagTree.CertManagerIssuer, "cert-manager-issuer", ctl.flagTree.CertManagerIssuer, "Name of the cert-manager issuer that signs the Black Duck nginx certificate for the PUBLIC_HUB_WEBSERVER_HOST environ [NAME[:Issuer|ClusterIssuer]]")
+	}
 
 	if !strings.Contains(cmd.CommandPath(), "native") {
 		cmd.Flags().BoolVar(&ctl.flagTree.MigrationMode, "migration-mode", ctl.flagTree.MigrationMode, "Create Black Duck in the database-migration state")
@@ -167,6 +188,10 @@ func (ctl *HelmValuesFromCobraFlags) AddCRSpecFlagsToCommand(cmd *cobra.Command,
 	if master {
 		cmd.Flags().StringVar(&ctl.flagTree.SealKey, "seal-key", ctl.flagTree.SealKey, "Seal key to encrypt the master key when Source code upload is enabled and it should be of length 32")
 	}
+
+	for _, name := range []string{"external-postgres-admin-password", "external-postgres-user-password", "admin-password", "user-password", "seal-key"} {
+		util.MarkFlagSecret(cmd.Flags(), name)
+	}
 }
 
 func isValidSize(size string) bool {
@@ -191,9 +216,17 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 		}
 	}
 	if FlagWasSet(flagset, "expose-ui") {
-		isValid := util.IsExposeServiceValid(ctl.flagTree.ExposeService)
+		isValid := util.IsExposeServiceValid(ctl.flagTree.ExposeService) || ctl.flagTree.ExposeService == util.INGRESS
 		if !isValid {
-			return fmt.Errorf("expose ui must be '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.NONE)
+			return fmt.Errorf("expose ui must be '%s', '%s', '%s', '%s' or '%s'", util.NODEPORT, util.LOADBALANCER, util.OPENSHIFT, util.INGRESS, util.NONE)
+		}
+		if ctl.flagTree.ExposeService == util.INGRESS && len(ctl.flagTree.IngressHost) == 0 && util.GetHelmValueFromMap(ctl.args, []string{"ingress", "host"}) == nil {
+			return fmt.Errorf("ingress host must be set to expose the user interface with an ingress")
+		}
+	}
+	if FlagWasSet(flagset, "autoscale") {
+		if err := util.ValidateAutoscaleFlag(ctl.flagTree.Autoscale, AutoscaleComponents); err != nil {
+			return err
 		}
 	}
 	if FlagWasSet(flagset, "environs") {
@@ -213,6 +246,14 @@ func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet
 			return fmt.Errorf("seal key should be of length 32")
 		}
 	}
+	if FlagWasSet(flagset, "cert-manager-issuer") {
+		if FlagWasSet(flagset, "certificate-file-path") || FlagWasSet(flagset, "certificate-key-file-path") {
+			return fmt.Errorf("cannot set cert-manager-issuer with certificate-file-path or certificate-key-file-path")
+		}
+		if _, _, err := util.ParseCertManagerIssuer(ctl.flagTree.CertManagerIssuer); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -242,9 +283,28 @@ func (ctl *HelmValuesFromCobraFlags) AddHelmValueByCobraFlag(f *pflag.Flag) {
 				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, util.LOADBALANCER)
 			case util.OPENSHIFT:
 				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, util.OPENSHIFT)
+			case util.INGRESS:
+				util.SetHelmValueInMap(ctl.args, []string{"exposedServiceType"}, util.INGRESS)
+				if util.GetHelmValueFromMap(ctl.args, []string{"ingress", "class"}) == nil {
+					util.SetHelmValueInMap(ctl.args, []string{"ingress", "class"}, ctl.flagTree.IngressClass)
+				}
 			default:
 				util.SetHelmValueInMap(ctl.args, []string{"exposeui"}, false)
 			}
+		case "ingress-host":
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "host"}, ctl.flagTree.IngressHost)
+		case "ingress-class":
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "class"}, ctl.flagTree.IngressClass)
+		case "ingress-tls-secret":
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "tlsSecretName"}, ctl.flagTree.IngressTLSSecretName)
+		case "ingress-annotations":
+			annotations := map[string]interface{}{}
+			for k, v := range ctl.flagTree.IngressAnnotations {
+				annotations[k] = v
+			}
+			util.SetHelmValueInMap(ctl.args, []string{"ingress", "annotations"}, annotations)
+		case "autoscale":
+			util.SetAutoscaleHelmValues(ctl.args, ctl.flagTree.Autoscale)
 		case "environs":
 			for _, value := range ctl.flagTree.Environs {
 				values := strings.SplitN(value, ":", 2)
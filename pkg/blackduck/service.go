@@ -29,17 +29,24 @@ import (
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
-// CRUDServiceOrRoute will create or update Black Duck exposed service or route in case of OpenShift
-func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, name string, isExposedUI interface{}, exposedServiceType interface{}) error {
+// CRUDServiceOrRoute will create or update Black Duck exposed service, ingress or route in case of OpenShift
+func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient kubernetes.Interface, namespace string, name string, isExposedUI interface{}, exposedServiceType interface{}, ingressConfig util.IngressConfig) error {
 	serviceName := util.GetResourceName(name, util.BlackDuckName, "webserver-exposed")
 	routeName := util.GetResourceName(name, util.BlackDuckName, "")
+	ingressName := util.GetResourceName(name, util.BlackDuckName, "webserver-ingress")
 	isOpenShift := util.IsOpenshift(kubeClient)
 	var err error
+	if isExposedUI == nil || !isExposedUI.(bool) || exposedServiceType != util.INGRESS {
+		if err = util.DeleteIngressIfExists(kubeClient, namespace, ingressName); err != nil {
+			return fmt.Errorf("unable to delete the Black Duck webserver ingress due to %+v", err)
+		}
+	}
 	if isExposedUI != nil && isExposedUI.(bool) {
 		switch exposedServiceType.(string) {
 		case util.NODEPORT:
@@ -67,6 +74,25 @@ func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientse
 					return fmt.Errorf("failed to create Black Duck webserver route due to %+v", err)
 				}
 			}
+		case util.INGRESS:
+			if _, err = util.GetService(kubeClient, namespace, serviceName); err == nil {
+				err = util.DeleteService(kubeClient, namespace, serviceName)
+				if err != nil {
+					return fmt.Errorf("unable to delete the Black Duck webserver expose service due to %+v", err)
+				}
+			}
+			if isOpenShift {
+				routeClient := util.GetRouteClient(restConfig, kubeClient, namespace)
+				if _, err = util.GetRoute(routeClient, namespace, routeName); err == nil {
+					err = util.DeleteRoute(routeClient, namespace, routeName)
+					if err != nil {
+						return fmt.Errorf("unable to delete Black Duck webserver route due to %+v", err)
+					}
+				}
+			}
+			if _, err = util.CreateOrUpdateIngress(kubeClient, namespace, GetWebServerIngress(namespace, ingressName, name, ingressConfig)); err != nil {
+				return fmt.Errorf("failed to create Black Duck webserver ingress due to %+v", err)
+			}
 		}
 	} else {
 		if isOpenShift {
@@ -90,7 +116,7 @@ func CRUDServiceOrRoute(restConfig *rest.Config, kubeClient *kubernetes.Clientse
 }
 
 // crudExposedService crud for webserver exposed service
-func crudExposedService(restConfig *rest.Config, kubeClient *kubernetes.Clientset, namespace string, name string, serviceType corev1.ServiceType) error {
+func crudExposedService(restConfig *rest.Config, kubeClient kubernetes.Interface, namespace string, name string, serviceType corev1.ServiceType) error {
 	serviceName := util.GetResourceName(name, util.BlackDuckName, "webserver-exposed")
 	routeName := util.GetResourceName(name, util.BlackDuckName, "")
 	isOpenShift := util.IsOpenshift(kubeClient)
@@ -156,3 +182,19 @@ func GetWebServerRoute(namespace string, routeName string, name string) *routev1
 		map[string]string{"app": util.BlackDuckName, "name": name, "component": "route"},
 	)
 }
+
+// GetWebServerIngress return the Kubernetes ingress
+func GetWebServerIngress(namespace string, ingressName string, name string, ingressConfig util.IngressConfig) *networkingv1beta1.Ingress {
+	return util.GetKubeIngress(
+		namespace,
+		ingressName,
+		map[string]string{
+			"app":       util.BlackDuckName,
+			"component": "webserver-ingress",
+			"name":      name,
+		},
+		ingressConfig,
+		util.GetResourceName(name, util.BlackDuckName, "webserver"),
+		int32(443),
+	)
+}
@@ -52,7 +52,7 @@ func GetHubVersion(environs []string) string {
 }
 
 // GetLoadBalancerIPAddress will return the load balance service ip address
-func GetLoadBalancerIPAddress(kubeClient *kubernetes.Clientset, namespace string, serviceName string) (string, error) {
+func GetLoadBalancerIPAddress(kubeClient kubernetes.Interface, namespace string, serviceName string) (string, error) {
 	service, err := util.GetService(kubeClient, namespace, serviceName)
 	if err != nil {
 		return "", fmt.Errorf("unable to get service %s in %s namespace because %s", serviceName, namespace, err.Error())
@@ -67,7 +67,7 @@ func GetLoadBalancerIPAddress(kubeClient *kubernetes.Clientset, namespace string
 }
 
 // GetNodePortIPAddress will return the node port service ip address
-func GetNodePortIPAddress(kubeClient *kubernetes.Clientset, namespace string, serviceName string) (string, error) {
+func GetNodePortIPAddress(kubeClient kubernetes.Interface, namespace string, serviceName string) (string, error) {
 	// Get the node port service
 	service, err := util.GetService(kubeClient, namespace, serviceName)
 	if err != nil {
@@ -91,7 +91,7 @@ func intArrayToStringArray(intArr []int32, delim string) string {
 }
 
 // UpdateState will be used to update the hub object
-func UpdateState(h *blackduckclient.Clientset, name string, namespace string, statusState string, error error) (*blackduckv1.Blackduck, error) {
+func UpdateState(h blackduckclient.Interface, name string, namespace string, statusState string, error error) (*blackduckv1.Blackduck, error) {
 	errorMessage := ""
 	if error != nil {
 		errorMessage = fmt.Sprintf("%+v", error)
@@ -102,7 +102,7 @@ func UpdateState(h *blackduckclient.Clientset, name string, namespace string, st
 }
 
 // GetHubDBPassword will retrieve the blackduck and blackduck_user db password
-func GetHubDBPassword(kubeClient *kubernetes.Clientset, namespace string, name string) (string, string, error) {
+func GetHubDBPassword(kubeClient kubernetes.Interface, namespace string, name string) (string, string, error) {
 	var userPw, adminPw string
 
 	secret, err := util.GetSecret(kubeClient, namespace, util.GetResourceName(name, util.BlackDuckName, "db-creds"))
@@ -125,7 +125,7 @@ func GetHubDBPassword(kubeClient *kubernetes.Clientset, namespace string, name s
 }
 
 // CloneJob create a Kube job to clone a postgres instance
-func CloneJob(clientset *kubernetes.Clientset, fromNamespace string, from string, toNamespace string, to string, password string) error {
+func CloneJob(clientset kubernetes.Interface, fromNamespace string, from string, toNamespace string, to string, password string) error {
 	command := fmt.Sprintf("pg_dumpall -h %s.%s.svc.cluster.local -U postgres | psql -h %s.%s.svc.cluster.local -U postgres", util.GetResourceName(from, util.BlackDuckName, "postgres"), fromNamespace, util.GetResourceName(to, util.BlackDuckName, "postgres"), toNamespace)
 
 	cloneJob := &batchv1.Job{
@@ -0,0 +1,153 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AlertValues are the settings of an Alert instance
+type AlertValues struct {
+	// Version is the version of the chart in the chart repository, it isn't used if ChartURL is set. When it is set,
+	// the environs of Black Duck hosts are renamed to the Alert ones for Alert 5.0.0 and later
+	Version string
+	// ChartURL is the URL or the path of the chart
+	ChartURL string
+	// Values are the Helm values of the chart
+	Values map[string]interface{}
+	// Secrets are created or updated before the chart is installed, e.g. the custom certificate and Java keystore
+	// secrets that the values refer to
+	Secrets []corev1.Secret
+	// Certificate is a cert-manager Certificate that is created before the chart is installed. Its certificate and key are copied to
+	// the secret of the certificate that the values refer to
+	Certificate *util.CertManagerCertificate
+}
+
+// CreateAlert installs an Alert instance and creates its ingress if it is set in the values
+func (c *Client) CreateAlert(ctx context.Context, name string, values AlertValues) error {
+	namespace := c.options.Namespace
+	releaseName := fmt.Sprintf("%s%s", name, AlertReleaseSuffix)
+	chartURL, err := GetChartURL(Alert, c.options.ChartRepository, values.Version, values.ChartURL)
+	if err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Check Dry Run before deploying any resources
+	if err := c.helmClient.Create(releaseName, namespace, chartURL, values.Values, true); err != nil {
+		return fmt.Errorf("failed to create Alert resources: %+v", err)
+	}
+
+	if err := c.deployCertificate(ctx, values.Certificate); err != nil {
+		return err
+	}
+	if err := c.createSecrets(values.Secrets, false); err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	if err := c.helmClient.Create(releaseName, namespace, chartURL, values.Values, false); err != nil {
+		return fmt.Errorf("failed to create Alert resources: %+v", err)
+	}
+
+	return alertctl.CRUDIngress(c.kubeClient, namespace, name, values.Values)
+}
+
+// UpdateAlert upgrades an Alert instance to the chart and values, which replace the values of the previous revision.
+// The secrets are saved first so that they are restored if the release is rolled back
+func (c *Client) UpdateAlert(ctx context.Context, name string, values AlertValues) error {
+	namespace := c.options.Namespace
+	releaseName := fmt.Sprintf("%s%s", name, AlertReleaseSuffix)
+	chartURL, err := GetChartURL(Alert, c.options.ChartRepository, values.Version, values.ChartURL)
+	if err != nil {
+		return err
+	}
+	helmRelease, err := c.helmClient.Get(releaseName, namespace)
+	if err != nil {
+		return fmt.Errorf("unable to find Alert instance '%s' in namespace %s", name, namespace)
+	}
+	if len(values.Version) > 0 {
+		if err := SetAlertHostEnvirons(values.Values); err != nil {
+			return err
+		}
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Save the secrets that will be overwritten so that they can be restored on rollback
+	if err := c.saveRollbackSecrets(releaseName, helmRelease.Version, getSecretNames(values.Secrets)); err != nil {
+		return err
+	}
+	if err := c.createSecrets(values.Secrets, true); err != nil {
+		return err
+	}
+	if err := c.deployCertificate(ctx, values.Certificate); err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	if err := c.helmClient.Update(releaseName, namespace, chartURL, values.Values); err != nil {
+		return c.rollbackFailedUpdate(releaseName, helmRelease.Version, fmt.Errorf("failed to update Alert resources due to %+v", err))
+	}
+
+	return alertctl.CRUDIngress(c.kubeClient, namespace, name, values.Values)
+}
+
+// SetAlertHostEnvirons copies the PUBLIC_HUB_WEBSERVER_HOST and PUBLIC_HUB_WEBSERVER_PORT environs to ALERT_HOSTNAME
+// and ALERT_SERVER_PORT and removes them if the image tag in the Helm values is Alert 5.0.0 or later
+func SetAlertHostEnvirons(helmValues map[string]interface{}) error {
+	imageTag, ok := util.GetHelmValueFromMap(helmValues, []string{"alert", "imageTag"}).(string)
+	if !ok {
+		return nil
+	}
+	isGreaterThanOrEqualTo, err := util.IsNotDefaultVersionGreaterThanOrEqualTo(imageTag, 5, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to check Alert version: %+v", err)
+	}
+	environs, ok := helmValues["environs"].(map[string]interface{})
+	if !isGreaterThanOrEqualTo || !ok {
+		return nil
+	}
+	renamedEnvirons := map[string]string{"PUBLIC_HUB_WEBSERVER_HOST": "ALERT_HOSTNAME", "PUBLIC_HUB_WEBSERVER_PORT": "ALERT_SERVER_PORT"}
+	for oldName, newName := range renamedEnvirons {
+		value, ok := environs[oldName]
+		if !ok {
+			continue
+		}
+		if _, ok := environs[newName]; !ok {
+			environs[newName] = value
+		}
+		delete(environs, oldName)
+	}
+	return nil
+}
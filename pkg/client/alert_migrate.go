@@ -0,0 +1,253 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
+	v1 "github.com/blackducksoftware/synopsysctl/pkg/api/alert/v1"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/imdario/mergo"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MigrateAlert moves an Alert instance that Synopsys Operator manages to a Helm release with the values of its custom
+// resource, overridden by the values. Synopsys Operator is stopped and isn't removed or restarted afterwards
+func (c *Client) MigrateAlert(ctx context.Context, name string, values AlertValues) error {
+	namespace := c.options.Namespace
+	chartURL, err := GetChartURL(Alert, c.options.ChartRepository, values.Version, values.ChartURL)
+	if err != nil {
+		return err
+	}
+	operatorNamespace, crdNamespace, err := c.GetOperatorNamespaces()
+	if err != nil {
+		return err
+	}
+	alert, err := util.GetAlert(c.alertClient, crdNamespace, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting Alert '%s' in namespace '%s' due to %+v", name, crdNamespace, err)
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// TODO ensure operator is installed and running a recent version that doesn't require additional migration
+	if err := c.stopOperator(operatorNamespace); err != nil {
+		return err
+	}
+
+	// Generate Helm values for the current CR Instance
+	helmValuesMap, err := alertV1ToHelmValues(alert)
+	if err != nil {
+		return err
+	}
+	if err := mergo.Merge(&helmValuesMap, values.Values, mergo.WithOverride); err != nil {
+		return err
+	}
+
+	if alert.Spec.PersistentStorage {
+		// Set PVC Name to old pvc name format
+		pvcList, err := c.kubeClient.CoreV1().PersistentVolumeClaims(alert.Spec.Namespace).List(metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("app=%s, name=%s", "alert", alert.Name),
+		})
+		if err != nil {
+			return err
+		}
+		if len(pvcList.Items) != 1 {
+			return fmt.Errorf("there should be only 1 pvc for alert but got %+v", len(pvcList.Items))
+		}
+		pvc := pvcList.Items[0]
+		pvc.Labels = util.InitLabels(pvc.Labels)
+		pvc.Labels["name"] = fmt.Sprintf("%s%s", alert.Name, AlertReleaseSuffix)
+		if _, err = util.UpdatePVC(c.kubeClient, alert.Spec.Namespace, &pvc); err != nil {
+			log.Errorf("unable to update an alert persistent volume claim due to %+v", err)
+		}
+		util.SetHelmValueInMap(helmValuesMap, []string{"persistentVolumeClaimName"}, pvcList.Items[0].Name)
+	}
+
+	log.Info("upgrading Alert instance")
+
+	// Delete the Current Instance's Resources (except PVCs)
+	log.Info("cleaning Current Alert resources")
+	// TODO wait for resources to be deleted
+	if err := c.deleteComponents(alert.Spec.Namespace, alert.Name, util.AlertName); err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	if len(values.Version) > 0 {
+		if err := SetAlertHostEnvirons(helmValuesMap); err != nil {
+			return err
+		}
+	}
+
+	newReleaseName := fmt.Sprintf("%s%s", alert.Name, AlertReleaseSuffix)
+
+	// Verify Alert can be created with Dry-Run before creating resources
+	if err := c.helmClient.Create(newReleaseName, alert.Spec.Namespace, chartURL, helmValuesMap, true); err != nil {
+		return fmt.Errorf("failed to update Alert resources: %+v", err)
+	}
+
+	// Update the Secrets
+	secrets := []corev1.Secret{}
+	if len(alert.Spec.Certificate) > 0 && len(alert.Spec.CertificateKey) > 0 {
+		customCertificateSecretName := util.GetHelmValueFromMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}).(string)
+		secrets = append(secrets, alertctl.GetAlertCustomCertificateSecret(namespace, customCertificateSecretName, alert.Spec.Certificate, alert.Spec.CertificateKey))
+	}
+	if len(alert.Spec.JavaKeyStore) > 0 {
+		javaKeystoreSecretName := util.GetHelmValueFromMap(helmValuesMap, []string{"javaKeystoreSecretName"}).(string)
+		secrets = append(secrets, alertctl.GetAlertJavaKeystoreSecret(namespace, javaKeystoreSecretName, alert.Spec.JavaKeyStore))
+	}
+	if err := c.createSecrets(append(secrets, values.Secrets...), true); err != nil {
+		return err
+	}
+
+	if svc, err := util.GetService(c.kubeClient, namespace, fmt.Sprintf("%s-exposed", newReleaseName)); err == nil {
+		svc.Kind = "Service"
+		svc.APIVersion = "v1"
+		svc.Labels = util.InitLabels(svc.Labels)
+		svc.Labels["name"] = newReleaseName
+		svc.Spec.Selector = util.InitLabels(svc.Spec.Selector)
+		svc.Spec.Selector["name"] = newReleaseName
+		if _, err = util.UpdateService(c.kubeClient, namespace, svc); err != nil {
+			return fmt.Errorf("failed to deploy the alert exposed service: %s", err)
+		}
+	}
+
+	// Deploy new Resources
+	if err := c.helmClient.Create(newReleaseName, alert.Spec.Namespace, chartURL, helmValuesMap, false); err != nil {
+		return fmt.Errorf("failed to update Alert resources: %+v", err)
+	}
+
+	log.Info("deleting Alert custom resource")
+	if err := util.DeleteAlert(c.alertClient, alert.Name, alert.Namespace, &metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+
+	if _, err := util.CheckAndUpdateNamespace(c.kubeClient, util.AlertName, alert.Spec.Namespace, alert.Name, "", true); err != nil {
+		log.Warnf("unable to patch the namespace to remove an app labels due to %+v", err)
+	}
+	return nil
+}
+
+// alertV1ToHelmValues converts an Alert custom resource to Helm values
+func alertV1ToHelmValues(alert *v1.Alert) (map[string]interface{}, error) {
+	helmValuesMap := make(map[string]interface{})
+
+	if len(alert.Spec.Version) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"alert", "imageTag"}, alert.Spec.Version)
+	}
+
+	if len(alert.Spec.ExposeService) > 0 {
+		switch alert.Spec.ExposeService {
+		case util.NODEPORT:
+			util.SetHelmValueInMap(helmValuesMap, []string{"exposedServiceType"}, "NodePort")
+			util.SetHelmValueInMap(helmValuesMap, []string{"exposeui"}, false)
+		case util.LOADBALANCER:
+			util.SetHelmValueInMap(helmValuesMap, []string{"exposedServiceType"}, "LoadBalancer")
+			util.SetHelmValueInMap(helmValuesMap, []string{"exposeui"}, false)
+		case util.NONE:
+			util.SetHelmValueInMap(helmValuesMap, []string{"exposeui"}, false)
+		}
+	}
+
+	util.SetHelmValueInMap(helmValuesMap, []string{"enableStandalone"}, *alert.Spec.StandAlone)
+
+	if alert.Spec.Port != nil {
+		util.SetHelmValueInMap(helmValuesMap, []string{"alert", "port"}, *alert.Spec.Port)
+	}
+
+	if len(alert.Spec.EncryptionPassword) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"setEncryptionSecretData"}, true)
+		util.SetHelmValueInMap(helmValuesMap, []string{"alertEncryptionPassword"}, alert.Spec.EncryptionPassword)
+	}
+
+	if len(alert.Spec.EncryptionGlobalSalt) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"setEncryptionSecretData"}, true)
+		util.SetHelmValueInMap(helmValuesMap, []string{"alertEncryptionGlobalSalt"}, alert.Spec.EncryptionGlobalSalt)
+	}
+
+	util.SetHelmValueInMap(helmValuesMap, []string{"enablePersistentStorage"}, alert.Spec.PersistentStorage)
+
+	if len(alert.Spec.PVCName) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"persistentVolumeClaimName"}, alert.Spec.PVCName)
+	}
+
+	if len(alert.Spec.PVCStorageClass) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"storageClassName"}, alert.Spec.PVCStorageClass)
+	}
+
+	if len(alert.Spec.PVCSize) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"pvcSize"}, alert.Spec.PVCSize)
+	}
+
+	if len(alert.Spec.AlertMemory) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"alert", "resources", "limits", "memory"}, alert.Spec.AlertMemory)
+		util.SetHelmValueInMap(helmValuesMap, []string{"alert", "resources", "requests", "memory"}, alert.Spec.AlertMemory)
+	}
+
+	if len(alert.Spec.CfsslMemory) > 0 {
+		util.SetHelmValueInMap(helmValuesMap, []string{"cfssl", "resources", "limits", "memory"}, alert.Spec.CfsslMemory)
+		util.SetHelmValueInMap(helmValuesMap, []string{"cfssl", "resources", "requests", "memory"}, alert.Spec.CfsslMemory)
+	}
+
+	if len(alert.Spec.Environs) > 0 {
+		envMap := map[string]interface{}{}
+		for _, env := range alert.Spec.Environs {
+			envSplit := strings.Split(env, ":")
+			envMap[envSplit[0]] = envSplit[1]
+		}
+		util.SetHelmValueInMap(helmValuesMap, []string{"environs"}, envMap)
+	}
+
+	if len(alert.Spec.DesiredState) > 0 {
+		if strings.ToUpper(alert.Spec.DesiredState) == "STOPPED" {
+			util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Stopped")
+		} else {
+			util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Running")
+		}
+	}
+
+	if alert.Spec.RegistryConfiguration != nil {
+		util.SetHelmValueInMap(helmValuesMap, []string{"registry"}, alert.Spec.RegistryConfiguration.Registry)
+		util.SetHelmValueInMap(helmValuesMap, []string{"imagePullSecrets"}, alert.Spec.RegistryConfiguration.PullSecrets)
+	}
+
+	if len(alert.Spec.Certificate) > 0 && len(alert.Spec.CertificateKey) > 0 {
+		customCertificateSecretName := "alert-custom-certificate"
+		util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, customCertificateSecretName)
+	}
+
+	if len(alert.Spec.JavaKeyStore) > 0 {
+		javaKeystoreSecretName := "alert-java-keystore"
+		util.SetHelmValueInMap(helmValuesMap, []string{"javaKeystoreSecretName"}, javaKeystoreSecretName)
+	}
+
+	return helmValuesMap, nil
+}
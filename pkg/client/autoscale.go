@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+)
+
+// GetHorizontalPodAutoscalers returns the HorizontalPodAutoscalers of the autoscale configurations in the Helm values.
+// They target the Deployments of the components in the manifest of the release, and are autoscaling/v2beta2 objects since
+// client-go v0.17 doesn't have autoscaling/v2
+func GetHorizontalPodAutoscalers(namespace string, releaseName string, manifest string, helmValues map[string]interface{}) ([]*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	configs := util.GetAutoscaleConfigsFromHelmValues(helmValues)
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	resources, err := util.GetManifestResources(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", releaseName, namespace, err)
+	}
+	componentWorkloads := util.GetComponentWorkloads(resources, releaseName)
+
+	hpas := []*autoscalingv2beta2.HorizontalPodAutoscaler{}
+	for _, component := range util.GetAutoscaleComponents(configs) {
+		found := false
+		for _, workload := range componentWorkloads[component] {
+			if workload.Kind != "Deployment" {
+				continue
+			}
+			labels := map[string]string{util.AutoscaleReleaseLabel: releaseName, "component": component}
+			hpas = append(hpas, util.GetHorizontalPodAutoscaler(namespace, workload.Name, labels, workload.Name, configs[component]))
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("couldn't find the deployment of component '%s' to autoscale in '%s'", component, releaseName)
+		}
+	}
+	return hpas, nil
+}
+
+// UpdateHorizontalPodAutoscalers creates or updates the HorizontalPodAutoscalers of the autoscale configurations in the
+// Helm values of a release, and deletes those of the components that are no longer autoscaled
+func (c *Client) UpdateHorizontalPodAutoscalers(ctx context.Context, releaseName string, helmValues map[string]interface{}) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	namespace := c.options.Namespace
+	helmRelease, err := c.helmClient.Get(releaseName, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get release '%s' in namespace '%s' due to %+v", releaseName, namespace, err)
+	}
+	hpas, err := GetHorizontalPodAutoscalers(namespace, releaseName, helmRelease.Manifest, helmValues)
+	if err != nil {
+		return err
+	}
+	hpaNames := map[string]bool{}
+	for _, hpa := range hpas {
+		if err := util.CreateOrUpdateHorizontalPodAutoscaler(c.kubeClient, hpa); err != nil {
+			return fmt.Errorf("failed to create the horizontal pod autoscaler '%s' in namespace '%s' due to %+v", hpa.Name, namespace, err)
+		}
+		hpaNames[hpa.Name] = true
+		log.Debugf("created the horizontal pod autoscaler '%s' in namespace '%s'", hpa.Name, namespace)
+	}
+
+	existingHPAs, err := util.ListHorizontalPodAutoscalers(c.kubeClient, namespace, fmt.Sprintf("%s=%s", util.AutoscaleReleaseLabel, releaseName))
+	if err != nil {
+		return fmt.Errorf("failed to list the horizontal pod autoscalers in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, hpa := range existingHPAs.Items {
+		if hpaNames[hpa.Name] {
+			continue
+		}
+		if err := util.DeleteHorizontalPodAutoscalerIfExists(c.kubeClient, namespace, hpa.Name); err != nil {
+			return fmt.Errorf("failed to delete the horizontal pod autoscaler '%s' in namespace '%s' due to %+v", hpa.Name, namespace, err)
+		}
+		log.Debugf("deleted the horizontal pod autoscaler '%s' in namespace '%s'", hpa.Name, namespace)
+	}
+	return nil
+}
+
+// DeleteHorizontalPodAutoscalers deletes the HorizontalPodAutoscalers of a release
+func (c *Client) DeleteHorizontalPodAutoscalers(ctx context.Context, releaseName string) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	namespace := c.options.Namespace
+	hpas, err := util.ListHorizontalPodAutoscalers(c.kubeClient, namespace, fmt.Sprintf("%s=%s", util.AutoscaleReleaseLabel, releaseName))
+	if err != nil {
+		return fmt.Errorf("failed to list the horizontal pod autoscalers in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, hpa := range hpas.Items {
+		if err := util.DeleteHorizontalPodAutoscalerIfExists(c.kubeClient, namespace, hpa.Name); err != nil {
+			return fmt.Errorf("failed to delete the horizontal pod autoscaler '%s' in namespace '%s' due to %+v", hpa.Name, namespace, err)
+		}
+	}
+	return nil
+}
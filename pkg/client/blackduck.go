@@ -0,0 +1,134 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BlackDuckValues are the settings of a Black Duck instance
+type BlackDuckValues struct {
+	// Version is the version of the chart in the chart repository, it isn't used if ChartURL is set
+	Version string
+	// ChartURL is the URL or the path of the chart
+	ChartURL string
+	// Values are the Helm values of the chart. The values file of the size value, e.g. small.yaml, is merged with them
+	Values map[string]interface{}
+	// Secrets are created or updated before the chart is installed, e.g. the webserver certificate secret that the values refer to
+	Secrets []corev1.Secret
+	// Certificate is a cert-manager Certificate that is created before the chart is installed. Its certificate and key are copied to
+	// the secret of the certificate that the values refer to
+	Certificate *util.CertManagerCertificate
+}
+
+// CreateBlackDuck installs a Black Duck instance, then exposes its webserver and autoscales its components as set in the values
+func (c *Client) CreateBlackDuck(ctx context.Context, name string, values BlackDuckValues) error {
+	namespace := c.options.Namespace
+	chartURL, err := GetChartURL(BlackDuck, c.options.ChartRepository, values.Version, values.ChartURL)
+	if err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	if err := c.createSecrets(values.Secrets, false); err != nil {
+		return err
+	}
+
+	// Check Dry Run before deploying any resources
+	extraFiles := getSizeExtraFiles(values.Values)
+	if err := c.helmClient.Create(name, namespace, chartURL, values.Values, true, extraFiles...); err != nil {
+		return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+	}
+
+	if err := c.deployCertificate(ctx, values.Certificate); err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	if err := c.helmClient.Create(name, namespace, chartURL, values.Values, false, extraFiles...); err != nil {
+		return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+	}
+
+	if err := c.crudBlackDuckServiceOrRoute(namespace, name, values.Values); err != nil {
+		return err
+	}
+	return c.UpdateHorizontalPodAutoscalers(ctx, name, values.Values)
+}
+
+// UpdateBlackDuck upgrades a Black Duck instance to the chart and values, which replace the values of the previous
+// revision. The values file of the size of the previous revision is merged with them. The secrets are saved first so
+// that they are restored if the release is rolled back
+func (c *Client) UpdateBlackDuck(ctx context.Context, name string, values BlackDuckValues) error {
+	namespace := c.options.Namespace
+	chartURL, err := GetChartURL(BlackDuck, c.options.ChartRepository, values.Version, values.ChartURL)
+	if err != nil {
+		return err
+	}
+	helmRelease, err := c.helmClient.Get(name, namespace)
+	if err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Save the secrets that will be overwritten so that they can be restored on rollback
+	if err := c.saveRollbackSecrets(name, helmRelease.Version, getSecretNames(values.Secrets)); err != nil {
+		return err
+	}
+	if err := c.createSecrets(values.Secrets, true); err != nil {
+		return err
+	}
+	if err := c.deployCertificate(ctx, values.Certificate); err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	if err := c.helmClient.Update(name, namespace, chartURL, values.Values, getSizeExtraFiles(helmRelease.Config)...); err != nil {
+		return c.rollbackFailedUpdate(name, helmRelease.Version, err)
+	}
+
+	if err := c.crudBlackDuckServiceOrRoute(namespace, name, values.Values); err != nil {
+		return err
+	}
+	return c.UpdateHorizontalPodAutoscalers(ctx, name, values.Values)
+}
+
+// crudBlackDuckServiceOrRoute creates, updates or deletes the exposed service, ingress or route of the webserver of a Black Duck instance
+func (c *Client) crudBlackDuckServiceOrRoute(namespace string, name string, helmValues map[string]interface{}) error {
+	err := blackduck.CRUDServiceOrRoute(c.restConfig, c.kubeClient, namespace, name, helmValues["exposeui"], helmValues["exposedServiceType"], util.GetIngressConfigFromHelmValues(helmValues, "ingress"))
+	if err != nil {
+		return err
+	}
+	log.Debugf("exposed the webserver of Black Duck '%s' in namespace '%s'", name, namespace)
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package client manages the lifecycle of the Helm based instances of the Synopsys products so that other Go tools can
+// create, update, delete, migrate and check them without running synopsysctl. All the state is held by a Client, so
+// clients for different clusters or namespaces can be used at the same time.
+package client
+
+import (
+	"context"
+	"fmt"
+
+	alertclientset "github.com/blackducksoftware/synopsysctl/pkg/alert/client/clientset/versioned"
+	blackduckclientset "github.com/blackducksoftware/synopsysctl/pkg/blackduck/client/clientset/versioned"
+	"github.com/blackducksoftware/synopsysctl/pkg/protoform"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"helm.sh/helm/v3/pkg/release"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Products that are managed with Helm
+const (
+	BlackDuck        = util.BlackDuckName
+	Alert            = util.AlertName
+	Polaris          = "polaris"
+	PolarisReporting = "polaris-reporting"
+	BDBA             = "bdba"
+)
+
+// AlertReleaseSuffix is added to the name of an Alert instance to get the name of its release
+const AlertReleaseSuffix = "-alert"
+
+// products are the charts of the products in the chart repository
+var products = map[string]struct {
+	chartFormat string
+	takesName   bool
+}{
+	BlackDuck:        {chartFormat: "%s/charts/blackduck-%s.tgz", takesName: true},
+	Alert:            {chartFormat: "%s/charts/alert-helmchart-%s.tgz", takesName: true},
+	Polaris:          {chartFormat: "%s/charts/polaris-helmchart-%s.tgz"},
+	PolarisReporting: {chartFormat: "%s/charts/polaris-helmchart-reporting-%s.tgz"},
+	BDBA:             {chartFormat: "%s/charts/bdba-%s.tgz"},
+}
+
+// Options are the settings of a Client
+type Options struct {
+	// KubeConfigPath is the path of the kubeconfig, ~/.kube/config is used if it is empty
+	KubeConfigPath string
+	// KubeContext is the context of the kubeconfig, the current context is used if it is empty
+	KubeContext string
+	// InsecureSkipTLSVerify disables the validation of the certificate of the API server
+	InsecureSkipTLSVerify bool
+	// Namespace is the namespace of the instances
+	Namespace string
+	// ChartRepository is the base URL of the chart repository. The charts of a version are downloaded from
+	// <ChartRepository>/charts/<chart>-<version>.tgz unless the values of an instance have a chart URL
+	ChartRepository string
+	// ChartCacheDirectory is the directory that the downloaded charts are cached in, the charts aren't cached if it is
+	// empty
+	ChartCacheDirectory string
+	// ChartKeyring is the keyring that the cached charts are verified with, the charts aren't verified if it is empty
+	ChartKeyring string
+	// DisableRollback keeps a release at the failed revision when its update fails instead of rolling it back
+	DisableRollback bool
+	// Simulation is the simulated cluster that the client uses instead of the cluster of the kubeconfig, if it is set
+	Simulation *util.SimulatedCluster
+}
+
+// Client manages the instances of the Synopsys products in a namespace of a cluster
+type Client struct {
+	options            Options
+	restConfig         *rest.Config
+	kubeClient         kubernetes.Interface
+	apiExtensionClient apiextensionsclient.Interface
+	alertClient        alertclientset.Interface
+	blackDuckClient    blackduckclientset.Interface
+	helmClient         *util.HelmClient
+}
+
+// NewClient returns a Client for the cluster of the context of the kubeconfig in the options
+func NewClient(options Options) (*Client, error) {
+	if options.Simulation != nil {
+		return NewClientForConfig(options, nil)
+	}
+	restConfig, err := protoform.GetKubeClientFromOutsideClusterWithContext(options.KubeConfigPath, options.KubeContext, options.InsecureSkipTLSVerify)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the rest config of context '%s' due to %+v", options.KubeContext, err)
+	}
+	return NewClientForConfig(options, restConfig)
+}
+
+// NewClientForConfig returns a Client for the cluster of the rest config. The Helm actions still use the kubeconfig
+// and context in the options, so they must point to the same cluster. The rest config isn't used with a simulated cluster
+func NewClientForConfig(options Options, restConfig *rest.Config) (*Client, error) {
+	if len(options.Namespace) == 0 {
+		return nil, fmt.Errorf("the namespace of the client must be set")
+	}
+	helmClient := util.NewHelmClient(options.KubeConfigPath, options.KubeContext)
+	helmClient.InsecureSkipTLSVerify = options.InsecureSkipTLSVerify
+	helmClient.ChartCacheDirectory = options.ChartCacheDirectory
+	helmClient.ChartKeyring = options.ChartKeyring
+	if options.Simulation != nil {
+		helmClient.Simulation = options.Simulation
+		return &Client{
+			options:            options,
+			restConfig:         &rest.Config{Host: util.SimulatedClusterHost},
+			kubeClient:         options.Simulation.KubeClient,
+			apiExtensionClient: options.Simulation.APIExtensionClient,
+			alertClient:        options.Simulation.AlertClient,
+			blackDuckClient:    options.Simulation.BlackDuckClient,
+			helmClient:         helmClient,
+		}, nil
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Kubernetes client due to %+v", err)
+	}
+	apiExtensionClient, err := apiextensionsclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the API extension client due to %+v", err)
+	}
+	alertClient, err := alertclientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Alert client due to %+v", err)
+	}
+	blackDuckClient, err := blackduckclientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Black Duck client due to %+v", err)
+	}
+	return &Client{
+		options:            options,
+		restConfig:         restConfig,
+		kubeClient:         kubeClient,
+		apiExtensionClient: apiExtensionClient,
+		alertClient:        alertClient,
+		blackDuckClient:    blackDuckClient,
+		helmClient:         helmClient,
+	}, nil
+}
+
+// Namespace returns the namespace of the instances of the client
+func (c *Client) Namespace() string {
+	return c.options.Namespace
+}
+
+// GetRelease returns the release of an instance, or nil if the instance doesn't exist
+func (c *Client) GetRelease(product string, name string) (*release.Release, error) {
+	releaseName, err := GetReleaseName(product, name)
+	if err != nil {
+		return nil, err
+	}
+	if !c.helmClient.ReleaseExists(releaseName, c.options.Namespace) {
+		return nil, nil
+	}
+	return c.helmClient.Get(releaseName, c.options.Namespace)
+}
+
+// GetReleaseName returns the name of the release of an instance of a product. Polaris, Polaris Reporting and BDBA
+// have a single instance per namespace whose release is named after the product
+func GetReleaseName(product string, name string) (string, error) {
+	p, ok := products[product]
+	if !ok {
+		return "", fmt.Errorf("'%s' is an invalid product, must be one of [%s|%s|%s|%s|%s]", product, BlackDuck, Alert, BDBA, Polaris, PolarisReporting)
+	}
+	if !p.takesName {
+		return product, nil
+	}
+	if len(name) == 0 {
+		return "", fmt.Errorf("the name of the %s instance must be set", product)
+	}
+	if product == Alert {
+		return fmt.Sprintf("%s%s", name, AlertReleaseSuffix), nil
+	}
+	return name, nil
+}
+
+// GetChartURL returns the chart URL if it is set, otherwise the URL of the chart of the version in the chart repository
+func GetChartURL(product string, chartRepository string, version string, chartURL string) (string, error) {
+	if len(chartURL) > 0 {
+		return chartURL, nil
+	}
+	p, ok := products[product]
+	if !ok {
+		return "", fmt.Errorf("'%s' is an invalid product", product)
+	}
+	if len(version) == 0 {
+		return "", fmt.Errorf("the version or the chart URL of the %s instance must be set", product)
+	}
+	if len(chartRepository) == 0 {
+		return "", fmt.Errorf("the chart repository must be set to get the chart of %s version %s", product, version)
+	}
+	return fmt.Sprintf(p.chartFormat, chartRepository, version), nil
+}
+
+// checkContext returns the error of the context if it is canceled or its deadline is exceeded. The Kubernetes and Helm
+// calls don't take a context, so the operations check it between their steps
+func checkContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("the operation was stopped due to %+v", ctx.Err())
+	default:
+		return nil
+	}
+}
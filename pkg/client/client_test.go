@@ -0,0 +1,400 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetReleaseName(t *testing.T) {
+	assert := assert.New(t)
+
+	releaseName, err := GetReleaseName(Alert, "my")
+	assert.Nil(err)
+	assert.Equal("my-alert", releaseName)
+
+	releaseName, err = GetReleaseName(BlackDuck, "hub")
+	assert.Nil(err)
+	assert.Equal("hub", releaseName)
+
+	releaseName, err = GetReleaseName(BDBA, "ignored")
+	assert.Nil(err)
+	assert.Equal(BDBA, releaseName)
+
+	_, err = GetReleaseName(BlackDuck, "")
+	assert.NotNil(err)
+
+	_, err = GetReleaseName("opssight", "ops")
+	assert.NotNil(err)
+}
+
+func TestGetChartURL(t *testing.T) {
+	assert := assert.New(t)
+
+	chartURL, err := GetChartURL(BlackDuck, "https://repo", "2020.6.0", "")
+	assert.Nil(err)
+	assert.Equal("https://repo/charts/blackduck-2020.6.0.tgz", chartURL)
+
+	chartURL, err = GetChartURL(Alert, "https://repo", "6.0.0", "")
+	assert.Nil(err)
+	assert.Equal("https://repo/charts/alert-helmchart-6.0.0.tgz", chartURL)
+
+	chartURL, err = GetChartURL(Alert, "", "", "/tmp/alert.tgz")
+	assert.Nil(err)
+	assert.Equal("/tmp/alert.tgz", chartURL)
+
+	_, err = GetChartURL(BlackDuck, "https://repo", "", "")
+	assert.NotNil(err)
+
+	_, err = GetChartURL(BlackDuck, "", "2020.6.0", "")
+	assert.NotNil(err)
+}
+
+func TestIsProductRelease(t *testing.T) {
+	assert := assert.New(t)
+
+	alertRelease := &release.Release{Name: "my-alert"}
+	blackDuckRelease := &release.Release{Name: "hub", Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "blackduck"}}}
+	bdbaRelease := &release.Release{Name: BDBA}
+
+	assert.True(IsProductRelease(Alert, alertRelease))
+	assert.False(IsProductRelease(BlackDuck, alertRelease))
+	assert.True(IsProductRelease(BlackDuck, blackDuckRelease))
+	assert.False(IsProductRelease(Alert, blackDuckRelease))
+	assert.True(IsProductRelease(BDBA, bdbaRelease))
+	assert.False(IsProductRelease(Polaris, bdbaRelease))
+
+	assert.Equal("my", GetInstanceName(Alert, "my-alert"))
+	assert.Equal("hub", GetInstanceName(BlackDuck, "hub"))
+}
+
+func TestSetAlertHostEnvirons(t *testing.T) {
+	assert := assert.New(t)
+
+	helmValues := map[string]interface{}{
+		"alert": map[string]interface{}{"imageTag": "5.3.0"},
+		"environs": map[string]interface{}{
+			"PUBLIC_HUB_WEBSERVER_HOST": "alert.example.com",
+			"PUBLIC_HUB_WEBSERVER_PORT": "443",
+			"ALERT_SERVER_PORT":         "8443",
+		},
+	}
+	assert.Nil(SetAlertHostEnvirons(helmValues))
+	assert.Equal(map[string]interface{}{"ALERT_HOSTNAME": "alert.example.com", "ALERT_SERVER_PORT": "8443"}, helmValues["environs"])
+
+	helmValues = map[string]interface{}{
+		"alert":    map[string]interface{}{"imageTag": "4.2.0"},
+		"environs": map[string]interface{}{"PUBLIC_HUB_WEBSERVER_HOST": "alert.example.com"},
+	}
+	assert.Nil(SetAlertHostEnvirons(helmValues))
+	assert.Equal(map[string]interface{}{"PUBLIC_HUB_WEBSERVER_HOST": "alert.example.com"}, helmValues["environs"])
+}
+
+func TestGetSizeExtraFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"small.yaml"}, getSizeExtraFiles(map[string]interface{}{"size": "small"}))
+	assert.Nil(getSizeExtraFiles(map[string]interface{}{}))
+}
+
+func TestRollbackSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("my-alert-alert-custom-certificate-revision-2", getRollbackSecretName("my-alert", "alert-custom-certificate", 2))
+	assert.Equal("hub-blackduck-webserver-certificate-revision-3", getRollbackSecretName("hub", "hub-blackduck-webserver-certificate", 3))
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "alert-custom-certificate", Namespace: "ns"}, Data: map[string][]byte{"key": []byte("value")}}
+	c := &Client{options: Options{Namespace: "ns"}, kubeClient: fake.NewSimpleClientset(secret)}
+	for revision := 1; revision <= rollbackSecretRevisions+2; revision++ {
+		assert.Nil(c.saveRollbackSecrets("my-alert", revision, []string{"alert-custom-certificate"}))
+	}
+	secrets, err := c.kubeClient.CoreV1().Secrets("ns").List(metav1.ListOptions{LabelSelector: "component=rollback-secret,release=my-alert"})
+	assert.Nil(err)
+	assert.Equal(rollbackSecretRevisions, len(secrets.Items))
+	_, err = c.kubeClient.CoreV1().Secrets("ns").Get("my-alert-alert-custom-certificate-revision-2", metav1.GetOptions{})
+	assert.NotNil(err)
+	_, err = c.kubeClient.CoreV1().Secrets("ns").Get("my-alert-alert-custom-certificate-revision-3", metav1.GetOptions{})
+	assert.Nil(err)
+
+	// the secret saved for a revision is restored
+	secret.Data = map[string][]byte{"key": []byte("changed")}
+	_, err = c.kubeClient.CoreV1().Secrets("ns").Update(secret)
+	assert.Nil(err)
+	assert.Nil(c.restoreRollbackSecrets("my-alert", rollbackSecretRevisions+2))
+	restored, err := c.kubeClient.CoreV1().Secrets("ns").Get("alert-custom-certificate", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal("value", string(restored.Data["key"]))
+	// the secrets of other releases and the revisions that weren't saved are ignored
+	assert.Nil(c.restoreRollbackSecrets("other-alert", rollbackSecretRevisions+2))
+	assert.Nil(c.restoreRollbackSecrets("my-alert", 1))
+
+	// deleting the secrets of a release keeps the secrets of other releases
+	assert.Nil(c.saveRollbackSecrets("other-alert", 1, []string{"alert-custom-certificate"}))
+	assert.Nil(c.deleteRollbackSecrets("my-alert", 0))
+	secrets, err = c.kubeClient.CoreV1().Secrets("ns").List(metav1.ListOptions{LabelSelector: "component=rollback-secret"})
+	assert.Nil(err)
+	if assert.Equal(1, len(secrets.Items)) {
+		assert.Equal("other-alert-alert-custom-certificate-revision-1", secrets.Items[0].Name)
+	}
+}
+
+// testChartFiles are the files of a chart with a single Deployment, which is installed in the simulated cluster
+var testChartFiles = map[string]string{
+	"Chart.yaml":  "apiVersion: v2\nname: bdba\nversion: 1.0.0\ntype: application\n",
+	"values.yaml": "status: Running\nworker:\n  replicas: 1\n",
+	"templates/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-worker
+  labels:
+    app: bdba
+    component: worker
+spec:
+  replicas: {{ if eq .Values.status "Stopped" }}0{{ else }}{{ .Values.worker.replicas }}{{ end }}
+  selector:
+    matchLabels:
+      component: worker
+  template:
+    metadata:
+      labels:
+        component: worker
+    spec:
+      containers:
+      - name: worker
+        image: worker
+`,
+	"templates/statefulset.yaml": `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: {{ .Release.Name }}-scanner
+  labels:
+    app: bdba
+    component: scanner
+spec:
+  replicas: 1
+  serviceName: {{ .Release.Name }}-scanner
+  selector:
+    matchLabels:
+      component: scanner
+  template:
+    metadata:
+      labels:
+        component: scanner
+    spec:
+      containers:
+      - name: scanner
+        image: scanner
+`,
+}
+
+// writeTestChart writes the files of the test chart to the directory and returns the path of the chart
+func writeTestChart(t *testing.T, dir string) string {
+	chartPath := filepath.Join(dir, "bdba")
+	for name, content := range testChartFiles {
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(chartPath, name)), 0755))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(chartPath, name), []byte(content), 0644))
+	}
+	return chartPath
+}
+
+func TestCreateUpdateDeleteInstance(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "synopsysctl-client")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	chartPath := writeTestChart(t, dir)
+	simulation, err := util.NewSimulatedCluster(filepath.Join(dir, "simulate.json"))
+	assert.Nil(err)
+	c, err := NewClient(Options{Namespace: "ns", Simulation: simulation})
+	assert.Nil(err)
+	ctx := context.Background()
+
+	getReplicas := func() int32 {
+		deployment, err := simulation.KubeClient.AppsV1().Deployments("ns").Get("bdba-worker", metav1.GetOptions{})
+		if !assert.Nil(err) {
+			return -1
+		}
+		return *deployment.Spec.Replicas
+	}
+
+	secrets := []corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "bdba-secret", Namespace: "ns"}, Data: map[string][]byte{"key": []byte("value")}}}
+	assert.Nil(c.CreateInstance(ctx, BDBA, InstanceValues{ChartURL: chartPath, Values: map[string]interface{}{"worker": map[string]interface{}{"replicas": 2}}, Secrets: secrets}))
+	assert.Equal(int32(2), getReplicas())
+	_, err = simulation.KubeClient.CoreV1().Secrets("ns").Get("bdba-secret", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.NotNil(c.CreateInstance(ctx, BDBA, InstanceValues{ChartURL: chartPath}))
+	assert.NotNil(c.CreateInstance(ctx, BlackDuck, InstanceValues{ChartURL: chartPath}))
+
+	assert.Nil(c.UpdateInstance(ctx, BDBA, InstanceValues{ChartURL: chartPath, Values: map[string]interface{}{"worker": map[string]interface{}{"replicas": 3}}}))
+	assert.Equal(int32(3), getReplicas())
+
+	// the values that aren't set are kept
+	assert.Nil(c.UpdateValues(ctx, BDBA, "", "", map[string]interface{}{"status": "Stopped"}))
+	assert.Equal(int32(0), getReplicas())
+	assert.Nil(c.UpdateValues(ctx, BDBA, "", chartPath, map[string]interface{}{"status": "Running"}))
+	assert.Equal(int32(3), getReplicas())
+	helmRelease, err := c.helmClient.Get(BDBA, "ns")
+	assert.Nil(err)
+	assert.Equal(4, helmRelease.Version)
+
+	// a failed update is rolled back to the previous revision
+	assert.NotNil(c.UpdateInstance(ctx, BDBA, InstanceValues{ChartURL: filepath.Join(dir, "missing")}))
+	assert.NotNil(c.UpdateValues(ctx, BDBA, "", "", map[string]interface{}{"worker": map[string]interface{}{"replicas": "invalid"}}))
+	helmRelease, err = c.helmClient.Get(BDBA, "ns")
+	assert.Nil(err)
+	assert.EqualValues(3, helmRelease.Config["worker"].(map[string]interface{})["replicas"])
+	assert.Equal(int32(3), getReplicas())
+
+	// the secrets that an update overwrites are restored when it fails or is rolled back
+	getSecretValue := func() string {
+		secret, err := simulation.KubeClient.CoreV1().Secrets("ns").Get("bdba-secret", metav1.GetOptions{})
+		if !assert.Nil(err) {
+			return ""
+		}
+		return string(secret.Data["key"])
+	}
+	changedSecrets := []corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "bdba-secret", Namespace: "ns"}, Data: map[string][]byte{"key": []byte("changed")}}}
+	assert.NotNil(c.UpdateInstance(ctx, BDBA, InstanceValues{ChartURL: filepath.Join(dir, "missing"), Secrets: changedSecrets}))
+	assert.Equal("value", getSecretValue())
+	assert.Nil(c.UpdateInstance(ctx, BDBA, InstanceValues{ChartURL: chartPath, Secrets: changedSecrets}))
+	assert.Equal("changed", getSecretValue())
+	assert.Nil(c.Rollback(ctx, BDBA, "", 0))
+	assert.Equal("value", getSecretValue())
+
+	assert.Nil(c.Delete(ctx, BDBA, ""))
+	assert.False(c.helmClient.ReleaseExists(BDBA, "ns"))
+	_, err = simulation.KubeClient.AppsV1().Deployments("ns").Get("bdba-worker", metav1.GetOptions{})
+	assert.NotNil(err)
+}
+
+func TestGetComponentStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	readyPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "ns", Labels: map[string]string{"component": "webapp"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+	pendingPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "ns", Labels: map[string]string{"component": "webapp"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "webapp", Namespace: "ns"}}
+	c := &Client{options: Options{Namespace: "ns"}, kubeClient: fake.NewSimpleClientset(&readyPod, &pendingPod, service)}
+
+	status, err := c.getPodsStatus("ns", "Deployment", "webapp", "component=webapp")
+	assert.Nil(err)
+	assert.Equal("1/2 pods ready", status.Status)
+	assert.False(status.Ready)
+
+	status, err = c.getPodsStatus("ns", "Deployment", "jobrunner", "component=jobrunner")
+	assert.Nil(err)
+	assert.Equal("0/0 pods ready", status.Status)
+	assert.False(status.Ready)
+
+	assert.Nil(c.kubeClient.CoreV1().Pods("ns").Delete("pending", &metav1.DeleteOptions{}))
+	status, err = c.getPodsStatus("ns", "Deployment", "webapp", "component=webapp")
+	assert.Nil(err)
+	assert.True(status.Ready)
+
+	// the status doesn't wait for the endpoints of the service
+	status = c.getServiceEndpointStatus("ns", "webapp")
+	assert.Equal("no endpoints", status.Status)
+	assert.False(status.Ready)
+	status = c.getServiceEndpointStatus("ns", "missing")
+	assert.Equal("Missing", status.Status)
+	assert.False(status.Ready)
+
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "webapp", Namespace: "ns"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}, NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}}}},
+	}
+	_, err = c.kubeClient.CoreV1().Endpoints("ns").Create(endpoints)
+	assert.Nil(err)
+	status = c.getServiceEndpointStatus("ns", "webapp")
+	assert.Equal("1/2 endpoints ready", status.Status)
+	assert.True(status.Ready)
+}
+
+func TestScale(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "synopsysctl-client")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	chartPath := writeTestChart(t, dir)
+	simulation, err := util.NewSimulatedCluster(filepath.Join(dir, "simulate.json"))
+	assert.Nil(err)
+	c, err := NewClient(Options{Namespace: "ns", Simulation: simulation})
+	assert.Nil(err)
+	ctx := context.Background()
+
+	assert.Nil(c.CreateInstance(ctx, BDBA, InstanceValues{ChartURL: chartPath, Values: map[string]interface{}{"status": "Running", "worker": map[string]interface{}{"replicas": 2}}}))
+	assert.NotNil(c.Scale(ctx, BDBA, "", map[string]int{"missing": 1}))
+
+	// the replicas of the worker are set in the values and the scanner, which the chart doesn't parameterize, is patched
+	assert.Nil(c.Scale(ctx, BDBA, "", map[string]int{"worker": 4, "scanner": 2}))
+	helmRelease, err := c.helmClient.Get(BDBA, "ns")
+	assert.Nil(err)
+	assert.Equal(2, helmRelease.Version)
+	assert.EqualValues(4, util.GetHelmValueFromMap(helmRelease.Config, []string{"worker", "replicas"}))
+	assert.Equal("Running", helmRelease.Config["status"])
+	deployment, err := simulation.KubeClient.AppsV1().Deployments("ns").Get("bdba-worker", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(int32(4), *deployment.Spec.Replicas)
+	statefulSet, err := simulation.KubeClient.AppsV1().StatefulSets("ns").Get("bdba-scanner", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(int32(2), *statefulSet.Spec.Replicas)
+
+	// the replicas of the worker are kept by the next update
+	assert.Nil(c.UpdateValues(ctx, BDBA, "", "", map[string]interface{}{"status": "Running"}))
+	deployment, err = simulation.KubeClient.AppsV1().Deployments("ns").Get("bdba-worker", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(int32(4), *deployment.Spec.Replicas)
+}
+
+func TestGetSizeValues(t *testing.T) {
+	assert := assert.New(t)
+
+	sizeChart := &chart.Chart{Files: []*chart.File{{Name: "small.yaml", Data: []byte("jobrunner:\n  replicas: 1\n")}}}
+	sizeValues, err := getSizeValues(&release.Release{Chart: sizeChart, Config: map[string]interface{}{"size": "small"}})
+	assert.Nil(err)
+	assert.EqualValues(1, util.GetHelmValueFromMap(sizeValues, []string{"jobrunner", "replicas"}))
+
+	sizeValues, err = getSizeValues(&release.Release{Chart: sizeChart, Config: map[string]interface{}{}})
+	assert.Nil(err)
+	assert.Empty(sizeValues)
+
+	sizeChart.Files[0].Data = []byte("jobrunner: [")
+	_, err = getSizeValues(&release.Release{Chart: sizeChart, Config: map[string]interface{}{"size": "small"}})
+	assert.NotNil(err)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	polarisreporting "github.com/blackducksoftware/synopsysctl/pkg/polaris-reporting"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Delete uninstalls an instance of a product and removes the secrets, rollback secrets, cert-manager certificates, exposed services, ingresses,
+// routes, horizontal pod autoscalers and persistent volume claims that synopsysctl created for it outside of the chart
+func (c *Client) Delete(ctx context.Context, product string, name string) error {
+	releaseName, err := GetReleaseName(product, name)
+	if err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	switch product {
+	case Alert:
+		err = c.deleteAlert(ctx, name, releaseName)
+	case BlackDuck:
+		err = c.deleteBlackDuck(ctx, name)
+	default:
+		err = c.deleteRelease(ctx, product, releaseName)
+	}
+	if err != nil {
+		return err
+	}
+	// the cert-manager Certificates are labeled with the name of the instance
+	return c.deleteCertificates(fmt.Sprintf("app=%s, name=%s", product, name))
+}
+
+// deleteRelease deletes the release of a Polaris, Polaris Reporting or BDBA instance and the resources that synopsysctl created for it
+func (c *Client) deleteRelease(ctx context.Context, product string, releaseName string) error {
+	if product == PolarisReporting {
+		if err := c.deletePolarisReportingSecrets(); err != nil {
+			return err
+		}
+	}
+	if err := c.helmClient.Delete(releaseName, c.options.Namespace); err != nil {
+		return fmt.Errorf("failed to delete %s resources: %+v", product, err)
+	}
+	if err := c.deleteRollbackSecrets(releaseName, 0); err != nil {
+		return err
+	}
+	if product == BDBA {
+		return c.DeleteHorizontalPodAutoscalers(ctx, releaseName)
+	}
+	return nil
+}
+
+// deleteAlert deletes an Alert instance and its secrets, exposed service, ingress and persistent volume claims
+func (c *Client) deleteAlert(ctx context.Context, name string, releaseName string) error {
+	namespace := c.options.Namespace
+	helmRelease, err := c.helmClient.Get(releaseName, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get Alert values: unable to find instance '%s' in namespace %s", name, namespace)
+	}
+	for _, key := range []string{"webserverCustomCertificatesSecretName", "javaKeystoreSecretName"} {
+		if secretName, ok := helmRelease.Config[key].(string); ok {
+			if err := util.DeleteSecret(c.kubeClient, namespace, secretName); err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("couldn't delete secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+			}
+		}
+	}
+
+	if err := c.helmClient.Delete(releaseName, namespace); err != nil {
+		return fmt.Errorf("failed to delete Alert resources: %+v", err)
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	if err := c.deleteRollbackSecrets(releaseName, 0); err != nil {
+		return err
+	}
+
+	labelSelector := fmt.Sprintf("app=%s, name=%s", util.AlertName, releaseName)
+	if err := c.deleteExposedServices(labelSelector); err != nil {
+		return err
+	}
+	if err := util.DeleteIngressIfExists(c.kubeClient, namespace, util.GetResourceName(name, util.AlertName, "ingress")); err != nil {
+		return fmt.Errorf("couldn't delete the Alert ingress in namespace '%s' due to %+v", namespace, err)
+	}
+	return c.deletePVCs(labelSelector)
+}
+
+// deleteBlackDuck deletes a Black Duck instance and its secrets, exposed service, ingress, route, horizontal pod autoscalers
+// and persistent volume claims
+func (c *Client) deleteBlackDuck(ctx context.Context, name string) error {
+	namespace := c.options.Namespace
+	if err := c.helmClient.Delete(name, namespace); err != nil {
+		return fmt.Errorf("failed to delete Blackduck resources: %+v", err)
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	if err := c.deleteRollbackSecrets(name, 0); err != nil {
+		return err
+	}
+
+	for _, v := range []string{"webserver-certificate", "proxy-certificate", "auth-custom-ca"} {
+		if err := util.DeleteSecret(c.kubeClient, namespace, fmt.Sprintf("%s-%s-%s", name, util.BlackDuckName, v)); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete secret '%s' in namespace '%s' due to %+v", v, namespace, err)
+		}
+	}
+
+	labelSelector := fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, name)
+	if err := c.deleteExposedServices(labelSelector); err != nil {
+		return err
+	}
+	if err := util.DeleteIngressIfExists(c.kubeClient, namespace, util.GetResourceName(name, util.BlackDuckName, "webserver-ingress")); err != nil {
+		return fmt.Errorf("couldn't delete the Black Duck webserver ingress in namespace '%s' due to %+v", namespace, err)
+	}
+	if err := c.DeleteHorizontalPodAutoscalers(ctx, name); err != nil {
+		return err
+	}
+	if err := c.deletePVCs(labelSelector); err != nil {
+		return err
+	}
+
+	if util.IsOpenshift(c.kubeClient) {
+		routeName := util.GetResourceName(name, util.BlackDuckName, "")
+		routeClient := util.GetRouteClient(c.restConfig, c.kubeClient, namespace)
+		if _, err := util.GetRoute(routeClient, namespace, routeName); err == nil {
+			if err := util.DeleteRoute(routeClient, namespace, routeName); err != nil {
+				return fmt.Errorf("unable to delete Black Duck webserver route due to %+v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// deletePolarisReportingSecrets deletes the GCP service account secrets of Polaris Reporting
+func (c *Client) deletePolarisReportingSecrets() error {
+	namespace := c.options.Namespace
+	gcpServiceAccountSecrets, err := polarisreporting.GetPolarisReportingSecrets(namespace, "EMPTY_DATA")
+	if err != nil {
+		return fmt.Errorf("failed to generate GCP Service Account Secrets: %+v", err)
+	}
+	for _, object := range gcpServiceAccountSecrets {
+		secret, ok := object.(*corev1.Secret)
+		if !ok {
+			continue
+		}
+		if err := util.DeleteSecret(c.kubeClient, namespace, secret.Name); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete the gcpServiceAccount Secrets: %+v", err)
+		}
+	}
+	return nil
+}
+
+// deleteExposedServices deletes the exposed services that match the label selector
+func (c *Client) deleteExposedServices(labelSelector string) error {
+	namespace := c.options.Namespace
+	svcs, err := util.ListServices(c.kubeClient, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("couldn't list services in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, svc := range svcs.Items {
+		if strings.HasSuffix(svc.Name, "-exposed") {
+			if err := util.DeleteService(c.kubeClient, namespace, svc.Name); err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("couldn't delete service '%s' in namespace '%s' due to %+v", svc.Name, namespace, err)
+			}
+		}
+	}
+	return nil
+}
+
+// deletePVCs deletes the persistent volume claims that match the label selector
+func (c *Client) deletePVCs(labelSelector string) error {
+	namespace := c.options.Namespace
+	pvcs, err := util.ListPVCs(c.kubeClient, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("couldn't list pvc in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, pvc := range pvcs.Items {
+		if err := util.DeletePVC(c.kubeClient, namespace, pvc.Name); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete pvc '%s' in namespace '%s' due to %+v", pvc.Name, namespace, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// InstanceValues are the settings of a Polaris, Polaris Reporting or BDBA instance
+type InstanceValues struct {
+	// Version is the version of the chart in the chart repository, it isn't used if ChartURL is set
+	Version string
+	// ChartURL is the URL or the path of the chart
+	ChartURL string
+	// Values are the Helm values of the chart
+	Values map[string]interface{}
+	// Secrets are created or updated before the chart is installed, e.g. the GCP service account secrets of Polaris Reporting
+	Secrets []corev1.Secret
+	// Certificate is a cert-manager Certificate that is created before the chart is installed
+	Certificate *util.CertManagerCertificate
+}
+
+// getSingleInstanceReleaseName returns the name of the release of a product that has a single instance per namespace
+func getSingleInstanceReleaseName(product string) (string, error) {
+	if p, ok := products[product]; ok && p.takesName {
+		return "", fmt.Errorf("%s instances are managed with their own methods of the client", product)
+	}
+	return GetReleaseName(product, "")
+}
+
+// CreateInstance installs the Polaris, Polaris Reporting or BDBA instance of the namespace, then autoscales its
+// components as set in the values
+func (c *Client) CreateInstance(ctx context.Context, product string, values InstanceValues) error {
+	namespace := c.options.Namespace
+	releaseName, err := getSingleInstanceReleaseName(product)
+	if err != nil {
+		return err
+	}
+	chartURL, err := GetChartURL(product, c.options.ChartRepository, values.Version, values.ChartURL)
+	if err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Check Dry Run before deploying any resources
+	if err := c.helmClient.Create(releaseName, namespace, chartURL, values.Values, true); err != nil {
+		return fmt.Errorf("failed to create %s resources: %+v", product, err)
+	}
+
+	if err := c.createSecrets(values.Secrets, true); err != nil {
+		return err
+	}
+	if err := c.deployCertificate(ctx, values.Certificate); err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	if err := c.helmClient.Create(releaseName, namespace, chartURL, values.Values, false); err != nil {
+		return fmt.Errorf("failed to create %s resources: %+v", product, err)
+	}
+
+	return c.UpdateHorizontalPodAutoscalers(ctx, releaseName, values.Values)
+}
+
+// UpdateInstance upgrades the Polaris, Polaris Reporting or BDBA instance of the namespace to the chart and values,
+// which replace the values of the previous revision. The secrets are saved first so that they are restored if the
+// release is rolled back
+func (c *Client) UpdateInstance(ctx context.Context, product string, values InstanceValues) error {
+	namespace := c.options.Namespace
+	releaseName, err := getSingleInstanceReleaseName(product)
+	if err != nil {
+		return err
+	}
+	chartURL, err := GetChartURL(product, c.options.ChartRepository, values.Version, values.ChartURL)
+	if err != nil {
+		return err
+	}
+	helmRelease, err := c.helmClient.Get(releaseName, namespace)
+	if err != nil {
+		return fmt.Errorf("unable to find the %s instance in namespace %s", product, namespace)
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Save the secrets that will be overwritten so that they can be restored on rollback
+	if err := c.saveRollbackSecrets(releaseName, helmRelease.Version, getSecretNames(values.Secrets)); err != nil {
+		return err
+	}
+	if err := c.createSecrets(values.Secrets, true); err != nil {
+		return err
+	}
+	if err := c.deployCertificate(ctx, values.Certificate); err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	if err := c.helmClient.Update(releaseName, namespace, chartURL, values.Values); err != nil {
+		return c.rollbackFailedUpdate(releaseName, helmRelease.Version, fmt.Errorf("failed to update %s resources due to %+v", product, err))
+	}
+
+	return c.UpdateHorizontalPodAutoscalers(ctx, releaseName, values.Values)
+}
+
+// UpdateValues sets the values in the release of an instance and keeps the rest of its values, e.g. to stop or start it.
+// The release keeps the chart it was installed with unless chartURL is set
+func (c *Client) UpdateValues(ctx context.Context, product string, name string, chartURL string, values map[string]interface{}) error {
+	namespace := c.options.Namespace
+	releaseName, err := GetReleaseName(product, name)
+	if err != nil {
+		return err
+	}
+	helmRelease, err := c.helmClient.Get(releaseName, namespace)
+	if err != nil {
+		return fmt.Errorf("couldn't find instance %s in namespace %s", name, namespace)
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// The upgrade resets the values, so the values are merged with the values of the previous revision
+	mergedValues := chartutil.CoalesceTables(util.CopyHelmValues(values), util.CopyHelmValues(helmRelease.Config))
+	if len(chartURL) > 0 {
+		err = c.helmClient.Update(releaseName, namespace, chartURL, mergedValues)
+	} else {
+		err = c.helmClient.UpdateValues(releaseName, namespace, mergedValues)
+	}
+	if err != nil {
+		return c.rollbackFailedUpdate(releaseName, helmRelease.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CertificateTimeout is how long to wait for cert-manager to issue the certificate of an instance
+var CertificateTimeout = 5 * time.Minute
+
+// createSecrets creates the secrets in the namespace of the client. Existing secrets are updated if update is true,
+// otherwise they are left as they are
+func (c *Client) createSecrets(secrets []corev1.Secret, update bool) error {
+	namespace := c.options.Namespace
+	for _, v := range secrets {
+		secret := v
+		if _, err := c.kubeClient.CoreV1().Secrets(namespace).Create(&secret); err != nil {
+			if !k8serrors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create secret '%s' in namespace '%s' due to %+v", secret.Name, namespace, err)
+			}
+			if !update {
+				continue
+			}
+			existingSecret, err := util.GetSecret(c.kubeClient, namespace, secret.Name)
+			if err != nil {
+				return fmt.Errorf("couldn't get secret '%s' in namespace '%s' due to %+v", secret.Name, namespace, err)
+			}
+			existingSecret.Data = secret.Data
+			existingSecret.StringData = secret.StringData
+			if _, err := util.UpdateSecret(c.kubeClient, namespace, existingSecret); err != nil {
+				return fmt.Errorf("failed to update secret '%s' in namespace '%s' due to %+v", secret.Name, namespace, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getSecretNames returns the names of the secrets
+func getSecretNames(secrets []corev1.Secret) []string {
+	names := []string{}
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+	return names
+}
+
+// deployCertificate creates the cert-manager Certificate, waits until its secret is issued and copies the certificate and key
+// of the issued secret to the secret that the chart reads them from
+func (c *Client) deployCertificate(ctx context.Context, certificate *util.CertManagerCertificate) error {
+	if certificate == nil {
+		return nil
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	if _, err := util.CreateOrUpdateCertManagerCertificate(c.restConfig, certificate.Certificate); err != nil {
+		return fmt.Errorf("failed to create cert-manager certificate '%s' due to %+v", certificate.Certificate.GetName(), err)
+	}
+	secretName, _, _ := unstructured.NestedString(certificate.Certificate.Object, "spec", "secretName")
+	log.Infof("waiting for cert-manager to issue certificate '%s' in namespace '%s'...", certificate.Certificate.GetName(), certificate.Certificate.GetNamespace())
+	timeout := CertificateTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	issued, err := util.WaitForCertificateSecret(c.kubeClient, certificate.Certificate.GetNamespace(), secretName, timeout)
+	if err != nil {
+		return err
+	}
+	log.Infof("cert-manager issued certificate '%s'", certificate.Certificate.GetName())
+	if len(certificate.CopySecretName) == 0 {
+		return nil
+	}
+	return c.createSecrets([]corev1.Secret{*certificate.GetSecretCopy(issued)}, true)
+}
+
+// deleteCertificates deletes the cert-manager Certificates that match the label selector and the secrets that cert-manager issued for them
+func (c *Client) deleteCertificates(labelSelector string) error {
+	// cert-manager isn't simulated, so the simulated cluster never has Certificates
+	if c.options.Simulation != nil {
+		return nil
+	}
+	namespace := c.options.Namespace
+	certificates, err := util.ListCertManagerCertificates(c.restConfig, namespace, labelSelector)
+	if err != nil {
+		return fmt.Errorf("couldn't list cert-manager certificates in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, certificate := range certificates {
+		if err := util.DeleteCertManagerCertificate(c.restConfig, namespace, certificate.GetName()); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete cert-manager certificate '%s' in namespace '%s' due to %+v", certificate.GetName(), namespace, err)
+		}
+		// cert-manager leaves the secret of a deleted Certificate in place
+		if secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName"); len(secretName) > 0 {
+			if err := util.DeleteSecret(c.kubeClient, namespace, secretName); err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("couldn't delete secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getSizeExtraFiles returns the values file of the size in the Helm values, e.g. small.yaml
+func getSizeExtraFiles(helmValues map[string]interface{}) []string {
+	var extraFiles []string
+	if size, ok := helmValues["size"].(string); ok && len(size) > 0 {
+		extraFiles = append(extraFiles, fmt.Sprintf("%s.yaml", size))
+	}
+	return extraFiles
+}
@@ -0,0 +1,196 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rollbackSecretOriginalNameAnnotation stores the name of the secret that a rollback secret is a copy of
+const rollbackSecretOriginalNameAnnotation = "synopsys.com/rollback.secret"
+
+// rollbackSecretRevisions is the number of the latest revisions of a release whose secrets are kept for rollbacks,
+// which is the default history of helm upgrade
+const rollbackSecretRevisions = 10
+
+// Rollback rolls back the release of an instance to the revision and restores the secrets that were saved before the
+// revision was upgraded. If the revision is 0, the release is rolled back to the previous revision
+func (c *Client) Rollback(ctx context.Context, product string, name string, revision int) error {
+	releaseName, err := GetReleaseName(product, name)
+	if err != nil {
+		return err
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	return c.rollbackRelease(releaseName, revision)
+}
+
+// rollbackRelease rolls back the release to the revision and restores the secrets that were saved for it
+func (c *Client) rollbackRelease(releaseName string, revision int) error {
+	namespace := c.options.Namespace
+	if revision == 0 {
+		helmRelease, err := c.helmClient.Get(releaseName, namespace)
+		if err != nil {
+			return err
+		}
+		if helmRelease.Version <= 1 {
+			return fmt.Errorf("release '%s' in namespace '%s' doesn't have a previous revision", releaseName, namespace)
+		}
+		revision = helmRelease.Version - 1
+	}
+	if err := c.helmClient.Rollback(releaseName, namespace, revision); err != nil {
+		return fmt.Errorf("failed to roll back '%s' in namespace '%s' to revision %d due to %+v", releaseName, namespace, revision, err)
+	}
+	return c.restoreRollbackSecrets(releaseName, revision)
+}
+
+// RollbackFailedUpdate rolls back the release of an instance to the previous revision after a failed update, unless
+// rollbacks are disabled in the options. It returns an error that describes the failed update and the rollback
+func (c *Client) RollbackFailedUpdate(product string, name string, previousRevision int, updateErr error) error {
+	releaseName, err := GetReleaseName(product, name)
+	if err != nil {
+		return err
+	}
+	return c.rollbackFailedUpdate(releaseName, previousRevision, updateErr)
+}
+
+// rollbackFailedUpdate rolls back the release to the previous revision after a failed update
+func (c *Client) rollbackFailedUpdate(releaseName string, previousRevision int, updateErr error) error {
+	namespace := c.options.Namespace
+	if c.options.DisableRollback {
+		return fmt.Errorf("update of '%s' in namespace '%s' failed: %+v", releaseName, namespace, updateErr)
+	}
+	log.Warnf("update of '%s' in namespace '%s' failed, rolling back to revision %d: %+v", releaseName, namespace, previousRevision, updateErr)
+	if err := c.rollbackRelease(releaseName, previousRevision); err != nil {
+		return fmt.Errorf("update of '%s' in namespace '%s' failed: %+v; rollback failed: %+v", releaseName, namespace, updateErr, err)
+	}
+	return fmt.Errorf("update of '%s' in namespace '%s' failed and was rolled back to revision %d: %+v", releaseName, namespace, previousRevision, updateErr)
+}
+
+// getRollbackSecretLabelSelector returns the label selector of the secrets saved for a revision of the release
+func getRollbackSecretLabelSelector(releaseName string, revision int) string {
+	return fmt.Sprintf("component=rollback-secret,release=%s,revision=%d", releaseName, revision)
+}
+
+// getRollbackSecretName returns the name of the copy of a secret saved for a revision of the release. The name
+// includes the release name because secrets such as alert-custom-certificate can be shared by several releases
+func getRollbackSecretName(releaseName string, secretName string, revision int) string {
+	if !strings.HasPrefix(secretName, fmt.Sprintf("%s-", releaseName)) {
+		secretName = fmt.Sprintf("%s-%s", releaseName, secretName)
+	}
+	return fmt.Sprintf("%s-revision-%d", secretName, revision)
+}
+
+// saveRollbackSecrets stores a copy of the existing secrets so that they can be restored when the release is rolled back to the revision
+func (c *Client) saveRollbackSecrets(releaseName string, revision int, secretNames []string) error {
+	namespace := c.options.Namespace
+	for _, secretName := range secretNames {
+		secret, err := util.GetSecret(c.kubeClient, namespace, secretName)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("couldn't get secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+		}
+		rollbackSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      getRollbackSecretName(releaseName, secretName, revision),
+				Namespace: namespace,
+				Labels: map[string]string{
+					"component": "rollback-secret",
+					"release":   releaseName,
+					"revision":  fmt.Sprintf("%d", revision),
+				},
+				Annotations: map[string]string{rollbackSecretOriginalNameAnnotation: secretName},
+			},
+			Data: secret.Data,
+			Type: secret.Type,
+		}
+		if _, err := c.kubeClient.CoreV1().Secrets(namespace).Create(rollbackSecret); err != nil {
+			if !k8serrors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to save secret '%s' in namespace '%s' for rollback due to %+v", secretName, namespace, err)
+			}
+			if _, err := util.UpdateSecret(c.kubeClient, namespace, rollbackSecret); err != nil {
+				return fmt.Errorf("failed to save secret '%s' in namespace '%s' for rollback due to %+v", secretName, namespace, err)
+			}
+		}
+	}
+	if revision <= rollbackSecretRevisions {
+		return nil
+	}
+	return c.deleteRollbackSecrets(releaseName, revision-rollbackSecretRevisions+1)
+}
+
+// deleteRollbackSecrets deletes the secrets saved for the revisions of the release before the revision. All of the
+// secrets of the release are deleted if the revision is 0
+func (c *Client) deleteRollbackSecrets(releaseName string, beforeRevision int) error {
+	namespace := c.options.Namespace
+	rollbackSecrets, err := util.ListSecrets(c.kubeClient, namespace, fmt.Sprintf("component=rollback-secret,release=%s", releaseName))
+	if err != nil {
+		return fmt.Errorf("couldn't list the rollback secrets in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, rollbackSecret := range rollbackSecrets.Items {
+		if revision, err := strconv.Atoi(rollbackSecret.Labels["revision"]); beforeRevision > 0 && err == nil && revision >= beforeRevision {
+			continue
+		}
+		if err := util.DeleteSecret(c.kubeClient, namespace, rollbackSecret.Name); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("couldn't delete rollback secret '%s' in namespace '%s' due to %+v", rollbackSecret.Name, namespace, err)
+		}
+	}
+	return nil
+}
+
+// restoreRollbackSecrets restores the secrets that were saved for the revision of the release
+func (c *Client) restoreRollbackSecrets(releaseName string, revision int) error {
+	namespace := c.options.Namespace
+	rollbackSecrets, err := util.ListSecrets(c.kubeClient, namespace, getRollbackSecretLabelSelector(releaseName, revision))
+	if err != nil {
+		return fmt.Errorf("couldn't list the rollback secrets in namespace '%s' due to %+v", namespace, err)
+	}
+	for _, rollbackSecret := range rollbackSecrets.Items {
+		secretName := rollbackSecret.Annotations[rollbackSecretOriginalNameAnnotation]
+		if len(secretName) == 0 {
+			continue
+		}
+		secret, err := util.GetSecret(c.kubeClient, namespace, secretName)
+		if err != nil {
+			return fmt.Errorf("couldn't get secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+		}
+		secret.Data = rollbackSecret.Data
+		secret.StringData = nil
+		if _, err := util.UpdateSecret(c.kubeClient, namespace, secret); err != nil {
+			return fmt.Errorf("failed to restore secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+		}
+		log.Infof("restored secret '%s' in namespace '%s'", secretName, namespace)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Scale sets the replicas of the components of an instance. The replicas are set in the Helm values where the chart
+// parameterizes them, and the Deployments and StatefulSets of the other components are patched
+func (c *Client) Scale(ctx context.Context, product string, name string, componentReplicas map[string]int) error {
+	namespace := c.options.Namespace
+	releaseName, err := GetReleaseName(product, name)
+	if err != nil {
+		return err
+	}
+	helmRelease, err := c.helmClient.Get(releaseName, namespace)
+	if err != nil {
+		return fmt.Errorf("couldn't find instance %s in namespace %s", name, namespace)
+	}
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		return fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+
+	// Validate the components against the rendered chart
+	componentWorkloads := util.GetComponentWorkloads(resources, releaseName)
+	validComponents := []string{}
+	for component := range componentWorkloads {
+		validComponents = append(validComponents, component)
+	}
+	sort.Strings(validComponents)
+	autoscaleConfigs := util.GetAutoscaleConfigsFromHelmValues(helmRelease.Config)
+	components := []string{}
+	for component := range componentReplicas {
+		if _, ok := componentWorkloads[component]; !ok {
+			return fmt.Errorf("'%s' is not a component of %s '%s', must be one of [%s]", component, product, name, strings.Join(validComponents, "|"))
+		}
+		if _, ok := autoscaleConfigs[component]; ok {
+			return fmt.Errorf("component '%s' of %s '%s' is autoscaled, change its autoscaling with 'synopsysctl update %s --autoscale %s=MIN:MAX:CPU_PERCENT|off' instead", component, product, name, product, component)
+		}
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Set the replicas through the Helm values where the chart supports it
+	helmValuesMap := make(map[string]interface{})
+	var chartValues map[string]interface{}
+	if helmRelease.Chart != nil {
+		chartValues = helmRelease.Chart.Values
+	}
+	helmComponents := []string{}
+	patchComponents := []string{}
+	for _, component := range components {
+		if path := util.GetComponentReplicasValuePath(chartValues, helmRelease.Config, component); path != nil {
+			util.SetHelmValueInMap(helmValuesMap, path, componentReplicas[component])
+			helmComponents = append(helmComponents, component)
+		} else {
+			patchComponents = append(patchComponents, component)
+		}
+	}
+	if len(helmComponents) > 0 {
+		if err := c.UpdateValues(ctx, product, name, "", helmValuesMap); err != nil {
+			return fmt.Errorf("failed to update the replicas of %s '%s' due to %+v", product, name, err)
+		}
+		// The values file of the size is merged over the values by the next update of a Black Duck instance
+		sizeValues, err := getSizeValues(helmRelease)
+		if err != nil {
+			return err
+		}
+		for _, component := range helmComponents {
+			if util.GetHelmValueFromMap(sizeValues, []string{component, "replicas"}) != nil {
+				log.Warnf("scaled component '%s' to %d replicas; they will be reset to the replicas of size '%s' by the next update", component, componentReplicas[component], helmRelease.Config["size"])
+			} else {
+				log.Infof("scaled component '%s' to %d replicas", component, componentReplicas[component])
+			}
+		}
+	}
+
+	// Patch the workloads of the components that the chart doesn't parameterize
+	for _, component := range patchComponents {
+		replicas := util.IntToInt32(componentReplicas[component])
+		for _, workload := range componentWorkloads[component] {
+			if err := c.ScaleWorkload(workload, replicas); err != nil {
+				return err
+			}
+		}
+		log.Warnf("scaled component '%s' to %d replicas; the chart doesn't parameterize its replicas so they will be reset by the next update", component, *replicas)
+	}
+	return nil
+}
+
+// getSizeValues returns the values of the values file of the size of a release, which UpdateBlackDuck merges over the
+// values of the release. They are empty if the release doesn't have a size
+func getSizeValues(helmRelease *release.Release) (map[string]interface{}, error) {
+	sizeValues := make(map[string]interface{})
+	extraFiles := getSizeExtraFiles(helmRelease.Config)
+	if len(extraFiles) == 0 || helmRelease.Chart == nil {
+		return sizeValues, nil
+	}
+	for _, chartFile := range helmRelease.Chart.Files {
+		if chartFile.Name == extraFiles[0] {
+			if err := yaml.Unmarshal(chartFile.Data, &sizeValues); err != nil {
+				return nil, fmt.Errorf("failed to read %s of the chart of '%s' due to %+v", chartFile.Name, helmRelease.Name, err)
+			}
+		}
+	}
+	return sizeValues, nil
+}
+
+// ScaleWorkload sets the replicas of a Deployment or StatefulSet
+func (c *Client) ScaleWorkload(workload util.ComponentWorkload, replicas *int32) error {
+	namespace := c.options.Namespace
+	switch workload.Kind {
+	case "Deployment":
+		deployment, err := util.GetDeployment(c.kubeClient, namespace, workload.Name)
+		if err != nil {
+			return fmt.Errorf("couldn't get deployment '%s' in namespace '%s' due to %+v", workload.Name, namespace, err)
+		}
+		if _, err := util.PatchDeploymentForReplicas(c.kubeClient, deployment, replicas); err != nil {
+			return fmt.Errorf("couldn't scale deployment '%s' in namespace '%s' due to %+v", workload.Name, namespace, err)
+		}
+	case "StatefulSet":
+		statefulSet, err := util.GetStatefulSet(c.kubeClient, namespace, workload.Name)
+		if err != nil {
+			return fmt.Errorf("couldn't get stateful set '%s' in namespace '%s' due to %+v", workload.Name, namespace, err)
+		}
+		statefulSet.Spec.Replicas = replicas
+		if _, err := util.UpdateStatefulSet(c.kubeClient, namespace, statefulSet); err != nil {
+			return fmt.Errorf("couldn't scale stateful set '%s' in namespace '%s' due to %+v", workload.Name, namespace, err)
+		}
+	}
+	return nil
+}
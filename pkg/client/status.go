@@ -0,0 +1,228 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// InstanceStatus is the health of an instance of a Synopsys product
+type InstanceStatus struct {
+	Name          string            `json:"name"`
+	Namespace     string            `json:"namespace"`
+	Product       string            `json:"product"`
+	Version       string            `json:"version"`
+	Revision      int               `json:"revision"`
+	ReleaseStatus string            `json:"releaseStatus"`
+	Healthy       bool              `json:"healthy"`
+	Components    []ComponentStatus `json:"components"`
+}
+
+// ComponentStatus is the health of a Kubernetes resource of an instance
+type ComponentStatus struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Ready  bool   `json:"ready"`
+}
+
+// Status returns the health of an instance of a product, or of all the instances of the product in the namespace if the
+// name is empty. The pods, persistent volume claims and service endpoints of the release are checked
+func (c *Client) Status(ctx context.Context, product string, name string) ([]*InstanceStatus, error) {
+	namespace := c.options.Namespace
+	if _, ok := products[product]; !ok {
+		return nil, fmt.Errorf("'%s' is an invalid product, must be one of [%s|%s|%s|%s|%s]", product, BlackDuck, Alert, BDBA, Polaris, PolarisReporting)
+	}
+	releases := []*release.Release{}
+	if len(name) > 0 || !products[product].takesName {
+		releaseName, err := GetReleaseName(product, name)
+		if err != nil {
+			return nil, err
+		}
+		helmRelease, err := c.helmClient.Get(releaseName, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get release: unable to find instance '%s' in namespace %s", GetInstanceName(product, releaseName), namespace)
+		}
+		releases = append(releases, helmRelease)
+	} else {
+		helmReleases, err := c.helmClient.List(namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, helmRelease := range helmReleases {
+			if IsProductRelease(product, helmRelease) {
+				releases = append(releases, helmRelease)
+			}
+		}
+		if len(releases) == 0 {
+			return nil, fmt.Errorf("no instances found in namespace '%s'", namespace)
+		}
+	}
+
+	statuses := []*InstanceStatus{}
+	for _, helmRelease := range releases {
+		if err := checkContext(ctx); err != nil {
+			return nil, err
+		}
+		status, err := c.getInstanceStatus(product, GetInstanceName(product, helmRelease.Name), helmRelease)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// IsProductRelease returns true if the release is an instance of the product
+func IsProductRelease(product string, helmRelease *release.Release) bool {
+	switch product {
+	case Alert:
+		return strings.HasSuffix(helmRelease.Name, AlertReleaseSuffix)
+	case BlackDuck:
+		return helmRelease.Chart != nil && helmRelease.Chart.Metadata != nil && helmRelease.Chart.Metadata.Name == util.BlackDuckName
+	}
+	return helmRelease.Name == product
+}
+
+// GetInstanceName returns the name of the instance of a product from the name of its release
+func GetInstanceName(product string, releaseName string) string {
+	if product == Alert {
+		return strings.TrimSuffix(releaseName, AlertReleaseSuffix)
+	}
+	return releaseName
+}
+
+// getInstanceStatus checks the health of the pods, persistent volume claims and service endpoints of the release
+func (c *Client) getInstanceStatus(product string, name string, helmRelease *release.Release) (*InstanceStatus, error) {
+	status := &InstanceStatus{
+		Name:       name,
+		Namespace:  helmRelease.Namespace,
+		Product:    product,
+		Revision:   helmRelease.Version,
+		Healthy:    true,
+		Components: []ComponentStatus{},
+	}
+	status.Version = util.GetHelmReleaseVersion(helmRelease)
+	if helmRelease.Info != nil {
+		status.ReleaseStatus = helmRelease.Info.Status.String()
+		status.Healthy = helmRelease.Info.Status == release.StatusDeployed
+	}
+
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", name, helmRelease.Namespace, err)
+	}
+	pvcs, err := util.ListPVCs(c.kubeClient, helmRelease.Namespace, "")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list pvc in namespace '%s' due to %+v", helmRelease.Namespace, err)
+	}
+	pvcPhases := make(map[string]corev1.PersistentVolumeClaimPhase)
+	for _, pvc := range pvcs.Items {
+		pvcPhases[pvc.Name] = pvc.Status.Phase
+	}
+
+	for _, resource := range resources {
+		kind := fmt.Sprintf("%v", resource["kind"])
+		resourceName := fmt.Sprintf("%v", util.GetHelmValueFromMap(resource, []string{"metadata", "name"}))
+		var component *ComponentStatus
+		switch kind {
+		case "Deployment", "StatefulSet", "ReplicationController":
+			component, err = c.getPodsStatus(helmRelease.Namespace, kind, resourceName, util.GetWorkloadLabelSelector(resource))
+		case "PersistentVolumeClaim":
+			phase, ok := pvcPhases[resourceName]
+			if !ok {
+				phase = "Missing"
+			}
+			component = &ComponentStatus{Kind: kind, Name: resourceName, Status: string(phase), Ready: phase == corev1.ClaimBound}
+		case "Service":
+			// services without a selector don't have endpoints managed by Kubernetes
+			if util.GetHelmValueFromMap(resource, []string{"spec", "selector"}) == nil {
+				continue
+			}
+			component = c.getServiceEndpointStatus(helmRelease.Namespace, resourceName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if component == nil {
+			continue
+		}
+		if !component.Ready {
+			status.Healthy = false
+		}
+		status.Components = append(status.Components, *component)
+	}
+	return status, nil
+}
+
+// getPodsStatus returns the readiness of the pods that match the label selector of a workload
+func (c *Client) getPodsStatus(namespace string, kind string, name string, labelSelector string) (*ComponentStatus, error) {
+	if len(labelSelector) == 0 {
+		return nil, nil
+	}
+	pods, err := util.ListPodsWithLabels(c.kubeClient, namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list pods of %s '%s' in namespace '%s' due to %+v", kind, name, namespace, err)
+	}
+	readyPods := 0
+	for _, pod := range pods.Items {
+		if isPodReady(pod) {
+			readyPods++
+		}
+	}
+	ready := len(pods.Items) > 0 && readyPods == len(pods.Items)
+	return &ComponentStatus{Kind: kind, Name: name, Status: fmt.Sprintf("%d/%d pods ready", readyPods, len(pods.Items)), Ready: ready}, nil
+}
+
+// isPodReady returns true if the Ready condition of the pod is true
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// getServiceEndpointStatus returns whether the service has ready endpoints. The service and its endpoints are read once,
+// so a service without endpoints is reported as not ready instead of being waited for
+func (c *Client) getServiceEndpointStatus(namespace string, name string) *ComponentStatus {
+	if _, err := util.GetService(c.kubeClient, namespace, name); err != nil {
+		return &ComponentStatus{Kind: "Service", Name: name, Status: "Missing", Ready: false}
+	}
+	endpoint, err := util.GetServiceEndPoint(c.kubeClient, namespace, name)
+	if err != nil {
+		return &ComponentStatus{Kind: "Service", Name: name, Status: "no endpoints", Ready: false}
+	}
+	readyAddresses, notReadyAddresses := 0, 0
+	for _, subset := range endpoint.Subsets {
+		readyAddresses += len(subset.Addresses)
+		notReadyAddresses += len(subset.NotReadyAddresses)
+	}
+	return &ComponentStatus{Kind: "Service", Name: name, Status: fmt.Sprintf("%d/%d endpoints ready", readyAddresses, readyAddresses+notReadyAddresses), Ready: readyAddresses > 0}
+}
@@ -34,9 +34,9 @@ type FakeOpsSights struct {
 	ns   string
 }
 
-var opssightsResource = schema.GroupVersionResource{Group: "synopsys", Version: "v1", Resource: "opssights"}
+var opssightsResource = schema.GroupVersionResource{Group: "synopsys.com", Version: "v1", Resource: "opssights"}
 
-var opssightsKind = schema.GroupVersionKind{Group: "synopsys", Version: "v1", Kind: "OpsSight"}
+var opssightsKind = schema.GroupVersionKind{Group: "synopsys.com", Version: "v1", Kind: "OpsSight"}
 
 // Get takes name of the opsSight, and returns the corresponding opsSight object, and an error if there is any.
 func (c *FakeOpsSights) Get(name string, options v1.GetOptions) (result *opssightv1.OpsSight, err error) {
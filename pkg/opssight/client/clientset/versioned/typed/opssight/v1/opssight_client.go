@@ -29,7 +29,7 @@ type SynopsysV1Interface interface {
 	OpsSightsGetter
 }
 
-// SynopsysV1Client is used to interact with features provided by the synopsys group.
+// SynopsysV1Client is used to interact with features provided by the synopsys.com group.
 type SynopsysV1Client struct {
 	restClient rest.Interface
 }
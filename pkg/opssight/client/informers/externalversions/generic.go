@@ -52,7 +52,7 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=synopsys, Version=v1
+	// Group=synopsys.com, Version=v1
 	case v1.SchemeGroupVersion.WithResource("opssights"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Synopsys().V1().OpsSights().Informer()}, nil
 
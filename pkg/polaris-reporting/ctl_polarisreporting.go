@@ -45,6 +45,7 @@ type FlagTree struct {
 	FQDN                     string
 	GCPServiceAccount        string
 	IngressClass             string
+	CertManagerIssuer        string
 	StorageClass             string
 	ReportStorageSize        string
 	EventstoreSize           string
@@ -97,6 +98,7 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 	cmd.Flags().StringVar(&ctl.flagTree.IngressClass, "ingress-class", "", "Name of ingress class\n")
 
 	if master {
+		cmd.Flags().StringVar(&ctl.flagTree.CertManagerIssuer, "cert-manager-issuer", ctl.flagTree.CertManagerIssuer, "Name of the cert-manager issuer that signs the ingress certificate for the fully qualified domain name [NAME[:Issuer|ClusterIssuer]]\n")
 		cobra.MarkFlagRequired(cmd.Flags(), "fqdn")
 	}
 
@@ -146,11 +148,20 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 		cobra.MarkFlagRequired(cmd.Flags(), "postgres-password")
 	}
 
+	for _, name := range []string{"smtp-password", "postgres-password"} {
+		util.MarkFlagSecret(cmd.Flags(), name)
+	}
+
 	cmd.Flags().SortFlags = false
 }
 
 // CheckValuesFromFlags returns an error if a value set by a flag is invalid
 func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
+	if len(ctl.flagTree.CertManagerIssuer) > 0 {
+		if _, _, err := util.ParseCertManagerIssuer(ctl.flagTree.CertManagerIssuer); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -48,6 +48,7 @@ type FlagTree struct {
 	StorageClass              string
 	GCPServiceAccountFilePath string
 	IngressClass              string
+	CertManagerIssuer         string
 
 	PostgresHost     string
 	PostgresPort     int
@@ -108,6 +109,9 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 	// domain-name specific flags
 	cmd.Flags().StringVar(&ctl.flagTree.IngressClass, "ingress-class", "nginx", "Name of ingress class")
 	cmd.Flags().StringVar(&ctl.flagTree.FQDN, "fqdn", ctl.flagTree.FQDN, "Fully qualified domain name [Example: \"example.polaris.synopsys.com\"]\n")
+	if master {
+		cmd.Flags().StringVar(&ctl.flagTree.CertManagerIssuer, "cert-manager-issuer", ctl.flagTree.CertManagerIssuer, "Name of the cert-manager issuer that signs the ingress certificate for the fully qualified domain name [NAME[:Issuer|ClusterIssuer]]")
+	}
 
 	// license related flags
 	if master {
@@ -159,11 +163,23 @@ func (ctl *HelmValuesFromCobraFlags) AddCobraFlagsToCommand(cmd *cobra.Command,
 	cmd.Flags().BoolVar(&ctl.flagTree.EnableReporting, "enable-reporting", false, "Enable Reporting Platform")
 	cmd.Flags().StringVar(&ctl.flagTree.ReportStorageSize, "reportstorage-size", REPORT_STORAGE_PV_SIZE, "Persistent volume claim size for reportstorage. Only applicable if --enable-reporting is set to true")
 
+	for _, name := range []string{"smtp-password", "postgres-password"} {
+		util.MarkFlagSecret(cmd.Flags(), name)
+	}
+
 	cmd.Flags().SortFlags = false
 }
 
 // CheckValuesFromFlags returns an error if a value set by a flag is invalid
 func (ctl *HelmValuesFromCobraFlags) CheckValuesFromFlags(flagset *pflag.FlagSet) error {
+	if len(ctl.flagTree.CertManagerIssuer) > 0 {
+		if len(ctl.flagTree.FQDN) == 0 {
+			return fmt.Errorf("--fqdn must be set for --cert-manager-issuer")
+		}
+		if _, _, err := util.ParseCertManagerIssuer(ctl.flagTree.CertManagerIssuer); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
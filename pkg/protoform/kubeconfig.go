@@ -22,9 +22,7 @@ under the License.
 package protoform
 
 import (
-	"os"
-	"path/filepath"
-
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" //for auths
@@ -51,14 +49,6 @@ func GetKubeConfig(kubeconfigpath string, insecureSkipTLSVerify bool) (*rest.Con
 	return kubeConfig, nil
 }
 
-// homeDir determines the user's home directory path
-func homeDir() string {
-	if h := os.Getenv("HOME"); h != "" {
-		return h
-	}
-	return os.Getenv("USERPROFILE") // windows
-}
-
 // GetKubeClientSet will return the kube clientset
 func GetKubeClientSet(kubeConfig *rest.Config) (*kubernetes.Clientset, error) {
 	return kubernetes.NewForConfig(kubeConfig)
@@ -66,20 +56,21 @@ func GetKubeClientSet(kubeConfig *rest.Config) (*kubernetes.Clientset, error) {
 
 // GetKubeClientFromOutsideCluster returns the rest config of outside cluster
 func GetKubeClientFromOutsideCluster(kubeconfigpath string, insecureSkipTLSVerify bool) (*rest.Config, error) {
-	// Determine Config Paths
-	if home := homeDir(); len(kubeconfigpath) == 0 && home != "" {
-		kubeconfigpath = filepath.Join(home, ".kube", "config")
-	}
+	return GetKubeClientFromOutsideClusterWithContext(kubeconfigpath, "", insecureSkipTLSVerify)
+}
 
+// GetKubeClientFromOutsideClusterWithContext returns the rest config of outside cluster for a context of the kubeconfig.
+// If the context is empty, the current context is used. If the path is empty, the kubeconfig is loaded from the KUBECONFIG
+// environment variable or the home directory, and the in-cluster config is used if neither has a kubeconfig
+func GetKubeClientFromOutsideClusterWithContext(kubeconfigpath string, kubeContext string, insecureSkipTLSVerify bool) (*rest.Config, error) {
 	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{
-			ExplicitPath: kubeconfigpath,
-		},
+		util.GetKubeConfigLoadingRules(kubeconfigpath),
 		&clientcmd.ConfigOverrides{
 			ClusterInfo: clientcmdapi.Cluster{
 				Server:                "",
 				InsecureSkipTLSVerify: insecureSkipTLSVerify,
 			},
+			CurrentContext: kubeContext,
 		}).ClientConfig()
 	if err != nil {
 		return nil, err
@@ -27,6 +27,7 @@ import (
 	"reflect"
 	"strings"
 
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/yaml"
 
@@ -34,7 +35,9 @@ import (
 	"github.com/blackducksoftware/synopsysctl/pkg/api"
 	opssightapi "github.com/blackducksoftware/synopsysctl/pkg/api/opssight/v1"
 	"github.com/blackducksoftware/synopsysctl/pkg/apps"
+	blackduckclientset "github.com/blackducksoftware/synopsysctl/pkg/blackduck/client/clientset/versioned"
 	"github.com/blackducksoftware/synopsysctl/pkg/opssight"
+	opssightclientset "github.com/blackducksoftware/synopsysctl/pkg/opssight/client/clientset/versioned"
 	"github.com/blackducksoftware/synopsysctl/pkg/protoform"
 	"github.com/blackducksoftware/synopsysctl/pkg/soperator"
 )
@@ -86,7 +89,11 @@ func PrintResource(crd interface{}, format string, printKubeComponents bool) err
 		pc.SelfSetDefaults()
 		pc.DryRun = true
 		opsSight := crd.(opssightapi.OpsSight)
-		sc := opssight.NewSpecConfig(pc, kubeClient, opsSightClient, blackDuckClient, &opsSight, true, pc.DryRun)
+		// the dry run doesn't use the clients, which are nil in native mode and in the simulated cluster
+		kubeClientset, _ := kubeClient.(*kubernetes.Clientset)
+		opsSightClientset, _ := opsSightClient.(*opssightclientset.Clientset)
+		blackDuckClientset, _ := blackDuckClient.(*blackduckclientset.Clientset)
+		sc := opssight.NewSpecConfig(pc, kubeClientset, opsSightClientset, blackDuckClientset, &opsSight, true, pc.DryRun)
 		cList, err = sc.GetComponents()
 		if err != nil {
 			return fmt.Errorf("failed to get components: %s", err)
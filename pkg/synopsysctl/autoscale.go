@@ -0,0 +1,61 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"context"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+)
+
+// crudHorizontalPodAutoscalers creates or updates the HorizontalPodAutoscalers of the autoscale configurations in the Helm values
+// of a release, and deletes those of the components that are no longer autoscaled
+func crudHorizontalPodAutoscalers(releaseName string, helmValues map[string]interface{}) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	return c.UpdateHorizontalPodAutoscalers(context.Background(), releaseName, helmValues)
+}
+
+// printHorizontalPodAutoscalers prints the HorizontalPodAutoscalers of the autoscale configurations in the Helm values
+// for the manifest rendered from the chart
+func printHorizontalPodAutoscalers(releaseName string, chartURL string, helmValues map[string]interface{}, extraFiles ...string) error {
+	if len(util.GetAutoscaleConfigsFromHelmValues(helmValues)) == 0 {
+		return nil
+	}
+	rendered, err := util.RenderWithHelm3(releaseName, namespace, chartURL, helmValues, "", extraFiles...)
+	if err != nil {
+		return err
+	}
+	hpas, err := client.GetHorizontalPodAutoscalers(namespace, releaseName, rendered.Manifest, helmValues)
+	if err != nil {
+		return err
+	}
+	for _, hpa := range hpas {
+		if _, err := PrintComponent(hpa, "YAML"); err != nil { // helm only supports yaml
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/pflag"
+)
+
+// certManagerCertificateNameAnnotation is set by cert-manager on the secrets that it issues
+const certManagerCertificateNameAnnotation = "cert-manager.io/certificate-name"
+
+// getCertManagerCertificate returns the cert-manager Certificate for the instance if --cert-manager-issuer is set,
+// and sets the Helm values so that the instance uses the certificate. Black Duck and Alert read the certificate from
+// other keys than cert-manager writes, so they use a copy of the issued secret
+func getCertManagerCertificate(flagset *pflag.FlagSet, namespace string, product string, name string, helmValuesMap map[string]interface{}) (*util.CertManagerCertificate, error) {
+	issuerFlag := flagset.Lookup("cert-manager-issuer")
+	if issuerFlag == nil || !issuerFlag.Changed {
+		return nil, nil
+	}
+
+	var dnsName, secretName string
+	certificate := &util.CertManagerCertificate{}
+	switch product {
+	case util.BlackDuckName:
+		dnsName = getHelmStringValue(helmValuesMap, "environs", "PUBLIC_HUB_WEBSERVER_HOST")
+		if len(dnsName) == 0 {
+			return nil, fmt.Errorf("--environs PUBLIC_HUB_WEBSERVER_HOST:<fqdn> must be set for --cert-manager-issuer")
+		}
+		secretName = util.GetResourceName(name, util.BlackDuckName, "webserver-cert-manager-tls")
+		certificate.CopySecretName = util.GetResourceName(name, util.BlackDuckName, "webserver-certificate")
+		util.SetHelmValueInMap(helmValuesMap, []string{"tlsCertSecretName"}, certificate.CopySecretName)
+	case util.AlertName:
+		dnsName = getHelmStringValue(helmValuesMap, "environs", "ALERT_HOSTNAME")
+		if len(dnsName) == 0 {
+			return nil, fmt.Errorf("--environs ALERT_HOSTNAME:<fqdn> must be set for --cert-manager-issuer")
+		}
+		secretName = util.GetResourceName(name, util.AlertName, "cert-manager-tls")
+		certificate.CopySecretName = util.GetResourceName(name, util.AlertName, "certificate")
+		util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, certificate.CopySecretName)
+	case bdbaName:
+		dnsName = getHelmStringValue(helmValuesMap, "ingress", "host")
+		secretName = util.GetResourceName(name, "", "ingress-tls")
+		util.SetHelmValueInMap(helmValuesMap, []string{"ingress", "tls", "enabled"}, true)
+		util.SetHelmValueInMap(helmValuesMap, []string{"ingress", "tls", "secretName"}, secretName)
+	case polarisName, polarisReportingName:
+		dnsName = getHelmStringValue(helmValuesMap, "global", "rootDomain")
+		secretName = util.GetResourceName(name, "", "ingress-tls")
+		util.SetHelmValueInMap(helmValuesMap, []string{"ingressTLSSecretName"}, secretName)
+	default:
+		return nil, fmt.Errorf("--cert-manager-issuer is not supported for %s", product)
+	}
+	if len(dnsName) == 0 {
+		return nil, fmt.Errorf("the fully qualified domain name must be set for --cert-manager-issuer")
+	}
+	if len(certificate.CopySecretName) > 0 {
+		certificate.CopyCertificateKey = "WEBSERVER_CUSTOM_CERT_FILE"
+		certificate.CopyKeyKey = "WEBSERVER_CUSTOM_KEY_FILE"
+	}
+
+	var err error
+	certificate.Certificate, err = util.GetCertManagerCertificate(namespace, secretName, secretName, issuerFlag.Value.String(), []string{dnsName}, map[string]string{"app": product, "name": name})
+	if err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+// getHelmStringValue returns the string value at the path in the Helm values, or an empty string if it isn't set
+func getHelmStringValue(helmValuesMap map[string]interface{}, keys ...string) string {
+	if value, ok := util.GetHelmValueFromMap(helmValuesMap, keys).(string); ok {
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,378 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// Airgap Command flags
+var airgapVersion string
+var airgapArchivePath string
+var airgapValuesFilePath string
+var airgapRegistryUsername string
+var airgapRegistryPassword string
+var airgapInsecureRegistry bool
+var airgapRegistrySkipTLSVerify bool
+
+// airgapManifestAPIVersion is the version of the airgap bundle format
+const airgapManifestAPIVersion = "synopsysctl/v1"
+
+// Names of the files inside an airgap bundle
+const (
+	airgapManifestFileName = "airgap.yaml"
+	airgapChartsDirectory  = "charts"
+	airgapImagesDirectory  = "images"
+)
+
+// airgapManifest describes the content of an airgap bundle
+type airgapManifest struct {
+	APIVersion string            `json:"apiVersion"`
+	Product    string            `json:"product"`
+	Version    string            `json:"version"`
+	Chart      string            `json:"chart"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	Images     []util.SavedImage `json:"images"`
+}
+
+// productChart describes the chart of a product in the chart repository
+type productChart struct {
+	// chartURLFormat is the location of the chart in the chart repository for a version
+	chartURLFormat string
+	// releaseName is the Helm release name used to render the chart
+	releaseName string
+}
+
+// productCharts are the products whose charts can be bundled and mirrored
+var productCharts = map[string]productChart{
+	util.BlackDuckName:   {chartURLFormat: "%s/charts/blackduck-%s.tgz", releaseName: "blackduck"},
+	util.AlertName:       {chartURLFormat: "%s/charts/alert-helmchart-%s.tgz", releaseName: fmt.Sprintf("alert%s", AlertPostSuffix)},
+	polarisName:          {chartURLFormat: "%s/charts/polaris-helmchart-%s.tgz", releaseName: polarisName},
+	polarisReportingName: {chartURLFormat: "%s/charts/polaris-helmchart-reporting-%s.tgz", releaseName: polarisReportingName},
+	bdbaName:             {chartURLFormat: "%s/charts/bdba-%s.tgz", releaseName: bdbaName},
+}
+
+// checkProductChartArgs checks that the command has 1 argument that is a product with a chart
+func checkProductChartArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		cmd.Help()
+		return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+	}
+	if _, ok := productCharts[args[0]]; !ok {
+		return fmt.Errorf("unsupported product '%s', must be one of [%s|%s|%s|%s|%s]", args[0], util.BlackDuckName, util.AlertName, polarisName, polarisReportingName, bdbaName)
+	}
+	return nil
+}
+
+// airgapInstallProducts are the products that airgap install can create, because their create commands can point
+// the images to the private registry
+var airgapInstallProducts = []string{util.BlackDuckName, util.AlertName}
+
+// checkAirgapPackArgs checks that the command has 1 argument that is a product that can be installed from a bundle
+func checkAirgapPackArgs(cmd *cobra.Command, args []string) error {
+	if err := checkProductChartArgs(cmd, args); err != nil {
+		return err
+	}
+	for _, product := range airgapInstallProducts {
+		if args[0] == product {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s can't be installed from an airgap bundle, must be one of [%s]", args[0], strings.Join(airgapInstallProducts, "|"))
+}
+
+// getProductChartURL returns the location of the chart of the product version, or the --chart-location-path flag if it is set
+func getProductChartURL(cmd *cobra.Command, product string, version string) string {
+	if chartLocationFlag := cmd.Flag("chart-location-path"); chartLocationFlag != nil && chartLocationFlag.Changed {
+		return chartLocationFlag.Value.String()
+	}
+	return fmt.Sprintf(productCharts[product].chartURLFormat, baseChartRepository, version)
+}
+
+// getProductChartImages renders the chart and returns the images it uses. The values file can enable the optional
+// components so that their images are returned as well
+func getProductChartImages(product string, chartURL string, valuesFilePath string) ([]string, error) {
+	vals := make(map[string]interface{})
+	if len(valuesFilePath) > 0 {
+		data, err := ioutil.ReadFile(valuesFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file '%s' due to %+v", valuesFilePath, err)
+		}
+		if err := yaml.Unmarshal(data, &vals); err != nil {
+			return nil, fmt.Errorf("failed to parse values file '%s' due to %+v", valuesFilePath, err)
+		}
+	}
+	rendered, err := util.RenderWithHelm3(productCharts[product].releaseName, "default", chartURL, vals, kubeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart '%s' due to %+v", chartURL, err)
+	}
+	images, err := util.GetManifestImages(rendered.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the images of chart '%s' due to %+v", chartURL, err)
+	}
+	return images, nil
+}
+
+// airgapCmd bundles Synopsys resources for clusters without internet access
+var airgapCmd = &cobra.Command{
+	Use:   "airgap",
+	Short: "Bundle and install Synopsys resources in a cluster without internet access",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// airgapPackCmd saves the chart and the images of a product in a bundle
+var airgapPackCmd = &cobra.Command{
+	Use:           "pack PRODUCT --version VERSION",
+	Example:       "synopsysctl airgap pack blackduck --version 2020.4.0\nsynopsysctl airgap pack alert --version 5.3.0 --archive-path /tmp/alert.tar.gz --values values.yaml",
+	Short:         fmt.Sprintf("Save the chart and the images of a product in a bundle [%s]", strings.Join(airgapInstallProducts, "|")),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          checkAirgapPackArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archivePath := airgapArchivePath
+		if len(archivePath) == 0 {
+			archivePath = fmt.Sprintf("%s-%s-airgap.tar.gz", args[0], airgapVersion)
+		}
+		if err := packAirgapBundle(args[0], getProductChartURL(cmd, args[0], airgapVersion), archivePath); err != nil {
+			return err
+		}
+		log.Infof("successfully saved %s %s to '%s'", args[0], airgapVersion, archivePath)
+		return nil
+	},
+}
+
+// packAirgapBundle downloads the chart, finds the images of the rendered chart and saves them in an archive
+func packAirgapBundle(product string, chartURL string, archivePath string) error {
+	dir, err := ioutil.TempDir("", "synopsysctl-airgap")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory due to %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// save the chart
+	chartLocation := chartURL
+	if len(util.ChartCacheDirectory) > 0 {
+		if chartLocation, err = util.NewChartCache(util.ChartCacheDirectory, util.ChartKeyring).Resolve(chartURL); err != nil {
+			return err
+		}
+	}
+	var chartBytes []byte
+	if strings.HasPrefix(chartLocation, "http://") || strings.HasPrefix(chartLocation, "https://") {
+		chartBytes, err = util.HTTPGet(chartLocation)
+	} else {
+		chartBytes, err = ioutil.ReadFile(chartLocation)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get chart '%s' due to %+v", chartURL, err)
+	}
+	chartPath := filepath.Join(airgapChartsDirectory, filepath.Base(chartURL))
+	if err := os.MkdirAll(filepath.Join(dir, airgapChartsDirectory), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the chart directory due to %+v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, chartPath), chartBytes, 0600); err != nil {
+		return fmt.Errorf("failed to save chart '%s' due to %+v", chartURL, err)
+	}
+
+	// find the images of the chart
+	images, err := getProductChartImages(product, filepath.Join(dir, chartPath), airgapValuesFilePath)
+	if err != nil {
+		return err
+	}
+
+	// save the images
+	manifest := airgapManifest{
+		APIVersion: airgapManifestAPIVersion,
+		Product:    product,
+		Version:    airgapVersion,
+		Chart:      filepath.ToSlash(chartPath),
+		CreatedAt:  time.Now().UTC(),
+	}
+	client := util.NewRegistryClient(airgapRegistryUsername, airgapRegistryPassword, airgapInsecureRegistry, airgapRegistrySkipTLSVerify)
+	for _, image := range images {
+		log.Infof("saving image '%s'", image)
+		saved, err := client.PullImage(image, filepath.Join(dir, airgapImagesDirectory))
+		if err != nil {
+			return err
+		}
+		manifest.Images = append(manifest.Images, *saved)
+	}
+	manifestBytes, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to convert the airgap manifest to yaml: %+v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, airgapManifestFileName), manifestBytes, 0600); err != nil {
+		return fmt.Errorf("failed to write the airgap manifest due to %+v", err)
+	}
+	return util.WriteTarGzArchiveFromDirectory(archivePath, dir)
+}
+
+// airgapInstallCmd installs a Synopsys resource from a bundle
+var airgapInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Push the images of a bundle to a private registry and create a Synopsys resource from it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// airgapInstallBlackDuckCmd installs a Black Duck instance from a bundle
+var airgapInstallBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --archive-path ARCHIVE --registry REGISTRY",
+	Example:       "synopsysctl airgap install blackduck <name> -n <namespace> --archive-path blackduck-2020.4.0-airgap.tar.gz --registry registry.example.com/blackducksoftware",
+	Short:         "Push the images of a Black Duck bundle to a private registry and create a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return createBlackDuckCmd.Args(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return installAirgapBundle(cmd, args, util.BlackDuckName, createBlackDuckCmd)
+	},
+}
+
+// airgapInstallAlertCmd installs an Alert instance from a bundle
+var airgapInstallAlertCmd = &cobra.Command{
+	Use:           "alert NAME -n NAMESPACE --archive-path ARCHIVE --registry REGISTRY",
+	Example:       "synopsysctl airgap install alert <name> -n <namespace> --archive-path alert-5.3.0-airgap.tar.gz --registry registry.example.com/blackducksoftware",
+	Short:         "Push the images of an Alert bundle to a private registry and create an Alert instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return createAlertCmd.Args(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return installAirgapBundle(cmd, args, util.AlertName, createAlertCmd)
+	},
+}
+
+// installAirgapBundle pushes the images of the bundle to the registry of the --registry flag and runs the create command with the chart of the bundle
+func installAirgapBundle(cmd *cobra.Command, args []string, product string, createCmd *cobra.Command) error {
+	dir, err := ioutil.TempDir("", "synopsysctl-airgap")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory due to %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := util.ExtractTarGzArchive(airgapArchivePath, dir); err != nil {
+		return err
+	}
+	manifest, err := readAirgapManifest(filepath.Join(dir, airgapManifestFileName))
+	if err != nil {
+		return fmt.Errorf("invalid airgap bundle '%s': %+v", airgapArchivePath, err)
+	}
+	if manifest.Product != product {
+		return fmt.Errorf("airgap bundle '%s' contains %s, not %s", airgapArchivePath, manifest.Product, product)
+	}
+
+	registry := cmd.Flag("registry").Value.String()
+	client := util.NewRegistryClient(airgapRegistryUsername, airgapRegistryPassword, airgapInsecureRegistry, airgapRegistrySkipTLSVerify)
+	for i := range manifest.Images {
+		targetImage, err := util.GetRegistryImage(manifest.Images[i].Image, registry)
+		if err != nil {
+			return err
+		}
+		log.Infof("pushing image '%s'", targetImage)
+		if err := client.PushImage(&manifest.Images[i], filepath.Join(dir, airgapImagesDirectory), targetImage); err != nil {
+			return err
+		}
+	}
+
+	if err := cmd.Flags().Set("chart-location-path", filepath.Join(dir, filepath.FromSlash(manifest.Chart))); err != nil {
+		return err
+	}
+	if versionFlag := cmd.Flag("version"); versionFlag != nil && !versionFlag.Changed {
+		if err := cmd.Flags().Set("version", manifest.Version); err != nil {
+			return err
+		}
+	}
+	return createCmd.RunE(cmd, args)
+}
+
+// readAirgapManifest reads and validates the manifest of an airgap bundle
+func readAirgapManifest(path string) (*airgapManifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &airgapManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.APIVersion != airgapManifestAPIVersion {
+		return nil, fmt.Errorf("unsupported apiVersion '%s', must be '%s'", manifest.APIVersion, airgapManifestAPIVersion)
+	}
+	return manifest, nil
+}
+
+// addAirgapRegistryFlags adds the flags to authenticate with the registries
+func addAirgapRegistryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&airgapRegistryUsername, "registry-username", airgapRegistryUsername, "Username of the registry")
+	cmd.Flags().StringVar(&airgapRegistryPassword, "registry-password", airgapRegistryPassword, "Password of the registry")
+	util.MarkFlagSecret(cmd.Flags(), "registry-password")
+	cmd.Flags().BoolVar(&airgapInsecureRegistry, "insecure-registry", airgapInsecureRegistry, "If true, connect to the registry with http instead of https")
+	cmd.Flags().BoolVar(&airgapRegistrySkipTLSVerify, "registry-skip-tls-verify", airgapRegistrySkipTLSVerify, "If true, don't verify the certificate of the registry")
+}
+
+func init() {
+	rootCmd.AddCommand(airgapCmd)
+
+	airgapPackCmd.Flags().StringVar(&airgapVersion, "version", airgapVersion, "Version of the product to bundle")
+	cobra.MarkFlagRequired(airgapPackCmd.Flags(), "version")
+	airgapPackCmd.Flags().StringVar(&airgapArchivePath, "archive-path", airgapArchivePath, "Path of the bundle to create (default <product>-<version>-airgap.tar.gz)")
+	airgapPackCmd.Flags().StringVar(&airgapValuesFilePath, "values", airgapValuesFilePath, "Path of a Helm values file used to render the chart, e.g. to bundle the images of optional components")
+	addAirgapRegistryFlags(airgapPackCmd)
+	addChartLocationPathFlag(airgapPackCmd)
+	airgapCmd.AddCommand(airgapPackCmd)
+
+	// the install commands have the flags of the create commands
+	airgapInstallBlackDuckCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(airgapInstallBlackDuckCmd.Flags(), "namespace")
+	createBlackDuckCobraHelper.AddCRSpecFlagsToCommand(airgapInstallBlackDuckCmd, true)
+	addChartLocationPathFlag(airgapInstallBlackDuckCmd)
+	airgapInstallCmd.AddCommand(airgapInstallBlackDuckCmd)
+
+	airgapInstallAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(airgapInstallAlertCmd.Flags(), "namespace")
+	createAlertCobraHelper.AddCobraFlagsToCommand(airgapInstallAlertCmd, true)
+	addChartLocationPathFlag(airgapInstallAlertCmd)
+	airgapInstallCmd.AddCommand(airgapInstallAlertCmd)
+
+	for _, cmd := range []*cobra.Command{airgapInstallBlackDuckCmd, airgapInstallAlertCmd} {
+		cmd.Flags().StringVar(&airgapArchivePath, "archive-path", airgapArchivePath, "Path of the bundle created with airgap pack")
+		cobra.MarkFlagRequired(cmd.Flags(), "archive-path")
+		cobra.MarkFlagRequired(cmd.Flags(), "registry")
+		addAirgapRegistryFlags(cmd)
+	}
+	airgapCmd.AddCommand(airgapInstallCmd)
+}
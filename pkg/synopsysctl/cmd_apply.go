@@ -0,0 +1,214 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/apply"
+	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Apply Command flags
+var applyFilePath string
+var applyDryRun bool
+
+// applyProducts are the products that can be managed by apply. They generate the values of an instance with the same
+// flags as the create and update commands of the product, but with helpers and flags of their own
+var applyProducts = map[string]apply.Product{
+	util.BlackDuckName: {
+		TakesName: true,
+		AddFlags: func(cmd *cobra.Command, create bool) apply.ValuesGenerator {
+			helper := blackduck.NewHelmValuesFromCobraFlags()
+			helper.AddCRSpecFlagsToCommand(cmd, create)
+			addApplyChartLocationPathFlag(cmd)
+			return func(flagset *pflag.FlagSet, currentValues map[string]interface{}) (map[string]interface{}, error) {
+				if currentValues != nil {
+					helper.SetArgs(currentValues)
+				}
+				return helper.GenerateHelmFlagsFromCobraFlags(flagset)
+			}
+		},
+		RequireCreateFlags: requireBlackDuckCreateFlags,
+		Create: func(ctx context.Context, c *client.Client, instance apply.Instance, flagset *pflag.FlagSet, values map[string]interface{}) error {
+			certificate, err := getCertManagerCertificate(flagset, instance.Namespace, util.BlackDuckName, instance.Name, values)
+			if err != nil {
+				return err
+			}
+			secrets, err := blackduck.GetCertsFromFlagsAndSetHelmValue(instance.Name, instance.Namespace, flagset, values)
+			if err != nil {
+				return err
+			}
+			version, chartURL := getApplyChart(flagset, blackduckChartRepository)
+			return c.CreateBlackDuck(ctx, instance.Name, client.BlackDuckValues{Version: version, ChartURL: chartURL, Values: values, Secrets: secrets, Certificate: certificate})
+		},
+		Update: func(ctx context.Context, c *client.Client, instance apply.Instance, flagset *pflag.FlagSet, values map[string]interface{}, previousRevision int) error {
+			secrets, err := blackduck.GetCertsFromFlagsAndSetHelmValue(instance.Name, instance.Namespace, flagset, values)
+			if err != nil {
+				return err
+			}
+			version, chartURL := getApplyChart(flagset, blackduckChartRepository)
+			if err := c.UpdateBlackDuck(ctx, instance.Name, client.BlackDuckValues{Version: version, ChartURL: chartURL, Values: values, Secrets: secrets}); err != nil {
+				return err
+			}
+			return waitForUpdateOrRollback(c, util.BlackDuckName, instance.Name, previousRevision)
+		},
+	},
+	util.AlertName: {
+		TakesName: true,
+		AddFlags: func(cmd *cobra.Command, create bool) apply.ValuesGenerator {
+			helper := alertctl.NewHelmValuesFromCobraFlags()
+			helper.AddCobraFlagsToCommand(cmd, create)
+			addApplyChartLocationPathFlag(cmd)
+			return func(flagset *pflag.FlagSet, currentValues map[string]interface{}) (map[string]interface{}, error) {
+				if currentValues != nil {
+					helper.SetArgs(currentValues)
+				}
+				return helper.GenerateHelmFlagsFromCobraFlags(flagset)
+			}
+		},
+		Create: func(ctx context.Context, c *client.Client, instance apply.Instance, flagset *pflag.FlagSet, values map[string]interface{}) error {
+			certificate, err := getCertManagerCertificate(flagset, instance.Namespace, util.AlertName, instance.Name, values)
+			if err != nil {
+				return err
+			}
+			secrets, err := alertctl.GetSecretsFromFlagsAndSetHelmValue(instance.Namespace, flagset, values)
+			if err != nil {
+				return err
+			}
+			version, chartURL := getApplyChart(flagset, alertChartRepository)
+			return c.CreateAlert(ctx, instance.Name, client.AlertValues{Version: version, ChartURL: chartURL, Values: values, Secrets: secrets, Certificate: certificate})
+		},
+		Update: func(ctx context.Context, c *client.Client, instance apply.Instance, flagset *pflag.FlagSet, values map[string]interface{}, previousRevision int) error {
+			secrets, err := alertctl.GetSecretsFromFlagsAndSetHelmValue(instance.Namespace, flagset, values)
+			if err != nil {
+				return err
+			}
+			version, chartURL := getApplyChart(flagset, alertChartRepository)
+			if err := c.UpdateAlert(ctx, instance.Name, client.AlertValues{Version: version, ChartURL: chartURL, Values: values, Secrets: secrets}); err != nil {
+				return err
+			}
+			return waitForUpdateOrRollback(c, util.AlertName, instance.Name, previousRevision)
+		},
+	},
+	bdbaName: {
+		AddFlags: func(cmd *cobra.Command, create bool) apply.ValuesGenerator {
+			helper := bdba.NewHelmValuesFromCobraFlags()
+			helper.AddCobraFlagsToCommand(cmd, create)
+			addApplyChartLocationPathFlag(cmd)
+			return func(flagset *pflag.FlagSet, currentValues map[string]interface{}) (map[string]interface{}, error) {
+				if currentValues != nil {
+					helper.SetArgs(currentValues)
+				}
+				return helper.GenerateHelmFlagsFromCobraFlags(flagset)
+			}
+		},
+		Create: func(ctx context.Context, c *client.Client, instance apply.Instance, flagset *pflag.FlagSet, values map[string]interface{}) error {
+			certificate, err := getCertManagerCertificate(flagset, instance.Namespace, bdbaName, bdbaName, values)
+			if err != nil {
+				return err
+			}
+			version, chartURL := getApplyChart(flagset, bdbaChartRepository)
+			return c.CreateInstance(ctx, client.BDBA, client.InstanceValues{Version: version, ChartURL: chartURL, Values: values, Certificate: certificate})
+		},
+		Update: func(ctx context.Context, c *client.Client, instance apply.Instance, flagset *pflag.FlagSet, values map[string]interface{}, previousRevision int) error {
+			version, chartURL := getApplyChart(flagset, bdbaChartRepository)
+			return c.UpdateInstance(ctx, client.BDBA, client.InstanceValues{Version: version, ChartURL: chartURL, Values: values})
+		},
+	},
+}
+
+// applyCmd creates or updates the Synopsys resources listed in a manifest
+var applyCmd = &cobra.Command{
+	Use:           "apply -f FILE",
+	Example:       "synopsysctl apply -f synopsys.yaml\nsynopsysctl apply -f synopsys.yaml --dry-run",
+	Short:         "Create or update the Synopsys resources listed in a manifest file",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		applier := &apply.Applier{
+			Products:        applyProducts,
+			NewClient:       newClientForNamespace,
+			ConfigureCreate: applyProfile,
+			DryRun:          applyDryRun,
+		}
+		data, err := ioutil.ReadFile(applyFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest '%s': %+v", applyFilePath, err)
+		}
+		manifest, err := applier.ParseManifest(data)
+		if err != nil {
+			return fmt.Errorf("invalid manifest '%s': %+v", applyFilePath, err)
+		}
+		for _, instance := range manifest.Instances {
+			if err := applier.Apply(context.Background(), instance); err != nil {
+				return fmt.Errorf("failed to apply %s '%s' in namespace '%s': %+v", instance.Product, instance.Name, instance.Namespace, err)
+			}
+		}
+		return nil
+	},
+}
+
+// addApplyChartLocationPathFlag adds the chart location path flag to the command of an instance without binding it to the one of the commands
+func addApplyChartLocationPathFlag(cmd *cobra.Command) {
+	cmd.Flags().String("chart-location-path", "", "Absolute path to the Helm Chart Tarball")
+}
+
+// getApplyChart returns the version and the chart URL of an instance. The chart of the version is used if only the version is set,
+// and the default chart if neither is set
+func getApplyChart(flagset *pflag.FlagSet, defaultChartURL string) (string, string) {
+	version := ""
+	if versionFlag := flagset.Lookup("version"); versionFlag != nil && versionFlag.Changed {
+		version = versionFlag.Value.String()
+	}
+	if chartLocationFlag := flagset.Lookup("chart-location-path"); chartLocationFlag != nil && chartLocationFlag.Changed {
+		return version, chartLocationFlag.Value.String()
+	}
+	if len(version) > 0 {
+		return version, ""
+	}
+	return version, defaultChartURL
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringVarP(&applyFilePath, "filename", "f", applyFilePath, "Path of the manifest file that lists the instances")
+	cobra.MarkFlagRequired(applyCmd.Flags(), "filename")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", applyDryRun, "If true, only print the instances that would be created or updated")
+	addUpdateRollbackFlags(applyCmd)
+}
@@ -0,0 +1,252 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Backup Command flags
+var backupArchivePath string
+
+// Names of the files inside a backup archive
+const (
+	backupManifestFileName = "manifest.yaml"
+	backupValuesFileName   = "values.yaml"
+	backupSecretsDirectory = "secrets"
+	backupPostgresDirecory = "postgres"
+)
+
+// backupManifest describes the content of a backup archive
+type backupManifest struct {
+	Name         string    `json:"name"`
+	Namespace    string    `json:"namespace"`
+	Version      string    `json:"version"`
+	ChartVersion string    `json:"chartVersion"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Databases    []string  `json:"databases"`
+	Secrets      []string  `json:"secrets"`
+}
+
+// backupCmd backs up a Synopsys resource from the cluster
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up a Synopsys resource from your cluster",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// backupBlackDuckCmd backs up a Black Duck instance
+var backupBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE",
+	Example:       "synopsysctl backup blackduck <name> -n <namespace>\nsynopsysctl backup blackduck <name> -n <namespace> --archive-path /tmp/blackduck.tar.gz",
+	Short:         "Back up the database, configuration and certificates of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archivePath := backupArchivePath
+		if len(archivePath) == 0 {
+			archivePath = fmt.Sprintf("%s-%s-%s.tar.gz", namespace, args[0], time.Now().Format("20060102150405"))
+		}
+		if err := backupBlackDuck(namespace, args[0], archivePath); err != nil {
+			return err
+		}
+		log.Infof("successfully backed up Black Duck '%s' in namespace '%s' to '%s'", args[0], namespace, archivePath)
+		return nil
+	},
+}
+
+// backupBlackDuck stores the Helm values, the certificate secrets and a dump of the databases of a Black Duck instance in an archive
+func backupBlackDuck(namespace string, name string, archivePath string) error {
+	helmRelease, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to get Black Duck values: %+v", err)
+	}
+	if blackduck.IsExternalPostgres(helmRelease.Config) {
+		return fmt.Errorf("backup of Black Duck '%s' in namespace '%s' isn't supported because it uses an external Postgres database", name, namespace)
+	}
+
+	manifest := backupManifest{
+		Name:         name,
+		Namespace:    namespace,
+		Version:      util.GetHelmReleaseVersion(helmRelease),
+		ChartVersion: helmRelease.Chart.Metadata.Version,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	// the files of the archive are written to a temporary directory first so that the dumps aren't held in memory
+	dir, err := ioutil.TempDir("", "synopsysctl-backup")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory for the backup due to %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// store the user defined Helm values
+	values, err := yaml.Marshal(helmRelease.Config)
+	if err != nil {
+		return fmt.Errorf("failed to convert the Black Duck values to yaml: %+v", err)
+	}
+	if err := writeBackupFile(dir, backupValuesFileName, values); err != nil {
+		return err
+	}
+
+	// store the certificate secrets
+	for _, v := range blackduck.BackupCertificateSecrets {
+		secretName := util.GetResourceName(name, util.BlackDuckName, v)
+		secret, err := util.GetSecret(kubeClient, namespace, secretName)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("couldn't get secret '%s' in namespace '%s' due to %+v", secretName, namespace, err)
+		}
+		secretBytes, err := yaml.Marshal(secret)
+		if err != nil {
+			return fmt.Errorf("failed to convert secret '%s' to yaml: %+v", secretName, err)
+		}
+		if err := writeBackupFile(dir, filepath.Join(backupSecretsDirectory, fmt.Sprintf("%s.yaml", secretName)), secretBytes); err != nil {
+			return err
+		}
+		manifest.Secrets = append(manifest.Secrets, secretName)
+	}
+
+	// dump the databases
+	postgresPod, err := blackduck.GetPostgresPod(kubeClient, namespace, name)
+	if err != nil {
+		return fmt.Errorf("unable to find the postgres pod of Black Duck '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	adminUser := blackduck.GetPostgresAdminUser(helmRelease.Config)
+	for _, database := range blackduck.BackupDatabases {
+		log.Infof("dumping database '%s' of Black Duck '%s' in namespace '%s'...", database, name, namespace)
+		if err := dumpBackupDatabase(dir, postgresPod, adminUser, database); err != nil {
+			return err
+		}
+		manifest.Databases = append(manifest.Databases, database)
+	}
+
+	manifestBytes, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to convert the backup manifest to yaml: %+v", err)
+	}
+	if err := writeBackupFile(dir, backupManifestFileName, manifestBytes); err != nil {
+		return err
+	}
+
+	return util.WriteTarGzArchiveFromDirectory(archivePath, dir)
+}
+
+// writeBackupFile writes a file of the backup archive to the directory of the backup
+func writeBackupFile(dir string, name string, data []byte) error {
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create the directory of '%s' due to %+v", name, err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write '%s' of the backup due to %+v", name, err)
+	}
+	return nil
+}
+
+// dumpBackupDatabase streams the dump of a database to its file in the directory of the backup
+func dumpBackupDatabase(dir string, postgresPod *corev1.Pod, adminUser string, database string) error {
+	path := filepath.Join(dir, backupPostgresDirecory, fmt.Sprintf("%s.dump", database))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create the directory of the dump of database '%s' due to %+v", database, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create the dump of database '%s' due to %+v", database, err)
+	}
+	defer file.Close()
+	return blackduck.DumpDatabase(restconfig, kubeClient, postgresPod, adminUser, database, file)
+}
+
+// extractBackupArchive extracts a backup archive created by the backup command to the directory and returns its manifest
+func extractBackupArchive(archivePath string, dir string) (*backupManifest, error) {
+	if err := util.ExtractTarGzArchive(archivePath, dir); err != nil {
+		return nil, err
+	}
+	manifestBytes, err := ioutil.ReadFile(filepath.Join(dir, backupManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid backup archive: missing %s", archivePath, backupManifestFileName)
+	}
+	manifest := &backupManifest{}
+	if err := yaml.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, fmt.Errorf("failed to read the manifest of backup archive '%s': %+v", archivePath, err)
+	}
+	return manifest, nil
+}
+
+// getBackupSecret returns a secret from an extracted backup archive that can be created in the namespace
+func getBackupSecret(dir string, secretName string, namespace string) (*corev1.Secret, error) {
+	secretBytes, err := ioutil.ReadFile(filepath.Join(dir, backupSecretsDirectory, fmt.Sprintf("%s.yaml", secretName)))
+	if err != nil {
+		return nil, fmt.Errorf("secret '%s' is missing in the backup archive", secretName)
+	}
+	backupSecret := &corev1.Secret{}
+	if err := yaml.Unmarshal(secretBytes, backupSecret); err != nil {
+		return nil, fmt.Errorf("failed to read secret '%s' from the backup archive: %+v", secretName, err)
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupSecret.Name,
+			Namespace: namespace,
+			Labels:    backupSecret.Labels,
+		},
+		Data: backupSecret.Data,
+		Type: backupSecret.Type,
+	}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+
+	backupBlackDuckCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(backupBlackDuckCmd.Flags(), "namespace")
+	backupBlackDuckCmd.Flags().StringVar(&backupArchivePath, "archive-path", backupArchivePath, "Path of the backup archive to create (default <namespace>-<name>-<timestamp>.tar.gz)")
+	backupCmd.AddCommand(backupBlackDuckCmd)
+}
@@ -0,0 +1,445 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Certificate Command flags
+var certificateOutputFormat = "table"
+var certificateExpiryWarningDays = 30
+var certificateType = "webserver"
+var certificateFilePath = ""
+var certificateKeyFilePath = ""
+var certificateSelfSigned = false
+
+// restartedAtAnnotation is set on the pod template of a deployment to restart its pods
+const restartedAtAnnotation = "synopsys.com/restartedAt"
+
+// certificateSecret is a secret of an instance that holds a certificate
+type certificateSecret struct {
+	certificateType string
+	secretName      string
+	certificateKey  string
+	keyKey          string
+}
+
+// certificateStatus is the state of a certificate of an instance
+type certificateStatus struct {
+	Instance     string    `json:"instance"`
+	Namespace    string    `json:"namespace"`
+	Product      string    `json:"product"`
+	Type         string    `json:"type"`
+	Secret       string    `json:"secret"`
+	Subject      string    `json:"subject"`
+	SANs         []string  `json:"sans"`
+	Issuer       string    `json:"issuer"`
+	NotAfter     time.Time `json:"notAfter"`
+	DaysToExpiry int       `json:"daysToExpiry"`
+	Status       string    `json:"status"`
+}
+
+// certificateCmd inspects and rotates the certificates of Synopsys resources
+var certificateCmd = &cobra.Command{
+	Use:   "certificate",
+	Short: "Inspect and rotate the certificates of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// certificateStatusCmd reports the certificates of one or many instances
+var certificateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the certificates of one or many instances",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// certificateStatusBlackDuckCmd reports the certificates of one or many Black Duck instances
+var certificateStatusBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck [NAME] -n NAMESPACE",
+	Example:       "synopsysctl certificate status blackduck <name> -n <namespace>\nsynopsysctl certificate status blackduck -n <namespace> -o json",
+	Short:         "Show the certificates of one or many Black Duck instances",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes up to 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		releases, err := getStatusReleases(args, func(name string) string { return name }, isBlackDuckRelease)
+		if err != nil {
+			return err
+		}
+		return printCertificateStatus(util.BlackDuckName, releases, func(releaseName string) string { return releaseName })
+	},
+}
+
+// certificateStatusAlertCmd reports the certificates of one or many Alert instances
+var certificateStatusAlertCmd = &cobra.Command{
+	Use:           "alert [NAME] -n NAMESPACE",
+	Example:       "synopsysctl certificate status alert <name> -n <namespace>\nsynopsysctl certificate status alert -n <namespace> -o json",
+	Short:         "Show the certificates of one or many Alert instances",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes up to 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		releases, err := getStatusReleases(args, func(name string) string { return fmt.Sprintf("%s%s", name, AlertPostSuffix) }, isAlertRelease)
+		if err != nil {
+			return err
+		}
+		return printCertificateStatus(util.AlertName, releases, func(releaseName string) string { return strings.TrimSuffix(releaseName, AlertPostSuffix) })
+	},
+}
+
+// certificateRotateCmd replaces a certificate of an instance
+var certificateRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Replace a certificate of an instance and restart the deployments that use it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// certificateRotateBlackDuckCmd replaces a certificate of a Black Duck instance
+var certificateRotateBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE",
+	Example:       "synopsysctl certificate rotate blackduck <name> -n <namespace> --certificate-file-path tls.crt --certificate-key-file-path tls.key\nsynopsysctl certificate rotate blackduck <name> -n <namespace> --self-signed\nsynopsysctl certificate rotate blackduck <name> -n <namespace> --type proxy --certificate-file-path proxy.crt\nsynopsysctl certificate rotate blackduck <name> -n <namespace> # copies the certificate renewed by cert-manager",
+	Short:         "Replace a certificate of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		helmRelease, err := util.GetWithHelm3(args[0], namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to get release: %+v", err)
+		}
+		return rotateCertificate(util.BlackDuckName, args[0], helmRelease, func() (string, string, error) {
+			cert, key := blackduck.CreateSelfSignedCert()
+			return cert, key, nil
+		})
+	},
+}
+
+// certificateRotateAlertCmd replaces the certificate of an Alert instance
+var certificateRotateAlertCmd = &cobra.Command{
+	Use:           "alert NAME -n NAMESPACE",
+	Example:       "synopsysctl certificate rotate alert <name> -n <namespace> --certificate-file-path tls.crt --certificate-key-file-path tls.key\nsynopsysctl certificate rotate alert <name> -n <namespace> --self-signed\nsynopsysctl certificate rotate alert <name> -n <namespace> # copies the certificate renewed by cert-manager",
+	Short:         "Replace the certificate of an Alert instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		if certificateType != "webserver" {
+			return fmt.Errorf("'%s' is an invalid certificate type for Alert, must be [webserver]", certificateType)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		helmRelease, err := util.GetWithHelm3(fmt.Sprintf("%s%s", args[0], AlertPostSuffix), namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to get release: %+v", err)
+		}
+		return rotateCertificate(util.AlertName, args[0], helmRelease, func() (string, string, error) {
+			return util.GeneratePemSelfSignedCertificateAndKey(pkix.Name{CommonName: fmt.Sprintf("%s-alert", args[0])})
+		})
+	},
+}
+
+// getCertificateSecrets returns the certificate secrets of an instance, using the default secret names if the release doesn't set them
+func getCertificateSecrets(product string, name string, helmRelease *release.Release) []certificateSecret {
+	getSecretName := func(key string, defaultName string) string {
+		if secretName, ok := util.GetHelmValueFromMap(helmRelease.Config, []string{key}).(string); ok && len(secretName) > 0 {
+			return secretName
+		}
+		return defaultName
+	}
+	switch product {
+	case util.BlackDuckName:
+		return []certificateSecret{
+			{certificateType: "webserver", secretName: getSecretName("tlsCertSecretName", util.GetResourceName(name, util.BlackDuckName, "webserver-certificate")), certificateKey: "WEBSERVER_CUSTOM_CERT_FILE", keyKey: "WEBSERVER_CUSTOM_KEY_FILE"},
+			{certificateType: "proxy", secretName: getSecretName("proxyCertSecretName", util.GetResourceName(name, util.BlackDuckName, "proxy-certificate")), certificateKey: "HUB_PROXY_CERT_FILE"},
+			{certificateType: "auth-custom-ca", secretName: getSecretName("certAuthCACertSecretName", util.GetResourceName(name, util.BlackDuckName, "auth-custom-ca")), certificateKey: "AUTH_CUSTOM_CA"},
+		}
+	case util.AlertName:
+		return []certificateSecret{
+			{certificateType: "webserver", secretName: getSecretName("webserverCustomCertificatesSecretName", "alert-custom-certificate"), certificateKey: "WEBSERVER_CUSTOM_CERT_FILE", keyKey: "WEBSERVER_CUSTOM_KEY_FILE"},
+		}
+	}
+	return nil
+}
+
+// getCertificateStatuses parses the certificates of the secret and returns their state. A secret can hold a certificate chain or a CA bundle
+func getCertificateStatuses(product string, name string, secret certificateSecret, data []byte) ([]*certificateStatus, error) {
+	certs, err := util.ParsePemCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the certificates in secret '%s' due to %+v", secret.secretName, err)
+	}
+	statuses := []*certificateStatus{}
+	for _, cert := range certs {
+		sans := append([]string{}, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+		daysToExpiry := int(time.Until(cert.NotAfter).Hours() / 24)
+		status := "valid"
+		if time.Now().After(cert.NotAfter) {
+			status = "expired"
+		} else if daysToExpiry < certificateExpiryWarningDays {
+			status = "expiring"
+		}
+		statuses = append(statuses, &certificateStatus{
+			Instance:     name,
+			Namespace:    namespace,
+			Product:      product,
+			Type:         secret.certificateType,
+			Secret:       secret.secretName,
+			Subject:      cert.Subject.String(),
+			SANs:         sans,
+			Issuer:       cert.Issuer.String(),
+			NotAfter:     cert.NotAfter,
+			DaysToExpiry: daysToExpiry,
+			Status:       status,
+		})
+	}
+	return statuses, nil
+}
+
+// printCertificateStatus prints the certificates of the releases and returns an error if any of them is expired
+func printCertificateStatus(product string, releases []*release.Release, getInstanceName func(string) string) error {
+	statuses := []*certificateStatus{}
+	expired := 0
+	for _, helmRelease := range releases {
+		name := getInstanceName(helmRelease.Name)
+		for _, secret := range getCertificateSecrets(product, name, helmRelease) {
+			k8sSecret, err := util.GetSecret(kubeClient, namespace, secret.secretName)
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return fmt.Errorf("failed to get secret '%s' due to %+v", secret.secretName, err)
+			}
+			data, ok := k8sSecret.Data[secret.certificateKey]
+			if !ok {
+				// secrets issued by cert-manager store the certificate in tls.crt
+				data = k8sSecret.Data[corev1.TLSCertKey]
+			}
+			if len(data) == 0 {
+				continue
+			}
+			if issuedSecretName, ok := k8sSecret.Annotations[util.CertManagerSecretAnnotation]; ok {
+				if issuedSecret, err := util.GetSecret(kubeClient, namespace, issuedSecretName); err == nil && !bytes.Equal(issuedSecret.Data[corev1.TLSCertKey], data) {
+					log.Warnf("cert-manager renewed the certificate of secret '%s', run 'synopsysctl certificate rotate %s %s -n %s' to use it", secret.secretName, product, name, namespace)
+				}
+			}
+			secretStatuses, err := getCertificateStatuses(product, name, secret, data)
+			if err != nil {
+				return err
+			}
+			for _, status := range secretStatuses {
+				if status.Status == "expired" {
+					expired++
+				}
+			}
+			statuses = append(statuses, secretStatuses...)
+		}
+	}
+
+	switch strings.ToLower(certificateOutputFormat) {
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "INSTANCE\tTYPE\tSECRET\tSUBJECT\tSANS\tISSUER\tEXPIRES\tDAYS\tSTATUS")
+		for _, status := range statuses {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", status.Instance, status.Type, status.Secret, status.Subject, strings.Join(status.SANs, ","), status.Issuer, status.NotAfter.Format("2006-01-02"), status.DaysToExpiry, status.Status)
+		}
+		w.Flush()
+	case "json", "yaml":
+		if _, err := PrintComponent(statuses, certificateOutputFormat); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("'%s' is an invalid output format, must be one of [table|json|yaml]", certificateOutputFormat)
+	}
+
+	if expired > 0 {
+		return fmt.Errorf("%d of %d certificate(s) in namespace '%s' are expired", expired, len(statuses), namespace)
+	}
+	return nil
+}
+
+// rotateCertificate replaces the certificate in the secret of the instance and restarts the deployments that use the secret
+func rotateCertificate(product string, name string, helmRelease *release.Release, createSelfSignedCert func() (string, string, error)) error {
+	var secret *certificateSecret
+	for _, s := range getCertificateSecrets(product, name, helmRelease) {
+		if s.certificateType == certificateType {
+			secret = &s
+			break
+		}
+	}
+	if secret == nil {
+		return fmt.Errorf("'%s' is an invalid certificate type, must be one of [webserver|proxy|auth-custom-ca]", certificateType)
+	}
+
+	// Get the secret
+	k8sSecret, err := util.GetSecret(kubeClient, namespace, secret.secretName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("instance '%s' doesn't have a %s certificate secret, use 'synopsysctl update %s' to add one", name, secret.certificateType, product)
+		}
+		return fmt.Errorf("failed to get secret '%s' due to %+v", secret.secretName, err)
+	}
+	if _, ok := k8sSecret.Annotations[certManagerCertificateNameAnnotation]; ok {
+		return fmt.Errorf("secret '%s' is managed by cert-manager, which renews the certificate", secret.secretName)
+	}
+
+	// Get the new certificate
+	var cert, key string
+	if issuedSecretName, ok := k8sSecret.Annotations[util.CertManagerSecretAnnotation]; ok {
+		// the secret is a copy of a secret issued by cert-manager, so it's replaced with the renewed certificate
+		if certificateSelfSigned || len(certificateFilePath) > 0 || len(certificateKeyFilePath) > 0 {
+			return fmt.Errorf("the certificate in secret '%s' is issued by cert-manager, rotate it without --self-signed, --certificate-file-path and --certificate-key-file-path to copy the renewed certificate", secret.secretName)
+		}
+		issuedSecret, err := util.GetSecret(kubeClient, namespace, issuedSecretName)
+		if err != nil {
+			return fmt.Errorf("failed to get the secret '%s' issued by cert-manager due to %+v", issuedSecretName, err)
+		}
+		cert, key = string(issuedSecret.Data[corev1.TLSCertKey]), string(issuedSecret.Data[corev1.TLSPrivateKeyKey])
+	} else if certificateSelfSigned {
+		if len(secret.keyKey) == 0 {
+			return fmt.Errorf("a self-signed certificate can't be used for the %s certificate", secret.certificateType)
+		}
+		if cert, key, err = createSelfSignedCert(); err != nil {
+			return fmt.Errorf("failed to create a self-signed certificate due to %+v", err)
+		}
+	} else {
+		if len(certificateFilePath) == 0 {
+			return fmt.Errorf("--certificate-file-path or --self-signed must be set")
+		}
+		if cert, err = util.ReadFileData(certificateFilePath); err != nil {
+			return fmt.Errorf("failed to read certificate file due to %+v", err)
+		}
+		if len(secret.keyKey) > 0 {
+			if len(certificateKeyFilePath) == 0 {
+				return fmt.Errorf("--certificate-key-file-path must be set for the %s certificate", secret.certificateType)
+			}
+			if key, err = util.ReadFileData(certificateKeyFilePath); err != nil {
+				return fmt.Errorf("failed to read certificate key file due to %+v", err)
+			}
+		}
+	}
+	if _, err := util.ParsePemCertificates([]byte(cert)); err != nil {
+		return fmt.Errorf("failed to parse the certificate due to %+v", err)
+	}
+
+	// Update the secret
+	if k8sSecret.Data == nil {
+		k8sSecret.Data = map[string][]byte{}
+	}
+	k8sSecret.Data[secret.certificateKey] = []byte(cert)
+	if len(secret.keyKey) > 0 {
+		k8sSecret.Data[secret.keyKey] = []byte(key)
+	}
+	if _, err := util.UpdateSecret(kubeClient, namespace, k8sSecret); err != nil {
+		return fmt.Errorf("failed to update secret '%s' due to %+v", secret.secretName, err)
+	}
+	log.Infof("successfully updated the %s certificate in secret '%s'", secret.certificateType, secret.secretName)
+
+	// Restart only the deployments that use the secret. The resources of an instance are labeled with its release name, e.g. <name>-alert for Alert
+	deployments, err := util.ListDeployments(kubeClient, namespace, fmt.Sprintf("app=%s, name=%s", product, helmRelease.Name))
+	if err != nil {
+		return fmt.Errorf("failed to list the deployments of instance '%s' due to %+v", name, err)
+	}
+	restartedAt := time.Now().Format(time.RFC3339)
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if !util.PodSpecReferencesSecret(deployment.Spec.Template.Spec, secret.secretName) {
+			continue
+		}
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[restartedAtAnnotation] = restartedAt
+		if _, err := util.UpdateDeployment(kubeClient, namespace, deployment); err != nil {
+			return fmt.Errorf("failed to restart deployment '%s' due to %+v", deployment.Name, err)
+		}
+		log.Infof("restarted deployment '%s'", deployment.Name)
+	}
+
+	log.Infof("successfully rotated the %s certificate of instance '%s' in namespace '%s'", secret.certificateType, name, namespace)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(certificateCmd)
+	certificateCmd.AddCommand(certificateStatusCmd)
+	certificateCmd.AddCommand(certificateRotateCmd)
+
+	certificateCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(certificateCmd.PersistentFlags(), "namespace")
+
+	certificateStatusCmd.PersistentFlags().StringVarP(&certificateOutputFormat, "output", "o", certificateOutputFormat, "Output format [table|json|yaml]")
+	certificateStatusCmd.PersistentFlags().IntVar(&certificateExpiryWarningDays, "expiry-warning-days", certificateExpiryWarningDays, "Number of days before expiry at which a certificate is reported as expiring")
+	certificateStatusCmd.AddCommand(certificateStatusBlackDuckCmd)
+	certificateStatusCmd.AddCommand(certificateStatusAlertCmd)
+
+	certificateRotateCmd.PersistentFlags().StringVar(&certificateType, "type", certificateType, "Type of the certificate to replace [webserver|proxy|auth-custom-ca]")
+	certificateRotateCmd.PersistentFlags().StringVar(&certificateFilePath, "certificate-file-path", certificateFilePath, "Absolute path to a file for the new certificate")
+	certificateRotateCmd.PersistentFlags().StringVar(&certificateKeyFilePath, "certificate-key-file-path", certificateKeyFilePath, "Absolute path to a file for the new certificate key")
+	certificateRotateCmd.PersistentFlags().BoolVar(&certificateSelfSigned, "self-signed", certificateSelfSigned, "If true, replace the certificate with a new self-signed certificate")
+	certificateRotateCmd.AddCommand(certificateRotateBlackDuckCmd)
+	certificateRotateCmd.AddCommand(certificateRotateAlertCmd)
+}
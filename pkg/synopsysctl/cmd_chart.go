@@ -0,0 +1,164 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Chart Command flags
+var chartVersion string
+var chartSHA256 string
+var chartPruneAll bool
+var chartPruneOlderThan time.Duration
+
+// chartCmd manages the chart cache
+var chartCmd = &cobra.Command{
+	Use:   "chart",
+	Short: "Manage the cache of the Helm charts in ~/.synopsysctl/charts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// chartPullCmd downloads a chart to the cache
+var chartPullCmd = &cobra.Command{
+	Use:           "pull PRODUCT --version VERSION",
+	Example:       "synopsysctl chart pull blackduck --version 2020.4.0\nsynopsysctl chart pull alert --version 5.3.0 --sha256 <digest>",
+	Short:         fmt.Sprintf("Download the chart of a product version to the chart cache [%s|%s|%s|%s|%s]", util.BlackDuckName, util.AlertName, polarisName, polarisReportingName, bdbaName),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          checkProductChartArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := getChartCache()
+		if err != nil {
+			return err
+		}
+		cached, err := cache.Pull(getProductChartURL(cmd, args[0], chartVersion), chartSHA256)
+		if err != nil {
+			return err
+		}
+		log.Infof("successfully pulled chart '%s' with digest sha256:%s", cached.Name, cached.SHA256)
+		return nil
+	},
+}
+
+// chartListCmd lists the cached charts
+var chartListCmd = &cobra.Command{
+	Use:           "list",
+	Example:       "synopsysctl chart list",
+	Short:         "List the charts in the chart cache",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := getChartCache()
+		if err != nil {
+			return err
+		}
+		charts, err := cache.List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSHA256\tPINNED\tSIGNED BY\tPULLED\tURL")
+		for _, cached := range charts {
+			signedBy := cached.SignedBy
+			if len(signedBy) == 0 {
+				signedBy = "<none>"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\n", cached.Name, cached.SHA256, cached.Pinned, signedBy, cached.PulledAt.Format(time.RFC3339), cached.URL)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// chartPruneCmd removes charts from the cache
+var chartPruneCmd = &cobra.Command{
+	Use:           "prune",
+	Example:       "synopsysctl chart prune\nsynopsysctl chart prune --older-than 720h\nsynopsysctl chart prune --all",
+	Short:         "Remove the corrupted, unknown and old charts from the chart cache",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := getChartCache()
+		if err != nil {
+			return err
+		}
+		removed, err := cache.Prune(func(cached util.CachedChart) bool {
+			return chartPruneAll || (chartPruneOlderThan > 0 && time.Since(cached.PulledAt) > chartPruneOlderThan)
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range removed {
+			log.Infof("removed '%s'", name)
+		}
+		log.Infof("successfully pruned %d chart(s) from the chart cache", len(removed))
+		return nil
+	},
+}
+
+// getChartCache returns the chart cache
+func getChartCache() (*util.ChartCache, error) {
+	if len(util.ChartCacheDirectory) == 0 {
+		return nil, fmt.Errorf("the chart cache is disabled")
+	}
+	return util.NewChartCache(util.ChartCacheDirectory, util.ChartKeyring), nil
+}
+
+func init() {
+	rootCmd.AddCommand(chartCmd)
+
+	chartPullCmd.Flags().StringVar(&chartVersion, "version", chartVersion, "Version of the product")
+	cobra.MarkFlagRequired(chartPullCmd.Flags(), "version")
+	chartPullCmd.Flags().StringVar(&chartSHA256, "sha256", chartSHA256, "If set, the chart must have this SHA256 digest and the digest is pinned")
+	addChartLocationPathFlag(chartPullCmd)
+	chartCmd.AddCommand(chartPullCmd)
+
+	chartCmd.AddCommand(chartListCmd)
+
+	chartPruneCmd.Flags().BoolVar(&chartPruneAll, "all", chartPruneAll, "If true, remove all the charts")
+	chartPruneCmd.Flags().DurationVar(&chartPruneOlderThan, "older-than", chartPruneOlderThan, "If set, remove the charts that were pulled before this duration, e.g. 720h")
+	chartCmd.AddCommand(chartPruneCmd)
+}
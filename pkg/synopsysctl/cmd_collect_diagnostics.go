@@ -0,0 +1,373 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/yaml"
+)
+
+// Collect Diagnostics Command flags
+var diagnosticsArchivePath string
+var diagnosticsNamespaces []string
+
+// sensitiveHelmValues are the password, seal key and license values that are redacted from the collected and printed Helm values.
+// The certificates are stored in secrets whose data is redacted from the collected manifest
+var sensitiveHelmValues = [][]string{
+	// Black Duck
+	{"sealKey"},
+	{"postgres", "adminPassword"},
+	{"postgres", "userPassword"},
+	{"postgres", "password"},
+	// Alert
+	{"alertEncryptionPassword"},
+	{"alertEncryptionGlobalSalt"},
+	// Polaris, Polaris Reporting and BDBA
+	{"imageCredentials", "password"},
+	{"coverity", "license"},
+	{"postgresql", "postgresqlPassword"},
+	{"onprem-auth-service", "smtp", "password"},
+	{"frontend", "database", "postgresqlPassword"},
+	{"frontend", "email", "smtpPassword"},
+	{"frontend", "ldap", "bindPassword"},
+	{"frontend", "licensing", "password"},
+}
+
+// diagnosticsClusterInfo describes the cluster the diagnostics were collected from
+type diagnosticsClusterInfo struct {
+	KubernetesVersion  string                    `json:"kubernetesVersion"`
+	IsOpenshift        bool                      `json:"isOpenshift"`
+	SynopsysctlVersion string                    `json:"synopsysctlVersion"`
+	Instances          []diagnosticsInstanceInfo `json:"instances"`
+	CollectedAt        time.Time                 `json:"collectedAt"`
+	CollectionErrors   []string                  `json:"collectionErrors,omitempty"`
+}
+
+// diagnosticsInstanceInfo describes the release of an instance whose diagnostics were collected
+type diagnosticsInstanceInfo struct {
+	Namespace       string `json:"namespace"`
+	Product         string `json:"product"`
+	Name            string `json:"name"`
+	ReleaseName     string `json:"releaseName"`
+	ReleaseStatus   string `json:"releaseStatus"`
+	ReleaseRevision int    `json:"releaseRevision"`
+	ChartVersion    string `json:"chartVersion"`
+}
+
+// collectDiagnosticsCmd collects the diagnostics of a Synopsys resource for a support ticket
+var collectDiagnosticsCmd = &cobra.Command{
+	Use:   "collect-diagnostics",
+	Short: "Collect the logs, events and configuration of a Synopsys resource into an archive for support",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// collectDiagnosticsAlertCmd collects the diagnostics of an Alert instance
+var collectDiagnosticsAlertCmd = &cobra.Command{
+	Use:           "alert NAME -n NAMESPACE",
+	Example:       "synopsysctl collect-diagnostics alert <name> -n <namespace>",
+	Short:         "Collect the diagnostics of an Alert instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return collectDiagnostics(util.AlertName, args[0], fmt.Sprintf("%s%s", args[0], AlertPostSuffix))
+	},
+}
+
+// collectDiagnosticsBlackDuckCmd collects the diagnostics of a Black Duck instance
+var collectDiagnosticsBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE",
+	Example:       "synopsysctl collect-diagnostics blackduck <name> -n <namespace>\nsynopsysctl collect-diagnostics blackduck <name> -n <namespace1>,<namespace2>",
+	Short:         "Collect the diagnostics of a Black Duck instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return collectDiagnostics(util.BlackDuckName, args[0], args[0])
+	},
+}
+
+// collectDiagnosticsPolarisCmd collects the diagnostics of a Polaris instance
+var collectDiagnosticsPolarisCmd = &cobra.Command{
+	Use:           "polaris -n NAMESPACE",
+	Example:       "synopsysctl collect-diagnostics polaris -n <namespace>",
+	Short:         "Collect the diagnostics of a Polaris instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return collectDiagnostics(polarisName, polarisName, polarisName)
+	},
+}
+
+// collectDiagnosticsPolarisReportingCmd collects the diagnostics of a Polaris Reporting instance
+var collectDiagnosticsPolarisReportingCmd = &cobra.Command{
+	Use:           "polaris-reporting -n NAMESPACE",
+	Example:       "synopsysctl collect-diagnostics polaris-reporting -n <namespace>",
+	Short:         "Collect the diagnostics of a Polaris Reporting instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return collectDiagnostics(polarisReportingName, polarisReportingName, polarisReportingName)
+	},
+}
+
+// collectDiagnosticsBDBACmd collects the diagnostics of a BDBA instance
+var collectDiagnosticsBDBACmd = &cobra.Command{
+	Use:           "bdba -n NAMESPACE",
+	Example:       "synopsysctl collect-diagnostics bdba -n <namespace>",
+	Short:         "Collect the diagnostics of a BDBA instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return collectDiagnostics(bdbaName, bdbaName, bdbaName)
+	},
+}
+
+// collectDiagnostics writes the logs, events, resource descriptions and redacted Helm release of the instance in each namespace
+// to a tar.gz archive. The files of an instance are stored in the directory of its namespace. Failures to collect a single item
+// are recorded in the archive instead of stopping the collection
+func collectDiagnostics(product string, name string, releaseName string) error {
+	namespaces := []string{}
+	helmReleases := map[string]*release.Release{}
+	for _, ns := range diagnosticsNamespaces {
+		if _, ok := helmReleases[ns]; ok {
+			continue
+		}
+		helmRelease, err := util.GetWithHelm3(releaseName, ns, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf(strings.Replace(fmt.Sprintf("failed to get release: %+v", err), fmt.Sprintf("instance '%s' ", releaseName), fmt.Sprintf("instance '%s' ", name), 0))
+		}
+		namespaces = append(namespaces, ns)
+		helmReleases[ns] = helmRelease
+	}
+
+	collectedAt := time.Now().UTC()
+	archivePath := diagnosticsArchivePath
+	if len(archivePath) == 0 {
+		archivePath = fmt.Sprintf("diagnostics-%s-%s-%s-%s.tar.gz", product, strings.Join(namespaces, "_"), name, collectedAt.Format("20060102150405"))
+	}
+
+	files := make(map[string][]byte)
+	info := diagnosticsClusterInfo{
+		SynopsysctlVersion: rootCmd.Version,
+		IsOpenshift:        util.IsOpenshift(kubeClient),
+		CollectedAt:        collectedAt,
+	}
+	addError := func(format string, args ...interface{}) {
+		msg := fmt.Sprintf(format, args...)
+		log.Warn(msg)
+		info.CollectionErrors = append(info.CollectionErrors, msg)
+	}
+	var err error
+	if info.KubernetesVersion, err = util.GetKubernetesVersion(kubeClient); err != nil {
+		addError("unable to get the Kubernetes version due to %+v", err)
+	}
+	if simulate {
+		addError("the resources aren't described because the describers can't read the simulated cluster")
+	}
+	for _, ns := range namespaces {
+		info.Instances = append(info.Instances, collectInstanceDiagnostics(files, ns, product, name, helmReleases[ns], addError))
+	}
+
+	infoBytes, err := yaml.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to convert the cluster information to yaml: %+v", err)
+	}
+	files["cluster.yaml"] = infoBytes
+
+	if err := util.WriteTarGzArchive(archivePath, files); err != nil {
+		return err
+	}
+	log.Infof("successfully collected the diagnostics of '%s' in namespace(s) '%s' to '%s'", name, strings.Join(namespaces, "', '"), archivePath)
+	return nil
+}
+
+// collectInstanceDiagnostics adds the diagnostics of the release of an instance to the files in the directory of its namespace
+func collectInstanceDiagnostics(files map[string][]byte, namespace string, product string, name string, helmRelease *release.Release, addError func(string, ...interface{})) diagnosticsInstanceInfo {
+	info := diagnosticsInstanceInfo{
+		Namespace:       namespace,
+		Product:         product,
+		Name:            name,
+		ReleaseName:     helmRelease.Name,
+		ReleaseRevision: helmRelease.Version,
+	}
+	if helmRelease.Info != nil {
+		info.ReleaseStatus = helmRelease.Info.Status.String()
+	}
+	if helmRelease.Chart != nil && helmRelease.Chart.Metadata != nil {
+		info.ChartVersion = helmRelease.Chart.Metadata.Version
+	}
+
+	// Helm release with the secrets redacted
+	if values, err := yaml.Marshal(util.RedactHelmValues(helmRelease.Config, sensitiveHelmValues)); err == nil {
+		files[path.Join(namespace, "helm", "values.yaml")] = values
+	} else {
+		addError("unable to convert the Helm values in namespace '%s' to yaml due to %+v", namespace, err)
+	}
+	if manifest, err := util.RedactManifestSecrets(helmRelease.Manifest); err == nil {
+		files[path.Join(namespace, "helm", "manifest.yaml")] = []byte(manifest)
+	} else {
+		addError("unable to redact the Helm manifest in namespace '%s' due to %+v", namespace, err)
+	}
+
+	// Descriptions of the resources of the release and their pods
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		addError("unable to read the resources of the release in namespace '%s' due to %+v", namespace, err)
+	}
+	objects := make(map[util.ObjectKey]bool)
+	for _, resource := range resources {
+		apiVersion := fmt.Sprintf("%v", resource["apiVersion"])
+		kind := fmt.Sprintf("%v", resource["kind"])
+		resourceName := fmt.Sprintf("%v", util.GetHelmValueFromMap(resource, []string{"metadata", "name"}))
+		objects[util.ObjectKey{Kind: kind, Name: resourceName}] = true
+		collectDescription(files, namespace, apiVersion, kind, resourceName, addError)
+
+		labelSelector := util.GetWorkloadLabelSelector(resource)
+		if len(labelSelector) == 0 {
+			continue
+		}
+		pods, err := util.ListPodsWithLabels(kubeClient, namespace, labelSelector)
+		if err != nil {
+			addError("unable to list the pods of %s '%s' in namespace '%s' due to %+v", kind, resourceName, namespace, err)
+			continue
+		}
+		for _, pod := range pods.Items {
+			if objects[util.ObjectKey{Kind: "Pod", Name: pod.Name}] {
+				continue
+			}
+			for _, key := range util.GetPodObjectKeys(pod) {
+				objects[key] = true
+			}
+			collectDescription(files, namespace, "v1", "Pod", pod.Name, addError)
+
+			// Logs of the current and previous containers
+			for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+				collectLogs(files, namespace, pod.Name, status.Name, false, addError)
+				if status.RestartCount > 0 {
+					collectLogs(files, namespace, pod.Name, status.Name, true, addError)
+				}
+			}
+		}
+	}
+
+	// Events of the resources of the release, their pods, the owners of the pods and their persistent volume claims
+	if events, err := util.ListEvents(kubeClient, namespace); err == nil {
+		events.Items = util.FilterEvents(events.Items, objects)
+		if eventBytes, err := yaml.Marshal(events); err == nil {
+			files[path.Join(namespace, "events.yaml")] = eventBytes
+		} else {
+			addError("unable to convert the events in namespace '%s' to yaml due to %+v", namespace, err)
+		}
+	} else {
+		addError("unable to list the events in namespace '%s' due to %+v", namespace, err)
+	}
+	return info
+}
+
+// collectDescription adds the describe output of a resource to the diagnostics files. The kinds without a describer
+// are skipped because the manifest of the release already contains them
+func collectDescription(files map[string][]byte, namespace string, apiVersion string, kind string, name string, addError func(string, ...interface{})) {
+	if simulate {
+		return
+	}
+	out, ok, err := util.DescribeObject(restconfig, namespace, apiVersion, kind, name)
+	if err != nil {
+		addError("unable to describe %s '%s' in namespace '%s' due to %+v", kind, name, namespace, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	files[path.Join(namespace, "describe", strings.ToLower(kind), fmt.Sprintf("%s.txt", name))] = []byte(out)
+}
+
+// collectLogs adds the logs of a container to the diagnostics files
+func collectLogs(files map[string][]byte, namespace string, podName string, containerName string, previous bool, addError func(string, ...interface{})) {
+	logs, err := util.GetPodLogs(kubeClient, namespace, podName, containerName, previous)
+	if err != nil {
+		addError("unable to get the logs of container '%s' in pod '%s' in namespace '%s' due to %+v", containerName, podName, namespace, err)
+		return
+	}
+	fileName := fmt.Sprintf("%s.log", containerName)
+	if previous {
+		fileName = fmt.Sprintf("%s.previous.log", containerName)
+	}
+	files[path.Join(namespace, "logs", podName, fileName)] = logs
+}
+
+func init() {
+	rootCmd.AddCommand(collectDiagnosticsCmd)
+
+	collectDiagnosticsCmd.PersistentFlags().StringSliceVarP(&diagnosticsNamespaces, "namespace", "n", diagnosticsNamespaces, "Namespace(s) of the instance(s), the diagnostics of the instance in each namespace are collected into one archive, e.g. -n ns1,ns2")
+	cobra.MarkFlagRequired(collectDiagnosticsCmd.PersistentFlags(), "namespace")
+	collectDiagnosticsCmd.PersistentFlags().StringVar(&diagnosticsArchivePath, "archive-path", diagnosticsArchivePath, "Path of the diagnostics archive to create (default diagnostics-<product>-<namespaces>-<name>-<timestamp>.tar.gz)")
+
+	collectDiagnosticsCmd.AddCommand(collectDiagnosticsAlertCmd)
+	collectDiagnosticsCmd.AddCommand(collectDiagnosticsBlackDuckCmd)
+	collectDiagnosticsCmd.AddCommand(collectDiagnosticsPolarisCmd)
+	collectDiagnosticsCmd.AddCommand(collectDiagnosticsPolarisReportingCmd)
+	collectDiagnosticsCmd.AddCommand(collectDiagnosticsBDBACmd)
+}
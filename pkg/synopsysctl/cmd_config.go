@@ -0,0 +1,303 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/apply"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	homedir "github.com/mitchellh/go-homedir"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// Config Command flags
+var configProduct string
+var configCommand string
+
+// Keys of the profiles in the config file, which set the flags of the commands except the update commands, e.g.
+//
+//	current-profile: prod
+//	profiles:
+//	  prod:
+//	    flags:                # default flag values of every command
+//	      registry: registry.example.com/blackducksoftware
+//	    products:
+//	      blackduck:          # default flag values of the Black Duck commands
+//	        pvc-storage-class: ssd
+//	    commands:
+//	      create blackduck:   # default flag values of a command
+//	        size: medium
+const (
+	configCurrentProfileKey = "current-profile"
+	configProfilesKey       = "profiles"
+	configDefaultProfile    = "default"
+)
+
+// profileProducts are the products that can have default flag values in a profile
+var profileProducts = []string{util.BlackDuckName, util.AlertName, util.OpsSightName, polarisName, polarisReportingName, bdbaName}
+
+// configCmd manages the config file
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the profiles of default flag values in the synopsysctl config file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// configViewCmd prints the config file
+var configViewCmd = &cobra.Command{
+	Use:           "view",
+	Example:       "synopsysctl config view\nsynopsysctl config view --profile prod",
+	Short:         "Print the profiles in the config file",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := map[string]interface{}{
+			configCurrentProfileKey: getProfileName(),
+			configProfilesKey:       viper.GetStringMap(configProfilesKey),
+		}
+		if cmd.Flag("profile").Changed {
+			profile := viper.GetStringMap(fmt.Sprintf("%s.%s", configProfilesKey, profileName))
+			if len(profile) == 0 {
+				return fmt.Errorf("profile '%s' doesn't exist", profileName)
+			}
+			config = profile
+		}
+		configBytes, err := yaml.Marshal(config)
+		if err != nil {
+			return fmt.Errorf("failed to convert the config to yaml: %+v", err)
+		}
+		fmt.Print(string(configBytes))
+		return nil
+	},
+}
+
+// configSetCmd sets a default flag value in a profile
+var configSetCmd = &cobra.Command{
+	Use:           "set FLAG VALUE",
+	Example:       "synopsysctl config set registry registry.example.com/blackducksoftware --profile prod\nsynopsysctl config set pvc-storage-class ssd --profile prod --product blackduck\nsynopsysctl config set size medium --profile prod --command \"create blackduck\"",
+	Short:         "Set the default value of a flag in a profile",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 2 arguments, but got %+v", args)
+		}
+		if cmd.Flag("product").Changed && cmd.Flag("command").Changed {
+			return fmt.Errorf("cannot set both --product and --command")
+		}
+		if cmd.Flag("product").Changed && !isProfileProduct(configProduct) {
+			return fmt.Errorf("unsupported product '%s', must be one of [%s]", configProduct, strings.Join(profileProducts, "|"))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := getProfileName()
+		key := fmt.Sprintf("%s.%s.flags", configProfilesKey, name)
+		if len(configProduct) > 0 {
+			key = fmt.Sprintf("%s.%s.products.%s", configProfilesKey, name, configProduct)
+		} else if len(configCommand) > 0 {
+			key = fmt.Sprintf("%s.%s.commands.%s", configProfilesKey, name, normalizeCommandPath(configCommand))
+		}
+		viper.Set(fmt.Sprintf("%s.%s", key, args[0]), args[1])
+		if err := writeConfig(); err != nil {
+			return err
+		}
+		log.Infof("successfully set '%s' to '%s' in profile '%s'", args[0], args[1], name)
+		return nil
+	},
+}
+
+// configUseProfileCmd sets the profile used when --profile isn't set
+var configUseProfileCmd = &cobra.Command{
+	Use:           "use-profile NAME",
+	Example:       "synopsysctl config use-profile prod",
+	Short:         "Set the profile used when --profile isn't set",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(viper.GetStringMap(fmt.Sprintf("%s.%s", configProfilesKey, args[0]))) == 0 {
+			return fmt.Errorf("profile '%s' doesn't exist", args[0])
+		}
+		viper.Set(configCurrentProfileKey, args[0])
+		if err := writeConfig(); err != nil {
+			return err
+		}
+		log.Infof("successfully switched to profile '%s'", args[0])
+		return nil
+	},
+}
+
+// getProfileName returns the profile of the --profile flag, the current profile of the config file or the default profile
+func getProfileName() string {
+	if len(profileName) > 0 {
+		return profileName
+	}
+	if current := viper.GetString(configCurrentProfileKey); len(current) > 0 {
+		return current
+	}
+	return configDefaultProfile
+}
+
+// wrapProfileCommands makes the commands and their sub-commands set their flags from the profile before their arguments are validated,
+// as cobra validates the arguments before it runs PersistentPreRunE
+func wrapProfileCommands(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		validateArgs := cmd.Args
+		if validateArgs == nil {
+			validateArgs = cobra.ArbitraryArgs
+		}
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			// the commands that manage the profiles don't use them, the commands that run in several contexts leave them to each run,
+			// and the update commands keep the values of the release, which the flags set from the profile would override
+			if cmd.Parent() != configCmd && !isAllContextsCommand(cmd) && !isUpdateCommand(cmd) {
+				if err := applyProfile(cmd, args); err != nil {
+					return err
+				}
+			}
+			return validateArgs(cmd, args)
+		}
+		wrapProfileCommands(cmd.Commands()...)
+	}
+}
+
+// applyProfile sets the flags of the command that weren't set on the command line from the profile.
+// The flag values of the command override the ones of the product, which override the ones of every command
+func applyProfile(cmd *cobra.Command, args []string) error {
+	name := getProfileName()
+	profileKey := fmt.Sprintf("%s.%s", configProfilesKey, name)
+	if !viper.IsSet(profileKey) {
+		if len(profileName) > 0 {
+			return fmt.Errorf("profile '%s' doesn't exist", profileName)
+		}
+		return nil
+	}
+
+	profile := util.Profile{}
+	if err := viper.UnmarshalKey(profileKey, &profile); err != nil {
+		return fmt.Errorf("failed to read profile '%s' due to %+v", name, err)
+	}
+	commandPath := normalizeCommandPath(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	settings := profile.GetSettings(getCommandProduct(commandPath, args), commandPath)
+	return setProfileFlags(cmd.Flags(), settings)
+}
+
+// isUpdateCommand returns true if the command is an update command or one of its sub-commands
+func isUpdateCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == updateCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// setProfileFlags sets the flags of the flagset that weren't changed from the settings and skips the settings that aren't flags
+func setProfileFlags(flagset *pflag.FlagSet, settings map[string]interface{}) error {
+	profileSettings := make(map[string]interface{})
+	for flagName, value := range settings {
+		if flag := flagset.Lookup(flagName); flag != nil && !flag.Changed {
+			log.Debugf("setting flag '%s' from the profile", flagName)
+			profileSettings[flagName] = value
+		}
+	}
+	return apply.SetFlags(flagset, profileSettings, false)
+}
+
+// getCommandProduct returns the product of a command path such as "create blackduck", or the product of the first argument
+// of commands such as "images list blackduck"
+func getCommandProduct(commandPath string, args []string) string {
+	for _, word := range strings.Fields(commandPath) {
+		if isProfileProduct(word) {
+			return word
+		}
+	}
+	if len(args) > 0 && isProfileProduct(args[0]) {
+		return args[0]
+	}
+	return ""
+}
+
+// normalizeCommandPath returns the command path with single spaces, e.g. "create blackduck"
+func normalizeCommandPath(commandPath string) string {
+	return strings.Join(strings.Fields(commandPath), " ")
+}
+
+func isProfileProduct(product string) bool {
+	for _, p := range profileProducts {
+		if p == product {
+			return true
+		}
+	}
+	return false
+}
+
+// writeConfig writes the config to the config file in use or to ~/.synopsysctl.yaml
+func writeConfig() error {
+	configFile := viper.ConfigFileUsed()
+	if len(configFile) == 0 {
+		home, err := homedir.Dir()
+		if err != nil {
+			return fmt.Errorf("unable to find the home directory due to %+v", err)
+		}
+		configFile = filepath.Join(home, ".synopsysctl.yaml")
+	}
+	if err := viper.WriteConfigAs(configFile); err != nil {
+		return fmt.Errorf("failed to write config file '%s' due to %+v", configFile, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	configCmd.AddCommand(configViewCmd)
+
+	configSetCmd.Flags().StringVar(&configProduct, "product", configProduct, fmt.Sprintf("If set, set the default value for the commands of the product [%s]", strings.Join(profileProducts, "|")))
+	configSetCmd.Flags().StringVar(&configCommand, "command", configCommand, "If set, set the default value for the command, e.g. \"create blackduck\"")
+	configCmd.AddCommand(configSetCmd)
+
+	configCmd.AddCommand(configUseProfileCmd)
+}
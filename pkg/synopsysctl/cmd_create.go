@@ -22,6 +22,7 @@ under the License.
 package synopsysctl
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -29,6 +30,7 @@ import (
 	opssightv1 "github.com/blackducksoftware/synopsysctl/pkg/api/opssight/v1"
 	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
 	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
 	"github.com/blackducksoftware/synopsysctl/pkg/opssight"
 	"github.com/blackducksoftware/synopsysctl/pkg/polaris"
 	polarisreporting "github.com/blackducksoftware/synopsysctl/pkg/polaris-reporting"
@@ -38,7 +40,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -107,51 +108,26 @@ var createAlertCmd = &cobra.Command{
 			}
 		}
 
-		// Check Dry Run before deploying any resources
-		err = util.CreateWithHelm3(alertName, namespace, alertChartRepository, helmValuesMap, kubeConfigPath, true)
+		// Get the cert-manager certificate for Alert
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, util.AlertName, args[0], helmValuesMap)
 		if err != nil {
-			return fmt.Errorf(strings.Replace(fmt.Sprintf("failed to create Alert resources: %+v", err), fmt.Sprintf("release '%s' ", alertName), fmt.Sprintf("release '%s' ", args[0]), 0))
-		}
-
-		// Create secrets for Alert
-		certificateFlag := cmd.Flag("certificate-file-path")
-		certificateKeyFlag := cmd.Flag("certificate-key-file-path")
-		if certificateFlag.Changed && certificateKeyFlag.Changed {
-			certificateData, err := util.ReadFileData(certificateFlag.Value.String())
-			if err != nil {
-				log.Fatalf("failed to read certificate file: %+v", err)
-			}
-
-			certificateKeyData, err := util.ReadFileData(certificateKeyFlag.Value.String())
-			if err != nil {
-				log.Fatalf("failed to read certificate file: %+v", err)
-			}
-			customCertificateSecretName := "alert-custom-certificate"
-			customCertificateSecret := alertctl.GetAlertCustomCertificateSecret(namespace, customCertificateSecretName, certificateData, certificateKeyData)
-			util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, customCertificateSecretName)
-			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&customCertificateSecret); err != nil && !k8serrors.IsAlreadyExists(err) {
-				return fmt.Errorf("failed to create certifacte secret: %+v", err)
-			}
+			return err
 		}
 
-		javaKeystoreFlag := cmd.Flag("java-keystore-file-path")
-		if javaKeystoreFlag.Changed {
-			javaKeystoreData, err := util.ReadFileData(javaKeystoreFlag.Value.String())
-			if err != nil {
-				log.Fatalf("failed to read Java Keystore file: %+v", err)
-			}
-			javaKeystoreSecretName := "alert-java-keystore"
-			javaKeystoreSecret := alertctl.GetAlertJavaKeystoreSecret(namespace, javaKeystoreSecretName, javaKeystoreData)
-			util.SetHelmValueInMap(helmValuesMap, []string{"javaKeystoreSecretName"}, javaKeystoreSecretName)
-			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&javaKeystoreSecret); err != nil && !k8serrors.IsAlreadyExists(err) {
-				return fmt.Errorf("failed to create javakeystore secret: %+v", err)
-			}
+		// Get the secrets for Alert
+		secrets, err := alertctl.GetSecretsFromFlagsAndSetHelmValue(namespace, cmd.Flags(), helmValuesMap)
+		if err != nil {
+			return err
 		}
 
 		// Deploy Alert Resources
-		err = util.CreateWithHelm3(alertName, namespace, alertChartRepository, helmValuesMap, kubeConfigPath, false)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf(strings.Replace(fmt.Sprintf("failed to create Alert resources: %+v", err), fmt.Sprintf("release '%s' ", alertName), fmt.Sprintf("release '%s' ", args[0]), 0))
+			return err
+		}
+		err = c.CreateAlert(context.Background(), args[0], client.AlertValues{ChartURL: alertChartRepository, Values: helmValuesMap, Secrets: secrets, Certificate: certificate})
+		if err != nil {
+			return fmt.Errorf(strings.Replace(err.Error(), fmt.Sprintf("release '%s' ", alertName), fmt.Sprintf("release '%s' ", args[0]), 0))
 		}
 
 		log.Infof("Alert has been successfully Created!")
@@ -194,6 +170,17 @@ var createAlertNativeCmd = &cobra.Command{
 			}
 		}
 
+		// Get the cert-manager certificate for Alert
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, util.AlertName, args[0], helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if certificate != nil {
+			if _, err = PrintComponent(certificate, "YAML"); err != nil {
+				return err
+			}
+		}
+
 		// Get secrets for Alert
 		certificateFlag := cmd.Flag("certificate-file-path")
 		certificateKeyFlag := cmd.Flag("certificate-key-file-path")
@@ -229,6 +216,12 @@ var createAlertNativeCmd = &cobra.Command{
 			}
 		}
 
+		if enabled, ok := util.GetHelmValueFromMap(helmValuesMap, []string{"ingress", "enabled"}).(bool); ok && enabled {
+			if _, err = PrintComponent(alertctl.GetAlertIngress(namespace, args[0], helmValuesMap), "YAML"); err != nil {
+				return err
+			}
+		}
+
 		// Deploy Alert Resources
 		err = util.TemplateWithHelm3(alertName, namespace, alertChartRepository, helmValuesMap)
 		if err != nil {
@@ -265,6 +258,16 @@ func checkSealKey(flagset *pflag.FlagSet) {
 	cobra.MarkFlagRequired(flagset, "seal-key")
 }
 
+// requireBlackDuckCreateFlags marks the flags that creating a Black Duck instance requires, which depend on its database and certificate flags
+func requireBlackDuckCreateFlags(flagset *pflag.FlagSet) {
+	checkPasswords(flagset)
+	if !flagset.Lookup("cert-manager-issuer").Changed {
+		cobra.MarkFlagRequired(flagset, "certificate-file-path")
+		cobra.MarkFlagRequired(flagset, "certificate-key-file-path")
+	}
+	checkSealKey(flagset)
+}
+
 // createBlackDuckCmd creates a Black Duck instance
 var createBlackDuckCmd = &cobra.Command{
 	Use:           "blackduck NAME -n NAMESPACE",
@@ -278,10 +281,7 @@ var createBlackDuckCmd = &cobra.Command{
 			cmd.Help()
 			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
 		}
-		checkPasswords(cmd.Flags())
-		cobra.MarkFlagRequired(cmd.Flags(), "certificate-file-path")
-		cobra.MarkFlagRequired(cmd.Flags(), "certificate-key-file-path")
-		checkSealKey(cmd.Flags())
+		requireBlackDuckCreateFlags(cmd.Flags())
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -301,36 +301,23 @@ var createBlackDuckCmd = &cobra.Command{
 			}
 		}
 
-		secrets, err := blackduck.GetCertsFromFlagsAndSetHelmValue(args[0], namespace, cmd.Flags(), helmValuesMap)
+		// Get the cert-manager certificate for Black Duck
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, util.BlackDuckName, args[0], helmValuesMap)
 		if err != nil {
 			return err
 		}
-		for _, v := range secrets {
-			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&v); err != nil && !k8serrors.IsAlreadyExists(err) {
-				return fmt.Errorf("failed to create certifacte secret: %+v", err)
-			}
-		}
 
-		var extraFiles []string
-		size, found := helmValuesMap["size"]
-		if found {
-			extraFiles = append(extraFiles, fmt.Sprintf("%s.yaml", size.(string)))
-		}
-
-		// Check Dry Run before deploying any resources
-		err = util.CreateWithHelm3(args[0], namespace, blackduckChartRepository, helmValuesMap, kubeConfigPath, true, extraFiles...)
+		secrets, err := blackduck.GetCertsFromFlagsAndSetHelmValue(args[0], namespace, cmd.Flags(), helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+			return err
 		}
 
 		// Deploy Resources
-		err = util.CreateWithHelm3(args[0], namespace, blackduckChartRepository, helmValuesMap, kubeConfigPath, false, extraFiles...)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
+			return err
 		}
-
-		err = blackduck.CRUDServiceOrRoute(restconfig, kubeClient, namespace, args[0], helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
-		if err != nil {
+		if err := c.CreateBlackDuck(context.Background(), args[0], client.BlackDuckValues{ChartURL: blackduckChartRepository, Values: helmValuesMap, Secrets: secrets, Certificate: certificate}); err != nil {
 			return err
 		}
 
@@ -352,10 +339,7 @@ var createBlackDuckNativeCmd = &cobra.Command{
 			cmd.Help()
 			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
 		}
-		checkPasswords(cmd.Flags())
-		cobra.MarkFlagRequired(cmd.Flags(), "certificate-file-path")
-		cobra.MarkFlagRequired(cmd.Flags(), "certificate-key-file-path")
-		checkSealKey(cmd.Flags())
+		requireBlackDuckCreateFlags(cmd.Flags())
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -376,6 +360,12 @@ var createBlackDuckNativeCmd = &cobra.Command{
 			}
 		}
 
+		// Get the cert-manager certificate for Black Duck
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, util.BlackDuckName, args[0], helmValuesMap)
+		if err != nil {
+			return err
+		}
+
 		secrets, err := blackduck.GetCertsFromFlagsAndSetHelmValue(args[0], namespace, cmd.Flags(), helmValuesMap)
 		if err != nil {
 			return err
@@ -383,6 +373,9 @@ var createBlackDuckNativeCmd = &cobra.Command{
 		for _, v := range secrets {
 			PrintComponent(v, "YAML") // helm only supports yaml
 		}
+		if certificate != nil {
+			PrintComponent(certificate, "YAML") // helm only supports yaml
+		}
 
 		if helmValuesMap["exposeui"] != nil && helmValuesMap["exposeui"].(bool) {
 			switch helmValuesMap["exposedServiceType"].(string) {
@@ -395,9 +388,17 @@ var createBlackDuckNativeCmd = &cobra.Command{
 			case util.OPENSHIFT:
 				route := blackduck.GetWebServerRoute(namespace, util.GetResourceName(args[0], util.BlackDuckName, ""), args[0])
 				PrintComponent(route, "YAML") // helm only supports yaml
+			case util.INGRESS:
+				ingress := blackduck.GetWebServerIngress(namespace, util.GetResourceName(args[0], util.BlackDuckName, "webserver-ingress"), args[0], util.GetIngressConfigFromHelmValues(helmValuesMap, "ingress"))
+				PrintComponent(ingress, "YAML") // helm only supports yaml
 			}
 		}
 
+		// Print the horizontal pod autoscalers of the autoscaled components
+		if err := printHorizontalPodAutoscalers(args[0], blackduckChartRepository, helmValuesMap); err != nil {
+			return fmt.Errorf("failed to generate the horizontal pod autoscalers: %+v", err)
+		}
+
 		// Check Dry Run before deploying any resources
 		err = util.TemplateWithHelm3(args[0], namespace, blackduckChartRepository, helmValuesMap)
 		if err != nil {
@@ -556,16 +557,19 @@ var createPolarisCmd = &cobra.Command{
 			}
 		}
 
-		// Check Dry Run before deploying any resources
-		err = util.CreateWithHelm3(polarisName, namespace, polarisChartRepository, helmValuesMap, kubeConfigPath, true)
+		// Get the cert-manager certificate for Polaris
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, polarisName, polarisName, helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to create Polaris resources: %+v", err)
+			return err
 		}
 
 		// Deploy Polaris Resources
-		err = util.CreateWithHelm3(polarisName, namespace, polarisChartRepository, helmValuesMap, kubeConfigPath, false)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to create Polaris resources: %+v", err)
+			return err
+		}
+		if err := c.CreateInstance(context.Background(), client.Polaris, client.InstanceValues{ChartURL: polarisChartRepository, Values: helmValuesMap, Certificate: certificate}); err != nil {
+			return err
 		}
 
 		log.Infof("Polaris has been successfully Created!")
@@ -606,6 +610,17 @@ var createPolarisNativeCmd = &cobra.Command{
 			}
 		}
 
+		// Get the cert-manager certificate for Polaris
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, polarisName, polarisName, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if certificate != nil {
+			if _, err = PrintComponent(certificate, "YAML"); err != nil {
+				return err
+			}
+		}
+
 		// Print Polaris Resources
 		err = util.TemplateWithHelm3(polarisName, namespace, polarisChartRepository, helmValuesMap)
 		if err != nil {
@@ -672,10 +687,10 @@ var createPolarisReportingCmd = &cobra.Command{
 			}
 		}
 
-		// Check Dry Run before deploying any resources
-		err = util.CreateWithHelm3(polarisReportingName, namespace, polarisReportingChartRepository, helmValuesMap, kubeConfigPath, true)
+		// Get the cert-manager certificate for Polaris-Reporting
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, polarisReportingName, polarisReportingName, helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to create Polaris-Reporting resources: %+v", err)
+			return err
 		}
 
 		// Get Secret For the GCP Key
@@ -688,17 +703,20 @@ var createPolarisReportingCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to create GCP Service Account Secrets: %+v", err)
 		}
-
-		// Deploy the Secret
-		err = KubectlApplyRuntimeObjects(gcpServiceAccountSecrets)
-		if err != nil {
-			return fmt.Errorf("failed to deploy the gcpServiceAccount Secrets: %s", err)
+		secrets := []corev1.Secret{}
+		for _, obj := range gcpServiceAccountSecrets {
+			if secret, ok := obj.(*corev1.Secret); ok {
+				secrets = append(secrets, *secret)
+			}
 		}
 
 		// Deploy Polaris-Reporting Resources
-		err = util.CreateWithHelm3(polarisReportingName, namespace, polarisReportingChartRepository, helmValuesMap, kubeConfigPath, false)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to create Polaris-Reporting resources: %+v", err)
+			return err
+		}
+		if err := c.CreateInstance(context.Background(), client.PolarisReporting, client.InstanceValues{ChartURL: polarisReportingChartRepository, Values: helmValuesMap, Secrets: secrets, Certificate: certificate}); err != nil {
+			return err
 		}
 
 		log.Infof("Polaris-Reporting has been successfully Created!")
@@ -755,6 +773,17 @@ var createPolarisReportingNativeCmd = &cobra.Command{
 			PrintComponent(obj, "YAML") // helm only supports yaml
 		}
 
+		// Get the cert-manager certificate for Polaris-Reporting
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, polarisReportingName, polarisReportingName, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if certificate != nil {
+			if _, err = PrintComponent(certificate, "YAML"); err != nil {
+				return err
+			}
+		}
+
 		// Print Polaris-Reporting Resources
 		err = util.TemplateWithHelm3(polarisReportingName, namespace, polarisReportingChartRepository, helmValuesMap)
 		if err != nil {
@@ -799,16 +828,19 @@ var createBDBACmd = &cobra.Command{
 			}
 		}
 
-		// Check Dry Run before deploying any resources
-		err = util.CreateWithHelm3(bdbaName, namespace, bdbaChartRepository, helmValuesMap, kubeConfigPath, true)
+		// Get the cert-manager certificate for BDBA
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, bdbaName, bdbaName, helmValuesMap)
 		if err != nil {
-			return fmt.Errorf("failed to create BDBA resources: %+v", err)
+			return err
 		}
 
-		// Deploy Resources
-		err = util.CreateWithHelm3(bdbaName, namespace, bdbaChartRepository, helmValuesMap, kubeConfigPath, false)
+		// Deploy Resources and the horizontal pod autoscalers of the autoscaled components
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to create BDBA resources: %+v", err)
+			return err
+		}
+		if err := c.CreateInstance(context.Background(), client.BDBA, client.InstanceValues{ChartURL: bdbaChartRepository, Values: helmValuesMap, Certificate: certificate}); err != nil {
+			return err
 		}
 
 		log.Infof("BDBA has been successfully Created!")
@@ -849,6 +881,22 @@ var createBDBANativeCmd = &cobra.Command{
 			}
 		}
 
+		// Get the cert-manager certificate for BDBA
+		certificate, err := getCertManagerCertificate(cmd.Flags(), namespace, bdbaName, bdbaName, helmValuesMap)
+		if err != nil {
+			return err
+		}
+		if certificate != nil {
+			if _, err = PrintComponent(certificate, "YAML"); err != nil {
+				return err
+			}
+		}
+
+		// Print the horizontal pod autoscalers of the autoscaled components
+		if err := printHorizontalPodAutoscalers(bdbaName, bdbaChartRepository, helmValuesMap); err != nil {
+			return fmt.Errorf("failed to generate the horizontal pod autoscalers: %+v", err)
+		}
+
 		// Print Resources
 		err = util.TemplateWithHelm3(bdbaName, namespace, bdbaChartRepository, helmValuesMap)
 		if err != nil {
@@ -875,6 +923,7 @@ func init() {
 	cobra.MarkFlagRequired(createAlertCmd.PersistentFlags(), "namespace")
 	createAlertCobraHelper.AddCobraFlagsToCommand(createAlertCmd, true)
 	addChartLocationPathFlag(createAlertCmd)
+	addWaitFlags(util.AlertName, createAlertCmd)
 	createCmd.AddCommand(createAlertCmd)
 
 	createAlertCobraHelper.AddCobraFlagsToCommand(createAlertNativeCmd, true)
@@ -886,6 +935,7 @@ func init() {
 	cobra.MarkFlagRequired(createBlackDuckCmd.PersistentFlags(), "namespace")
 	addChartLocationPathFlag(createBlackDuckCmd)
 	createBlackDuckCobraHelper.AddCRSpecFlagsToCommand(createBlackDuckCmd, true)
+	addWaitFlags(util.BlackDuckName, createBlackDuckCmd)
 	createCmd.AddCommand(createBlackDuckCmd)
 
 	createBlackDuckCobraHelper.AddCRSpecFlagsToCommand(createBlackDuckNativeCmd, true)
@@ -896,6 +946,7 @@ func init() {
 	createOpsSightCmd.PersistentFlags().StringVar(&baseOpsSightSpec, "template", baseOpsSightSpec, "Base resource configuration to modify with flags [empty|upstream|default|disabledBlackDuck]")
 	createOpsSightCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	createOpsSightCobraHelper.AddCRSpecFlagsToCommand(createOpsSightCmd, true)
+	addWaitFlags(util.OpsSightName, createOpsSightCmd)
 	createCmd.AddCommand(createOpsSightCmd)
 
 	createOpsSightCobraHelper.AddCRSpecFlagsToCommand(createOpsSightNativeCmd, true)
@@ -907,6 +958,7 @@ func init() {
 	cobra.MarkFlagRequired(createPolarisCmd.PersistentFlags(), "namespace")
 	createPolarisCobraHelper.AddCobraFlagsToCommand(createPolarisCmd, true)
 	addChartLocationPathFlag(createPolarisCmd)
+	addWaitFlags(polarisName, createPolarisCmd)
 	createCmd.AddCommand(createPolarisCmd)
 
 	createPolarisCobraHelper.AddCobraFlagsToCommand(createPolarisNativeCmd, true)
@@ -918,6 +970,7 @@ func init() {
 	cobra.MarkFlagRequired(createPolarisReportingCmd.PersistentFlags(), "namespace")
 	createPolarisReportingCobraHelper.AddCobraFlagsToCommand(createPolarisReportingCmd, true)
 	addChartLocationPathFlag(createPolarisReportingCmd)
+	addWaitFlags(polarisReportingName, createPolarisReportingCmd)
 	createCmd.AddCommand(createPolarisReportingCmd)
 
 	createPolarisReportingCobraHelper.AddCobraFlagsToCommand(createPolarisReportingNativeCmd, true)
@@ -929,6 +982,7 @@ func init() {
 	cobra.MarkFlagRequired(createBDBACmd.PersistentFlags(), "namespace")
 	createBDBACobraHelper.AddCobraFlagsToCommand(createBDBACmd, true)
 	addChartLocationPathFlag(createBDBACmd)
+	addWaitFlags(bdbaName, createBDBACmd)
 	createCmd.AddCommand(createBDBACmd)
 
 	createBDBACobraHelper.AddCobraFlagsToCommand(createBDBANativeCmd, true)
@@ -22,14 +22,13 @@ under the License.
 package synopsysctl
 
 import (
+	"context"
 	"fmt"
-	"strings"
 
-	polarisreporting "github.com/blackducksoftware/synopsysctl/pkg/polaris-reporting"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -59,54 +58,13 @@ var deleteAlertCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		alertName := fmt.Sprintf("%s%s", args[0], AlertPostSuffix)
-
-		// Delete the Secrets
-		helmRelease, err := util.GetWithHelm3(alertName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf(strings.Replace(fmt.Sprintf("failed to get Alert values: %+v", err), fmt.Sprintf("instance '%s' ", alertName), fmt.Sprintf("instance '%s' ", args[0]), 0))
-		}
-		var name interface{}
-		var ok bool
-		if name, ok = helmRelease.Config["webserverCustomCertificatesSecretName"]; ok {
-			if err := util.DeleteSecret(kubeClient, namespace, name.(string)); err != nil {
-				return fmt.Errorf("failed to delete Alert custom certiface secret: %+v", err)
-			}
-		}
-		if name, ok = helmRelease.Config["javaKeystoreSecretName"]; ok {
-			if err := util.DeleteSecret(kubeClient, namespace, name.(string)); err != nil {
-				return fmt.Errorf("failed to delete Alert javaKeystore secret: %+v", err)
-			}
-		}
-
-		// Delete Alert Resources
-		err = util.DeleteWithHelm3(alertName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to delete Alert resources: %+v", err)
-		}
-
-		labelSelector := fmt.Sprintf("app=%s, name=%s", util.AlertName, alertName)
-		svcs, err := util.ListServices(kubeClient, namespace, labelSelector)
+		c, err := newClient()
 		if err != nil {
 			return err
 		}
-		for _, svc := range svcs.Items {
-			if strings.HasSuffix(svc.Name, "-exposed") {
-				if err := util.DeleteService(kubeClient, namespace, svc.Name); !k8serrors.IsNotFound(err) {
-					return err
-				}
-			}
-		}
-
-		pvcs, err := util.ListPVCs(kubeClient, namespace, labelSelector)
-		if err != nil {
+		if err := c.Delete(context.Background(), client.Alert, args[0]); err != nil {
 			return err
 		}
-		for _, pvc := range pvcs.Items {
-			if err := util.DeletePVC(kubeClient, namespace, pvc.Name); !k8serrors.IsNotFound(err) {
-				return err
-			}
-		}
 
 		log.Infof("Alert has been successfully Deleted!")
 		return nil
@@ -128,55 +86,12 @@ var deleteBlackDuckCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		err := util.DeleteWithHelm3(args[0], namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to delete Blackduck resources: %+v", err)
-		}
-
-		// delete secret
-		secrets := []string{"webserver-certificate", "proxy-certificate", "auth-custom-ca"}
-		for _, v := range secrets {
-			if err := util.DeleteSecret(kubeClient, namespace, fmt.Sprintf("%s-%s-%s", args[0], util.BlackDuckName, v)); err != nil && !k8serrors.IsNotFound(err) {
-				return fmt.Errorf("couldn't delete secret '%s' in namespace '%s' due to %+v", v, namespace, err)
-			}
-		}
-
-		labelSelector := fmt.Sprintf("app=%s, name=%s", util.BlackDuckName, args[0])
-		// delete exposed service
-		svcs, err := util.ListServices(kubeClient, namespace, labelSelector)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("couldn't list services in namespace '%s' due to %+v", namespace, err)
-		}
-		for _, svc := range svcs.Items {
-			if strings.HasSuffix(svc.Name, "-exposed") {
-				if err := util.DeleteService(kubeClient, namespace, svc.Name); err != nil && !k8serrors.IsNotFound(err) {
-					return fmt.Errorf("couldn't delete service '%s' in namespace '%s' due to %+v", svc.Name, namespace, err)
-				}
-			}
-		}
-
-		// delete PVC
-		pvcs, err := util.ListPVCs(kubeClient, namespace, labelSelector)
-		if err != nil {
-			return fmt.Errorf("couldn't list pvc in namespace '%s' due to %+v", namespace, err)
-		}
-		for _, pvc := range pvcs.Items {
-			if err := util.DeletePVC(kubeClient, namespace, pvc.Name); err != nil && !k8serrors.IsNotFound(err) {
-				return fmt.Errorf("couldn't delete pvc '%s' in namespace '%s' due to %+v", pvc.Name, namespace, err)
-			}
+			return err
 		}
-
-		// delete route
-		isOpenShift := util.IsOpenshift(kubeClient)
-		if isOpenShift {
-			routeName := util.GetResourceName(args[0], util.BlackDuckName, "")
-			routeClient := util.GetRouteClient(restconfig, kubeClient, namespace)
-			if _, err = util.GetRoute(routeClient, namespace, routeName); err == nil {
-				err = util.DeleteRoute(routeClient, namespace, routeName)
-				if err != nil {
-					return fmt.Errorf("unable to delete Black Duck webserver route due to %+v", err)
-				}
-			}
+		if err := c.Delete(context.Background(), client.BlackDuck, args[0]); err != nil {
+			return err
 		}
 
 		log.Infof("Black Duck has been successfully Deleted!")
@@ -231,10 +146,12 @@ var deletePolarisCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Delete Polaris Resources
-		err := util.DeleteWithHelm3(polarisName, namespace, kubeConfigPath)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to delete Polaris resources: %+v", err)
+			return err
+		}
+		if err := c.Delete(context.Background(), client.Polaris, polarisName); err != nil {
+			return err
 		}
 
 		log.Infof("Polaris has been successfully Deleted!")
@@ -258,22 +175,12 @@ var deletePolarisReportingCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get Secret For the GCP Key
-		gcpServiceAccountSecrets, err := polarisreporting.GetPolarisReportingSecrets(namespace, "EMPTY_DATA")
-		if err != nil {
-			return fmt.Errorf("failed to generate GCP Service Account Secrets: %+v", err)
-		}
-
-		// Delete the Secret
-		err = KubectlDeleteRuntimeObjects(gcpServiceAccountSecrets)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to delete the gcpServiceAccount Secrets: %s", err)
+			return err
 		}
-
-		// Delete Polaris-Reporting Resources
-		err = util.DeleteWithHelm3(polarisReportingName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to delete Polaris-Reporting resources: %+v", err)
+		if err := c.Delete(context.Background(), client.PolarisReporting, polarisReportingName); err != nil {
+			return err
 		}
 
 		log.Infof("Polaris-Reporting has been successfully Deleted!")
@@ -297,10 +204,12 @@ var deleteBDBACmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Delete Resources
-		err := util.DeleteWithHelm3(bdbaName, namespace, kubeConfigPath)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to delete BDBA resources: %+v", err)
+			return err
+		}
+		if err := c.Delete(context.Background(), client.BDBA, bdbaName); err != nil {
+			return err
 		}
 
 		log.Infof("BDBA has been successfully Deleted!")
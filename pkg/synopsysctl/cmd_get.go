@@ -32,10 +32,14 @@ import (
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // Get Command flag for -output functionality
-var getOutputFormat string
+var getOutputFormat = "table"
 
 // Get Command flag for -selector functionality
 var getSelector string
@@ -43,28 +47,6 @@ var getSelector string
 // Get Command flag for --all-namespaces functionality
 var getAllNamespaces bool
 
-func generateKubectlGetCommand(resourceName string, args []string) []string {
-	kubectlCmd := []string{"get", resourceName}
-	if len(namespace) > 0 {
-		kubectlCmd = append(kubectlCmd, "-n", namespace)
-	}
-	if len(args) > 0 {
-		kubectlCmd = append(kubectlCmd, args...)
-	}
-	if getOutputFormat != "" {
-		kubectlCmd = append(kubectlCmd, "-o")
-		kubectlCmd = append(kubectlCmd, getOutputFormat)
-	}
-	if getSelector != "" {
-		kubectlCmd = append(kubectlCmd, "-l")
-		kubectlCmd = append(kubectlCmd, getSelector)
-	}
-	if getAllNamespaces {
-		kubectlCmd = append(kubectlCmd, allNamespacesFlag)
-	}
-	return kubectlCmd
-}
-
 // getCmd lists resources in the cluster
 var getCmd = &cobra.Command{
 	Use:   "get",
@@ -76,53 +58,40 @@ var getCmd = &cobra.Command{
 
 // getAlertCmd display one or many Alert instances
 var getAlertCmd = &cobra.Command{
-	Use:           "alert NAME -n NAMESPACE",
-	Example:       "synopsysctl get alert <name> -n <namespace>",
+	Use:           "alert [NAME] -n NAMESPACE",
+	Example:       "synopsysctl get alert <name> -n <namespace>\nsynopsysctl get alerts -n <namespace> -o wide\nsynopsysctl get alerts --all-namespaces -o jsonpath='{.items[*].url}'",
 	Aliases:       []string{"alerts"},
-	Short:         "Display an Alert instance",
+	Short:         "Display one or many Alert instances",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		if len(args) > 1 {
+			return fmt.Errorf("this command takes up to 1 argument, but got %+v", args)
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		alertName := fmt.Sprintf("%s%s", args[0], AlertPostSuffix)
-		helmRelease, err := util.GetWithHelm3(alertName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf(strings.Replace(fmt.Sprintf("failed to get Alert values: %+v", err), fmt.Sprintf("instance '%s' ", alertName), fmt.Sprintf("instance '%s' ", args[0]), 0))
-		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return runGetHelmInstances(util.AlertName, args)
 	},
 }
 
-// getBlackDuckCmd display a Black Duck instances
+// getBlackDuckCmd display one or many Black Duck instances
 var getBlackDuckCmd = &cobra.Command{
-	Use:           "blackduck NAME -n NAMESPACE",
-	Example:       "synopsysctl get blackduck <name> -n <namespace>",
+	Use:           "blackduck [NAME] -n NAMESPACE",
+	Example:       "synopsysctl get blackduck <name> -n <namespace>\nsynopsysctl get blackducks -n <namespace> -o wide\nsynopsysctl get blackduck <name> -n <namespace> -o yaml",
 	Aliases:       []string{"blackducks"},
-	Short:         "Display a Black Duck instance",
+	Short:         "Display one or many Black Duck instances",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
+		if len(args) > 1 {
 			cmd.Help()
-			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+			return fmt.Errorf("this command takes up to 1 argument, but got %+v", args)
 		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		helmRelease, err := util.GetWithHelm3(args[0], namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to get Blackduck values: %+v", err)
-		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return runGetHelmInstances(util.BlackDuckName, args)
 	},
 }
 
@@ -141,6 +110,9 @@ var getBlackDuckRootKeyCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(namespace) == 0 {
+			return fmt.Errorf("required flag(s) \"namespace\" not set")
+		}
 		return getBlackDuckMasterKey(namespace, args[0], args[1])
 	},
 }
@@ -182,29 +154,31 @@ func getBlackDuckMasterKey(namespace string, name string, filePath string) error
 // getOpsSightCmd display one or many OpsSight instances
 var getOpsSightCmd = &cobra.Command{
 	Use:           "opssight [NAME...]",
-	Example:       "synopsysctl get opssights\nsynopsysctl get opssight <name>\nsynopsysctl get opssights <name1> <name2>",
+	Example:       "synopsysctl get opssights\nsynopsysctl get opssight <name>\nsynopsysctl get opssights <name1> <name2> -o json",
 	Aliases:       []string{"opssights"},
 	Short:         "Display one or many OpsSight instances",
+	Long:          "Display one or many OpsSight instances.\nThe output formats are those of the other get commands; the kubectl output formats 'name', 'custom-columns', 'custom-columns-file', 'jsonpath-file' and 'go-template-file' are no longer supported.",
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	Args: func(cmd *cobra.Command, args []string) error {
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		log.Debugf("getting OpsSight instances...")
-		out, err := RunKubeCmd(restconfig, kubeClient, generateKubectlGetCommand("opssights", args)...)
+		if err := util.ValidateGetOutputFormat(getOutputFormat); err != nil {
+			return err
+		}
+		instances, err := getOpsSightGetInstances(args)
 		if err != nil {
-			return fmt.Errorf("error getting OpsSight instances due to %+v - %s", out, err)
+			return err
 		}
-		fmt.Printf("%+v", out)
-		return nil
+		return printGetInstances(util.OpsSightName, instances, len(args) == 1)
 	},
 }
 
 // getPolarisCmd display the Polaris  instance
 var getPolarisCmd = &cobra.Command{
 	Use:           "polaris -n NAMESPACE",
-	Example:       "synopsysctl get polaris -n <namespace>",
+	Example:       "synopsysctl get polaris -n <namespace>\nsynopsysctl get polaris --all-namespaces",
 	Short:         "Display the polaris instance",
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -215,20 +189,14 @@ var getPolarisCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		helmRelease, err := util.GetWithHelm3(polarisName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to get Polaris values: %+v", err)
-		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return runGetHelmInstances(polarisName, args)
 	},
 }
 
 // getPolarisReportingCmd display the Polaris Reporting instance
 var getPolarisReportingCmd = &cobra.Command{
 	Use:           "polaris-reporting -n NAMESPACE",
-	Example:       "synopsysctl get polaris-reporting -n <namespace>",
+	Example:       "synopsysctl get polaris-reporting -n <namespace>\nsynopsysctl get polaris-reporting --all-namespaces",
 	Short:         "Display the polaris-reporting instance",
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -239,20 +207,14 @@ var getPolarisReportingCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		helmRelease, err := util.GetWithHelm3(polarisReportingName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to get Polaris-Reporting values: %+v", err)
-		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
+		return runGetHelmInstances(polarisReportingName, args)
 	},
 }
 
 // getBDBACmd display the BDBA instance
 var getBDBACmd = &cobra.Command{
 	Use:           "bdba -n NAMESPACE",
-	Example:       "synopsysctl get bdba -n <namespace>",
+	Example:       "synopsysctl get bdba -n <namespace>\nsynopsysctl get bdba --all-namespaces",
 	Short:         "Display the BDBA instance",
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -263,14 +225,243 @@ var getBDBACmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		helmRelease, err := util.GetWithHelm3(bdbaName, namespace, kubeConfigPath)
+		return runGetHelmInstances(bdbaName, args)
+	},
+}
+
+// runGetHelmInstances prints the Helm instances of the product. An instance is printed as an object if it was requested by name,
+// or if the product has a single instance per namespace and a namespace was given
+func runGetHelmInstances(product string, args []string) error {
+	if err := util.ValidateGetOutputFormat(getOutputFormat); err != nil {
+		return err
+	}
+	if !getAllNamespaces && len(namespace) == 0 {
+		return fmt.Errorf("namespace needs to be provided. please use the 'namespace' or the 'all-namespaces' option to set it")
+	}
+	instances, err := getHelmGetInstances(product, args)
+	if err != nil {
+		return err
+	}
+	single := len(args) == 1 || (!releaseProducts[product].takesName && !getAllNamespaces)
+	return printGetInstances(product, instances, single)
+}
+
+// printGetInstances prints the instances in the output format of the get command
+func printGetInstances(product string, instances []*util.GetInstance, single bool) error {
+	if len(instances) == 0 && (getOutputFormat == "table" || getOutputFormat == "wide") {
+		log.Infof("no %s instances found", product)
+		return nil
+	}
+	return util.PrintGetInstances(os.Stdout, instances, getOutputFormat, single)
+}
+
+// getHelmGetInstances returns the instance in args, or all the instances of the product in the namespace or in all namespaces
+func getHelmGetInstances(product string, args []string) ([]*util.GetInstance, error) {
+	var helmReleases []*release.Release
+	switch {
+	case getAllNamespaces:
+		var err error
+		if helmReleases, err = util.ListAllNamespacesWithHelm3(kubeConfigPath); err != nil {
+			return nil, err
+		}
+	case len(args) == 1 || !releaseProducts[product].takesName:
+		instanceName := product
+		if len(args) == 1 {
+			instanceName = args[0]
+		}
+		releaseName := releaseProducts[product].getReleaseName(instanceName)
+		helmRelease, err := util.GetWithHelm3(releaseName, namespace, kubeConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to get BDBA values: %+v", err)
+			return nil, fmt.Errorf(strings.Replace(fmt.Sprintf("failed to get instance: %+v", err), fmt.Sprintf("instance '%s' ", releaseName), fmt.Sprintf("instance '%s' ", instanceName), 0))
 		}
-		helmSetValues := helmRelease.Config
-		PrintComponent(helmSetValues, "YAML")
-		return nil
-	},
+		helmReleases = []*release.Release{helmRelease}
+	default:
+		var err error
+		if helmReleases, err = util.ListWithHelm3(namespace, kubeConfigPath); err != nil {
+			return nil, err
+		}
+	}
+
+	selector, err := labels.Parse(getSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector '%s' due to %+v", getSelector, err)
+	}
+	urlResolver := &instanceURLResolver{}
+	instances := []*util.GetInstance{}
+	for _, helmRelease := range helmReleases {
+		releaseProduct, name, ok := getReleaseInventoryProduct(helmRelease)
+		if !ok || releaseProduct != product || (len(args) == 1 && name != args[0]) {
+			continue
+		}
+		if selected, err := isHelmReleaseSelected(helmRelease, selector); err != nil {
+			return nil, err
+		} else if !selected {
+			continue
+		}
+		inventoryInstance := getHelmInventoryInstance(product, name, helmRelease)
+		instance := &util.GetInstance{
+			InventoryInstance: *inventoryInstance,
+			URL:               urlResolver.getURL(product, name, helmRelease.Namespace, helmRelease.Manifest),
+			Revision:          helmRelease.Version,
+			Values:            helmRelease.Config,
+		}
+		if helmRelease.Info != nil && !helmRelease.Info.LastDeployed.IsZero() {
+			instance.Updated = helmRelease.Info.LastDeployed.UTC().Format("2006-01-02 15:04:05 UTC")
+		}
+		instances = append(instances, instance)
+	}
+	if len(args) == 1 && len(instances) == 0 {
+		if getAllNamespaces {
+			return nil, fmt.Errorf("unable to find instance '%s' in any namespace", args[0])
+		}
+		return nil, fmt.Errorf("unable to find instance '%s' in namespace '%s'", args[0], namespace)
+	}
+	return instances, nil
+}
+
+// isHelmReleaseSelected returns true if a Deployment or StatefulSet of the release has labels that match the selector
+func isHelmReleaseSelected(helmRelease *release.Release, selector labels.Selector) (bool, error) {
+	if selector.Empty() {
+		return true, nil
+	}
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", helmRelease.Name, helmRelease.Namespace, err)
+	}
+	for _, resource := range resources {
+		kind := fmt.Sprintf("%v", resource["kind"])
+		if kind != "Deployment" && kind != "StatefulSet" {
+			continue
+		}
+		resourceLabels := labels.Set{}
+		if values, ok := util.GetHelmValueFromMap(resource, []string{"metadata", "labels"}).(map[string]interface{}); ok {
+			for key, value := range values {
+				resourceLabels[key] = fmt.Sprintf("%v", value)
+			}
+		}
+		if selector.Matches(resourceLabels) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getOpsSightGetInstances returns the OpsSight instances in args, or all of them in the namespace or in all namespaces
+func getOpsSightGetInstances(args []string) ([]*util.GetInstance, error) {
+	if _, err := util.GetCustomResourceDefinition(apiExtensionClient, util.OpsSightCRDName); err != nil {
+		return nil, fmt.Errorf("unable to get Custom Resource Definition '%s' in your cluster due to %+v", util.OpsSightCRDName, err)
+	}
+	opsSights, err := util.ListOpsSights(opsSightClient, metav1.NamespaceAll, metav1.ListOptions{LabelSelector: getSelector})
+	if err != nil {
+		return nil, fmt.Errorf("error getting OpsSight instances due to %+v", err)
+	}
+	urlResolver := &instanceURLResolver{}
+	instances := []*util.GetInstance{}
+	found := []string{}
+	for i := range opsSights.Items {
+		opsSight := opsSights.Items[i]
+		if len(args) > 0 && !util.IsExistInStringSlice(args, opsSight.Name) {
+			continue
+		}
+		if !getAllNamespaces && len(namespace) > 0 && opsSight.Spec.Namespace != namespace {
+			continue
+		}
+		found = append(found, opsSight.Name)
+		instances = append(instances, &util.GetInstance{
+			InventoryInstance: *util.GetInventoryInstanceFromOpsSight(&opsSight),
+			URL:               urlResolver.getURL(util.OpsSightName, opsSight.Name, opsSight.Spec.Namespace, ""),
+			Values:            opsSight.Spec,
+		})
+	}
+	for _, name := range args {
+		if !util.IsExistInStringSlice(found, name) {
+			return nil, fmt.Errorf("unable to find OpsSight instance '%s'", name)
+		}
+	}
+	return instances, nil
+}
+
+// instanceURLResolver finds the URLs of instances, caching what is the same for all of them
+type instanceURLResolver struct {
+	isOpenShift *bool
+	nodeAddress *string
+}
+
+// getURL returns the URL of an instance from its ingress, route or exposed service. These are either part of the release manifest,
+// or were created by synopsysctl with the app and name labels of the instance
+func (r *instanceURLResolver) getURL(product string, name string, namespace string, manifest string) string {
+	manifestObjects := map[string]bool{}
+	if resources, err := util.GetManifestResources(manifest); err == nil {
+		for _, resource := range resources {
+			manifestObjects[fmt.Sprintf("%v/%v", resource["kind"], util.GetHelmValueFromMap(resource, []string{"metadata", "name"}))] = true
+		}
+	}
+	isInstanceObject := func(kind string, objectMeta metav1.ObjectMeta) bool {
+		return manifestObjects[fmt.Sprintf("%s/%s", kind, objectMeta.Name)] || (objectMeta.Labels["app"] == product && objectMeta.Labels["name"] == name)
+	}
+
+	if ingresses, err := kubeClient.NetworkingV1beta1().Ingresses(namespace).List(metav1.ListOptions{}); err == nil {
+		for i := range ingresses.Items {
+			if isInstanceObject("Ingress", ingresses.Items[i].ObjectMeta) {
+				if url := util.GetIngressURL(&ingresses.Items[i]); len(url) > 0 {
+					return url
+				}
+			}
+		}
+	}
+	if r.isOpenShift == nil {
+		isOpenShift := util.IsOpenshift(kubeClient)
+		r.isOpenShift = &isOpenShift
+	}
+	if *r.isOpenShift {
+		routeClient := util.GetRouteClient(restconfig, kubeClient, namespace)
+		if routes, err := util.ListRoutes(routeClient, namespace, ""); err == nil {
+			for i := range routes.Items {
+				if isInstanceObject("Route", routes.Items[i].ObjectMeta) {
+					if url := util.GetRouteURL(&routes.Items[i]); len(url) > 0 {
+						return url
+					}
+				}
+			}
+		}
+	}
+	services, err := util.ListServices(kubeClient, namespace, "")
+	if err != nil {
+		return ""
+	}
+	for i := range services.Items {
+		service := &services.Items[i]
+		if !isInstanceObject("Service", service.ObjectMeta) {
+			continue
+		}
+		nodeAddress := ""
+		if service.Spec.Type == corev1.ServiceTypeNodePort {
+			nodeAddress = r.getNodeAddress()
+		}
+		if url := util.GetServiceURL(service, nodeAddress); len(url) > 0 {
+			return url
+		}
+	}
+	return ""
+}
+
+// getNodeAddress returns the address of a node of the cluster to reach NodePort services
+func (r *instanceURLResolver) getNodeAddress() string {
+	if r.nodeAddress == nil {
+		nodeAddress := ""
+		if nodes, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{}); err == nil && len(nodes.Items) > 0 {
+			nodeAddress = util.GetNodeAddress(&nodes.Items[0])
+		}
+		r.nodeAddress = &nodeAddress
+	}
+	return *r.nodeAddress
+}
+
+// addGetOutputFlags adds the output format, selector and all namespaces flags of the get commands
+func addGetOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&getOutputFormat, "output", "o", getOutputFormat, "Output format [table|wide|json|yaml|jsonpath=...|go-template=...]")
+	cmd.Flags().StringVarP(&getSelector, "selector", "l", getSelector, "Selector (label query) on the custom resources of OpsSight, or on the deployments and stateful sets of the other instances, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVar(&getAllNamespaces, "all-namespaces", getAllNamespaces, "If present, list the instances across all namespaces")
 }
 
 func init() {
@@ -279,35 +470,33 @@ func init() {
 
 	// Alert
 	getAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getAlertCmd.Flags(), "namespace")
+	addGetOutputFlags(getAlertCmd)
 	getCmd.AddCommand(getAlertCmd)
 
 	// Black Duck
 	getBlackDuckCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getBlackDuckCmd.PersistentFlags(), "namespace")
+	addGetOutputFlags(getBlackDuckCmd)
 	getCmd.AddCommand(getBlackDuckCmd)
 
 	getBlackDuckCmd.AddCommand(getBlackDuckRootKeyCmd)
 
 	// OpsSight
-	getOpsSightCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	getOpsSightCmd.Flags().StringVarP(&getOutputFormat, "output", "o", getOutputFormat, "Output format [json,yaml,wide,name,custom-columns=...,custom-columns-file=...,go-template=...,go-template-file=...,jsonpath=...,jsonpath-file=...]")
-	getOpsSightCmd.Flags().StringVarP(&getSelector, "selector", "l", getSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
-	getOpsSightCmd.Flags().BoolVar(&getAllNamespaces, "all-namespaces", getAllNamespaces, "If present, list the requested object(s) across all namespaces")
+	getOpsSightCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s) [default: all namespaces]")
+	addGetOutputFlags(getOpsSightCmd)
 	getCmd.AddCommand(getOpsSightCmd)
 
 	// Polaris
 	getPolarisCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getPolarisCmd.Flags(), "namespace")
+	addGetOutputFlags(getPolarisCmd)
 	getCmd.AddCommand(getPolarisCmd)
 
 	// Polaris Reporting
 	getPolarisReportingCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getPolarisReportingCmd.Flags(), "namespace")
+	addGetOutputFlags(getPolarisReportingCmd)
 	getCmd.AddCommand(getPolarisReportingCmd)
 
 	// BDBA
 	getBDBACmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
-	cobra.MarkFlagRequired(getBDBACmd.Flags(), "namespace")
+	addGetOutputFlags(getBDBACmd)
 	getCmd.AddCommand(getBDBACmd)
 }
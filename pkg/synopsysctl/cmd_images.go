@@ -0,0 +1,193 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Images Command flags
+var imagesVersion string
+var imagesValuesFilePath string
+var imagesRegistry string
+var imagesPullSecretName string
+var imagesPinDigest bool
+var imagesRegistryUsername string
+var imagesRegistryPassword string
+var imagesInsecureRegistry bool
+var imagesRegistrySkipTLSVerify bool
+
+// imagesCmd lists and mirrors the images of the Synopsys resources
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "List and mirror the images of a Synopsys resource",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// imagesListCmd lists the images of a product version
+var imagesListCmd = &cobra.Command{
+	Use:           "list PRODUCT --version VERSION",
+	Example:       "synopsysctl images list blackduck --version 2020.4.0\nsynopsysctl images list alert --version 5.3.0 --registry registry.example.com/blackducksoftware",
+	Short:         fmt.Sprintf("List the images of a product version [%s|%s|%s|%s|%s]", util.BlackDuckName, util.AlertName, polarisName, polarisReportingName, bdbaName),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args:          checkProductChartArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		images, err := getProductChartImages(args[0], getProductChartURL(cmd, args[0], imagesVersion), imagesValuesFilePath)
+		if err != nil {
+			return err
+		}
+		for _, image := range images {
+			if len(imagesRegistry) > 0 {
+				if image, err = util.GetRegistryImage(image, imagesRegistry); err != nil {
+					return err
+				}
+			}
+			fmt.Println(image)
+		}
+		return nil
+	},
+}
+
+// imagesMirrorCmd copies the images of a product version to a registry
+var imagesMirrorCmd = &cobra.Command{
+	Use:           "mirror PRODUCT --version VERSION --registry REGISTRY",
+	Example:       "synopsysctl images mirror blackduck --version 2020.4.0 --registry registry.example.com/blackducksoftware\nsynopsysctl images mirror blackduck --version 2020.4.0 --registry registry.example.com/blackducksoftware --registry-username <username> --registry-password <password> --pull-secret-name <name> -n <namespace>",
+	Short:         fmt.Sprintf("Copy the images of a product version to a registry [%s|%s|%s|%s|%s]", util.BlackDuckName, util.AlertName, polarisName, polarisReportingName, bdbaName),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := checkProductChartArgs(cmd, args); err != nil {
+			return err
+		}
+		if cmd.Flag("pull-secret-name").Changed {
+			cobra.MarkFlagRequired(cmd.Flags(), "namespace")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		images, err := getProductChartImages(args[0], getProductChartURL(cmd, args[0], imagesVersion), imagesValuesFilePath)
+		if err != nil {
+			return err
+		}
+		pinnedImages, err := mirrorImages(images, imagesRegistry)
+		if err != nil {
+			return err
+		}
+		if imagesPinDigest {
+			for _, image := range pinnedImages {
+				fmt.Println(image)
+			}
+		}
+		log.Infof("successfully mirrored %d images of %s %s to '%s'", len(images), args[0], imagesVersion, imagesRegistry)
+
+		installFlags := fmt.Sprintf("--registry %s", imagesRegistry)
+		if len(imagesPullSecretName) > 0 {
+			secret, err := util.GetImagePullSecret(namespace, imagesPullSecretName, imagesRegistry, imagesRegistryUsername, imagesRegistryPassword)
+			if err != nil {
+				return fmt.Errorf("failed to create the image pull secret: %+v", err)
+			}
+			if _, err := kubeClient.CoreV1().Secrets(namespace).Create(secret); err != nil {
+				if !k8serrors.IsAlreadyExists(err) {
+					return fmt.Errorf("failed to create image pull secret '%s' in namespace '%s' due to %+v", imagesPullSecretName, namespace, err)
+				}
+				if _, err := util.UpdateSecret(kubeClient, namespace, secret); err != nil {
+					return fmt.Errorf("failed to update image pull secret '%s' in namespace '%s' due to %+v", imagesPullSecretName, namespace, err)
+				}
+			}
+			log.Infof("successfully created image pull secret '%s' in namespace '%s'", imagesPullSecretName, namespace)
+			installFlags = fmt.Sprintf("%s --pull-secret-name %s", installFlags, imagesPullSecretName)
+		}
+		if args[0] == util.BlackDuckName || args[0] == util.AlertName {
+			log.Infof("use '%s' to install %s from the mirrored images", installFlags, args[0])
+		}
+		return nil
+	},
+}
+
+// mirrorImages copies the images to the registry and returns the mirrored images pinned by digest
+func mirrorImages(images []string, registry string) ([]string, error) {
+	dir, err := ioutil.TempDir("", "synopsysctl-images")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory due to %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the credentials are only sent to the registry the images are copied to
+	sourceClient := util.NewRegistryClient("", "", false, false)
+	targetClient := util.NewRegistryClient(imagesRegistryUsername, imagesRegistryPassword, imagesInsecureRegistry, imagesRegistrySkipTLSVerify)
+	pinnedImages := []string{}
+	for _, image := range images {
+		targetImage, err := util.GetRegistryImage(image, registry)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("copying image '%s' to '%s'", image, targetImage)
+		saved, err := sourceClient.PullImage(image, dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := targetClient.PushImage(saved, dir, targetImage); err != nil {
+			return nil, err
+		}
+		pinnedImage, err := util.GetPinnedImage(targetImage, saved.ManifestDigest)
+		if err != nil {
+			return nil, err
+		}
+		pinnedImages = append(pinnedImages, pinnedImage)
+	}
+	return pinnedImages, nil
+}
+
+func init() {
+	rootCmd.AddCommand(imagesCmd)
+
+	for _, cmd := range []*cobra.Command{imagesListCmd, imagesMirrorCmd} {
+		cmd.Flags().StringVar(&imagesVersion, "version", imagesVersion, "Version of the product")
+		cobra.MarkFlagRequired(cmd.Flags(), "version")
+		cmd.Flags().StringVar(&imagesValuesFilePath, "values", imagesValuesFilePath, "Path of a Helm values file used to render the chart, e.g. to include the images of optional components")
+		addChartLocationPathFlag(cmd)
+		imagesCmd.AddCommand(cmd)
+	}
+
+	imagesListCmd.Flags().StringVar(&imagesRegistry, "registry", imagesRegistry, "If set, list the images as they are named in the registry they are mirrored to")
+
+	imagesMirrorCmd.Flags().StringVar(&imagesRegistry, "registry", imagesRegistry, "Registry to copy the images to, e.g. registry.example.com/blackducksoftware")
+	cobra.MarkFlagRequired(imagesMirrorCmd.Flags(), "registry")
+	imagesMirrorCmd.Flags().BoolVar(&imagesPinDigest, "pin-digest", imagesPinDigest, "If true, print the mirrored images pinned by digest")
+	imagesMirrorCmd.Flags().StringVar(&imagesPullSecretName, "pull-secret-name", imagesPullSecretName, "If set, create an image pull secret with the credentials of the registry to use with the --pull-secret-name flag of the create commands")
+	imagesMirrorCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the image pull secret")
+	imagesMirrorCmd.Flags().StringVar(&imagesRegistryUsername, "registry-username", imagesRegistryUsername, "Username of the registry to copy the images to")
+	imagesMirrorCmd.Flags().StringVar(&imagesRegistryPassword, "registry-password", imagesRegistryPassword, "Password of the registry to copy the images to")
+	util.MarkFlagSecret(imagesMirrorCmd.Flags(), "registry-password")
+	imagesMirrorCmd.Flags().BoolVar(&imagesInsecureRegistry, "insecure-registry", imagesInsecureRegistry, "If true, connect to the registry to copy the images to with http instead of https")
+	imagesMirrorCmd.Flags().BoolVar(&imagesRegistrySkipTLSVerify, "registry-skip-tls-verify", imagesRegistrySkipTLSVerify, "If true, don't verify the certificate of the registry to copy the images to")
+}
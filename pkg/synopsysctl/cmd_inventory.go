@@ -0,0 +1,303 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Inventory Command flags
+var inventoryOutputFormat = "table"
+
+// inventoryCmd lists the instances of all Synopsys products in the cluster
+var inventoryCmd = &cobra.Command{
+	Use:           "inventory [-n NAMESPACE]",
+	Example:       "synopsysctl inventory\nsynopsysctl inventory -n <namespace> -o csv",
+	Short:         "List the Helm and operator based instances of all Synopsys products",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := strings.ToLower(inventoryOutputFormat)
+		if format != "table" && format != "json" && format != "csv" {
+			return fmt.Errorf("'%s' is an invalid output format, must be one of [table|json|csv]", inventoryOutputFormat)
+		}
+		helmInstances, err := getHelmInventory()
+		if err != nil {
+			return err
+		}
+		operatorInstances, err := getOperatorInventory()
+		if err != nil {
+			return err
+		}
+		instances := append(helmInstances, operatorInstances...)
+		util.SortInventoryInstances(instances)
+
+		switch format {
+		case "json":
+			if _, err := PrintComponent(instances, "json"); err != nil {
+				return err
+			}
+		case "csv":
+			if err := util.WriteInventoryCSV(os.Stdout, instances); err != nil {
+				return fmt.Errorf("failed to write the inventory due to %+v", err)
+			}
+		default:
+			printInventoryTable(instances)
+		}
+		return nil
+	},
+}
+
+// printInventoryTable prints the instances as a table
+func printInventoryTable(instances []*util.InventoryInstance) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(util.InventoryColumns, "\t"))
+	for _, instance := range instances {
+		row := util.GetInventoryRow(instance)
+		for i := range row {
+			if len(row[i]) == 0 {
+				row[i] = "<none>"
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// getHelmInventory returns the Helm based instances in the namespace, or in all namespaces if no namespace is given
+func getHelmInventory() ([]*util.InventoryInstance, error) {
+	var helmReleases []*release.Release
+	var err error
+	if len(namespace) > 0 {
+		helmReleases, err = util.ListWithHelm3(namespace, kubeConfigPath)
+	} else {
+		helmReleases, err = util.ListAllNamespacesWithHelm3(kubeConfigPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ownedNamespaces := map[string]bool{}
+	instances := []*util.InventoryInstance{}
+	for _, helmRelease := range helmReleases {
+		product, name, ok := getReleaseInventoryProduct(helmRelease)
+		if !ok {
+			continue
+		}
+		instance := getHelmInventoryInstance(product, name, helmRelease)
+		// instances migrated from the operator keep the namespace that was created by it
+		if _, ok := ownedNamespaces[helmRelease.Namespace]; !ok {
+			ownedNamespaces[helmRelease.Namespace] = util.IsOwnerLabelExistInNamespace(kubeClient, helmRelease.Namespace)
+		}
+		if ownedNamespaces[helmRelease.Namespace] {
+			instance.Deployment = util.InventoryDeploymentMigrated
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// getHelmInventoryInstance returns the inventory of a Helm instance. Its state is unknown if its workloads couldn't be read
+func getHelmInventoryInstance(product string, name string, helmRelease *release.Release) *util.InventoryInstance {
+	instance := &util.InventoryInstance{
+		Product:    product,
+		Name:       name,
+		Namespace:  helmRelease.Namespace,
+		Deployment: util.InventoryDeploymentHelm,
+		ExposeType: util.GetInventoryExposeTypeFromHelmValues(helmRelease.Config),
+	}
+	if helmRelease.Chart != nil && helmRelease.Chart.Metadata != nil {
+		instance.Version = helmRelease.Chart.Metadata.Version
+	}
+	if size, ok := util.GetHelmValueFromMap(helmRelease.Config, []string{"size"}).(string); ok {
+		instance.Size = size
+	}
+	releaseStatus := ""
+	if helmRelease.Info != nil {
+		releaseStatus = helmRelease.Info.Status.String()
+	}
+	instance.State = util.GetInventoryStateFromHelmValues(helmRelease.Config, releaseStatus)
+	if !releaseProducts[product].takesName {
+		stopped, err := isHelmReleaseStopped(helmRelease)
+		if err != nil {
+			log.Warnf("the state of %s '%s' in namespace '%s' is unknown: %+v", product, name, helmRelease.Namespace, err)
+			instance.State = util.InventoryStateUnknown
+		} else if stopped {
+			instance.State = "Stopped"
+		}
+	}
+	return instance
+}
+
+// getReleaseInventoryProduct returns the product and the instance name of a release, and false if the release isn't a Synopsys product
+func getReleaseInventoryProduct(helmRelease *release.Release) (string, string, bool) {
+	switch {
+	case isBlackDuckRelease(helmRelease):
+		return util.BlackDuckName, helmRelease.Name, true
+	case isAlertRelease(helmRelease):
+		return util.AlertName, strings.TrimSuffix(helmRelease.Name, AlertPostSuffix), true
+	case helmRelease.Name == bdbaName, helmRelease.Name == polarisName, helmRelease.Name == polarisReportingName:
+		return helmRelease.Name, helmRelease.Name, true
+	}
+	return "", "", false
+}
+
+// isHelmReleaseStopped returns true if all the Deployments and StatefulSets of the release were scaled down by the stop command
+func isHelmReleaseStopped(helmRelease *release.Release) (bool, error) {
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		return false, fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", helmRelease.Name, helmRelease.Namespace, err)
+	}
+	workloads := 0
+	for _, resource := range resources {
+		kind := fmt.Sprintf("%v", resource["kind"])
+		name := fmt.Sprintf("%v", util.GetHelmValueFromMap(resource, []string{"metadata", "name"}))
+		var annotations map[string]string
+		switch kind {
+		case "Deployment":
+			deployment, err := util.GetDeployment(kubeClient, helmRelease.Namespace, name)
+			if err != nil {
+				return false, fmt.Errorf("couldn't get deployment '%s' in namespace '%s' due to %+v", name, helmRelease.Namespace, err)
+			}
+			annotations = deployment.Annotations
+		case "StatefulSet":
+			statefulSet, err := util.GetStatefulSet(kubeClient, helmRelease.Namespace, name)
+			if err != nil {
+				return false, fmt.Errorf("couldn't get statefulset '%s' in namespace '%s' due to %+v", name, helmRelease.Namespace, err)
+			}
+			annotations = statefulSet.Annotations
+		default:
+			continue
+		}
+		if _, ok := annotations[util.StoppedReplicasAnnotation]; !ok {
+			return false, nil
+		}
+		workloads++
+	}
+	return workloads > 0, nil
+}
+
+// getOperatorInventory returns the Black Duck, Alert and OpsSight custom resources in the namespace, or in all namespaces if no namespace is given
+func getOperatorInventory() ([]*util.InventoryInstance, error) {
+	// operator namespaces are only used to report which operator manages an instance
+	operatorNamespaces, _ := util.GetOperatorNamespace(kubeClient, metav1.NamespaceAll)
+	isClusterScoped := util.GetClusterScope(apiExtensionClient)
+
+	instances := []*util.InventoryInstance{}
+	if exists, err := isCustomResourceDefinitionExist(util.BlackDuckCRDName); err != nil {
+		return nil, err
+	} else if exists {
+		blackDucks, err := util.ListBlackduck(blackDuckClient, metav1.NamespaceAll, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Black Duck instances due to %+v", err)
+		}
+		for i := range blackDucks.Items {
+			instance := util.GetInventoryInstanceFromBlackDuck(&blackDucks.Items[i])
+			instance.OperatorNamespace = getManagingOperatorNamespace(blackDucks.Items[i].Namespace, operatorNamespaces, isClusterScoped)
+			instances = append(instances, instance)
+		}
+	}
+	if exists, err := isCustomResourceDefinitionExist(util.AlertCRDName); err != nil {
+		return nil, err
+	} else if exists {
+		alerts, err := util.ListAlerts(alertClient, metav1.NamespaceAll, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Alert instances due to %+v", err)
+		}
+		for i := range alerts.Items {
+			instance := util.GetInventoryInstanceFromAlert(&alerts.Items[i])
+			instance.OperatorNamespace = getManagingOperatorNamespace(alerts.Items[i].Namespace, operatorNamespaces, isClusterScoped)
+			instances = append(instances, instance)
+		}
+	}
+	if exists, err := isCustomResourceDefinitionExist(util.OpsSightCRDName); err != nil {
+		return nil, err
+	} else if exists {
+		opsSights, err := util.ListOpsSights(opsSightClient, metav1.NamespaceAll, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list OpsSight instances due to %+v", err)
+		}
+		for i := range opsSights.Items {
+			instance := util.GetInventoryInstanceFromOpsSight(&opsSights.Items[i])
+			instance.OperatorNamespace = getManagingOperatorNamespace(opsSights.Items[i].Namespace, operatorNamespaces, isClusterScoped)
+			instances = append(instances, instance)
+		}
+	}
+
+	if len(namespace) == 0 {
+		return instances, nil
+	}
+	namespaceInstances := []*util.InventoryInstance{}
+	for _, instance := range instances {
+		if instance.Namespace == namespace {
+			namespaceInstances = append(namespaceInstances, instance)
+		}
+	}
+	return namespaceInstances, nil
+}
+
+// isCustomResourceDefinitionExist returns true if the custom resource definition exists, and an error if it couldn't be read
+func isCustomResourceDefinitionExist(name string) (bool, error) {
+	if _, err := util.GetCustomResourceDefinition(apiExtensionClient, name); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("couldn't get custom resource definition '%s' due to %+v", name, err)
+	}
+	return true, nil
+}
+
+// getManagingOperatorNamespace returns the namespace of the operator that manages a custom resource. A cluster scoped
+// operator manages the custom resources of all namespaces, a namespace scoped one only those of its own namespace.
+func getManagingOperatorNamespace(crNamespace string, operatorNamespaces []string, isClusterScoped bool) string {
+	if isClusterScoped {
+		return strings.Join(operatorNamespaces, ",")
+	}
+	if util.IsExistInStringSlice(operatorNamespaces, crNamespace) {
+		return crNamespace
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+
+	inventoryCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s) [default: all namespaces]")
+	inventoryCmd.Flags().StringVarP(&inventoryOutputFormat, "output", "o", inventoryOutputFormat, "Output format [table|json|csv]")
+}
@@ -0,0 +1,221 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/blackduck"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Restore Command flags
+var restoreArchivePath string
+var restoreTimeout int64 = 600
+
+// restoreCmd restores a Synopsys resource in the cluster from a backup
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a Synopsys resource in your cluster from a backup",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// restoreBlackDuckCmd restores a Black Duck instance from a backup archive
+var restoreBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE --archive-path ARCHIVE",
+	Example:       "synopsysctl restore blackduck <name> -n <namespace> --archive-path <namespace>-<name>-<timestamp>.tar.gz",
+	Short:         "Restore a Black Duck instance from a backup archive",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := ioutil.TempDir("", "synopsysctl-restore")
+		if err != nil {
+			return fmt.Errorf("failed to create a temporary directory for the backup due to %+v", err)
+		}
+		defer os.RemoveAll(dir)
+		manifest, err := extractBackupArchive(restoreArchivePath, dir)
+		if err != nil {
+			return err
+		}
+		if util.ReleaseExists(args[0], namespace, kubeConfigPath) {
+			return fmt.Errorf("Black Duck '%s' already exists in namespace '%s'", args[0], namespace)
+		}
+
+		helmValuesMap := make(map[string]interface{})
+		values, err := ioutil.ReadFile(filepath.Join(dir, backupValuesFileName))
+		if err != nil {
+			return fmt.Errorf("failed to read the Black Duck values from the backup archive: %+v", err)
+		}
+		if err := yaml.Unmarshal(values, &helmValuesMap); err != nil {
+			return fmt.Errorf("failed to read the Black Duck values from the backup archive: %+v", err)
+		}
+
+		// Update the Helm Chart Location
+		chartLocationFlag := cmd.Flag("chart-location-path")
+		if chartLocationFlag.Changed {
+			blackduckChartRepository = chartLocationFlag.Value.String()
+		} else if len(manifest.ChartVersion) > 0 {
+			blackduckChartRepository = fmt.Sprintf("%s/charts/blackduck-%s.tgz", baseChartRepository, manifest.ChartVersion)
+		}
+
+		// Recreate the certificate secrets with the name of the restored instance
+		secrets := []corev1.Secret{}
+		for _, secretName := range manifest.Secrets {
+			secret, err := getBackupSecret(dir, secretName, namespace)
+			if err != nil {
+				return err
+			}
+			if manifest.Name != args[0] {
+				for _, v := range blackduck.BackupCertificateSecrets {
+					if secretName == util.GetResourceName(manifest.Name, util.BlackDuckName, v) {
+						secret.Name = util.GetResourceName(args[0], util.BlackDuckName, v)
+						if len(secret.Labels) > 0 {
+							secret.Labels["name"] = args[0]
+						}
+					}
+				}
+			}
+			secrets = append(secrets, *secret)
+		}
+
+		// Deploy Resources
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		if err := c.CreateBlackDuck(context.Background(), args[0], client.BlackDuckValues{ChartURL: blackduckChartRepository, Values: helmValuesMap, Secrets: secrets}); err != nil {
+			return err
+		}
+
+		// Stop every component except postgres so that nothing writes to the databases until they are restored
+		stoppedWorkloads, err := stopRestoreComponents(c, args[0])
+		if err != nil {
+			return err
+		}
+
+		// Restore the databases once postgres is ready
+		log.Infof("waiting for the postgres pod of Black Duck '%s' in namespace '%s' to be ready...", args[0], namespace)
+		labelSelector := fmt.Sprintf("app=%s,name=%s,component=postgres", util.BlackDuckName, args[0])
+		if err := util.WaitUntilPodsAreReady(kubeClient, namespace, labelSelector, restoreTimeout); err != nil {
+			return fmt.Errorf("postgres of Black Duck '%s' in namespace '%s' isn't ready: %+v", args[0], namespace, err)
+		}
+		postgresPod, err := blackduck.GetPostgresPod(kubeClient, namespace, args[0])
+		if err != nil {
+			return fmt.Errorf("unable to find the postgres pod of Black Duck '%s' in namespace '%s' due to %+v", args[0], namespace, err)
+		}
+		adminUser := blackduck.GetPostgresAdminUser(helmValuesMap)
+		for _, database := range manifest.Databases {
+			log.Infof("restoring database '%s' of Black Duck '%s' in namespace '%s'...", database, args[0], namespace)
+			if err := restoreBackupDatabase(dir, postgresPod, adminUser, database); err != nil {
+				return err
+			}
+		}
+
+		// Start the other components with the restored databases
+		for _, workload := range stoppedWorkloads {
+			if err := c.ScaleWorkload(workload, &workload.Replicas); err != nil {
+				return err
+			}
+		}
+
+		log.Infof("successfully restored Black Duck '%s' in namespace '%s' from '%s'", args[0], namespace, restoreArchivePath)
+		return nil
+	},
+}
+
+// stopRestoreComponents scales the workloads of every component of a restored Black Duck instance except postgres to 0
+// and waits for their pods to be removed. It returns the stopped workloads with the replicas of their manifest
+func stopRestoreComponents(c *client.Client, name string) ([]util.ComponentWorkload, error) {
+	helmRelease, err := util.GetWithHelm3(name, namespace, kubeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find instance %s in namespace %s", name, namespace)
+	}
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", name, namespace, err)
+	}
+	stoppedWorkloads := []util.ComponentWorkload{}
+	waitResources := []util.WaitResource{}
+	for component, workloads := range util.GetComponentWorkloads(resources, name) {
+		if component == "postgres" {
+			continue
+		}
+		for _, workload := range workloads {
+			if err := c.ScaleWorkload(workload, util.IntToInt32(0)); err != nil {
+				return nil, err
+			}
+			stoppedWorkloads = append(stoppedWorkloads, workload)
+			waitResources = append(waitResources, util.WaitResource{Kind: workload.Kind, Name: workload.Name})
+		}
+	}
+	target := &util.WaitTarget{
+		Description: fmt.Sprintf("components of Black Duck '%s' in namespace '%s'", name, namespace),
+		Namespace:   namespace,
+		Resources:   waitResources,
+		Stopped:     true,
+	}
+	if err := waitForTargets([]*util.WaitTarget{target}, time.Duration(restoreTimeout)*time.Second); err != nil {
+		return nil, err
+	}
+	return stoppedWorkloads, nil
+}
+
+// restoreBackupDatabase streams the dump of a database from the extracted backup archive to pg_restore
+func restoreBackupDatabase(dir string, postgresPod *corev1.Pod, adminUser string, database string) error {
+	file, err := os.Open(filepath.Join(dir, backupPostgresDirecory, fmt.Sprintf("%s.dump", database)))
+	if err != nil {
+		return fmt.Errorf("dump of database '%s' is missing in the backup archive", database)
+	}
+	defer file.Close()
+	return blackduck.RestoreDatabase(restconfig, kubeClient, postgresPod, adminUser, database, file)
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreBlackDuckCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(restoreBlackDuckCmd.Flags(), "namespace")
+	restoreBlackDuckCmd.Flags().StringVar(&restoreArchivePath, "archive-path", restoreArchivePath, "Path of the backup archive to restore")
+	cobra.MarkFlagRequired(restoreBlackDuckCmd.Flags(), "archive-path")
+	restoreBlackDuckCmd.Flags().Int64Var(&restoreTimeout, "timeout", restoreTimeout, "Seconds to wait for postgres to be ready and for the other components to be stopped before restoring the databases")
+	addChartLocationPathFlag(restoreBlackDuckCmd)
+	restoreCmd.AddCommand(restoreBlackDuckCmd)
+}
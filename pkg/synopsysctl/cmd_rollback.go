@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Rollback Command flags
+var rollbackToRevision int
+
+// Update rollback flags
+var updateTimeout int64 = 900
+var updateDisableRollback = false
+
+// rollbackCmd rolls back a Synopsys resource to a previous revision
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back a Synopsys resource to a previous revision",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// rollbackAlertCmd rolls back an Alert instance to a previous revision
+var rollbackAlertCmd = &cobra.Command{
+	Use:           "alert NAME -n NAMESPACE",
+	Example:       "synopsysctl rollback alert <name> -n <namespace>\nsynopsysctl rollback alert <name> -n <namespace> --to-revision 2",
+	Short:         "Roll back an Alert instance to a previous revision",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rollbackRelease(util.AlertName, args[0], rollbackToRevision); err != nil {
+			return err
+		}
+		log.Infof("successfully rolled back Alert '%s' in namespace '%s'", args[0], namespace)
+		return nil
+	},
+}
+
+// rollbackBlackDuckCmd rolls back a Black Duck instance to a previous revision
+var rollbackBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck NAME -n NAMESPACE",
+	Example:       "synopsysctl rollback blackduck <name> -n <namespace>\nsynopsysctl rollback blackduck <name> -n <namespace> --to-revision 2",
+	Short:         "Roll back a Black Duck instance to a previous revision",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := rollbackRelease(util.BlackDuckName, args[0], rollbackToRevision); err != nil {
+			return err
+		}
+		log.Infof("successfully rolled back Black Duck '%s' in namespace '%s'", args[0], namespace)
+		return nil
+	},
+}
+
+// rollbackRelease rolls back the release of an instance to the revision and restores the secrets that were saved before the revision was upgraded.
+// If the revision is 0, the release is rolled back to the previous revision
+func rollbackRelease(product string, instanceName string, revision int) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	return c.Rollback(context.Background(), product, instanceName, revision)
+}
+
+// waitForUpdateOrRollback waits for the pods of an updated instance to be ready and rolls back the release to the previous revision if they aren't.
+// The wait is skipped if rollbacks are disabled and --wait isn't set. Otherwise it also serves --wait, so the instance isn't waited for twice
+func waitForUpdateOrRollback(c *client.Client, product string, instanceName string, previousRevision int) error {
+	if updateDisableRollback && !waitForInstance {
+		return nil
+	}
+	target, err := getReleaseWaitTarget(c.Namespace(), product, instanceName, false)
+	if err == nil {
+		err = waitForTargets([]*util.WaitTarget{target}, time.Duration(updateTimeout)*time.Second)
+	}
+	if err == nil {
+		waitedForInstance = true
+		return nil
+	}
+	return c.RollbackFailedUpdate(product, instanceName, previousRevision, err)
+}
+
+// addUpdateRollbackFlags adds the flags that control the readiness wait and the automatic rollback of an update
+func addUpdateRollbackFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64Var(&updateTimeout, "timeout", updateTimeout, "Seconds to wait for the pods to be ready after the update before rolling back, or before failing if --disable-rollback is set")
+	addDisableRollbackFlag(cmd)
+}
+
+// addDisableRollbackFlag adds the flag that disables the automatic rollback of a failed update
+func addDisableRollbackFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&updateDisableRollback, "disable-rollback", updateDisableRollback, "If true, don't roll back to the previous revision when the update fails")
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(rollbackAlertCmd.Flags(), "namespace")
+	rollbackAlertCmd.Flags().IntVar(&rollbackToRevision, "to-revision", rollbackToRevision, "Revision to roll back to (default the previous revision)")
+	rollbackCmd.AddCommand(rollbackAlertCmd)
+
+	rollbackBlackDuckCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(rollbackBlackDuckCmd.Flags(), "namespace")
+	rollbackBlackDuckCmd.Flags().IntVar(&rollbackToRevision, "to-revision", rollbackToRevision, "Revision to roll back to (default the previous revision)")
+	rollbackCmd.AddCommand(rollbackBlackDuckCmd)
+}
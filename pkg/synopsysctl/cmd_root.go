@@ -24,8 +24,10 @@ package synopsysctl
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	homedir "github.com/mitchellh/go-homedir"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -35,8 +37,14 @@ import (
 // Root Command Options and Defaults
 var cfgFile string
 var kubeConfigPath = ""
+var kubeContext = ""
 var insecureSkipTLSVerify = false
 var logLevelCtl = "info"
+var disableChartCache = false
+var chartKeyring = ""
+var profileName string
+var simulate = false
+var simulateStatePath = ""
 
 // synopsysctlVersion is the current version of the synopsysctl utility
 var synopsysctlVersion string
@@ -54,12 +62,29 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
+		// The command runs once in each context with --context, so it doesn't access a cluster itself
+		if isAllContextsCommand(cmd) {
+			if cmd.Flags().Lookup("context").Changed {
+				return fmt.Errorf("--context can't be used with --all-contexts or --contexts")
+			}
+			if simulate {
+				return fmt.Errorf("--simulate can't be used with --all-contexts or --contexts")
+			}
+			return nil
+		}
+		util.KubeContext = kubeContext
+
 		// Determine if synopsysctl is running in native command
-		nativeMode := strings.Contains(cmd.CommandPath(), "native")
+		nativeMode := strings.Contains(cmd.CommandPath(), "native") || isOfflineCommand(cmd)
 
 		// Don't set cluster resources if we are in native mode (aka the command doesn't need access the cluster)
 		// This allows users to use native when not connected to a cluster
-		if !nativeMode {
+		if !nativeMode && simulate {
+			if err := setGlobalSimulatedClients(); err != nil {
+				return err
+			}
+			util.RegisterSecretProvider("k8s", util.NewKubernetesSecretProvider(kubeClient, namespace))
+		} else if !nativeMode {
 			if err := setGlobalKubeConfigPath(cmd); err != nil {
 				log.Error(err)
 				os.Exit(1)
@@ -76,6 +101,12 @@ var rootCmd = &cobra.Command{
 				log.Error(err)
 				os.Exit(1)
 			}
+			util.RegisterSecretProvider("k8s", util.NewKubernetesSecretProvider(kubeClient, namespace))
+		}
+
+		// Read the secret flags from files, stdin, environment variables or secret stores and prompt for the missing required ones
+		if err := util.ResolveSecretFlags(cmd.Flags(), os.Stdin, util.NewTerminalSecretPrompt()); err != nil {
+			return err
 		}
 		return nil
 	},
@@ -84,11 +115,32 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// isOfflineCommand returns true if the command runs on a machine with internet access that doesn't need to be connected to the cluster
+func isOfflineCommand(cmd *cobra.Command) bool {
+	switch cmd {
+	case airgapPackCmd, imagesListCmd, chartPullCmd, chartListCmd, chartPruneCmd, configViewCmd, configSetCmd, configUseProfileCmd:
+		return true
+	case imagesMirrorCmd:
+		return !cmd.Flag("pull-secret-name").Changed
+	}
+	return false
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(version string) {
 	rootCmd.Version = version
-	if err := rootCmd.Execute(); err != nil {
+	wrapAllContextsCommands(getCmd, statusCmd, updateCmd)
+	wrapProfileCommands(rootCmd)
+	err := rootCmd.Execute()
+	// The changes are saved even if the command failed part way, as they would have been made in a real cluster
+	if util.Simulation != nil {
+		if saveErr := util.Simulation.Save(); saveErr != nil {
+			log.Errorf("synopsyctl failed: %+v", saveErr)
+			os.Exit(1)
+		}
+	}
+	if err != nil {
 		log.Errorf("synopsyctl failed: %+v", err)
 		os.Exit(1)
 	}
@@ -98,24 +150,48 @@ func init() {
 	//(PassCmd) rootCmd.DisableFlagParsing = true // lets rootCmd pass flags to kube/oc
 
 	cobra.OnInitialize(initConfig)
+	log.AddHook(&util.SecretMaskingHook{})
+	util.RegisterSecretProvider("vault", util.NewVaultSecretProviderFromEnv())
 	rootCmd.PersistentFlags().StringVar(&kubeConfigPath, "kubeconfig", kubeConfigPath, "Path to a kubeconfig file with the context set to a cluster for synopsysctl to access")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", kubeContext, "Name of the kubeconfig context to use instead of the current context")
 	rootCmd.PersistentFlags().BoolVar(&insecureSkipTLSVerify, "insecure-skip-tls-verify", insecureSkipTLSVerify, "Server's certificate won't be validated. HTTPS will be less secure")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", profileName, "Name of the profile in the config file to set the default flag values from")
+	rootCmd.PersistentFlags().BoolVar(&disableChartCache, "disable-chart-cache", disableChartCache, "If true, don't use the chart cache in ~/.synopsysctl/charts, which provides the pinned charts and the charts that can't be downloaded from the chart repository")
+	rootCmd.PersistentFlags().StringVar(&chartKeyring, "chart-keyring", chartKeyring, "Path to a PGP keyring that the provenance files of the downloaded charts must be signed with. The charts aren't verified if it isn't set")
+	rootCmd.PersistentFlags().BoolVar(&simulate, "simulate", simulate, "If true, run against a simulated cluster with fake clients and in-memory Helm releases instead of the cluster of the kubeconfig")
+	rootCmd.PersistentFlags().StringVar(&simulateStatePath, "simulate-state", simulateStatePath, "Path of the file that the simulated cluster is loaded from and saved to (default ~/.synopsysctl/simulate.json)")
 	rootCmd.PersistentFlags().StringVarP(&logLevelCtl, "verbose-level", "v", logLevelCtl, "Log level for synopsysctl [trace|debug|info|warn|error|fatal|panic]")
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	// Find home directory.
+	home, err := homedir.Dir()
+	if err != nil {
+		log.Errorf("unable to find the home directory due to %+v", err)
+		os.Exit(1)
+	}
+
+	// Cache the downloaded charts
+	if !disableChartCache {
+		util.ChartCacheDirectory = filepath.Join(home, ".synopsysctl", "charts")
+	}
+	// The charts are verified when they are pulled to the cache
+	if len(chartKeyring) > 0 && disableChartCache {
+		log.Errorf("--chart-keyring can't be used with --disable-chart-cache")
+		os.Exit(1)
+	}
+	util.ChartKeyring = chartKeyring
+
+	// Keep the simulated cluster in the home directory unless --simulate-state is set
+	if len(simulateStatePath) == 0 {
+		simulateStatePath = filepath.Join(home, ".synopsysctl", "simulate.json")
+	}
+
 	if cfgFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(cfgFile)
 	} else {
-		// Find home directory.
-		home, err := homedir.Dir()
-		if err != nil {
-			log.Errorf("unable to find the home directory due to %+v", err)
-			os.Exit(1)
-		}
-
 		// Search config in home directory with name ".synopsysctl" (without extension).
 		viper.AddConfigPath(home)
 		viper.SetConfigName(".synopsysctl")
@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Scale Command flags
+var scaleComponentReplicas map[string]int
+
+// scaleCmd sets the replicas of the components of an instance
+var scaleCmd = &cobra.Command{
+	Use:           "scale PRODUCT [NAME] -n NAMESPACE --component COMPONENT=REPLICAS",
+	Example:       "synopsysctl scale blackduck <name> -n <namespace> --component jobrunner=3,scan=2\nsynopsysctl scale bdba -n <namespace> --component worker=4",
+	Short:         "Set the replicas of the components of an instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := getReleaseInstance(args); err != nil {
+			cmd.Help()
+			return err
+		}
+		if len(scaleComponentReplicas) == 0 {
+			return fmt.Errorf("must specify at least one --component")
+		}
+		for component, replicas := range scaleComponentReplicas {
+			if replicas < 0 {
+				return fmt.Errorf("replicas of component '%s' must be 0 or more, but got %d", component, replicas)
+			}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		product, name, _ := getReleaseInstance(args)
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		if err := c.Scale(context.Background(), product, name, scaleComponentReplicas); err != nil {
+			return err
+		}
+		log.Infof("successfully scaled %s '%s' in namespace '%s'", product, name, namespace)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scaleCmd)
+
+	scaleCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(scaleCmd.Flags(), "namespace")
+	scaleCmd.Flags().StringToIntVar(&scaleComponentReplicas, "component", scaleComponentReplicas, "Replicas of the components, e.g. jobrunner=3,scan=2")
+}
@@ -0,0 +1,241 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Schedule Command flags
+var scheduleStop string
+var scheduleStart string
+var scheduleImage string
+
+// scheduleCmd manages the start/stop schedules of Synopsys resources
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage the start/stop schedules of Synopsys resources",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// scheduleSetCmd creates or replaces the start/stop schedule of an instance
+var scheduleSetCmd = &cobra.Command{
+	Use:           "set PRODUCT [NAME] -n NAMESPACE",
+	Example:       "synopsysctl schedule set blackduck <name> -n <namespace> --stop \"0 20 * * 1-5\" --start \"0 7 * * 1-5\"\nsynopsysctl schedule set bdba -n <namespace> --stop \"0 20 * * 5\" --start \"0 7 * * 1\"",
+	Short:         "Stop and start an instance on a cron schedule",
+	Long:          "Stop and start an instance on a cron schedule.\nThe schedule runs 'synopsysctl stop' and 'synopsysctl start' in the synopsysctl image with a service account whose role only allows upgrading the release of the instance and scaling its deployments and stateful sets.\nThe default image docker.io/blackducksoftware/synopsysctl:<version of synopsysctl> is built by 'make image' and must be pushed to a registry that the cluster can pull from, otherwise set --image.",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := getReleaseInstance(args); err != nil {
+			cmd.Help()
+			return err
+		}
+		if len(scheduleStop) == 0 && len(scheduleStart) == 0 {
+			return fmt.Errorf("must specify --stop, --start or both")
+		}
+		for _, schedule := range []string{scheduleStop, scheduleStart} {
+			if len(schedule) == 0 {
+				continue
+			}
+			if err := util.ValidateCronSchedule(schedule); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		product, name, _ := getReleaseInstance(args)
+		releaseName := releaseProducts[product].getReleaseName(name)
+
+		helmRelease, err := util.GetWithHelm3(releaseName, namespace, kubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("couldn't find %s '%s' in namespace '%s' due to %+v", product, name, namespace, err)
+		}
+		resources, err := util.GetManifestResources(helmRelease.Manifest)
+		if err != nil {
+			return fmt.Errorf("failed to read the resources of %s '%s' in namespace '%s' due to %+v", product, name, namespace, err)
+		}
+		image := scheduleImage
+		if len(image) == 0 {
+			image = getScheduleDefaultImage(rootCmd.Version)
+			log.Warnf("the schedule runs in image '%s', which is built by 'make image'; push it to a registry that the cluster can pull from or set --image", image)
+		}
+
+		scheduleName := fmt.Sprintf("%s-schedule", releaseName)
+		labels := getScheduleLabels(product, name, releaseName)
+		if err := util.CreateOrUpdateScheduleServiceAccount(kubeClient, util.GetScheduleServiceAccount(namespace, scheduleName, labels)); err != nil {
+			return fmt.Errorf("failed to create the schedule service account due to %+v", err)
+		}
+		if err := util.CreateOrUpdateRole(kubeClient, util.GetScheduleRole(namespace, scheduleName, labels, resources)); err != nil {
+			return fmt.Errorf("failed to create the schedule role due to %+v", err)
+		}
+		if err := util.CreateOrUpdateRoleBinding(kubeClient, util.GetScheduleRoleBinding(namespace, scheduleName, labels)); err != nil {
+			return fmt.Errorf("failed to create the schedule role binding due to %+v", err)
+		}
+
+		actions := []struct {
+			action   string
+			schedule string
+		}{
+			{action: "stop", schedule: scheduleStop},
+			{action: "start", schedule: scheduleStart},
+		}
+		for _, action := range actions {
+			cronJobName := fmt.Sprintf("%s-%s", releaseName, action.action)
+			// setting a schedule replaces the previous one, so an action without a schedule is removed
+			if len(action.schedule) == 0 {
+				if err := util.DeleteCronJobIfExists(kubeClient, namespace, cronJobName); err != nil {
+					return fmt.Errorf("failed to delete the %s schedule due to %+v", action.action, err)
+				}
+				continue
+			}
+			cronJobLabels := getScheduleLabels(product, name, releaseName)
+			cronJobLabels[util.ScheduleActionLabel] = action.action
+			command := util.GetScheduleCommand(action.action, product, name, namespace)
+			cronJob := util.GetScheduleCronJob(namespace, cronJobName, cronJobLabels, action.schedule, image, scheduleName, command)
+			if err := util.CreateOrUpdateCronJob(kubeClient, cronJob); err != nil {
+				return fmt.Errorf("failed to create the %s schedule due to %+v", action.action, err)
+			}
+		}
+
+		log.Infof("successfully scheduled %s '%s' in namespace '%s'", product, name, namespace)
+		return nil
+	},
+}
+
+// scheduleListCmd lists the start/stop schedules
+var scheduleListCmd = &cobra.Command{
+	Use:           "list [-n NAMESPACE]",
+	Example:       "synopsysctl schedule list\nsynopsysctl schedule list -n <namespace>",
+	Short:         "List the start/stop schedules of the instances",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cronJobs, err := util.ListCronJobs(kubeClient, namespace, util.ScheduleReleaseLabel)
+		if err != nil {
+			return fmt.Errorf("failed to list the schedules due to %+v", err)
+		}
+		sort.Slice(cronJobs.Items, func(i, j int) bool {
+			if cronJobs.Items[i].Namespace != cronJobs.Items[j].Namespace {
+				return cronJobs.Items[i].Namespace < cronJobs.Items[j].Namespace
+			}
+			return cronJobs.Items[i].Name < cronJobs.Items[j].Name
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAMESPACE\tPRODUCT\tNAME\tACTION\tSCHEDULE\tSUSPENDED\tLAST SCHEDULE")
+		for _, cronJob := range cronJobs.Items {
+			lastSchedule := "<none>"
+			if cronJob.Status.LastScheduleTime != nil {
+				lastSchedule = cronJob.Status.LastScheduleTime.UTC().Format("2006-01-02 15:04:05 UTC")
+			}
+			suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n", cronJob.Namespace, cronJob.Labels["app"], cronJob.Labels["name"], cronJob.Labels[util.ScheduleActionLabel], cronJob.Spec.Schedule, suspended, lastSchedule)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// scheduleRemoveCmd removes the start/stop schedule of an instance
+var scheduleRemoveCmd = &cobra.Command{
+	Use:           "remove PRODUCT [NAME] -n NAMESPACE",
+	Example:       "synopsysctl schedule remove blackduck <name> -n <namespace>\nsynopsysctl schedule remove bdba -n <namespace>",
+	Short:         "Remove the start/stop schedule of an instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if _, _, err := getReleaseInstance(args); err != nil {
+			cmd.Help()
+			return err
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		product, name, _ := getReleaseInstance(args)
+		releaseName := releaseProducts[product].getReleaseName(name)
+
+		for _, action := range []string{"stop", "start"} {
+			if err := util.DeleteCronJobIfExists(kubeClient, namespace, fmt.Sprintf("%s-%s", releaseName, action)); err != nil {
+				return fmt.Errorf("failed to delete the %s schedule due to %+v", action, err)
+			}
+		}
+		if err := util.DeleteScheduleRBAC(kubeClient, namespace, fmt.Sprintf("%s-schedule", releaseName)); err != nil {
+			return fmt.Errorf("failed to delete the schedule service account due to %+v", err)
+		}
+
+		log.Infof("successfully removed the schedule of %s '%s' in namespace '%s'", product, name, namespace)
+		return nil
+	},
+}
+
+// getScheduleDefaultImage returns the synopsysctl image of this version, or the latest one for a development build
+func getScheduleDefaultImage(version string) string {
+	if len(version) == 0 {
+		version = "latest"
+	}
+	return fmt.Sprintf("docker.io/blackducksoftware/synopsysctl:%s", version)
+}
+
+// getScheduleLabels returns the labels of the resources of a schedule
+func getScheduleLabels(product string, name string, releaseName string) map[string]string {
+	return map[string]string{
+		"app":                     product,
+		"name":                    name,
+		util.ScheduleReleaseLabel: releaseName,
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+
+	scheduleSetCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(scheduleSetCmd.Flags(), "namespace")
+	scheduleSetCmd.Flags().StringVar(&scheduleStop, "stop", scheduleStop, "Cron schedule to stop the instance, e.g. \"0 20 * * 1-5\"")
+	scheduleSetCmd.Flags().StringVar(&scheduleStart, "start", scheduleStart, "Cron schedule to start the instance, e.g. \"0 7 * * 1-5\"")
+	scheduleSetCmd.Flags().StringVar(&scheduleImage, "image", scheduleImage, "synopsysctl image that runs the scheduled stop and start, which must be pullable by the cluster [default: docker.io/blackducksoftware/synopsysctl:<version of synopsysctl> built by 'make image']")
+	scheduleCmd.AddCommand(scheduleSetCmd)
+
+	scheduleListCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s) [default: all namespaces]")
+	scheduleCmd.AddCommand(scheduleListCmd)
+
+	scheduleRemoveCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance")
+	cobra.MarkFlagRequired(scheduleRemoveCmd.Flags(), "namespace")
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+}
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -58,31 +59,11 @@ var startAlertCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		alertName := args[0]
-
-		instance, err := util.GetWithHelm3(alertName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("couldn't find instance %s in namespace %s", args[0], namespace)
-		}
-
-		// Update the Helm Chart Location
-		configAlertData := instance.Config["alert"].(map[string]interface{})
-		configAlertVersion := configAlertData["imageTag"]
-		chartLocationFlag := cmd.Flag("chart-location-path")
-		if chartLocationFlag.Changed {
-			alertChartRepository = chartLocationFlag.Value.String()
-		} else {
-			alertChartRepository = fmt.Sprintf("%s/charts/alert-%s.tgz", baseChartRepository, configAlertVersion)
-		}
-
-		helmValuesMap := map[string]interface{}{"status": "Running"}
-
-		err = util.UpdateWithHelm3(alertName, namespace, alertChartRepository, helmValuesMap, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to create Alert resources: %+v", err)
+		if err := setInstanceStatus(cmd, client.Alert, args[0], "Running"); err != nil {
+			return err
 		}
 
-		log.Infof("successfully submitted start Alert '%s' in namespace '%s'", alertName, namespace)
+		log.Infof("successfully submitted start Alert '%s' in namespace '%s'", args[0], namespace)
 
 		return nil
 	},
@@ -103,28 +84,7 @@ var startBlackDuckCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-
-		instance, err := util.GetWithHelm3(args[0], namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("couldn't find instance %s in namespace %s", args[0], namespace)
-		}
-
-		// Update the Helm Chart Location
-		chartLocationFlag := cmd.Flag("chart-location-path")
-		if chartLocationFlag.Changed {
-			blackduckChartRepository = chartLocationFlag.Value.String()
-		} else {
-			blackduckChartRepository = fmt.Sprintf("%s/charts/blackduck-%s.tgz", baseChartRepository, instance.Chart.Values["imageTag"])
-		}
-
-		helmValuesMap := make(map[string]interface{})
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Running")
-
-		err = util.UpdateWithHelm3(args[0], namespace, blackduckChartRepository, helmValuesMap, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
-		}
-		return nil
+		return setInstanceStatus(cmd, client.BlackDuck, args[0], "Running")
 	},
 }
 
@@ -177,6 +137,122 @@ var startOpsSightCmd = &cobra.Command{
 	},
 }
 
+// startPolarisCmd starts a Polaris instance
+var startPolarisCmd = &cobra.Command{
+	Use:           "polaris -n NAMESPACE",
+	Example:       "synopsysctl start polaris -n <namespace>",
+	Short:         "Start a Polaris instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := startHelmRelease(polarisName, namespace); err != nil {
+			return fmt.Errorf("failed to start Polaris: %+v", err)
+		}
+		log.Infof("successfully started Polaris in namespace '%s'", namespace)
+		return nil
+	},
+}
+
+// startPolarisReportingCmd starts a Polaris-Reporting instance
+var startPolarisReportingCmd = &cobra.Command{
+	Use:           "polaris-reporting -n NAMESPACE",
+	Example:       "synopsysctl start polaris-reporting -n <namespace>",
+	Short:         "Start a Polaris-Reporting instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := startHelmRelease(polarisReportingName, namespace); err != nil {
+			return fmt.Errorf("failed to start Polaris-Reporting: %+v", err)
+		}
+		log.Infof("successfully started Polaris-Reporting in namespace '%s'", namespace)
+		return nil
+	},
+}
+
+// startBDBACmd starts a BDBA instance
+var startBDBACmd = &cobra.Command{
+	Use:           "bdba -n NAMESPACE",
+	Example:       "synopsysctl start bdba -n <namespace>",
+	Short:         "Start a BDBA instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := startHelmRelease(bdbaName, namespace); err != nil {
+			return fmt.Errorf("failed to start BDBA: %+v", err)
+		}
+		log.Infof("successfully started BDBA in namespace '%s'", namespace)
+		return nil
+	},
+}
+
+// startHelmRelease restores the replicas of the Deployments and StatefulSets of a release stopped by stopHelmRelease
+func startHelmRelease(releaseName string, namespace string) error {
+	workloads, err := getReleaseWorkloads(releaseName, namespace)
+	if err != nil {
+		return err
+	}
+	for _, name := range workloads["Deployment"] {
+		deployment, err := util.GetDeployment(kubeClient, namespace, name)
+		if err != nil {
+			return fmt.Errorf("couldn't get deployment '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		replicas, ok, err := util.GetStoppedReplicas(deployment.Annotations)
+		if err != nil {
+			return fmt.Errorf("couldn't start deployment '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		if !ok {
+			continue
+		}
+		delete(deployment.Annotations, util.StoppedReplicasAnnotation)
+		deployment.Spec.Replicas = replicas
+		if _, err := util.UpdateDeployment(kubeClient, namespace, deployment); err != nil {
+			return fmt.Errorf("couldn't start deployment '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		log.Debugf("started deployment '%s' in namespace '%s' with %d replicas", name, namespace, *replicas)
+	}
+	for _, name := range workloads["StatefulSet"] {
+		statefulSet, err := util.GetStatefulSet(kubeClient, namespace, name)
+		if err != nil {
+			return fmt.Errorf("couldn't get stateful set '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		replicas, ok, err := util.GetStoppedReplicas(statefulSet.Annotations)
+		if err != nil {
+			return fmt.Errorf("couldn't start stateful set '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		if !ok {
+			continue
+		}
+		delete(statefulSet.Annotations, util.StoppedReplicasAnnotation)
+		statefulSet.Spec.Replicas = replicas
+		if _, err := util.UpdateStatefulSet(kubeClient, namespace, statefulSet); err != nil {
+			return fmt.Errorf("couldn't start stateful set '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		log.Debugf("started stateful set '%s' in namespace '%s' with %d replicas", name, namespace, *replicas)
+	}
+	return nil
+}
+
 func init() {
 	startAlertCobraHelper = *alertctl.NewHelmValuesFromCobraFlags()
 
@@ -185,13 +261,31 @@ func init() {
 	startAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(startAlertCmd.Flags(), "namespace")
 	addChartLocationPathFlag(startAlertCmd)
+	addWaitFlags(util.AlertName, startAlertCmd)
 	startCmd.AddCommand(startAlertCmd)
 
 	startBlackDuckCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(startBlackDuckCmd.Flags(), "namespace")
 	addChartLocationPathFlag(startBlackDuckCmd)
+	addWaitFlags(util.BlackDuckName, startBlackDuckCmd)
 	startCmd.AddCommand(startBlackDuckCmd)
 
 	startOpsSightCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	addWaitFlags(util.OpsSightName, startOpsSightCmd)
 	startCmd.AddCommand(startOpsSightCmd)
+
+	startPolarisCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(startPolarisCmd.Flags(), "namespace")
+	addWaitFlags(polarisName, startPolarisCmd)
+	startCmd.AddCommand(startPolarisCmd)
+
+	startPolarisReportingCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(startPolarisReportingCmd.Flags(), "namespace")
+	addWaitFlags(polarisReportingName, startPolarisReportingCmd)
+	startCmd.AddCommand(startPolarisReportingCmd)
+
+	startBDBACmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(startBDBACmd.Flags(), "namespace")
+	addWaitFlags(bdbaName, startBDBACmd)
+	startCmd.AddCommand(startBDBACmd)
 }
@@ -0,0 +1,247 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Status Command flag for -output functionality
+var statusOutputFormat = "table"
+
+// statusCmd shows the health of Synopsys resources in the cluster
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the health of a Synopsys resource in your cluster",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("must specify a sub-command")
+	},
+}
+
+// statusAlertCmd shows the health of one or many Alert instances
+var statusAlertCmd = &cobra.Command{
+	Use:           "alert [NAME] -n NAMESPACE",
+	Example:       "synopsysctl status alert <name> -n <namespace>\nsynopsysctl status alert -n <namespace> -o json",
+	Short:         "Show the health of one or many Alert instances",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes up to 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(client.Alert, args)
+	},
+}
+
+// statusBlackDuckCmd shows the health of one or many Black Duck instances
+var statusBlackDuckCmd = &cobra.Command{
+	Use:           "blackduck [NAME] -n NAMESPACE",
+	Example:       "synopsysctl status blackduck <name> -n <namespace>\nsynopsysctl status blackduck -n <namespace> -o json",
+	Short:         "Show the health of one or many Black Duck instances",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			cmd.Help()
+			return fmt.Errorf("this command takes up to 1 argument, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(client.BlackDuck, args)
+	},
+}
+
+// statusPolarisCmd shows the health of a Polaris instance
+var statusPolarisCmd = &cobra.Command{
+	Use:           "polaris -n NAMESPACE",
+	Example:       "synopsysctl status polaris -n <namespace>",
+	Short:         "Show the health of a Polaris instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(client.Polaris, args)
+	},
+}
+
+// statusPolarisReportingCmd shows the health of a Polaris Reporting instance
+var statusPolarisReportingCmd = &cobra.Command{
+	Use:           "polaris-reporting -n NAMESPACE",
+	Example:       "synopsysctl status polaris-reporting -n <namespace>",
+	Short:         "Show the health of a Polaris Reporting instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(client.PolarisReporting, args)
+	},
+}
+
+// statusBDBACmd shows the health of a BDBA instance
+var statusBDBACmd = &cobra.Command{
+	Use:           "bdba -n NAMESPACE",
+	Example:       "synopsysctl status bdba -n <namespace>",
+	Short:         "Show the health of a BDBA instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printStatus(client.BDBA, args)
+	},
+}
+
+// getStatusReleases returns the release of the instance in args, or all the releases of the product in the namespace if no instance is given
+func getStatusReleases(args []string, getReleaseName func(string) string, isProductRelease func(*release.Release) bool) ([]*release.Release, error) {
+	if len(args) == 1 {
+		helmRelease, err := util.GetWithHelm3(getReleaseName(args[0]), namespace, kubeConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf(strings.Replace(fmt.Sprintf("failed to get release: %+v", err), fmt.Sprintf("instance '%s' ", getReleaseName(args[0])), fmt.Sprintf("instance '%s' ", args[0]), 0))
+		}
+		return []*release.Release{helmRelease}, nil
+	}
+	helmReleases, err := util.ListWithHelm3(namespace, kubeConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	releases := []*release.Release{}
+	for _, helmRelease := range helmReleases {
+		if isProductRelease(helmRelease) {
+			releases = append(releases, helmRelease)
+		}
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no instances found in namespace '%s'", namespace)
+	}
+	return releases, nil
+}
+
+// isAlertRelease returns true if the release is an Alert instance
+func isAlertRelease(helmRelease *release.Release) bool {
+	return client.IsProductRelease(client.Alert, helmRelease)
+}
+
+// isBlackDuckRelease returns true if the release is a Black Duck instance
+func isBlackDuckRelease(helmRelease *release.Release) bool {
+	return client.IsProductRelease(client.BlackDuck, helmRelease)
+}
+
+// printStatus prints the health of the instance in args, or of all the instances of the product in the namespace if no
+// instance is given, and returns an error if any of them is degraded
+func printStatus(product string, args []string) error {
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	statuses, err := c.Status(context.Background(), product, name)
+	if err != nil {
+		return err
+	}
+	degraded := 0
+	for _, status := range statuses {
+		if !status.Healthy {
+			degraded++
+		}
+	}
+
+	switch strings.ToLower(statusOutputFormat) {
+	case "table":
+		printStatusTable(statuses)
+	case "json", "yaml":
+		var v interface{} = statuses
+		if len(statuses) == 1 {
+			v = statuses[0]
+		}
+		if _, err := PrintComponent(v, statusOutputFormat); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("'%s' is an invalid output format, must be one of [table|json|yaml]", statusOutputFormat)
+	}
+
+	if degraded > 0 {
+		return fmt.Errorf("%d of %d instance(s) in namespace '%s' are degraded", degraded, len(statuses), namespace)
+	}
+	return nil
+}
+
+// printStatusTable prints the health of the instances and their components as a table
+func printStatusTable(statuses []*client.InstanceStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE\tKIND\tNAME\tSTATUS\tREADY")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%s\tRelease\t%s\t%s (version %s, revision %d)\t%t\n", status.Name, status.Name, status.ReleaseStatus, status.Version, status.Revision, status.Healthy)
+		for _, component := range status.Components {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", status.Name, component.Kind, component.Name, component.Status, component.Ready)
+		}
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(statusCmd.PersistentFlags(), "namespace")
+	statusCmd.PersistentFlags().StringVarP(&statusOutputFormat, "output", "o", statusOutputFormat, "Output format [table|json|yaml]")
+
+	statusCmd.AddCommand(statusAlertCmd)
+	statusCmd.AddCommand(statusBlackDuckCmd)
+	statusCmd.AddCommand(statusPolarisCmd)
+	statusCmd.AddCommand(statusPolarisReportingCmd)
+	statusCmd.AddCommand(statusBDBACmd)
+}
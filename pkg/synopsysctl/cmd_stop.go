@@ -22,12 +22,14 @@ under the License.
 package synopsysctl
 
 import (
+	"context"
 	"fmt"
 
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	alertctl "github.com/blackducksoftware/synopsysctl/pkg/alert"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -59,31 +61,11 @@ var stopAlertCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		alertName := args[0]
-
-		instance, err := util.GetWithHelm3(alertName, namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("couldn't find instance %s in namespace %s", args[0], namespace)
-		}
-
-		// Update the Helm Chart Location
-		configAlertData := instance.Config["alert"].(map[string]interface{})
-		configAlertVersion := configAlertData["imageTag"]
-		chartLocationFlag := cmd.Flag("chart-location-path")
-		if chartLocationFlag.Changed {
-			alertChartRepository = chartLocationFlag.Value.String()
-		} else {
-			alertChartRepository = fmt.Sprintf("%s/charts/alert-%s.tgz", baseChartRepository, configAlertVersion)
-		}
-
-		helmValuesMap := map[string]interface{}{"status": "Stopped"}
-
-		err = util.UpdateWithHelm3(alertName, namespace, alertChartRepository, helmValuesMap, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to create Alert resources: %+v", err)
+		if err := setInstanceStatus(cmd, client.Alert, args[0], "Stopped"); err != nil {
+			return err
 		}
 
-		log.Infof("successfully submitted stop Alert '%s' in namespace '%s'", alertName, namespace)
+		log.Infof("successfully submitted stop Alert '%s' in namespace '%s'", args[0], namespace)
 
 		return nil
 	},
@@ -104,27 +86,7 @@ var stopBlackDuckCmd = &cobra.Command{
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		instance, err := util.GetWithHelm3(args[0], namespace, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("couldn't find instance %s in namespace %s", args[0], namespace)
-		}
-
-		// Update the Helm Chart Location
-		chartLocationFlag := cmd.Flag("chart-location-path")
-		if chartLocationFlag.Changed {
-			blackduckChartRepository = chartLocationFlag.Value.String()
-		} else {
-			blackduckChartRepository = fmt.Sprintf("%s/charts/blackduck-%s.tgz", baseChartRepository, instance.Chart.Values["imageTag"])
-		}
-
-		helmValuesMap := make(map[string]interface{})
-		util.SetHelmValueInMap(helmValuesMap, []string{"status"}, "Stopped")
-
-		err = util.UpdateWithHelm3(args[0], namespace, blackduckChartRepository, helmValuesMap, kubeConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to create Blackduck resources: %+v", err)
-		}
-		return nil
+		return setInstanceStatus(cmd, client.BlackDuck, args[0], "Stopped")
 	},
 }
 
@@ -181,6 +143,150 @@ var stopOpsSightCmd = &cobra.Command{
 	},
 }
 
+// stopPolarisCmd stops a Polaris instance
+var stopPolarisCmd = &cobra.Command{
+	Use:           "polaris -n NAMESPACE",
+	Example:       "synopsysctl stop polaris -n <namespace>",
+	Short:         "Stop a Polaris instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := stopHelmRelease(polarisName, namespace); err != nil {
+			return fmt.Errorf("failed to stop Polaris: %+v", err)
+		}
+		log.Infof("successfully stopped Polaris in namespace '%s'", namespace)
+		return nil
+	},
+}
+
+// stopPolarisReportingCmd stops a Polaris-Reporting instance
+var stopPolarisReportingCmd = &cobra.Command{
+	Use:           "polaris-reporting -n NAMESPACE",
+	Example:       "synopsysctl stop polaris-reporting -n <namespace>",
+	Short:         "Stop a Polaris-Reporting instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := stopHelmRelease(polarisReportingName, namespace); err != nil {
+			return fmt.Errorf("failed to stop Polaris-Reporting: %+v", err)
+		}
+		log.Infof("successfully stopped Polaris-Reporting in namespace '%s'", namespace)
+		return nil
+	},
+}
+
+// stopBDBACmd stops a BDBA instance
+var stopBDBACmd = &cobra.Command{
+	Use:           "bdba -n NAMESPACE",
+	Example:       "synopsysctl stop bdba -n <namespace>",
+	Short:         "Stop a BDBA instance",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			cmd.Help()
+			return fmt.Errorf("this command takes 0 arguments, but got %+v", args)
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := stopHelmRelease(bdbaName, namespace); err != nil {
+			return fmt.Errorf("failed to stop BDBA: %+v", err)
+		}
+		log.Infof("successfully stopped BDBA in namespace '%s'", namespace)
+		return nil
+	},
+}
+
+// getReleaseWorkloads returns the names of the Deployments and StatefulSets in a release's manifest, keyed by kind
+func getReleaseWorkloads(releaseName string, namespace string) (map[string][]string, error) {
+	helmRelease, err := util.GetWithHelm3(releaseName, namespace, kubeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find instance %s in namespace %s", releaseName, namespace)
+	}
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", releaseName, namespace, err)
+	}
+	workloads := map[string][]string{}
+	for _, resource := range resources {
+		kind := fmt.Sprintf("%v", resource["kind"])
+		if kind != "Deployment" && kind != "StatefulSet" {
+			continue
+		}
+		workloads[kind] = append(workloads[kind], fmt.Sprintf("%v", util.GetHelmValueFromMap(resource, []string{"metadata", "name"})))
+	}
+	return workloads, nil
+}
+
+// setInstanceStatus sets the status value of a Black Duck or Alert instance and keeps the rest of its values. The
+// instance keeps its chart unless the chart location path flag is set
+func setInstanceStatus(cmd *cobra.Command, product string, name string, status string) error {
+	chartURL := ""
+	if chartLocationFlag := cmd.Flag("chart-location-path"); chartLocationFlag != nil && chartLocationFlag.Changed {
+		chartURL = chartLocationFlag.Value.String()
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	return c.UpdateValues(context.Background(), product, name, chartURL, map[string]interface{}{"status": status})
+}
+
+// stopHelmRelease scales the Deployments and StatefulSets of a release to zero and records their replicas in
+// an annotation so that they can be restored by startHelmRelease. Persistent volume claims are left untouched.
+func stopHelmRelease(releaseName string, namespace string) error {
+	workloads, err := getReleaseWorkloads(releaseName, namespace)
+	if err != nil {
+		return err
+	}
+	for _, name := range workloads["Deployment"] {
+		deployment, err := util.GetDeployment(kubeClient, namespace, name)
+		if err != nil {
+			return fmt.Errorf("couldn't get deployment '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		if _, ok := deployment.Annotations[util.StoppedReplicasAnnotation]; ok {
+			continue
+		}
+		deployment.Annotations = util.SetStoppedReplicasAnnotation(deployment.Annotations, deployment.Spec.Replicas)
+		deployment.Spec.Replicas = util.IntToInt32(0)
+		if _, err := util.UpdateDeployment(kubeClient, namespace, deployment); err != nil {
+			return fmt.Errorf("couldn't stop deployment '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		log.Debugf("stopped deployment '%s' in namespace '%s'", name, namespace)
+	}
+	for _, name := range workloads["StatefulSet"] {
+		statefulSet, err := util.GetStatefulSet(kubeClient, namespace, name)
+		if err != nil {
+			return fmt.Errorf("couldn't get stateful set '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		if _, ok := statefulSet.Annotations[util.StoppedReplicasAnnotation]; ok {
+			continue
+		}
+		statefulSet.Annotations = util.SetStoppedReplicasAnnotation(statefulSet.Annotations, statefulSet.Spec.Replicas)
+		statefulSet.Spec.Replicas = util.IntToInt32(0)
+		if _, err := util.UpdateStatefulSet(kubeClient, namespace, statefulSet); err != nil {
+			return fmt.Errorf("couldn't stop stateful set '%s' in namespace '%s' due to %+v", name, namespace, err)
+		}
+		log.Debugf("stopped stateful set '%s' in namespace '%s'", name, namespace)
+	}
+	return nil
+}
+
 func init() {
 	stopAlertCobraHelper = *alertctl.NewHelmValuesFromCobraFlags()
 
@@ -189,12 +295,30 @@ func init() {
 	stopAlertCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(stopAlertCmd.Flags(), "namespace")
 	addChartLocationPathFlag(stopAlertCmd)
+	addWaitFlags(util.AlertName, stopAlertCmd)
 	stopCmd.AddCommand(stopAlertCmd)
 
 	stopBlackDuckCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	cobra.MarkFlagRequired(stopBlackDuckCmd.Flags(), "namespace")
 	addChartLocationPathFlag(stopBlackDuckCmd)
+	addWaitFlags(util.BlackDuckName, stopBlackDuckCmd)
 	stopCmd.AddCommand(stopBlackDuckCmd)
 
+	addWaitFlags(util.OpsSightName, stopOpsSightCmd)
 	stopCmd.AddCommand(stopOpsSightCmd)
+
+	stopPolarisCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(stopPolarisCmd.Flags(), "namespace")
+	addWaitFlags(polarisName, stopPolarisCmd)
+	stopCmd.AddCommand(stopPolarisCmd)
+
+	stopPolarisReportingCmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(stopPolarisReportingCmd.Flags(), "namespace")
+	addWaitFlags(polarisReportingName, stopPolarisReportingCmd)
+	stopCmd.AddCommand(stopPolarisReportingCmd)
+
+	stopBDBACmd.Flags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
+	cobra.MarkFlagRequired(stopBDBACmd.Flags(), "namespace")
+	addWaitFlags(bdbaName, stopBDBACmd)
+	stopCmd.AddCommand(stopBDBACmd)
 }
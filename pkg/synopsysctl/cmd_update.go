@@ -22,6 +22,7 @@ under the License.
 package synopsysctl
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
@@ -37,8 +38,8 @@ import (
 	blackduckapi "github.com/blackducksoftware/synopsysctl/pkg/api/blackduck/v1"
 	opssightapi "github.com/blackducksoftware/synopsysctl/pkg/api/opssight/v1"
 	"github.com/blackducksoftware/synopsysctl/pkg/bdba"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
 	polarisreporting "github.com/blackducksoftware/synopsysctl/pkg/polaris-reporting"
-	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
 	// bdappsutil "github.com/blackducksoftware/synopsysctl/pkg/apps/util"
 
@@ -115,6 +116,9 @@ var updateAlertCmd = &cobra.Command{
 		if !isOperatorBased && instance != nil {
 			err = updateAlertHelmBased(cmd, fmt.Sprintf("%s%s", alertName, AlertPostSuffix), alertName)
 		} else if isOperatorBased {
+			if updateDiff {
+				return fmt.Errorf("--diff is only supported for Alert instances that were created with Helm")
+			}
 			versionFlag := cmd.Flag("version")
 			if !versionFlag.Changed {
 				return fmt.Errorf("you must upgrade this Alert version with --version to use this synopsysctl binary")
@@ -127,11 +131,14 @@ var updateAlertCmd = &cobra.Command{
 			// if !isGreaterThanOrEqualTo {
 			// 	return fmt.Errorf("you must upgrade this Alert to version 6.0.0 or after in order to use this synopsysctl binary - you gave version %+v", versionFlag.Value.String())
 			// }
-			err = updateAlertOperatorBased(cmd, alertName)
+			err = migrateAlert(alertName, cmd.Flags())
 		}
 		if err != nil {
 			return err
 		}
+		if updateDiff {
+			return nil
+		}
 
 		log.Infof("Alert has been successfully Updated in namespace '%s'!", namespace)
 
@@ -145,6 +152,7 @@ func updateAlertHelmBased(cmd *cobra.Command, alertName string, customerReleaseN
 	if err != nil {
 		return fmt.Errorf(strings.Replace(fmt.Sprintf("failed to get previous user defined values: %+v", err), fmt.Sprintf("instance '%s' ", alertName), fmt.Sprintf("instance '%s' ", customerReleaseName), 0))
 	}
+	previousValues := util.CopyHelmValues(helmRelease.Config)
 	updateAlertCobraHelper.SetArgs(helmRelease.Config)
 
 	// Update Helm Values with flags
@@ -153,125 +161,35 @@ func updateAlertHelmBased(cmd *cobra.Command, alertName string, customerReleaseN
 		return err
 	}
 
-	// check whether the update Alert version is greater than or equal to 5.0.0
+	version := ""
 	if cmd.Flag("version").Changed {
-		helmValuesMapAlertData := helmValuesMap["alert"].(map[string]interface{})
-		oldAlertVersion := helmValuesMapAlertData["imageTag"].(string)
-		isGreaterThanOrEqualTo, err := util.IsNotDefaultVersionGreaterThanOrEqualTo(oldAlertVersion, 5, 0, 0)
-		if err != nil {
-			return fmt.Errorf("failed to check Alert version: %+v", err)
-		}
-
-		// if greater than or equal to 5.0.0, then copy PUBLIC_HUB_WEBSERVER_HOST to ALERT_HOSTNAME and PUBLIC_HUB_WEBSERVER_PORT to ALERT_SERVER_PORT
-		// and delete PUBLIC_HUB_WEBSERVER_HOST and PUBLIC_HUB_WEBSERVER_PORT from the environs. In future, we need to request the customer to use the new params
-		if isGreaterThanOrEqualTo && helmValuesMap["environs"] != nil {
-			maps := helmValuesMap["environs"].(map[string]interface{})
-			isChanged := false
-			if _, ok := maps["PUBLIC_HUB_WEBSERVER_HOST"]; ok {
-				if _, ok1 := maps["ALERT_HOSTNAME"]; !ok1 {
-					maps["ALERT_HOSTNAME"] = maps["PUBLIC_HUB_WEBSERVER_HOST"]
-					isChanged = true
-				}
-				delete(maps, "PUBLIC_HUB_WEBSERVER_HOST")
-			}
-
-			if _, ok := maps["PUBLIC_HUB_WEBSERVER_PORT"]; ok {
-				if _, ok1 := maps["ALERT_SERVER_PORT"]; !ok1 {
-					maps["ALERT_SERVER_PORT"] = maps["PUBLIC_HUB_WEBSERVER_PORT"]
-					isChanged = true
-				}
-				delete(maps, "PUBLIC_HUB_WEBSERVER_PORT")
-			}
-
-			if isChanged {
-				util.SetHelmValueInMap(helmValuesMap, []string{"environs"}, maps)
-			}
-		}
-	}
-
-	// Get secrets for Alert
-	certificateFlag := cmd.Flag("certificate-file-path")
-	certificateKeyFlag := cmd.Flag("certificate-key-file-path")
-	if certificateFlag.Changed && certificateKeyFlag.Changed {
-		certificateData, err := util.ReadFileData(certificateFlag.Value.String())
-		if err != nil {
-			log.Fatalf("failed to read certificate file: %+v", err)
-		}
-
-		certificateKeyData, err := util.ReadFileData(certificateKeyFlag.Value.String())
-		if err != nil {
-			log.Fatalf("failed to read certificate file: %+v", err)
-		}
-		customCertificateSecretName := "alert-custom-certificate"
-		customCertificateSecret := alertctl.GetAlertCustomCertificateSecret(namespace, customCertificateSecretName, certificateData, certificateKeyData)
-		util.SetHelmValueInMap(helmValuesMap, []string{"webserverCustomCertificatesSecretName"}, customCertificateSecretName)
-		if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&customCertificateSecret); err != nil {
-			if k8serrors.IsAlreadyExists(err) {
-				if _, err := kubeClient.CoreV1().Secrets(namespace).Update(&customCertificateSecret); err != nil {
-					return fmt.Errorf("failed to update certificate secret: %+v", err)
-				}
-			} else {
-				return fmt.Errorf("failed to create certificate secret: %+v", err)
-			}
-		}
-	}
-	javaKeystoreFlag := cmd.Flag("java-keystore-file-path")
-	if javaKeystoreFlag.Changed {
-		javaKeystoreData, err := util.ReadFileData(javaKeystoreFlag.Value.String())
-		if err != nil {
-			log.Fatalf("failed to read Java Keystore file: %+v", err)
-		}
-		javaKeystoreSecretName := "alert-java-keystore"
-		javaKeystoreSecret := alertctl.GetAlertJavaKeystoreSecret(namespace, javaKeystoreSecretName, javaKeystoreData)
-		util.SetHelmValueInMap(helmValuesMap, []string{"javaKeystoreSecretName"}, javaKeystoreSecretName)
-		if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&javaKeystoreSecret); err != nil {
-			if k8serrors.IsAlreadyExists(err) {
-				if _, err := kubeClient.CoreV1().Secrets(namespace).Update(&javaKeystoreSecret); err != nil {
-					return fmt.Errorf("failed to update javakeystore secret: %+v", err)
-				}
-			} else {
-				return fmt.Errorf("failed to create javakeystore secret: %+v", err)
-			}
-		}
+		version = cmd.Flag("version").Value.String()
 	}
 
-	// Update Alert Resources
-	err = util.UpdateWithHelm3(alertName, namespace, alertChartRepository, helmValuesMap, kubeConfigPath)
+	// Get the secrets for Alert
+	secrets, err := alertctl.GetSecretsFromFlagsAndSetHelmValue(namespace, cmd.Flags(), helmValuesMap)
 	if err != nil {
-		return fmt.Errorf("failed to update Alert resources due to %+v", err)
+		return err
 	}
-	return nil
-}
 
-func updateAlertOperatorBased(cmd *cobra.Command, alertName string) error {
-	operatorNamespace := namespace
-	isClusterScoped := util.GetClusterScope(apiExtensionClient)
-	if isClusterScoped {
-		opNamespace, err := util.GetOperatorNamespace(kubeClient, metav1.NamespaceAll)
-		if err != nil {
-			return err
-		}
-		if len(opNamespace) > 1 {
-			return fmt.Errorf("more than 1 Synopsys Operator found in your cluster")
+	if updateDiff {
+		if len(version) > 0 {
+			if err := client.SetAlertHostEnvirons(helmValuesMap); err != nil {
+				return err
+			}
 		}
-		operatorNamespace = opNamespace[0]
+		return printUpdateDiff(helmRelease, alertChartRepository, previousValues, helmValuesMap)
 	}
 
-	crdNamespace := namespace
-	if isClusterScoped {
-		crdNamespace = metav1.NamespaceAll
-	}
-
-	currAlert, err := util.GetAlert(alertClient, crdNamespace, alertName, metav1.GetOptions{})
+	// Update Alert Resources
+	c, err := newClient()
 	if err != nil {
-		return fmt.Errorf("error getting Alert '%s' in namespace '%s' due to %+v", alertName, crdNamespace, err)
+		return err
 	}
-
-	if err := migrateAlert(currAlert, operatorNamespace, crdNamespace, cmd.Flags()); err != nil {
-		// TODO restart operator if migration failed?
+	if err := c.UpdateAlert(context.Background(), customerReleaseName, client.AlertValues{Version: version, ChartURL: alertChartRepository, Values: helmValuesMap, Secrets: secrets}); err != nil {
 		return err
 	}
-	return nil
+	return waitForUpdateOrRollback(c, util.AlertName, customerReleaseName, helmRelease.Version)
 }
 
 // updateBlackDuckCmd updates a Black Duck instance
@@ -306,6 +224,7 @@ var updateBlackDuckCmd = &cobra.Command{
 		}
 
 		if !isOperatorBased && instance != nil {
+			previousValues := util.CopyHelmValues(instance.Config)
 			updateBlackDuckCobraHelper.SetArgs(instance.Config)
 			helmValuesMap, err := updateBlackDuckCobraHelper.GenerateHelmFlagsFromCobraFlags(cmd.Flags())
 			if err != nil {
@@ -316,72 +235,35 @@ var updateBlackDuckCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
-			for _, v := range secrets {
-				if secret, err := util.GetSecret(kubeClient, namespace, v.Name); err == nil {
-					secret.Data = v.Data
-					secret.StringData = v.StringData
-					if _, err := util.UpdateSecret(kubeClient, namespace, secret); err != nil {
-						return fmt.Errorf("failed to update certificate secret: %+v", err)
-					}
-				} else {
-					if _, err := kubeClient.CoreV1().Secrets(namespace).Create(&v); err != nil {
-						return fmt.Errorf("failed to create certificate secret: %+v", err)
-					}
+
+			if updateDiff {
+				var extraFiles []string
+				if size, ok := instance.Config["size"].(string); ok && len(size) > 0 {
+					extraFiles = append(extraFiles, fmt.Sprintf("%s.yaml", size))
 				}
+				return printUpdateDiff(instance, blackduckChartRepository, previousValues, helmValuesMap, extraFiles...)
 			}
 
-			var extraFiles []string
-			size, found := instance.Config["size"]
-			if found {
-				extraFiles = append(extraFiles, fmt.Sprintf("%s.yaml", size.(string)))
+			c, err := newClient()
+			if err != nil {
+				return err
 			}
-
-			if err := util.UpdateWithHelm3(args[0], namespace, blackduckChartRepository, helmValuesMap, kubeConfigPath, extraFiles...); err != nil {
+			if err := c.UpdateBlackDuck(context.Background(), args[0], client.BlackDuckValues{ChartURL: blackduckChartRepository, Values: helmValuesMap, Secrets: secrets}); err != nil {
 				return err
 			}
 
-			err = blackduck.CRUDServiceOrRoute(restconfig, kubeClient, namespace, args[0], helmValuesMap["exposeui"], helmValuesMap["exposedServiceType"])
-			if err != nil {
+			if err := waitForUpdateOrRollback(c, util.BlackDuckName, args[0], instance.Version); err != nil {
 				return err
 			}
 
 		} else if isOperatorBased {
+			if updateDiff {
+				return fmt.Errorf("--diff is only supported for Black Duck instances that were created with Helm")
+			}
 			if !cmd.Flag("version").Changed {
 				return fmt.Errorf("you must upgrade this Blackduck version with --version 2020.4.0 and above to use this synopsysctl binary")
 			}
-			ok, err := util.IsVersionGreaterThanOrEqualTo(cmd.Flag("version").Value.String(), 2020, time.April, 0)
-			if err != nil {
-				return err
-			}
-
-			if !ok {
-				return fmt.Errorf("migration is only suported for version 2020.4.0 and above")
-			}
-
-			operatorNamespace := namespace
-			isClusterScoped := util.GetClusterScope(apiExtensionClient)
-			if isClusterScoped {
-				opNamespace, err := util.GetOperatorNamespace(kubeClient, metav1.NamespaceAll)
-				if err != nil {
-					return err
-				}
-				if len(opNamespace) > 1 {
-					return fmt.Errorf("more than 1 Synopsys Operator found in your cluster")
-				}
-				operatorNamespace = opNamespace[0]
-			}
-
-			blackDuckName := args[0]
-			crdNamespace := namespace
-			if isClusterScoped {
-				crdNamespace = metav1.NamespaceAll
-			}
-
-			currBlackDuck, err := util.GetBlackduck(blackDuckClient, crdNamespace, blackDuckName, metav1.GetOptions{})
-			if err != nil {
-				return fmt.Errorf("error getting Black Duck '%s' in namespace '%s' due to %+v", blackDuckName, crdNamespace, err)
-			}
-			if err := migrate(currBlackDuck, operatorNamespace, crdNamespace, cmd.Flags()); err != nil {
+			if err := migrate(args[0], cmd.Flags()); err != nil {
 				return err
 			}
 		}
@@ -562,7 +444,11 @@ func updateMasterKey(namespace string, name string, oldMasterKeyFilePath string,
 	} else {
 		helmValuesMap := make(map[string]interface{})
 		util.SetHelmValueInMap(helmValuesMap, []string{"sealKey"}, newSealKey)
-		if err := util.UpdateWithHelm3(name, namespace, blackduckChartRepository, helmValuesMap, kubeConfigPath); err != nil {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		if err := c.UpdateValues(context.Background(), client.BlackDuck, name, "", helmValuesMap); err != nil {
 			return err
 		}
 
@@ -600,7 +486,11 @@ var updateBlackDuckAddEnvironCmd = &cobra.Command{
 		helmValuesMap := make(map[string]interface{})
 		util.SetHelmValueInMap(helmValuesMap, []string{"environs", vals[0]}, vals[1])
 
-		if err := util.UpdateWithHelm3(args[0], namespace, blackduckChartRepository, helmValuesMap, kubeConfigPath); err != nil {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		if err := c.UpdateValues(context.Background(), client.BlackDuck, args[0], "", helmValuesMap); err != nil {
 			return err
 		}
 
@@ -931,9 +821,12 @@ var updatePolarisCmd = &cobra.Command{
 		}
 
 		// Deploy Polaris Resources
-		err = util.UpdateWithHelm3(polarisName, namespace, polarisChartRepository, helmValuesMap, kubeConfigPath)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to update Polaris resources due to %+v", err)
+			return err
+		}
+		if err := c.UpdateInstance(context.Background(), client.Polaris, client.InstanceValues{ChartURL: polarisChartRepository, Values: helmValuesMap}); err != nil {
+			return err
 		}
 
 		log.Infof("Polaris has been successfully Updated in namespace '%s'!", namespace)
@@ -981,9 +874,12 @@ var updatePolarisReportingCmd = &cobra.Command{
 		}
 
 		// Update Polaris-Reporting Resources
-		err = util.UpdateWithHelm3(polarisReportingName, namespace, polarisReportingChartRepository, helmValuesMap, kubeConfigPath)
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to update Polaris-Reporting resources due to %+v", err)
+			return err
+		}
+		if err := c.UpdateInstance(context.Background(), client.PolarisReporting, client.InstanceValues{ChartURL: polarisReportingChartRepository, Values: helmValuesMap}); err != nil {
+			return err
 		}
 
 		log.Infof("Polaris-Reporting has been successfully Updated in namespace '%s'!", namespace)
@@ -1030,10 +926,13 @@ var updateBDBACmd = &cobra.Command{
 			}
 		}
 
-		// Update Resources
-		err = util.UpdateWithHelm3(bdbaName, namespace, bdbaChartRepository, helmValuesMap, kubeConfigPath)
+		// Update Resources and the horizontal pod autoscalers of the autoscaled components
+		c, err := newClient()
 		if err != nil {
-			return fmt.Errorf("failed to update BDBA resources due to %+v", err)
+			return err
+		}
+		if err := c.UpdateInstance(context.Background(), client.BDBA, client.InstanceValues{ChartURL: bdbaChartRepository, Values: helmValuesMap}); err != nil {
+			return err
 		}
 
 		log.Infof("BDBA has been successfully Updated in namespace '%s'!", namespace)
@@ -1057,6 +956,9 @@ func init() {
 	cobra.MarkFlagRequired(updateAlertCmd.PersistentFlags(), "namespace")
 	updateAlertCobraHelper.AddCobraFlagsToCommand(updateAlertCmd, false)
 	addChartLocationPathFlag(updateAlertCmd)
+	addUpdateRollbackFlags(updateAlertCmd)
+	addUpdateDiffFlag(updateAlertCmd)
+	addWaitFlags(util.AlertName, updateAlertCmd)
 	updateCmd.AddCommand(updateAlertCmd)
 
 	/* Update Black Duck Comamnds */
@@ -1066,6 +968,9 @@ func init() {
 	cobra.MarkFlagRequired(updateBlackDuckCmd.PersistentFlags(), "namespace")
 	addChartLocationPathFlag(updateBlackDuckCmd)
 	updateBlackDuckCobraHelper.AddCRSpecFlagsToCommand(updateBlackDuckCmd, false)
+	addUpdateRollbackFlags(updateBlackDuckCmd)
+	addUpdateDiffFlag(updateBlackDuckCmd)
+	addWaitFlags(util.BlackDuckName, updateBlackDuckCmd)
 	updateCmd.AddCommand(updateBlackDuckCmd)
 
 	// updateBlackDuckMasterKeyCmd
@@ -1082,6 +987,7 @@ func init() {
 	// updateOpsSightCmd
 	updateOpsSightCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", namespace, "Namespace of the instance(s)")
 	updateOpsSightCobraHelper.AddCRSpecFlagsToCommand(updateOpsSightCmd, false)
+	addWaitFlags(util.OpsSightName, updateOpsSightCmd)
 	updateCmd.AddCommand(updateOpsSightCmd)
 
 	// updateOpsSightExternalHostCmd
@@ -1101,6 +1007,8 @@ func init() {
 	cobra.MarkFlagRequired(updatePolarisCmd.PersistentFlags(), "namespace")
 	updatePolarisCobraHelper.AddCobraFlagsToCommand(updatePolarisCmd, false)
 	addChartLocationPathFlag(updatePolarisCmd)
+	addDisableRollbackFlag(updatePolarisCmd)
+	addWaitFlags(polarisName, updatePolarisCmd)
 	updateCmd.AddCommand(updatePolarisCmd)
 
 	// Polaris-Reporting
@@ -1108,6 +1016,8 @@ func init() {
 	cobra.MarkFlagRequired(updatePolarisReportingCmd.PersistentFlags(), "namespace")
 	updatePolarisReportingCobraHelper.AddCobraFlagsToCommand(updatePolarisReportingCmd, false)
 	addChartLocationPathFlag(updatePolarisReportingCmd)
+	addDisableRollbackFlag(updatePolarisReportingCmd)
+	addWaitFlags(polarisReportingName, updatePolarisReportingCmd)
 	updateCmd.AddCommand(updatePolarisReportingCmd)
 
 	// BDBA
@@ -1115,5 +1025,7 @@ func init() {
 	cobra.MarkFlagRequired(updateBDBACmd.PersistentFlags(), "namespace")
 	updateBDBACobraHelper.AddCobraFlagsToCommand(updateBDBACmd, false)
 	addChartLocationPathFlag(updateBDBACmd)
+	addDisableRollbackFlag(updateBDBACmd)
+	addWaitFlags(bdbaName, updateBDBACmd)
 	updateCmd.AddCommand(updateBDBACmd)
 }
@@ -37,9 +37,6 @@ const defaultBaseOpsSightSpec string = "default"
 // busybox image
 const defaultBusyBoxImage string = "docker.io/busybox:1.28"
 
-// flag for all namespaces
-const allNamespacesFlag string = "--all-namespaces"
-
 // Flags for using native mode - doesn't deploy
 var nativeFormat = "json"
 
@@ -0,0 +1,107 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// All Contexts flags
+var allKubeContexts = false
+var selectedKubeContexts []string
+
+// isAllContextsCommand returns true if the command was asked to run in all or a list of the contexts of the kubeconfig
+func isAllContextsCommand(cmd *cobra.Command) bool {
+	if cmd.Flags().Lookup("all-contexts") == nil {
+		return false
+	}
+	return allKubeContexts || len(selectedKubeContexts) > 0
+}
+
+// wrapAllContextsCommands makes the sub-commands of the commands run in every selected context when --all-contexts or --contexts is set.
+// Native commands don't access a cluster, so they are left as is
+func wrapAllContextsCommands(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		for _, subCmd := range cmd.Commands() {
+			if subCmd.Name() == "native" {
+				continue
+			}
+			if runE := subCmd.RunE; runE != nil {
+				subCmd.RunE = func(cmd *cobra.Command, args []string) error {
+					if isAllContextsCommand(cmd) {
+						return runInKubeContexts()
+					}
+					return runE(cmd, args)
+				}
+			}
+			wrapAllContextsCommands(subCmd)
+		}
+	}
+}
+
+// runInKubeContexts runs synopsysctl with the same arguments in each selected context and prints the results per cluster
+func runInKubeContexts() error {
+	contexts, err := util.GetKubeContexts(kubeConfigPath)
+	if err != nil {
+		return err
+	}
+	contexts, err = util.SelectKubeContexts(contexts, selectedKubeContexts)
+	if err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the synopsysctl executable due to %+v", err)
+	}
+
+	failedContexts := []string{}
+	for i, context := range contexts {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("==> Context: %s <==\n", context)
+		contextCmd := exec.Command(executable, util.GetKubeContextArgs(os.Args[1:], context)...)
+		contextCmd.Stdin = os.Stdin
+		contextCmd.Stdout = os.Stdout
+		contextCmd.Stderr = os.Stderr
+		if err := contextCmd.Run(); err != nil {
+			failedContexts = append(failedContexts, context)
+		}
+	}
+	if len(failedContexts) > 0 {
+		return fmt.Errorf("the command failed in %d of %d contexts: %s", len(failedContexts), len(contexts), strings.Join(failedContexts, ", "))
+	}
+	return nil
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{getCmd, statusCmd, updateCmd} {
+		cmd.PersistentFlags().BoolVar(&allKubeContexts, "all-contexts", allKubeContexts, "If true, run the command in every context of the kubeconfig")
+		cmd.PersistentFlags().StringSliceVar(&selectedKubeContexts, "contexts", selectedKubeContexts, "List of the contexts of the kubeconfig to run the command in")
+	}
+}
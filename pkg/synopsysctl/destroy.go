@@ -94,8 +94,13 @@ func restartSynopsysOperator(namespace string) error {
 
 func deleteSynopsysOperatorResources(namespace string, crds []string) {
 	log.Debugf("deleting the Synopsys Operator resources in namespace '%s'", namespace)
+	clientset, err := getKubeClientset()
+	if err != nil {
+		log.Errorf("unable to delete the Synopsys Operator resources in namespace '%s' due to %+v", namespace, err)
+		return
+	}
 	// delete synopsys operator resources
-	commonConfig := crdupdater.NewCRUDComponents(restconfig, kubeClient, false, false, namespace, "", &api.ComponentList{}, "app=synopsys-operator", false)
+	commonConfig := crdupdater.NewCRUDComponents(restconfig, clientset, false, false, namespace, "", &api.ComponentList{}, "app=synopsys-operator", false)
 	_, crudErrors := commonConfig.CRUDComponents()
 	if len(crudErrors) > 0 {
 		log.Errorf("unable to delete the Synopsys Operator resources in namespace '%s' due to %+v", namespace, crudErrors)
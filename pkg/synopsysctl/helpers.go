@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
 	"github.com/spf13/cobra"
 )
 
@@ -49,3 +50,42 @@ func addChartLocationPathFlag(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&baseURL, "chart-location-path", "", baseURL, "Absolute path to the Helm Chart Tarball")
 	cmd.Flags().MarkHidden("chart-location-path")
 }
+
+// releaseProduct describes how the instances of a Helm based product are named
+type releaseProduct struct {
+	// getReleaseName returns the Helm release name of an instance
+	getReleaseName func(name string) string
+	// takesName is true if the product can have multiple named instances in a namespace
+	takesName bool
+}
+
+// releaseProducts are the Helm based products that are managed by their release
+var releaseProducts = map[string]releaseProduct{
+	util.BlackDuckName:   {getReleaseName: func(name string) string { return name }, takesName: true},
+	util.AlertName:       {getReleaseName: func(name string) string { return fmt.Sprintf("%s%s", name, AlertPostSuffix) }, takesName: true},
+	bdbaName:             {getReleaseName: func(name string) string { return bdbaName }},
+	polarisName:          {getReleaseName: func(name string) string { return polarisName }},
+	polarisReportingName: {getReleaseName: func(name string) string { return polarisReportingName }},
+}
+
+// getReleaseInstance returns the product and instance name from the PRODUCT [NAME] arguments of a command
+func getReleaseInstance(args []string) (string, string, error) {
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("this command takes 1 or 2 arguments, but got %+v", args)
+	}
+	product := strings.ToLower(args[0])
+	releaseProduct, ok := releaseProducts[product]
+	if !ok {
+		return "", "", fmt.Errorf("'%s' is an invalid product, must be one of [%s|%s|%s|%s|%s]", args[0], util.BlackDuckName, util.AlertName, bdbaName, polarisName, polarisReportingName)
+	}
+	if releaseProduct.takesName {
+		if len(args) != 2 {
+			return "", "", fmt.Errorf("%s requires 1 NAME argument, but got %+v", product, args[1:])
+		}
+		return product, args[1], nil
+	}
+	if len(args) != 1 {
+		return "", "", fmt.Errorf("%s doesn't take a name argument, but got %+v", product, args[1:])
+	}
+	return product, product, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Update Command flag for --diff functionality
+var updateDiff bool
+
+// addUpdateDiffFlag adds the flag that previews an update instead of applying it
+func addUpdateDiffFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&updateDiff, "diff", updateDiff, "If true, print the Helm values and Kubernetes resources that would change without updating the instance")
+}
+
+// printUpdateDiff prints the Helm values that change with their source and a unified diff of each Kubernetes object
+// between the deployed release and the release rendered with the new values
+func printUpdateDiff(helmRelease *release.Release, chartURL string, previousValues map[string]interface{}, flagValues map[string]interface{}, extraFiles ...string) error {
+	rendered, err := util.RenderWithHelm3(helmRelease.Name, helmRelease.Namespace, chartURL, flagValues, kubeConfigPath, extraFiles...)
+	if err != nil {
+		return err
+	}
+
+	// Helm values
+	changes, err := util.DiffHelmValues(previousValues, rendered.Values)
+	if err != nil {
+		return err
+	}
+	flagChanges, err := util.DiffHelmValues(previousValues, flagValues)
+	if err != nil {
+		return err
+	}
+	flagKeys := make(map[string]bool)
+	for _, change := range flagChanges {
+		flagKeys[change.Key] = true
+	}
+	extraFileValues, err := util.FlattenHelmValues(rendered.ExtraFileValues)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("Helm values: no changes")
+	} else {
+		fmt.Println("Helm values:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "KEY\tDEPLOYED\tUPDATED\tSOURCE")
+		for _, change := range changes {
+			source := "previous config"
+			if _, ok := extraFileValues[change.Key]; ok {
+				source = fmt.Sprintf("size file (%s)", strings.Join(extraFiles, ", "))
+			} else if flagKeys[change.Key] {
+				source = "flag"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Key, formatDiffHelmValue(change.Key, change.OldValue), formatDiffHelmValue(change.Key, change.NewValue), source)
+		}
+		w.Flush()
+	}
+
+	// Kubernetes objects
+	deployedManifest := helmRelease.Manifest
+	for _, hook := range helmRelease.Hooks {
+		deployedManifest = fmt.Sprintf("%s\n---\n%s", deployedManifest, hook.Manifest)
+	}
+	deployedManifest, err = util.RedactManifestSecrets(deployedManifest)
+	if err != nil {
+		return err
+	}
+	updatedManifest, err := util.RedactManifestSecrets(rendered.Manifest)
+	if err != nil {
+		return err
+	}
+	diff, err := util.DiffManifests(deployedManifest, updatedManifest, terminal.IsTerminal(int(os.Stdout.Fd())))
+	if err != nil {
+		return err
+	}
+	if len(diff) == 0 {
+		fmt.Println("\nKubernetes resources: no changes")
+	} else {
+		fmt.Printf("\nKubernetes resources:\n%s", diff)
+	}
+	return nil
+}
+
+// formatDiffHelmValue returns the value to print in the diff, the passwords and seal keys are redacted
+func formatDiffHelmValue(key string, value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	for _, keyList := range sensitiveHelmValues {
+		if key == strings.Join(keyList, ".") {
+			return util.RedactedValue
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
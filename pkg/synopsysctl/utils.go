@@ -30,6 +30,7 @@ import (
 
 	alertclientset "github.com/blackducksoftware/synopsysctl/pkg/alert/client/clientset/versioned"
 	blackduckclientset "github.com/blackducksoftware/synopsysctl/pkg/blackduck/client/clientset/versioned"
+	"github.com/blackducksoftware/synopsysctl/pkg/client"
 	opssightclientset "github.com/blackducksoftware/synopsysctl/pkg/opssight/client/clientset/versioned"
 	"github.com/blackducksoftware/synopsysctl/pkg/protoform"
 	"github.com/blackducksoftware/synopsysctl/pkg/util"
@@ -43,11 +44,11 @@ import (
 )
 
 var restconfig *rest.Config
-var kubeClient *kubernetes.Clientset
-var apiExtensionClient *apiextensionsclient.Clientset
-var alertClient *alertclientset.Clientset
-var blackDuckClient *blackduckclientset.Clientset
-var opsSightClient *opssightclientset.Clientset
+var kubeClient kubernetes.Interface
+var apiExtensionClient apiextensionsclient.Interface
+var alertClient alertclientset.Interface
+var blackDuckClient blackduckclientset.Interface
+var opsSightClient opssightclientset.Interface
 
 // setSynopsysctlLogLevel sets the binary's log level to the value stored in logLevelCtl
 func setSynopsysctlLogLevel() error {
@@ -81,7 +82,7 @@ func setGlobalKubeConfigPath(cmd *cobra.Command) error {
 // setGlobalRestConfig sets the global variable 'restconfig' for other commands to use
 func setGlobalRestConfig() error {
 	var err error
-	restconfig, err = protoform.GetKubeClientFromOutsideCluster(kubeConfigPath, insecureSkipTLSVerify)
+	restconfig, err = protoform.GetKubeClientFromOutsideClusterWithContext(kubeConfigPath, kubeContext, insecureSkipTLSVerify)
 	log.Debugf("rest config: %+v", restconfig)
 	if err != nil {
 		return err
@@ -122,8 +123,36 @@ func setGlobalResourceClients() error {
 	return nil
 }
 
+// setGlobalSimulatedClients sets the global variables for the rest config and the clients to the simulated cluster
+// saved in the simulate state file
+func setGlobalSimulatedClients() error {
+	simulation, err := util.NewSimulatedCluster(simulateStatePath)
+	if err != nil {
+		return err
+	}
+	log.Infof("simulating the cluster in '%s'", simulateStatePath)
+	util.Simulation = simulation
+	restconfig = &rest.Config{Host: util.SimulatedClusterHost}
+	kubeClient = simulation.KubeClient
+	apiExtensionClient = simulation.APIExtensionClient
+	alertClient = simulation.AlertClient
+	blackDuckClient = simulation.BlackDuckClient
+	opsSightClient = simulation.OpsSightClient
+	return nil
+}
+
+// getKubeClientset returns the clientset of the cluster for the Synopsys Operator resources, which are managed with the
+// concrete clientset and aren't supported in the simulated cluster
+func getKubeClientset() (*kubernetes.Clientset, error) {
+	clientset, ok := kubeClient.(*kubernetes.Clientset)
+	if !ok {
+		return nil, fmt.Errorf("the Synopsys Operator resources aren't supported in the simulated cluster")
+	}
+	return clientset, nil
+}
+
 // getKubeClient gets the kubernetes client
-func getKubeClient(kubeConfig *rest.Config) (*kubernetes.Clientset, error) {
+func getKubeClient(kubeConfig *rest.Config) (kubernetes.Interface, error) {
 	client, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
 		return nil, err
@@ -131,9 +160,29 @@ func getKubeClient(kubeConfig *rest.Config) (*kubernetes.Clientset, error) {
 	return client, nil
 }
 
+// newClient returns a client for the instances in the namespace of the command, in the cluster of the kubeconfig flags
+func newClient() (*client.Client, error) {
+	return newClientForNamespace(namespace)
+}
+
+// newClientForNamespace returns a client for the instances in the namespace, in the cluster of the kubeconfig flags
+func newClientForNamespace(namespace string) (*client.Client, error) {
+	return client.NewClientForConfig(client.Options{
+		KubeConfigPath:        kubeConfigPath,
+		KubeContext:           util.KubeContext,
+		InsecureSkipTLSVerify: insecureSkipTLSVerify,
+		Namespace:             namespace,
+		ChartRepository:       baseChartRepository,
+		ChartCacheDirectory:   util.ChartCacheDirectory,
+		ChartKeyring:          util.ChartKeyring,
+		DisableRollback:       updateDisableRollback,
+		Simulation:            util.Simulation,
+	}, restconfig)
+}
+
 // DetermineClusterClients returns bool values for which client
 // to use. They will never both be true
-func DetermineClusterClients(restConfig *rest.Config, kubeClient *kubernetes.Clientset) (kube, openshift bool) {
+func DetermineClusterClients(restConfig *rest.Config, kubeClient kubernetes.Interface) (kube, openshift bool) {
 	openshift = false
 	kube = false
 
@@ -173,7 +222,10 @@ func DetermineClusterClients(restConfig *rest.Config, kubeClient *kubernetes.Cli
 	return false, false // neither client exists
 }
 
-func getKubeExecCmd(restconfig *rest.Config, kubeClient *kubernetes.Clientset, args ...string) (*exec.Cmd, error) {
+func getKubeExecCmd(restconfig *rest.Config, kubeClient kubernetes.Interface, args ...string) (*exec.Cmd, error) {
+	if util.Simulation != nil {
+		return nil, fmt.Errorf("kubectl and oc can't run against the simulated cluster")
+	}
 	kube, openshift := DetermineClusterClients(restconfig, kubeClient)
 
 	// cluster-info in kube doesnt seem to be in
@@ -182,10 +234,13 @@ func getKubeExecCmd(restconfig *rest.Config, kubeClient *kubernetes.Clientset, a
 	if args[0] == "cluster-info" && openshift {
 		args[0] = "status"
 	}
-	// add global flags: insecure-skip-tls-verify and --kubeconfig
+	// add global flags: insecure-skip-tls-verify, --context and --kubeconfig
 	if insecureSkipTLSVerify == true {
 		args = append([]string{fmt.Sprintf("--insecure-skip-tls-verify=%t", insecureSkipTLSVerify)}, args...)
 	}
+	if kubeContext != "" {
+		args = append([]string{fmt.Sprintf("--context=%s", kubeContext)}, args...)
+	}
 	if kubeConfigPath != "" {
 		args = append([]string{fmt.Sprintf("--kubeconfig=%s", kubeConfigPath)}, args...)
 	}
@@ -200,7 +255,7 @@ func getKubeExecCmd(restconfig *rest.Config, kubeClient *kubernetes.Clientset, a
 
 // RunKubeCmd is a simple wrapper to oc/kubectl exec that captures output.
 // TODO consider replacing w/ go api but not crucial for now.
-func RunKubeCmd(restconfig *rest.Config, kubeClient *kubernetes.Clientset, args ...string) (string, error) {
+func RunKubeCmd(restconfig *rest.Config, kubeClient kubernetes.Interface, args ...string) (string, error) {
 	cmd2, err := getKubeExecCmd(restconfig, kubeClient, args...)
 	if err != nil {
 		return "", err
@@ -214,7 +269,7 @@ func RunKubeCmd(restconfig *rest.Config, kubeClient *kubernetes.Clientset, args
 }
 
 // RunKubeCmdWithStdin is a simple wrapper to kubectl exec command with standard input
-func RunKubeCmdWithStdin(restconfig *rest.Config, kubeClient *kubernetes.Clientset, stdin string, args ...string) (string, error) {
+func RunKubeCmdWithStdin(restconfig *rest.Config, kubeClient kubernetes.Interface, stdin string, args ...string) (string, error) {
 	cmd2, err := getKubeExecCmd(restconfig, kubeClient, args...)
 	if err != nil {
 		return "", err
@@ -239,7 +294,7 @@ func RunKubeCmdWithStdin(restconfig *rest.Config, kubeClient *kubernetes.Clients
 
 // RunKubeEditorCmd is a wrapper for oc/kubectl but redirects
 // input/output to the user - ex: let user control text editor
-func RunKubeEditorCmd(restConfig *rest.Config, kubeClient *kubernetes.Clientset, args ...string) error {
+func RunKubeEditorCmd(restConfig *rest.Config, kubeClient kubernetes.Interface, args ...string) error {
 	var cmd *exec.Cmd
 	kube, openshift := DetermineClusterClients(restconfig, kubeClient)
 
@@ -249,10 +304,13 @@ func RunKubeEditorCmd(restConfig *rest.Config, kubeClient *kubernetes.Clientset,
 	if args[0] == "cluster-info" && openshift {
 		args[0] = "status"
 	}
-	// add global flags: insecure-skip-tls-verify and --kubeconfig
+	// add global flags: insecure-skip-tls-verify, --context and --kubeconfig
 	if insecureSkipTLSVerify == true {
 		args = append([]string{fmt.Sprintf("--insecure-skip-tls-verify=%t", insecureSkipTLSVerify)}, args...)
 	}
+	if kubeContext != "" {
+		args = append([]string{fmt.Sprintf("--context=%s", kubeContext)}, args...)
+	}
 	if kubeConfigPath != "" {
 		args = append([]string{fmt.Sprintf("--kubeconfig=%s", kubeConfigPath)}, args...)
 	}
@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package synopsysctl
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/blackducksoftware/synopsysctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Wait flags
+var waitForInstance = false
+var waitTimeout int64 = 900
+
+// waitedForInstance is set by commands that already waited for their instance, e.g. an update that waits before rolling back,
+// so that --wait doesn't wait for it again
+var waitedForInstance = false
+
+// addWaitFlags adds the --wait and --timeout flags to the commands of a product, which then wait for the instance to be ready,
+// or to be stopped for the stop commands, after they succeed. Commands that already have a --timeout flag keep it
+func addWaitFlags(product string, cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags().BoolVar(&waitForInstance, "wait", waitForInstance, "Wait until the pods, jobs and persistent volume claims of the instance are ready, or until its pods are removed when stopping")
+		if cmd.Flags().Lookup("timeout") == nil {
+			cmd.Flags().Int64Var(&waitTimeout, "timeout", waitTimeout, "Seconds to wait for the instance when --wait is set")
+		}
+		runE := cmd.RunE
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if err := runE(cmd, args); err != nil || !waitForInstance || waitedForInstance {
+				return err
+			}
+			timeout, err := cmd.Flags().GetInt64("timeout")
+			if err != nil {
+				return err
+			}
+			targets, err := getWaitTargets(product, getCommandVerb(cmd), args)
+			if err != nil {
+				return err
+			}
+			return waitForTargets(targets, time.Duration(timeout)*time.Second)
+		}
+	}
+}
+
+// waitForTargets waits for each instance and shows its progress, redrawn on a terminal or line by line otherwise
+func waitForTargets(targets []*util.WaitTarget, timeout time.Duration) error {
+	if util.Simulation != nil {
+		log.Infof("skipping the wait because the pods of the simulated cluster never start")
+		return nil
+	}
+	for _, target := range targets {
+		progress := util.NewWaitProgress(os.Stdout, target.Description, terminal.IsTerminal(int(os.Stdout.Fd())))
+		if err := util.WaitForInstance(kubeClient, target, timeout, progress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getCommandVerb returns the name of the top level command of a sub-command, e.g. create for create blackduck
+func getCommandVerb(cmd *cobra.Command) string {
+	for cmd.HasParent() && cmd.Parent().HasParent() {
+		cmd = cmd.Parent()
+	}
+	return cmd.Name()
+}
+
+// getWaitTargets returns the instances to wait for after a command. Helm instances are waited for by the resources of their release,
+// OpsSight instances by the labels of the resources that the operator creates for them
+func getWaitTargets(product string, verb string, args []string) ([]*util.WaitTarget, error) {
+	stopped := verb == "stop"
+	if product == util.OpsSightName {
+		appName := util.OpsSightName
+		if verb == "create" {
+			// create resolves the namespace of a new instance without looking up its namespace label
+			appName = ""
+		}
+		targets := []*util.WaitTarget{}
+		for _, opsSightName := range args {
+			opsSightNamespace, _, _, err := getInstanceInfo(util.OpsSightCRDName, appName, namespace, opsSightName)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, &util.WaitTarget{
+				Description: fmt.Sprintf("OpsSight '%s' in namespace '%s'", opsSightName, opsSightNamespace),
+				Namespace:   opsSightNamespace,
+				Selector:    labels.SelectorFromSet(map[string]string{"app": util.OpsSightName, "name": opsSightName}),
+				Stopped:     stopped,
+			})
+		}
+		return targets, nil
+	}
+
+	instanceName := product
+	if releaseProducts[product].takesName {
+		instanceName = args[0]
+	}
+	target, err := getReleaseWaitTarget(namespace, product, instanceName, stopped)
+	if err != nil {
+		return nil, err
+	}
+	return []*util.WaitTarget{target}, nil
+}
+
+// getReleaseWaitTarget returns the resources of the release of a Helm instance to wait for
+func getReleaseWaitTarget(namespace string, product string, instanceName string, stopped bool) (*util.WaitTarget, error) {
+	releaseName := releaseProducts[product].getReleaseName(instanceName)
+	helmRelease, err := util.GetWithHelm3(releaseName, namespace, kubeConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find instance %s in namespace %s", instanceName, namespace)
+	}
+	resources, err := util.GetManifestResources(helmRelease.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the resources of '%s' in namespace '%s' due to %+v", instanceName, namespace, err)
+	}
+	return &util.WaitTarget{
+		Description: fmt.Sprintf("%s '%s' in namespace '%s'", product, instanceName, namespace),
+		Namespace:   namespace,
+		Resources:   util.GetWaitResources(resources),
+		Stopped:     stopped,
+	}, nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WriteTarGzArchive writes the files to a gzip compressed tar archive at filePath. The keys of the map
+// are used as the paths of the files inside the archive
+func WriteTarGzArchive(filePath string, files map[string][]byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the directory for archive '%s' due to %+v", filePath, err)
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive '%s' due to %+v", filePath, err)
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	// sort the names so that the archive content is deterministic
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		header := &tar.Header{
+			Name:    filepath.ToSlash(name),
+			Mode:    0600,
+			Size:    int64(len(files[name])),
+			ModTime: time.Now(),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write header for '%s' to archive '%s' due to %+v", name, filePath, err)
+		}
+		if _, err := tarWriter.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write '%s' to archive '%s' due to %+v", name, filePath, err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close archive '%s' due to %+v", filePath, err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive '%s' due to %+v", filePath, err)
+	}
+	return nil
+}
+
+// ReadTarGzArchive reads all regular files from a gzip compressed tar archive and returns them
+// as a map of the path inside the archive to the file content
+func ReadTarGzArchive(filePath string) (map[string][]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive '%s' due to %+v", filePath, err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive '%s' due to %+v", filePath, err)
+	}
+	defer gzipReader.Close()
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive '%s' due to %+v", filePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '%s' from archive '%s' due to %+v", header.Name, filePath, err)
+		}
+		files[header.Name] = data
+	}
+	return files, nil
+}
+
+// WriteTarGzArchiveFromDirectory writes the regular files of the directory to a gzip compressed tar archive at filePath.
+// The files are streamed so that the archive can be larger than the memory
+func WriteTarGzArchiveFromDirectory(filePath string, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the directory for archive '%s' due to %+v", filePath, err)
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive '%s' due to %+v", filePath, err)
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	// filepath.Walk visits the files in lexical order so that the archive content is deterministic
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Name:    filepath.ToSlash(name),
+			Mode:    0600,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write header for '%s' due to %+v", name, err)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(tarWriter, f); err != nil {
+			return fmt.Errorf("failed to write '%s' due to %+v", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write directory '%s' to archive '%s' due to %+v", dir, filePath, err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close archive '%s' due to %+v", filePath, err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive '%s' due to %+v", filePath, err)
+	}
+	return nil
+}
+
+// ExtractTarGzArchive extracts the regular files of a gzip compressed tar archive to the directory
+func ExtractTarGzArchive(filePath string, dir string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive '%s' due to %+v", filePath, err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to decompress archive '%s' due to %+v", filePath, err)
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive '%s' due to %+v", filePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// don't write files outside of the directory
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("archive '%s' contains an invalid path '%s'", filePath, header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create the directory for '%s' due to %+v", header.Name, err)
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create '%s' due to %+v", path, err)
+		}
+		_, err = io.Copy(f, tarReader)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract '%s' from archive '%s' due to %+v", header.Name, filePath, err)
+		}
+	}
+	return nil
+}
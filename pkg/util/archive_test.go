@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAndReadTarGzArchive(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-archive")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		"manifest.json":         []byte(`{"name":"bd"}`),
+		"values.yaml":           []byte("size: small\n"),
+		"postgres/bds_hub.dump": {0x00, 0x01, 0xff},
+		"empty":                 {},
+	}
+	archivePath := filepath.Join(dir, "nested", "backup.tar.gz")
+	assert.Nil(WriteTarGzArchive(archivePath, files))
+
+	observed, err := ReadTarGzArchive(archivePath)
+	assert.Nil(err)
+	assert.Equal(files, observed)
+}
+
+func TestReadTarGzArchiveMissingFile(t *testing.T) {
+	_, err := ReadTarGzArchive(filepath.Join(os.TempDir(), "synopsysctl-does-not-exist.tar.gz"))
+	assert.NotNil(t, err)
+}
+
+func TestWriteTarGzArchiveFromDirectoryAndExtract(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-archive")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	sourceDir := filepath.Join(dir, "source")
+	files := map[string][]byte{
+		"airgap.yaml":                 []byte("product: blackduck\n"),
+		"charts/blackduck.tgz":        {0x1f, 0x8b},
+		"images/blobs/sha256/abcdef0": []byte("layer"),
+	}
+	for name, data := range files {
+		path := filepath.Join(sourceDir, filepath.FromSlash(name))
+		assert.Nil(os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assert.Nil(ioutil.WriteFile(path, data, 0600))
+	}
+
+	archivePath := filepath.Join(dir, "airgap.tar.gz")
+	assert.Nil(WriteTarGzArchiveFromDirectory(archivePath, sourceDir))
+
+	observed, err := ReadTarGzArchive(archivePath)
+	assert.Nil(err)
+	assert.Equal(files, observed)
+
+	extractDir := filepath.Join(dir, "extract")
+	assert.Nil(ExtractTarGzArchive(archivePath, extractDir))
+	for name, data := range files {
+		extracted, err := ioutil.ReadFile(filepath.Join(extractDir, filepath.FromSlash(name)))
+		assert.Nil(err)
+		assert.Equal(data, extracted)
+	}
+}
+
+func TestExtractTarGzArchiveInvalidPath(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-archive")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	archivePath := filepath.Join(dir, "invalid.tar.gz")
+	assert.Nil(WriteTarGzArchive(archivePath, map[string][]byte{"../outside": []byte("data")}))
+	assert.NotNil(ExtractTarGzArchive(archivePath, filepath.Join(dir, "extract")))
+}
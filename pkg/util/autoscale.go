@@ -0,0 +1,232 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AutoscaleReleaseLabel is the label of a HorizontalPodAutoscaler whose value is the Helm release name of the instance it scales
+const AutoscaleReleaseLabel = "synopsys.com/autoscale-release"
+
+// AutoscaleOff removes the HorizontalPodAutoscaler of a component
+const AutoscaleOff = "off"
+
+// AutoscaleConfig is the configuration of the HorizontalPodAutoscaler of a component
+type AutoscaleConfig struct {
+	MinReplicas                    int32
+	MaxReplicas                    int32
+	TargetCPUUtilizationPercentage int32
+}
+
+// ParseAutoscaleConfig parses a MIN:MAX:CPU_PERCENT autoscale configuration
+func ParseAutoscaleConfig(value string) (*AutoscaleConfig, error) {
+	fields := strings.Split(value, ":")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid autoscale configuration '%s', must be MIN:MAX:CPU_PERCENT", value)
+	}
+	numbers := make([]int32, 3)
+	for i, field := range fields {
+		number, err := strconv.ParseInt(field, 10, 32)
+		if err != nil || number < 1 {
+			return nil, fmt.Errorf("invalid autoscale configuration '%s', '%s' must be a positive number", value, field)
+		}
+		numbers[i] = int32(number)
+	}
+	config := &AutoscaleConfig{MinReplicas: numbers[0], MaxReplicas: numbers[1], TargetCPUUtilizationPercentage: numbers[2]}
+	if config.MinReplicas > config.MaxReplicas {
+		return nil, fmt.Errorf("invalid autoscale configuration '%s', the minimum replicas are greater than the maximum replicas", value)
+	}
+	return config, nil
+}
+
+// ValidateAutoscaleFlag checks the COMPONENT=MIN:MAX:CPU_PERCENT values of an autoscale flag
+func ValidateAutoscaleFlag(autoscale map[string]string, components []string) error {
+	for component, value := range autoscale {
+		if !IsExistInStringSlice(components, component) {
+			return fmt.Errorf("'%s' can't be autoscaled, must be one of [%s]", component, strings.Join(components, "|"))
+		}
+		if value == AutoscaleOff {
+			continue
+		}
+		if _, err := ParseAutoscaleConfig(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAutoscaleHelmValues sets the autoscale configurations of the components in the Helm values, or removes them if they are off
+func SetAutoscaleHelmValues(helmValues map[string]interface{}, autoscale map[string]string) {
+	for component, value := range autoscale {
+		if value == AutoscaleOff {
+			if configs, ok := helmValues["autoscale"].(map[string]interface{}); ok {
+				delete(configs, component)
+			}
+			continue
+		}
+		config, err := ParseAutoscaleConfig(value)
+		if err != nil {
+			continue
+		}
+		SetHelmValueInMap(helmValues, []string{"autoscale", component}, map[string]interface{}{
+			"minReplicas":                    config.MinReplicas,
+			"maxReplicas":                    config.MaxReplicas,
+			"targetCPUUtilizationPercentage": config.TargetCPUUtilizationPercentage,
+		})
+	}
+}
+
+// RemoveAutoscaledReplicasHelmValues sets the replicas of the autoscaled components to null in the Helm values where the chart
+// parameterizes them. The chart then doesn't render the replicas, which every upgrade would otherwise reset under the HorizontalPodAutoscaler.
+// The null replicas of the components that are no longer autoscaled are removed so that they get the replicas of the chart again
+func RemoveAutoscaledReplicasHelmValues(chartValues map[string]interface{}, helmValues map[string]interface{}) {
+	configs := GetAutoscaleConfigsFromHelmValues(helmValues)
+	for component, value := range helmValues {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if replicas, ok := values["replicas"]; ok && replicas == nil {
+			if _, ok := configs[component]; !ok {
+				delete(values, "replicas")
+			}
+		}
+	}
+	for component := range configs {
+		if path := GetComponentReplicasValuePath(chartValues, helmValues, component); path != nil {
+			SetHelmValueInMap(helmValues, path, nil)
+		}
+	}
+}
+
+// GetAutoscaleConfigsFromHelmValues returns the autoscale configurations of the components stored in the Helm values
+func GetAutoscaleConfigsFromHelmValues(helmValues map[string]interface{}) map[string]AutoscaleConfig {
+	configs := map[string]AutoscaleConfig{}
+	values, ok := helmValues["autoscale"].(map[string]interface{})
+	if !ok {
+		return configs
+	}
+	for component, value := range values {
+		config, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		configs[component] = AutoscaleConfig{
+			MinReplicas:                    helmValueToInt32(config["minReplicas"]),
+			MaxReplicas:                    helmValueToInt32(config["maxReplicas"]),
+			TargetCPUUtilizationPercentage: helmValueToInt32(config["targetCPUUtilizationPercentage"]),
+		}
+	}
+	return configs
+}
+
+// GetAutoscaleComponents returns the sorted components of the autoscale configurations
+func GetAutoscaleComponents(configs map[string]AutoscaleConfig) []string {
+	components := []string{}
+	for component := range configs {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// helmValueToInt32 converts a number of the Helm values, which is a float64 once it was stored in a release, to an int32
+func helmValueToInt32(value interface{}) int32 {
+	switch v := value.(type) {
+	case int:
+		return int32(v)
+	case int32:
+		return v
+	case int64:
+		return int32(v)
+	case float64:
+		return int32(v)
+	}
+	return 0
+}
+
+// GetHorizontalPodAutoscaler returns a HorizontalPodAutoscaler that scales the deployment on its CPU utilization.
+// It is an autoscaling/v2beta2 object because client-go v0.17 doesn't have autoscaling/v2; switch once the Kubernetes libraries are upgraded
+func GetHorizontalPodAutoscaler(namespace string, name string, labels map[string]string, deploymentName string, config AutoscaleConfig) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	minReplicas := config.MinReplicas
+	targetCPUUtilizationPercentage := config.TargetCPUUtilizationPercentage
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2beta2"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: deploymentName},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    config.MaxReplicas,
+			Metrics: []autoscalingv2beta2.MetricSpec{
+				{
+					Type: autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2beta2.MetricTarget{
+							Type:               autoscalingv2beta2.UtilizationMetricType,
+							AverageUtilization: &targetCPUUtilizationPercentage,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ListHorizontalPodAutoscalers will get all the HorizontalPodAutoscalers corresponding to a namespace
+func ListHorizontalPodAutoscalers(clientset kubernetes.Interface, namespace string, labelSelector string) (*autoscalingv2beta2.HorizontalPodAutoscalerList, error) {
+	return clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+}
+
+// CreateOrUpdateHorizontalPodAutoscaler creates the HorizontalPodAutoscaler, or updates its spec if it already exists
+func CreateOrUpdateHorizontalPodAutoscaler(clientset kubernetes.Interface, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) error {
+	existing, err := clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(hpa.Namespace).Get(hpa.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			_, err = clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(hpa.Namespace).Create(hpa)
+		}
+		return err
+	}
+	existing.Labels = hpa.Labels
+	existing.Spec = hpa.Spec
+	_, err = clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(hpa.Namespace).Update(existing)
+	return err
+}
+
+// DeleteHorizontalPodAutoscalerIfExists deletes the HorizontalPodAutoscaler if it exists
+func DeleteHorizontalPodAutoscalerIfExists(clientset kubernetes.Interface, namespace string, name string) error {
+	err := clientset.AutoscalingV2beta2().HorizontalPodAutoscalers(namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseAutoscaleConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	config, err := ParseAutoscaleConfig("2:10:70")
+	assert.Nil(err)
+	assert.Equal(&AutoscaleConfig{MinReplicas: 2, MaxReplicas: 10, TargetCPUUtilizationPercentage: 70}, config)
+
+	for _, value := range []string{"2:10", "2:10:70:1", "0:10:70", "a:10:70", "10:2:70"} {
+		_, err := ParseAutoscaleConfig(value)
+		assert.NotNil(err, value)
+	}
+}
+
+func TestValidateAutoscaleFlag(t *testing.T) {
+	assert := assert.New(t)
+
+	components := []string{"jobrunner", "scan"}
+	assert.Nil(ValidateAutoscaleFlag(map[string]string{"jobrunner": "1:5:70", "scan": AutoscaleOff}, components))
+	assert.NotNil(ValidateAutoscaleFlag(map[string]string{"webapp": "1:5:70"}, components))
+	assert.NotNil(ValidateAutoscaleFlag(map[string]string{"scan": "1:5"}, components))
+}
+
+func TestAutoscaleHelmValues(t *testing.T) {
+	assert := assert.New(t)
+
+	// the values of a release are float64 once they are stored
+	helmValues := map[string]interface{}{
+		"autoscale": map[string]interface{}{
+			"scan": map[string]interface{}{"minReplicas": float64(1), "maxReplicas": float64(3), "targetCPUUtilizationPercentage": float64(80)},
+		},
+	}
+	SetAutoscaleHelmValues(helmValues, map[string]string{"jobrunner": "2:6:70", "scan": AutoscaleOff})
+	assert.Equal(map[string]AutoscaleConfig{
+		"jobrunner": {MinReplicas: 2, MaxReplicas: 6, TargetCPUUtilizationPercentage: 70},
+	}, GetAutoscaleConfigsFromHelmValues(helmValues))
+
+	helmValues = map[string]interface{}{}
+	SetAutoscaleHelmValues(helmValues, map[string]string{"scan": "1:3:80"})
+	assert.Equal([]string{"scan"}, GetAutoscaleComponents(GetAutoscaleConfigsFromHelmValues(helmValues)))
+}
+
+func TestRemoveAutoscaledReplicasHelmValues(t *testing.T) {
+	assert := assert.New(t)
+
+	chartValues := map[string]interface{}{"webserver": map[string]interface{}{"replicas": 1}, "jobrunner": map[string]interface{}{"replicas": 1}}
+	helmValues := map[string]interface{}{"jobrunner": map[string]interface{}{"replicas": 2}}
+	SetAutoscaleHelmValues(helmValues, map[string]string{"webserver": "2:4:80", "scan": "1:3:70"})
+	RemoveAutoscaledReplicasHelmValues(chartValues, helmValues)
+
+	replicas, ok := helmValues["webserver"].(map[string]interface{})["replicas"]
+	assert.True(ok)
+	assert.Nil(replicas)
+	assert.Equal(2, GetHelmValueFromMap(helmValues, []string{"jobrunner", "replicas"}))
+	// the chart doesn't parameterize the replicas of the scan component
+	assert.Nil(helmValues["scan"])
+
+	SetAutoscaleHelmValues(helmValues, map[string]string{"webserver": AutoscaleOff})
+	RemoveAutoscaledReplicasHelmValues(chartValues, helmValues)
+	_, ok = helmValues["webserver"].(map[string]interface{})["replicas"]
+	assert.False(ok)
+}
+
+func TestGetHorizontalPodAutoscaler(t *testing.T) {
+	assert := assert.New(t)
+
+	hpa := GetHorizontalPodAutoscaler("bd", "bd-blackduck-jobrunner", map[string]string{AutoscaleReleaseLabel: "bd"}, "bd-blackduck-jobrunner", AutoscaleConfig{MinReplicas: 1, MaxReplicas: 5, TargetCPUUtilizationPercentage: 70})
+	assert.Equal("Deployment", hpa.Spec.ScaleTargetRef.Kind)
+	assert.Equal("bd-blackduck-jobrunner", hpa.Spec.ScaleTargetRef.Name)
+	assert.Equal(int32(1), *hpa.Spec.MinReplicas)
+	assert.Equal(int32(5), hpa.Spec.MaxReplicas)
+	assert.Equal(corev1.ResourceCPU, hpa.Spec.Metrics[0].Resource.Name)
+	assert.Equal(int32(70), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// CertManagerCertificateGroupVersionResource is the resource of the cert-manager Certificate custom resource
+var CertManagerCertificateGroupVersionResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// CertManagerSecretAnnotation is set on a secret that the certificate and key of a secret issued by cert-manager are copied to.
+// Its value is the name of the issued secret
+const CertManagerSecretAnnotation = "synopsys.com/cert-manager-secret"
+
+// CertManagerCertificate is a cert-manager Certificate of an instance. cert-manager stores the certificate and the key in the
+// tls.crt and tls.key keys of its secret, so they are copied to the secret CopySecretName for charts that read them from other keys
+type CertManagerCertificate struct {
+	// Certificate is the cert-manager Certificate object
+	Certificate *unstructured.Unstructured
+	// CopySecretName is the name of the secret that the certificate and the key are copied to, if any
+	CopySecretName string
+	// CopyCertificateKey is the key of the certificate in the copy, e.g. WEBSERVER_CUSTOM_CERT_FILE
+	CopyCertificateKey string
+	// CopyKeyKey is the key of the private key in the copy, e.g. WEBSERVER_CUSTOM_KEY_FILE
+	CopyKeyKey string
+}
+
+// GetSecretCopy returns the copy of the certificate and the key of the issued secret, labeled like the Certificate
+func (c *CertManagerCertificate) GetSecretCopy(issued *corev1.Secret) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        c.CopySecretName,
+			Namespace:   issued.Namespace,
+			Labels:      c.Certificate.GetLabels(),
+			Annotations: map[string]string{CertManagerSecretAnnotation: issued.Name},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			c.CopyCertificateKey: issued.Data[corev1.TLSCertKey],
+			c.CopyKeyKey:         issued.Data[corev1.TLSPrivateKeyKey],
+		},
+	}
+}
+
+// CertManagerIssuerKinds are the kinds of cert-manager issuers that can sign a certificate
+var CertManagerIssuerKinds = []string{"Issuer", "ClusterIssuer"}
+
+// ParseCertManagerIssuer returns the name and the kind of a cert-manager issuer in the NAME[:kind] format. The kind defaults to Issuer
+func ParseCertManagerIssuer(issuer string) (string, string, error) {
+	values := strings.SplitN(issuer, ":", 2)
+	name := strings.TrimSpace(values[0])
+	if len(name) == 0 {
+		return "", "", fmt.Errorf("'%s' is an invalid cert-manager issuer, must be NAME[:%s]", issuer, strings.Join(CertManagerIssuerKinds, "|"))
+	}
+	if len(values) == 1 {
+		return name, CertManagerIssuerKinds[0], nil
+	}
+	for _, kind := range CertManagerIssuerKinds {
+		if strings.EqualFold(kind, strings.TrimSpace(values[1])) {
+			return name, kind, nil
+		}
+	}
+	return "", "", fmt.Errorf("'%s' is an invalid cert-manager issuer kind, must be one of [%s]", values[1], strings.Join(CertManagerIssuerKinds, "|"))
+}
+
+// GetCertManagerCertificate returns a cert-manager Certificate object that stores the certificate for the DNS names in the secret
+func GetCertManagerCertificate(namespace string, name string, secretName string, issuer string, dnsNames []string, labels map[string]string) (*unstructured.Unstructured, error) {
+	issuerName, issuerKind, err := ParseCertManagerIssuer(issuer)
+	if err != nil {
+		return nil, err
+	}
+	names := []interface{}{}
+	for _, dnsName := range dnsNames {
+		names = append(names, dnsName)
+	}
+	certificate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": fmt.Sprintf("%s/%s", CertManagerCertificateGroupVersionResource.Group, CertManagerCertificateGroupVersionResource.Version),
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"secretName": secretName,
+				"commonName": dnsNames[0],
+				"dnsNames":   names,
+				"issuerRef": map[string]interface{}{
+					"name":  issuerName,
+					"kind":  issuerKind,
+					"group": CertManagerCertificateGroupVersionResource.Group,
+				},
+			},
+		},
+	}
+	certificate.SetLabels(labels)
+	return certificate, nil
+}
+
+// CreateOrUpdateCertManagerCertificate creates the cert-manager Certificate, or updates its spec if it already exists
+func CreateOrUpdateCertManagerCertificate(restConfig *rest.Config, certificate *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	client := dynamicClient.Resource(CertManagerCertificateGroupVersionResource).Namespace(certificate.GetNamespace())
+	created, err := client.Create(certificate, metav1.CreateOptions{})
+	if err == nil || !k8serrors.IsAlreadyExists(err) {
+		return created, err
+	}
+	existing, err := client.Get(certificate.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	existing.Object["spec"] = certificate.Object["spec"]
+	return client.Update(existing, metav1.UpdateOptions{})
+}
+
+// ListCertManagerCertificates returns the cert-manager Certificates in the namespace that match the label selector. It returns
+// no Certificates if cert-manager isn't installed in the cluster
+func ListCertManagerCertificates(restConfig *rest.Config, namespace string, labelSelector string) ([]unstructured.Unstructured, error) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	list, err := dynamicClient.Resource(CertManagerCertificateGroupVersionResource).Namespace(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return []unstructured.Unstructured{}, nil
+		}
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// DeleteCertManagerCertificate deletes the cert-manager Certificate
+func DeleteCertManagerCertificate(restConfig *rest.Config, namespace string, name string) error {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create the dynamic client due to %+v", err)
+	}
+	return dynamicClient.Resource(CertManagerCertificateGroupVersionResource).Namespace(namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+// WaitForCertificateSecret waits until cert-manager stores a signed certificate in the secret
+func WaitForCertificateSecret(clientset kubernetes.Interface, namespace string, name string, timeout time.Duration) (*corev1.Secret, error) {
+	var secret *corev1.Secret
+	err := wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		s, err := clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if len(s.Data[corev1.TLSCertKey]) == 0 {
+			return false, nil
+		}
+		secret = s
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return nil, fmt.Errorf("timed out after %s waiting for cert-manager to issue the certificate in secret '%s'", timeout, name)
+	}
+	return secret, err
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestParseCertManagerIssuer(t *testing.T) {
+	var tests = []struct {
+		issuer string
+		name   string
+		kind   string
+		err    bool
+	}{
+		{issuer: "letsencrypt", name: "letsencrypt", kind: "Issuer"},
+		{issuer: "letsencrypt:ClusterIssuer", name: "letsencrypt", kind: "ClusterIssuer"},
+		{issuer: "letsencrypt:clusterissuer", name: "letsencrypt", kind: "ClusterIssuer"},
+		{issuer: "letsencrypt:Secret", err: true},
+		{issuer: ":Issuer", err: true},
+	}
+
+	for _, test := range tests {
+		name, kind, err := ParseCertManagerIssuer(test.issuer)
+		if test.err {
+			assert.Error(t, err, test.issuer)
+			continue
+		}
+		assert.NoError(t, err, test.issuer)
+		assert.Equal(t, test.name, name)
+		assert.Equal(t, test.kind, kind)
+	}
+}
+
+func TestGetCertManagerCertificate(t *testing.T) {
+	certificate, err := GetCertManagerCertificate("ns", "bd-blackduck-certificate", "bd-blackduck-webserver-certificate", "letsencrypt:ClusterIssuer", []string{"bd.example.com"}, map[string]string{"app": "blackduck"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cert-manager.io/v1", certificate.GetAPIVersion())
+	assert.Equal(t, "Certificate", certificate.GetKind())
+	assert.Equal(t, "ns", certificate.GetNamespace())
+	assert.Equal(t, map[string]string{"app": "blackduck"}, certificate.GetLabels())
+
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	assert.Equal(t, "bd-blackduck-webserver-certificate", secretName)
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Equal(t, []string{"bd.example.com"}, dnsNames)
+	issuerRef, _, _ := unstructured.NestedStringMap(certificate.Object, "spec", "issuerRef")
+	assert.Equal(t, map[string]string{"name": "letsencrypt", "kind": "ClusterIssuer", "group": "cert-manager.io"}, issuerRef)
+
+	_, err = GetCertManagerCertificate("ns", "bd-blackduck-certificate", "bd-blackduck-webserver-certificate", "letsencrypt:Secret", []string{"bd.example.com"}, nil)
+	assert.Error(t, err)
+}
+
+func TestWaitForCertificateSecret(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "issued", Namespace: "ns"},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "ns"},
+		},
+	)
+
+	secret, err := WaitForCertificateSecret(clientset, "ns", "issued", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cert"), secret.Data[corev1.TLSCertKey])
+
+	_, err = WaitForCertificateSecret(clientset, "ns", "pending", time.Second)
+	assert.Error(t, err)
+
+	_, err = WaitForCertificateSecret(clientset, "ns", "missing", time.Second)
+	assert.Error(t, err)
+}
+
+func TestCertManagerCertificateGetSecretCopy(t *testing.T) {
+	certificate, err := GetCertManagerCertificate("ns", "bd-blackduck-webserver-cert-manager-tls", "bd-blackduck-webserver-cert-manager-tls", "letsencrypt", []string{"bd.example.com"}, map[string]string{"app": "blackduck", "name": "bd"})
+	assert.NoError(t, err)
+	c := &CertManagerCertificate{Certificate: certificate, CopySecretName: "bd-blackduck-webserver-certificate", CopyCertificateKey: "WEBSERVER_CUSTOM_CERT_FILE", CopyKeyKey: "WEBSERVER_CUSTOM_KEY_FILE"}
+
+	secret := c.GetSecretCopy(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bd-blackduck-webserver-cert-manager-tls", Namespace: "ns"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key"), "ca.crt": []byte("ca")},
+	})
+	assert.Equal(t, "bd-blackduck-webserver-certificate", secret.Name)
+	assert.Equal(t, "ns", secret.Namespace)
+	assert.Equal(t, map[string]string{"app": "blackduck", "name": "bd"}, secret.Labels)
+	assert.Equal(t, "bd-blackduck-webserver-cert-manager-tls", secret.Annotations[CertManagerSecretAnnotation])
+	assert.Equal(t, map[string][]byte{"WEBSERVER_CUSTOM_CERT_FILE": []byte("cert"), "WEBSERVER_CUSTOM_KEY_FILE": []byte("key")}, secret.Data)
+}
+
+func TestListCertManagerCertificates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/cert-manager.io/v1/namespaces/ns/certificates":
+			assert.Equal(t, "app=blackduck,name=bd", r.URL.Query().Get("labelSelector"))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"apiVersion":"cert-manager.io/v1","kind":"CertificateList","metadata":{},"items":[{"apiVersion":"cert-manager.io/v1","kind":"Certificate","metadata":{"name":"bd-blackduck-webserver-cert-manager-tls","namespace":"ns"}}]}`))
+		default:
+			// the Certificate resource doesn't exist if cert-manager isn't installed
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"apiVersion":"v1","kind":"Status","status":"Failure","reason":"NotFound","code":404}`))
+		}
+	}))
+	defer server.Close()
+
+	certificates, err := ListCertManagerCertificates(&rest.Config{Host: server.URL}, "ns", "app=blackduck,name=bd")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(certificates))
+	assert.Equal(t, "bd-blackduck-webserver-cert-manager-tls", certificates[0].GetName())
+
+	certificates, err = ListCertManagerCertificates(&rest.Config{Host: server.URL}, "other", "app=blackduck,name=bd")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(certificates))
+}
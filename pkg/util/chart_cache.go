@@ -0,0 +1,375 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// ChartCacheDirectory is the directory of the chart cache used by LoadChart. The cache is disabled if it is empty
+var ChartCacheDirectory = ""
+
+// ChartKeyring is the PGP keyring that the provenance files of the pulled charts are verified with. The charts aren't verified if it is empty
+var ChartKeyring = ""
+
+// chartCacheIndexFileName is the name of the file that lists the cached charts
+const chartCacheIndexFileName = "index.yaml"
+
+// CachedChart is a chart in the chart cache
+type CachedChart struct {
+	// Name is the file name of the chart, e.g. blackduck-2020.4.0.tgz
+	Name string `json:"name"`
+	// File is the name of the chart file in the cache directory. Charts are stored by digest so that charts with the same name from different URLs don't overwrite each other
+	File string `json:"file,omitempty"`
+	// URL is the location the chart was downloaded from
+	URL string `json:"url"`
+	// SHA256 is the digest of the chart
+	SHA256 string `json:"sha256"`
+	// Pinned is true if the digest was approved when the chart was pulled. A pinned chart can't be replaced by a chart with a different digest
+	Pinned bool `json:"pinned,omitempty"`
+	// SignedBy is the signer of the provenance file that the chart was verified with
+	SignedBy string `json:"signedBy,omitempty"`
+	// PulledAt is the time the chart was downloaded
+	PulledAt time.Time `json:"pulledAt"`
+}
+
+// ChartCache stores the charts downloaded from the chart repository
+type ChartCache struct {
+	dir     string
+	keyring string
+}
+
+// NewChartCache returns a chart cache in the directory. If keyring is set, the charts must have a provenance file signed by a key in the keyring
+func NewChartCache(dir string, keyring string) *ChartCache {
+	return &ChartCache{dir: dir, keyring: keyring}
+}
+
+// Pull downloads the chart, verifies its digest and stores it in the cache. If sha256Digest is set, the chart must have that digest
+// and the digest is pinned. If the cache has a keyring, the provenance file of the chart must be signed by a key in the keyring and list the digest of the chart
+func (c *ChartCache) Pull(chartURL string, sha256Digest string) (*CachedChart, error) {
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "synopsysctl-chart")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory due to %+v", err)
+	}
+	defer os.RemoveAll(dir)
+	chartPath, err := c.download(chartURL, dir)
+	if err != nil {
+		return nil, err
+	}
+	return c.add(index, chartURL, chartPath, sha256Digest)
+}
+
+// add verifies the digest and the provenance file of a downloaded chart and stores it in the cache
+func (c *ChartCache) add(index map[string]CachedChart, chartURL string, chartPath string, sha256Digest string) (*CachedChart, error) {
+	name := filepath.Base(chartURL)
+	chartBytes, err := ioutil.ReadFile(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the downloaded chart '%s' due to %+v", chartURL, err)
+	}
+	digest := chartDigest(chartBytes)
+
+	if len(sha256Digest) > 0 && !strings.EqualFold(sha256Digest, digest) {
+		return nil, fmt.Errorf("chart '%s' has digest '%s' but '%s' was expected", chartURL, digest, sha256Digest)
+	}
+	if cached, ok := index[chartURL]; ok && cached.Pinned && cached.SHA256 != digest {
+		return nil, fmt.Errorf("chart '%s' has digest '%s' but '%s' is pinned", chartURL, digest, cached.SHA256)
+	}
+	signedBy := ""
+	if len(c.keyring) > 0 {
+		if signedBy, err = c.verifyProvenance(chartURL, chartPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create the chart cache '%s' due to %+v", c.dir, err)
+	}
+	file := fmt.Sprintf("%s-%s", digest, name)
+	if err := ioutil.WriteFile(filepath.Join(c.dir, file), chartBytes, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write chart '%s' to the cache due to %+v", name, err)
+	}
+	cached := CachedChart{
+		Name:     name,
+		File:     file,
+		URL:      chartURL,
+		SHA256:   digest,
+		Pinned:   len(sha256Digest) > 0 || index[chartURL].Pinned,
+		SignedBy: signedBy,
+		PulledAt: time.Now().UTC(),
+	}
+	if previous, ok := index[chartURL]; ok && previous.File != file {
+		index[chartURL] = cached
+		if err := c.removeUnusedFile(index, previous.File); err != nil {
+			return nil, err
+		}
+	}
+	index[chartURL] = cached
+	if err := c.writeIndex(index); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+// Get returns the path of the cached chart that was downloaded from the URL. The chart file must still match the digest it was pulled with
+func (c *ChartCache) Get(chartURL string) (string, error) {
+	index, err := c.readIndex()
+	if err != nil {
+		return "", err
+	}
+	return c.get(index, chartURL)
+}
+
+func (c *ChartCache) get(index map[string]CachedChart, chartURL string) (string, error) {
+	cached, ok := index[chartURL]
+	if !ok {
+		return "", fmt.Errorf("chart '%s' isn't in the cache", chartURL)
+	}
+	path := filepath.Join(c.dir, cached.File)
+	chartBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read cached chart '%s' due to %+v", path, err)
+	}
+	if digest := chartDigest(chartBytes); digest != cached.SHA256 {
+		return "", fmt.Errorf("cached chart '%s' has digest '%s' but '%s' was pulled", path, digest, cached.SHA256)
+	}
+	return path, nil
+}
+
+// Resolve returns the path of the chart for the URL in the cache. A pinned chart is used from the cache, since its
+// digest can't change. Other charts are downloaded from the repository first so that the cache follows the repository,
+// and the cached chart is only used if the repository can't be reached. Local paths are returned as they are
+func (c *ChartCache) Resolve(chartURL string) (string, error) {
+	if !strings.HasPrefix(chartURL, "http://") && !strings.HasPrefix(chartURL, "https://") {
+		return chartURL, nil
+	}
+	index, err := c.readIndex()
+	if err != nil {
+		return "", err
+	}
+	if index[chartURL].Pinned {
+		if path, err := c.get(index, chartURL); err == nil {
+			log.Debugf("using pinned chart '%s'", path)
+			return path, nil
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "synopsysctl-chart")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a temporary directory due to %+v", err)
+	}
+	defer os.RemoveAll(dir)
+	chartPath, err := c.download(chartURL, dir)
+	if err != nil {
+		path, cacheErr := c.get(index, chartURL)
+		if cacheErr != nil {
+			return "", err
+		}
+		log.Warnf("using cached chart '%s' because %+v", path, err)
+		return path, nil
+	}
+	cached, err := c.add(index, chartURL, chartPath, "")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.dir, cached.File), nil
+}
+
+// List returns the cached charts sorted by name and URL
+func (c *ChartCache) List() ([]CachedChart, error) {
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	return sortCachedCharts(index), nil
+}
+
+// Prune removes the cached charts for which remove returns true, the charts that are missing or don't match their digest
+// and the files that aren't in the index. It returns the URLs of the removed charts and the names of the removed files
+func (c *ChartCache) Prune(remove func(cached CachedChart) bool) ([]string, error) {
+	index, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	removed := []string{}
+	for chartURL, cached := range index {
+		chartBytes, err := ioutil.ReadFile(filepath.Join(c.dir, cached.File))
+		if err == nil && chartDigest(chartBytes) == cached.SHA256 && !remove(cached) {
+			continue
+		}
+		delete(index, chartURL)
+		removed = append(removed, chartURL)
+	}
+
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read the chart cache '%s' due to %+v", c.dir, err)
+	}
+	used := map[string]bool{}
+	for _, cached := range index {
+		used[cached.File] = true
+	}
+	for _, f := range files {
+		if used[f.Name()] || f.Name() == chartCacheIndexFileName || f.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, f.Name())); err != nil {
+			return nil, fmt.Errorf("failed to remove '%s' due to %+v", f.Name(), err)
+		}
+		if !isCachedChartFile(f.Name()) {
+			removed = append(removed, f.Name())
+		}
+	}
+	sort.Strings(removed)
+	if len(removed) == 0 {
+		return removed, nil
+	}
+	return removed, c.writeIndex(index)
+}
+
+// removeUnusedFile removes the chart file if no chart in the index uses it anymore
+func (c *ChartCache) removeUnusedFile(index map[string]CachedChart, file string) error {
+	for _, cached := range index {
+		if cached.File == file {
+			return nil
+		}
+	}
+	if err := os.Remove(filepath.Join(c.dir, file)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cached chart '%s' due to %+v", file, err)
+	}
+	return nil
+}
+
+// download downloads the chart, and its provenance file if the cache has a keyring, to the directory. The Helm chart
+// downloader uses the credentials and TLS settings of the Helm repository of the URL like LocateChart does
+func (c *ChartCache) download(chartURL string, dir string) (string, error) {
+	chartDownloader := downloader.ChartDownloader{
+		Out:              ioutil.Discard,
+		Verify:           downloader.VerifyNever,
+		Getters:          getter.All(settings),
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+	if len(c.keyring) > 0 {
+		chartDownloader.Verify = downloader.VerifyLater
+	}
+	chartPath, _, err := chartDownloader.DownloadTo(chartURL, "", dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to download chart '%s' due to %+v", chartURL, err)
+	}
+	return chartPath, nil
+}
+
+// verifyProvenance verifies the signature of the provenance file of the downloaded chart with the keyring and checks that it
+// lists the digest of the chart. It returns the signer of the provenance file
+func (c *ChartCache) verifyProvenance(chartURL string, chartPath string) (string, error) {
+	if _, err := os.Stat(chartPath + ".prov"); err != nil {
+		return "", fmt.Errorf("failed to get the provenance file of chart '%s'", chartURL)
+	}
+	verification, err := downloader.VerifyChart(chartPath, c.keyring)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify chart '%s' with its provenance file due to %+v", chartURL, err)
+	}
+	for name := range verification.SignedBy.Identities {
+		return name, nil
+	}
+	return fmt.Sprintf("%X", verification.SignedBy.PrimaryKey.Fingerprint), nil
+}
+
+func (c *ChartCache) readIndex() (map[string]CachedChart, error) {
+	index := map[string]CachedChart{}
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, chartCacheIndexFileName))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the chart cache index due to %+v", err)
+	}
+	charts := []CachedChart{}
+	if err := yaml.Unmarshal(data, &charts); err != nil {
+		return nil, fmt.Errorf("failed to parse the chart cache index due to %+v", err)
+	}
+	for _, cached := range charts {
+		// charts pulled by older versions are stored by name
+		if len(cached.File) == 0 {
+			cached.File = cached.Name
+		}
+		index[cached.URL] = cached
+	}
+	return index, nil
+}
+
+func (c *ChartCache) writeIndex(index map[string]CachedChart) error {
+	data, err := yaml.Marshal(sortCachedCharts(index))
+	if err != nil {
+		return fmt.Errorf("failed to convert the chart cache index to yaml: %+v", err)
+	}
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the chart cache '%s' due to %+v", c.dir, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(c.dir, chartCacheIndexFileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to write the chart cache index due to %+v", err)
+	}
+	return nil
+}
+
+// sortCachedCharts returns the charts of the index sorted by name and URL
+func sortCachedCharts(index map[string]CachedChart) []CachedChart {
+	charts := []CachedChart{}
+	for _, cached := range index {
+		charts = append(charts, cached)
+	}
+	sort.Slice(charts, func(i, j int) bool {
+		if charts[i].Name != charts[j].Name {
+			return charts[i].Name < charts[j].Name
+		}
+		return charts[i].URL < charts[j].URL
+	})
+	return charts
+}
+
+// isCachedChartFile returns true if the file name has the form <sha256>-<chart name> of the cached charts
+func isCachedChartFile(name string) bool {
+	parts := strings.SplitN(name, "-", 2)
+	return len(parts) == 2 && len(parts[0]) == sha256.Size*2
+}
+
+func chartDigest(chartBytes []byte) string {
+	sum := sha256.Sum256(chartBytes)
+	return hex.EncodeToString(sum[:])
+}
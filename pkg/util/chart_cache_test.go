@@ -0,0 +1,261 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+func newTestChartRepository(charts map[string][]byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := charts[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(content)
+	}))
+}
+
+func TestChartCachePullAndResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-charts")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	chart := []byte("chart")
+	charts := map[string][]byte{"/charts/blackduck-2020.4.0.tgz": chart}
+	server := newTestChartRepository(charts)
+	chartURL := fmt.Sprintf("%s/charts/blackduck-2020.4.0.tgz", server.URL)
+
+	cache := NewChartCache(dir, "")
+	_, err = cache.Pull(chartURL, "0000")
+	assert.NotNil(err)
+
+	cached, err := cache.Pull(chartURL, chartDigest(chart))
+	assert.Nil(err)
+	assert.True(cached.Pinned)
+	assert.Equal("blackduck-2020.4.0.tgz", cached.Name)
+
+	// a pinned chart can't change and is resolved without the chart repository
+	charts["/charts/blackduck-2020.4.0.tgz"] = []byte("changed")
+	_, err = cache.Pull(chartURL, "")
+	assert.NotNil(err)
+	path, err := cache.Resolve(chartURL)
+	assert.Nil(err)
+	assert.Equal(filepath.Join(dir, fmt.Sprintf("%s-blackduck-2020.4.0.tgz", chartDigest(chart))), path)
+
+	// the other charts are downloaded again so that they follow the chart repository
+	alertURL := fmt.Sprintf("%s/charts/alert-5.3.0.tgz", server.URL)
+	charts["/charts/alert-5.3.0.tgz"] = []byte("alert")
+	path, err = cache.Resolve(alertURL)
+	assert.Nil(err)
+	assert.Equal(filepath.Join(dir, fmt.Sprintf("%s-alert-5.3.0.tgz", chartDigest([]byte("alert")))), path)
+	charts["/charts/alert-5.3.0.tgz"] = []byte("alert changed")
+	path, err = cache.Resolve(alertURL)
+	assert.Nil(err)
+	assert.Equal(filepath.Join(dir, fmt.Sprintf("%s-alert-5.3.0.tgz", chartDigest([]byte("alert changed")))), path)
+
+	// the cache is used when the chart repository can't be reached
+	server.Close()
+	path, err = cache.Resolve(alertURL)
+	assert.Nil(err)
+	assert.Equal(filepath.Join(dir, fmt.Sprintf("%s-alert-5.3.0.tgz", chartDigest([]byte("alert changed")))), path)
+	path, err = cache.Resolve(chartURL)
+	assert.Nil(err)
+	assert.Equal(filepath.Join(dir, fmt.Sprintf("%s-blackduck-2020.4.0.tgz", chartDigest(chart))), path)
+
+	// a chart with the same name from another URL isn't used
+	_, err = cache.Resolve("http://127.0.0.1:1/charts/blackduck-2020.4.0.tgz")
+	assert.NotNil(err)
+
+	// local paths aren't cached
+	path, err = cache.Resolve("/tmp/blackduck.tgz")
+	assert.Nil(err)
+	assert.Equal("/tmp/blackduck.tgz", path)
+
+	list, err := cache.List()
+	assert.Nil(err)
+	assert.Equal(2, len(list))
+}
+
+func TestChartCacheSameNameFromDifferentURLs(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-charts")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	server := newTestChartRepository(map[string][]byte{
+		"/stable/blackduck-2020.4.0.tgz": []byte("stable"),
+		"/forked/blackduck-2020.4.0.tgz": []byte("forked"),
+	})
+	defer server.Close()
+
+	cache := NewChartCache(dir, "")
+	stablePath, err := cache.Resolve(fmt.Sprintf("%s/stable/blackduck-2020.4.0.tgz", server.URL))
+	assert.Nil(err)
+	forkedPath, err := cache.Resolve(fmt.Sprintf("%s/forked/blackduck-2020.4.0.tgz", server.URL))
+	assert.Nil(err)
+	assert.NotEqual(stablePath, forkedPath)
+
+	content, err := ioutil.ReadFile(stablePath)
+	assert.Nil(err)
+	assert.Equal("stable", string(content))
+	content, err = ioutil.ReadFile(forkedPath)
+	assert.Nil(err)
+	assert.Equal("forked", string(content))
+}
+
+// newTestSignedChart returns a chart, its provenance file signed by a new key and a keyring with the key
+func newTestSignedChart(t *testing.T, dir string) ([]byte, []byte, string) {
+	entity, err := openpgp.NewEntity("synopsysctl", "test", "test@synopsys.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := filepath.Join(dir, "pubring.gpg")
+	keyFile := filepath.Join(dir, "secring.gpg")
+	for path, serialize := range map[string]func(w io.Writer) error{keyring: entity.Serialize, keyFile: func(w io.Writer) error { return entity.SerializePrivate(w, nil) }} {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := serialize(f); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+
+	chartPath, err := chartutil.Save(&chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "alert", Version: "5.3.0"}}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signatory, err := provenance.NewFromFiles(keyFile, keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signatory.ClearSign(chartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chartBytes, err := ioutil.ReadFile(chartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chartBytes, []byte(signature), keyring
+}
+
+func TestChartCacheProvenance(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-charts")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	keyDir, err := ioutil.TempDir("", "synopsysctl-keys")
+	assert.Nil(err)
+	defer os.RemoveAll(keyDir)
+
+	chartBytes, signature, keyring := newTestSignedChart(t, keyDir)
+	forged := regexp.MustCompile(`sha256:[0-9a-f]{64}`).ReplaceAll(signature, []byte(fmt.Sprintf("sha256:%s", chartDigest([]byte("other")))))
+	server := newTestChartRepository(map[string][]byte{
+		"/signed/alert-5.3.0.tgz":        chartBytes,
+		"/signed/alert-5.3.0.tgz.prov":   signature,
+		"/forged/alert-5.3.0.tgz":        []byte("other"),
+		"/forged/alert-5.3.0.tgz.prov":   forged,
+		"/unsigned/alert-5.3.0.tgz":      chartBytes,
+		"/tampered/alert-5.3.0.tgz":      []byte("other"),
+		"/tampered/alert-5.3.0.tgz.prov": signature,
+	})
+	defer server.Close()
+
+	cache := NewChartCache(dir, keyring)
+	cached, err := cache.Pull(fmt.Sprintf("%s/signed/alert-5.3.0.tgz", server.URL), "")
+	assert.Nil(err)
+	assert.Equal("synopsysctl (test) <test@synopsys.com>", cached.SignedBy)
+
+	// a provenance file whose digest was changed doesn't have a valid signature
+	_, err = cache.Pull(fmt.Sprintf("%s/forged/alert-5.3.0.tgz", server.URL), "")
+	assert.NotNil(err)
+	// the provenance file is required if the cache has a keyring
+	_, err = cache.Pull(fmt.Sprintf("%s/unsigned/alert-5.3.0.tgz", server.URL), "")
+	assert.NotNil(err)
+	// the chart must match the digest of the provenance file
+	_, err = cache.Pull(fmt.Sprintf("%s/tampered/alert-5.3.0.tgz", server.URL), "")
+	assert.NotNil(err)
+
+	// the charts aren't verified without a keyring
+	_, err = NewChartCache(dir, "").Pull(fmt.Sprintf("%s/unsigned/alert-5.3.0.tgz", server.URL), "")
+	assert.Nil(err)
+}
+
+func TestChartCachePrune(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-charts")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	server := newTestChartRepository(map[string][]byte{
+		"/charts/alert-5.3.0.tgz":        []byte("alert"),
+		"/charts/blackduck-2020.4.0.tgz": []byte("blackduck"),
+	})
+	defer server.Close()
+
+	cache := NewChartCache(dir, "")
+	for _, name := range []string{"alert-5.3.0.tgz", "blackduck-2020.4.0.tgz"} {
+		_, err := cache.Pull(fmt.Sprintf("%s/charts/%s", server.URL, name), "")
+		assert.Nil(err)
+	}
+	// corrupted charts and unknown files are always removed
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%s-alert-5.3.0.tgz", chartDigest([]byte("alert")))), []byte("corrupted"), 0600))
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "unknown.tgz"), []byte("unknown"), 0600))
+
+	removed, err := cache.Prune(func(cached CachedChart) bool { return false })
+	assert.Nil(err)
+	assert.Equal([]string{fmt.Sprintf("%s/charts/alert-5.3.0.tgz", server.URL), "unknown.tgz"}, removed)
+
+	removed, err = cache.Prune(func(cached CachedChart) bool { return true })
+	assert.Nil(err)
+	assert.Equal([]string{fmt.Sprintf("%s/charts/blackduck-2020.4.0.tgz", server.URL)}, removed)
+
+	list, err := cache.List()
+	assert.Nil(err)
+	assert.Equal(0, len(list))
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(err)
+	assert.Equal(1, len(files))
+}
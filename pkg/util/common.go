@@ -29,10 +29,6 @@ import (
 	"strings"
 	"time"
 
-	alertScheme "github.com/blackducksoftware/synopsysctl/pkg/alert/client/clientset/versioned/scheme"
-	blackduckScheme "github.com/blackducksoftware/synopsysctl/pkg/blackduck/client/clientset/versioned/scheme"
-	opssightScheme "github.com/blackducksoftware/synopsysctl/pkg/opssight/client/clientset/versioned/scheme"
-
 	horizonapi "github.com/blackducksoftware/horizon/pkg/api"
 	"github.com/blackducksoftware/horizon/pkg/components"
 	alertclientset "github.com/blackducksoftware/synopsysctl/pkg/alert/client/clientset/versioned"
@@ -71,6 +67,8 @@ const (
 	NODEPORT = "NODEPORT"
 	// LOADBALANCER denotes to create a LoadBalancer service
 	LOADBALANCER = "LOADBALANCER"
+	// INGRESS denotes to create an Ingress
+	INGRESS = "INGRESS"
 )
 
 // CreateContainer will create the container
@@ -343,7 +341,7 @@ func CreateServiceWithMultiplePort(name string, selectLabel map[string]string, n
 }
 
 // CreateSecretFromFile will create the secret from file
-func CreateSecretFromFile(clientset *kubernetes.Clientset, jsonFile string, namespace string, name string, dataKey string) (*corev1.Secret, error) {
+func CreateSecretFromFile(clientset kubernetes.Interface, jsonFile string, namespace string, name string, dataKey string) (*corev1.Secret, error) {
 	file, err := ioutil.ReadFile(jsonFile)
 	if err != nil {
 		log.Panicf("Unable to read the secret file %s due to error: %v\n", jsonFile, err)
@@ -359,7 +357,7 @@ func CreateSecretFromFile(clientset *kubernetes.Clientset, jsonFile string, name
 }
 
 // CreateSecret will create the secret
-func CreateSecret(clientset *kubernetes.Clientset, namespace string, name string, stringData map[string]string) (*corev1.Secret, error) {
+func CreateSecret(clientset kubernetes.Interface, namespace string, name string, stringData map[string]string) (*corev1.Secret, error) {
 	return clientset.CoreV1().Secrets(namespace).Create(&corev1.Secret{
 		Type:       corev1.SecretTypeOpaque,
 		StringData: stringData,
@@ -370,42 +368,42 @@ func CreateSecret(clientset *kubernetes.Clientset, namespace string, name string
 }
 
 // GetSecret will create the secret
-func GetSecret(clientset *kubernetes.Clientset, namespace string, name string) (*corev1.Secret, error) {
+func GetSecret(clientset kubernetes.Interface, namespace string, name string) (*corev1.Secret, error) {
 	return clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListSecrets will list the secret
-func ListSecrets(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.SecretList, error) {
+func ListSecrets(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.SecretList, error) {
 	return clientset.CoreV1().Secrets(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateSecret updates a secret
-func UpdateSecret(clientset *kubernetes.Clientset, namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
+func UpdateSecret(clientset kubernetes.Interface, namespace string, secret *corev1.Secret) (*corev1.Secret, error) {
 	return clientset.CoreV1().Secrets(namespace).Update(secret)
 }
 
 // DeleteSecret will delete the secret
-func DeleteSecret(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteSecret(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
 // GetConfigMap will get the config map
-func GetConfigMap(clientset *kubernetes.Clientset, namespace string, name string) (*corev1.ConfigMap, error) {
+func GetConfigMap(clientset kubernetes.Interface, namespace string, name string) (*corev1.ConfigMap, error) {
 	return clientset.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListConfigMaps will list the config map
-func ListConfigMaps(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ConfigMapList, error) {
+func ListConfigMaps(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ConfigMapList, error) {
 	return clientset.CoreV1().ConfigMaps(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateConfigMap updates a config map
-func UpdateConfigMap(clientset *kubernetes.Clientset, namespace string, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+func UpdateConfigMap(clientset kubernetes.Interface, namespace string, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
 	return clientset.CoreV1().ConfigMaps(namespace).Update(configMap)
 }
 
 // DeleteConfigMap will delete the config map
-func DeleteConfigMap(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteConfigMap(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().ConfigMaps(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -435,7 +433,7 @@ func DeleteConfigMap(clientset *kubernetes.Clientset, namespace string, name str
 // }
 
 // CreateNamespace will create the namespace
-func CreateNamespace(clientset *kubernetes.Clientset, namespace string) (*corev1.Namespace, error) {
+func CreateNamespace(clientset kubernetes.Interface, namespace string) (*corev1.Namespace, error) {
 	return clientset.CoreV1().Namespaces().Create(&corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
@@ -445,42 +443,52 @@ func CreateNamespace(clientset *kubernetes.Clientset, namespace string) (*corev1
 }
 
 // GetNamespace will get the namespace
-func GetNamespace(clientset *kubernetes.Clientset, namespace string) (*corev1.Namespace, error) {
+func GetNamespace(clientset kubernetes.Interface, namespace string) (*corev1.Namespace, error) {
 	return clientset.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
 }
 
 // ListNamespaces will list the namespace
-func ListNamespaces(clientset *kubernetes.Clientset, labelSelector string) (*corev1.NamespaceList, error) {
+func ListNamespaces(clientset kubernetes.Interface, labelSelector string) (*corev1.NamespaceList, error) {
 	return clientset.CoreV1().Namespaces().List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateNamespace updates a namespace
-func UpdateNamespace(clientset *kubernetes.Clientset, namespace *corev1.Namespace) (*corev1.Namespace, error) {
+func UpdateNamespace(clientset kubernetes.Interface, namespace *corev1.Namespace) (*corev1.Namespace, error) {
 	return clientset.CoreV1().Namespaces().Update(namespace)
 }
 
 // DeleteNamespace will delete the namespace
-func DeleteNamespace(clientset *kubernetes.Clientset, namespace string) error {
+func DeleteNamespace(clientset kubernetes.Interface, namespace string) error {
 	return clientset.CoreV1().Namespaces().Delete(namespace, &metav1.DeleteOptions{})
 }
 
 // GetPod will get the input pods corresponding to a namespace
-func GetPod(clientset *kubernetes.Clientset, namespace string, name string) (*corev1.Pod, error) {
+func GetPod(clientset kubernetes.Interface, namespace string, name string) (*corev1.Pod, error) {
 	return clientset.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListPods will get all the pods corresponding to a namespace
-func ListPods(clientset *kubernetes.Clientset, namespace string) (*corev1.PodList, error) {
+func ListPods(clientset kubernetes.Interface, namespace string) (*corev1.PodList, error) {
 	return clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{})
 }
 
 // ListPodsWithLabels will get all the pods corresponding to a namespace and labels
-func ListPodsWithLabels(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.PodList, error) {
+func ListPodsWithLabels(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.PodList, error) {
 	return clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
+// GetPodLogs will get the logs of a container of the pod, if previous is true it gets the logs of the previous terminated container
+func GetPodLogs(clientset kubernetes.Interface, namespace string, name string, containerName string, previous bool) ([]byte, error) {
+	return clientset.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{Container: containerName, Previous: previous}).Do().Raw()
+}
+
+// ListEvents will get all the events corresponding to a namespace
+func ListEvents(clientset kubernetes.Interface, namespace string) (*corev1.EventList, error) {
+	return clientset.CoreV1().Events(namespace).List(metav1.ListOptions{})
+}
+
 // DeletePod will delete the input pods corresponding to a namespace
-func DeletePod(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeletePod(clientset kubernetes.Interface, namespace string, name string) error {
 	propagationPolicy := metav1.DeletePropagationBackground
 	return clientset.CoreV1().Pods(namespace).Delete(name, &metav1.DeleteOptions{
 		PropagationPolicy: &propagationPolicy,
@@ -488,22 +496,22 @@ func DeletePod(clientset *kubernetes.Clientset, namespace string, name string) e
 }
 
 // GetReplicationController will get the replication controller corresponding to a namespace and name
-func GetReplicationController(clientset *kubernetes.Clientset, namespace string, name string) (*corev1.ReplicationController, error) {
+func GetReplicationController(clientset kubernetes.Interface, namespace string, name string) (*corev1.ReplicationController, error) {
 	return clientset.CoreV1().ReplicationControllers(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListReplicationControllers will get the replication controllers corresponding to a namespace
-func ListReplicationControllers(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ReplicationControllerList, error) {
+func ListReplicationControllers(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ReplicationControllerList, error) {
 	return clientset.CoreV1().ReplicationControllers(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateReplicationController updates the replication controller
-func UpdateReplicationController(clientset *kubernetes.Clientset, namespace string, rc *corev1.ReplicationController) (*corev1.ReplicationController, error) {
+func UpdateReplicationController(clientset kubernetes.Interface, namespace string, rc *corev1.ReplicationController) (*corev1.ReplicationController, error) {
 	return clientset.CoreV1().ReplicationControllers(namespace).Update(rc)
 }
 
 // DeleteReplicationController will delete the replication controller corresponding to a namespace and name
-func DeleteReplicationController(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteReplicationController(clientset kubernetes.Interface, namespace string, name string) error {
 	propagationPolicy := metav1.DeletePropagationBackground
 	return clientset.CoreV1().ReplicationControllers(namespace).Delete(name, &metav1.DeleteOptions{
 		PropagationPolicy: &propagationPolicy,
@@ -511,22 +519,32 @@ func DeleteReplicationController(clientset *kubernetes.Clientset, namespace stri
 }
 
 // GetDeployment will get the deployment corresponding to a namespace and name
-func GetDeployment(clientset *kubernetes.Clientset, namespace string, name string) (*appsv1.Deployment, error) {
+func GetDeployment(clientset kubernetes.Interface, namespace string, name string) (*appsv1.Deployment, error) {
 	return clientset.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListDeployments will get all the deployments corresponding to a namespace
-func ListDeployments(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*appsv1.DeploymentList, error) {
+func ListDeployments(clientset kubernetes.Interface, namespace string, labelSelector string) (*appsv1.DeploymentList, error) {
 	return clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateDeployment updates the deployment
-func UpdateDeployment(clientset *kubernetes.Clientset, namespace string, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+func UpdateDeployment(clientset kubernetes.Interface, namespace string, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
 	return clientset.AppsV1().Deployments(namespace).Update(deployment)
 }
 
+// GetStatefulSet will get the stateful set corresponding to a namespace and name
+func GetStatefulSet(clientset kubernetes.Interface, namespace string, name string) (*appsv1.StatefulSet, error) {
+	return clientset.AppsV1().StatefulSets(namespace).Get(name, metav1.GetOptions{})
+}
+
+// UpdateStatefulSet updates the stateful set
+func UpdateStatefulSet(clientset kubernetes.Interface, namespace string, statefulSet *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	return clientset.AppsV1().StatefulSets(namespace).Update(statefulSet)
+}
+
 // DeleteDeployment will delete the deployment corresponding to a namespace and name
-func DeleteDeployment(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteDeployment(clientset kubernetes.Interface, namespace string, name string) error {
 	propagationPolicy := metav1.DeletePropagationBackground
 	return clientset.AppsV1().Deployments(namespace).Delete(name, &metav1.DeleteOptions{
 		PropagationPolicy: &propagationPolicy,
@@ -534,7 +552,7 @@ func DeleteDeployment(clientset *kubernetes.Clientset, namespace string, name st
 }
 
 // CreatePersistentVolume will create the persistent volume
-func CreatePersistentVolume(clientset *kubernetes.Clientset, name string, storageClass string, claimSize string, nfsPath string, nfsServer string) (*corev1.PersistentVolume, error) {
+func CreatePersistentVolume(clientset kubernetes.Interface, name string, storageClass string, claimSize string, nfsPath string, nfsServer string) (*corev1.PersistentVolume, error) {
 	pvQuantity, _ := resource.ParseQuantity(claimSize)
 	return clientset.CoreV1().PersistentVolumes().Create(&corev1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
@@ -556,7 +574,7 @@ func CreatePersistentVolume(clientset *kubernetes.Clientset, name string, storag
 }
 
 // DeletePersistentVolume will delete the persistent volume
-func DeletePersistentVolume(clientset *kubernetes.Clientset, name string) error {
+func DeletePersistentVolume(clientset kubernetes.Interface, name string) error {
 	return clientset.CoreV1().PersistentVolumes().Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -587,7 +605,7 @@ func CreatePersistentVolumeClaim(name string, namespace string, pvcClaimSize str
 }
 
 // ValidateServiceEndpoint will validate whether the service endpoint is ready to serve
-func ValidateServiceEndpoint(clientset *kubernetes.Clientset, namespace string, name string) (*corev1.Endpoints, error) {
+func ValidateServiceEndpoint(clientset kubernetes.Interface, namespace string, name string) (*corev1.Endpoints, error) {
 	var endpoint *corev1.Endpoints
 	var err error
 	for i := 0; i < 20; i++ {
@@ -603,7 +621,7 @@ func ValidateServiceEndpoint(clientset *kubernetes.Clientset, namespace string,
 }
 
 // WaitForServiceEndpointReady will wait for the service endpoint to start the service
-func WaitForServiceEndpointReady(clientset *kubernetes.Clientset, namespace string, name string) error {
+func WaitForServiceEndpointReady(clientset kubernetes.Interface, namespace string, name string) error {
 	endpoint, err := ValidateServiceEndpoint(clientset, namespace, name)
 	if err != nil {
 		return fmt.Errorf("unable to get service endpoint %s in %s because %+v", name, namespace, err)
@@ -630,7 +648,7 @@ func WaitForServiceEndpointReady(clientset *kubernetes.Clientset, namespace stri
 }
 
 // FilterPodByNamePrefixInNamespace will filter the pod based on pod name prefix from a list a pods in a given namespace
-func FilterPodByNamePrefixInNamespace(clientset *kubernetes.Clientset, namespace string, prefix string) (*corev1.Pod, error) {
+func FilterPodByNamePrefixInNamespace(clientset kubernetes.Interface, namespace string, prefix string) (*corev1.Pod, error) {
 	pods, err := ListPods(clientset, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to list the pods in namespace %s due to %+v", namespace, err)
@@ -654,12 +672,12 @@ func FilterPodByNamePrefix(pods *corev1.PodList, prefix string) *corev1.Pod {
 }
 
 // GetService will get the service information for the input service name inside the input namespace
-func GetService(clientset *kubernetes.Clientset, namespace string, serviceName string) (*corev1.Service, error) {
+func GetService(clientset kubernetes.Interface, namespace string, serviceName string) (*corev1.Service, error) {
 	return clientset.CoreV1().Services(namespace).Get(serviceName, metav1.GetOptions{})
 }
 
 // ListServices will list the service information for the input service name inside the input namespace
-func ListServices(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ServiceList, error) {
+func ListServices(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ServiceList, error) {
 	return clientset.CoreV1().Services(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
@@ -691,108 +709,72 @@ func GetKubeService(namespace string, name string, labels map[string]string, sel
 }
 
 // CreateKubeService will create the kubernetes service
-func CreateKubeService(clientset *kubernetes.Clientset, namespace string, service *corev1.Service) (*corev1.Service, error) {
+func CreateKubeService(clientset kubernetes.Interface, namespace string, service *corev1.Service) (*corev1.Service, error) {
 	return clientset.CoreV1().Services(namespace).Create(service)
 }
 
 // UpdateService will update the service information for the input service name inside the input namespace
-func UpdateService(clientset *kubernetes.Clientset, namespace string, service *corev1.Service) (*corev1.Service, error) {
+func UpdateService(clientset kubernetes.Interface, namespace string, service *corev1.Service) (*corev1.Service, error) {
 	return clientset.CoreV1().Services(namespace).Update(service)
 }
 
 // DeleteService will delete the service information for the input service name inside the input namespace
-func DeleteService(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteService(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().Services(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
 // GetServiceEndPoint will get the service endpoint information for the input service name inside the input namespace
-func GetServiceEndPoint(clientset *kubernetes.Clientset, namespace string, serviceName string) (*corev1.Endpoints, error) {
+func GetServiceEndPoint(clientset kubernetes.Interface, namespace string, serviceName string) (*corev1.Endpoints, error) {
 	return clientset.CoreV1().Endpoints(namespace).Get(serviceName, metav1.GetOptions{})
 }
 
 // ListStorageClasses will list all the storageClass in the cluster
-func ListStorageClasses(clientset *kubernetes.Clientset) (*v1beta1.StorageClassList, error) {
+func ListStorageClasses(clientset kubernetes.Interface) (*v1beta1.StorageClassList, error) {
 	return clientset.StorageV1beta1().StorageClasses().List(metav1.ListOptions{})
 }
 
 // GetPVC will get the PVC for the given name
-func GetPVC(clientset *kubernetes.Clientset, namespace string, name string) (*corev1.PersistentVolumeClaim, error) {
+func GetPVC(clientset kubernetes.Interface, namespace string, name string) (*corev1.PersistentVolumeClaim, error) {
 	return clientset.CoreV1().PersistentVolumeClaims(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListPVCs will list the PVC for the given label selector
-func ListPVCs(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.PersistentVolumeClaimList, error) {
+func ListPVCs(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.PersistentVolumeClaimList, error) {
 	return clientset.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdatePVC will update the pvc information for the input pvc name inside the input namespace
-func UpdatePVC(clientset *kubernetes.Clientset, namespace string, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+func UpdatePVC(clientset kubernetes.Interface, namespace string, pvc *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
 	return clientset.CoreV1().PersistentVolumeClaims(namespace).Update(pvc)
 }
 
 // DeletePVC will delete the PVC information for the input pvc name inside the input namespace
-func DeletePVC(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeletePVC(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().PersistentVolumeClaims(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
 // CreateBlackduck will create hub in the cluster
-func CreateBlackduck(blackduckClientset *hubclientset.Clientset, namespace string, createHub *blackduckapi.Blackduck) (*blackduckapi.Blackduck, error) {
-	result := &blackduckapi.Blackduck{}
-	req := blackduckClientset.SynopsysV1().RESTClient().Post().Resource("blackducks").Body(createHub)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func CreateBlackduck(blackduckClientset hubclientset.Interface, namespace string, createHub *blackduckapi.Blackduck) (*blackduckapi.Blackduck, error) {
+	return blackduckClientset.SynopsysV1().Blackducks(namespace).Create(createHub)
 }
 
 // GetBlackduck will get hubs in the cluster
-func GetBlackduck(blackduckClientset *hubclientset.Clientset, namespace string, name string, options metav1.GetOptions) (*blackduckapi.Blackduck, error) {
-	result := &blackduckapi.Blackduck{}
-	req := blackduckClientset.SynopsysV1().RESTClient().Get().Resource("blackducks").Name(name).VersionedParams(&options, blackduckScheme.ParameterCodec)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func GetBlackduck(blackduckClientset hubclientset.Interface, namespace string, name string, options metav1.GetOptions) (*blackduckapi.Blackduck, error) {
+	return blackduckClientset.SynopsysV1().Blackducks(namespace).Get(name, options)
 }
 
 // ListBlackduck gets all blackducks
-func ListBlackduck(blackduckClientset *hubclientset.Clientset, namespace string, opts metav1.ListOptions) (*blackduckapi.BlackduckList, error) {
-	result := &blackduckapi.BlackduckList{}
-	var timeout time.Duration
-	if opts.TimeoutSeconds != nil {
-		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
-	}
-	req := blackduckClientset.SynopsysV1().RESTClient().Get().Resource("blackducks").VersionedParams(&opts, blackduckScheme.ParameterCodec).Timeout(timeout)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func ListBlackduck(blackduckClientset hubclientset.Interface, namespace string, opts metav1.ListOptions) (*blackduckapi.BlackduckList, error) {
+	return blackduckClientset.SynopsysV1().Blackducks(namespace).List(opts)
 }
 
 // UpdateBlackduck will update Blackduck in the cluster
-func UpdateBlackduck(blackduckClientset *hubclientset.Clientset, blackduck *blackduckapi.Blackduck) (*blackduckapi.Blackduck, error) {
-	result := &blackduckapi.Blackduck{}
-	req := blackduckClientset.SynopsysV1().RESTClient().Put().Resource("blackducks").Body(blackduck).Name(blackduck.Name)
-
-	if len(blackduck.Namespace) > 0 {
-		req = req.Namespace(blackduck.Namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func UpdateBlackduck(blackduckClientset hubclientset.Interface, blackduck *blackduckapi.Blackduck) (*blackduckapi.Blackduck, error) {
+	return blackduckClientset.SynopsysV1().Blackducks(blackduck.Namespace).Update(blackduck)
 }
 
 // UpdateBlackducks will update a set of Blackducks in the cluster
-func UpdateBlackducks(clientSet *hubclientset.Clientset, blackduckCRDs []blackduckapi.Blackduck) error {
+func UpdateBlackducks(clientSet hubclientset.Interface, blackduckCRDs []blackduckapi.Blackduck) error {
 	for _, crd := range blackduckCRDs {
 		_, err := UpdateBlackduck(clientSet, &crd)
 		if err != nil {
@@ -803,78 +785,37 @@ func UpdateBlackducks(clientSet *hubclientset.Clientset, blackduckCRDs []blackdu
 }
 
 // DeleteBlackduck will delete Blackduck in the cluster
-func DeleteBlackduck(blackduckClientset *hubclientset.Clientset, name string, namespace string, options *metav1.DeleteOptions) error {
-	req := blackduckClientset.SynopsysV1().RESTClient().Delete().Resource("blackducks").Name(name).Body(options)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-	return req.Do().Error()
+func DeleteBlackduck(blackduckClientset hubclientset.Interface, name string, namespace string, options *metav1.DeleteOptions) error {
+	return blackduckClientset.SynopsysV1().Blackducks(namespace).Delete(name, options)
 }
 
 // CreateOpsSight will create opsSight in the cluster
-func CreateOpsSight(opssightClientset *opssightclientset.Clientset, namespace string, opssight *opssightapi.OpsSight) (*opssightapi.OpsSight, error) {
-	result := &opssightapi.OpsSight{}
-	req := opssightClientset.SynopsysV1().RESTClient().Post().Resource("opssights").Body(opssight)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func CreateOpsSight(opssightClientset opssightclientset.Interface, namespace string, opssight *opssightapi.OpsSight) (*opssightapi.OpsSight, error) {
+	return opssightClientset.SynopsysV1().OpsSights(namespace).Create(opssight)
 }
 
 // ListOpsSights will list all opssights in the cluster
-func ListOpsSights(opssightClientset *opssightclientset.Clientset, namespace string, opts metav1.ListOptions) (*opssightapi.OpsSightList, error) {
-	result := &opssightapi.OpsSightList{}
-	var timeout time.Duration
-	if opts.TimeoutSeconds != nil {
-		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
-	}
-	req := opssightClientset.SynopsysV1().RESTClient().Get().Resource("opssights").VersionedParams(&opts, opssightScheme.ParameterCodec).Timeout(timeout)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func ListOpsSights(opssightClientset opssightclientset.Interface, namespace string, opts metav1.ListOptions) (*opssightapi.OpsSightList, error) {
+	return opssightClientset.SynopsysV1().OpsSights(namespace).List(opts)
 }
 
 // GetOpsSight will get OpsSight in the cluster
-func GetOpsSight(opssightClientset *opssightclientset.Clientset, namespace string, name string, options metav1.GetOptions) (*opssightapi.OpsSight, error) {
-	result := &opssightapi.OpsSight{}
-	req := opssightClientset.SynopsysV1().RESTClient().Get().Resource("opssights").Name(name).VersionedParams(&options, opssightScheme.ParameterCodec)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func GetOpsSight(opssightClientset opssightclientset.Interface, namespace string, name string, options metav1.GetOptions) (*opssightapi.OpsSight, error) {
+	return opssightClientset.SynopsysV1().OpsSights(namespace).Get(name, options)
 }
 
 // GetOpsSights gets all opssights
-func GetOpsSights(clientSet *opssightclientset.Clientset) (*opssightapi.OpsSightList, error) {
+func GetOpsSights(clientSet opssightclientset.Interface) (*opssightapi.OpsSightList, error) {
 	return clientSet.SynopsysV1().OpsSights(metav1.NamespaceAll).List(metav1.ListOptions{})
 }
 
 // UpdateOpsSight will update OpsSight in the cluster
-func UpdateOpsSight(opssightClientset *opssightclientset.Clientset, namespace string, opssight *opssightapi.OpsSight) (*opssightapi.OpsSight, error) {
-	result := &opssightapi.OpsSight{}
-	req := opssightClientset.SynopsysV1().RESTClient().Put().Resource("opssights").Body(opssight).Name(opssight.Name)
-
-	if len(opssight.Namespace) > 0 {
-		req = req.Namespace(opssight.Namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func UpdateOpsSight(opssightClientset opssightclientset.Interface, namespace string, opssight *opssightapi.OpsSight) (*opssightapi.OpsSight, error) {
+	return opssightClientset.SynopsysV1().OpsSights(opssight.Namespace).Update(opssight)
 }
 
 // UpdateOpsSights will update a set of OpsSights in the cluster
-func UpdateOpsSights(clientSet *opssightclientset.Clientset, opsSightCRDs []opssightapi.OpsSight) error {
+func UpdateOpsSights(clientSet opssightclientset.Interface, opsSightCRDs []opssightapi.OpsSight) error {
 	for _, crd := range opsSightCRDs {
 		_, err := UpdateOpsSight(clientSet, crd.Spec.Namespace, &crd)
 		if err != nil {
@@ -885,78 +826,37 @@ func UpdateOpsSights(clientSet *opssightclientset.Clientset, opsSightCRDs []opss
 }
 
 // DeleteOpsSight will delete OpsSight in the cluster
-func DeleteOpsSight(clientSet *opssightclientset.Clientset, name string, namespace string, options *metav1.DeleteOptions) error {
-	req := clientSet.SynopsysV1().RESTClient().Delete().Resource("opssights").Name(name).Body(options)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-	return req.Do().Error()
+func DeleteOpsSight(clientSet opssightclientset.Interface, name string, namespace string, options *metav1.DeleteOptions) error {
+	return clientSet.SynopsysV1().OpsSights(namespace).Delete(name, options)
 }
 
 // CreateAlert will create alert in the cluster
-func CreateAlert(alertClientset *alertclientset.Clientset, namespace string, createAlert *alertapi.Alert) (*alertapi.Alert, error) {
-	result := &alertapi.Alert{}
-	req := alertClientset.SynopsysV1().RESTClient().Post().Resource("alerts").Body(createAlert)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func CreateAlert(alertClientset alertclientset.Interface, namespace string, createAlert *alertapi.Alert) (*alertapi.Alert, error) {
+	return alertClientset.SynopsysV1().Alerts(namespace).Create(createAlert)
 }
 
 // ListAlerts will list all alerts in the cluster
-func ListAlerts(clientSet *alertclientset.Clientset, namespace string, opts metav1.ListOptions) (*alertapi.AlertList, error) {
-	result := &alertapi.AlertList{}
-	var timeout time.Duration
-	if opts.TimeoutSeconds != nil {
-		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
-	}
-	req := clientSet.SynopsysV1().RESTClient().Get().Resource("alerts").VersionedParams(&opts, alertScheme.ParameterCodec).Timeout(timeout)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func ListAlerts(clientSet alertclientset.Interface, namespace string, opts metav1.ListOptions) (*alertapi.AlertList, error) {
+	return clientSet.SynopsysV1().Alerts(namespace).List(opts)
 }
 
 // GetAlert will get Alert in the cluster
-func GetAlert(clientSet *alertclientset.Clientset, namespace string, name string, options metav1.GetOptions) (*alertapi.Alert, error) {
-	result := &alertapi.Alert{}
-	req := clientSet.SynopsysV1().RESTClient().Get().Resource("alerts").Name(name).VersionedParams(&options, alertScheme.ParameterCodec)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func GetAlert(clientSet alertclientset.Interface, namespace string, name string, options metav1.GetOptions) (*alertapi.Alert, error) {
+	return clientSet.SynopsysV1().Alerts(namespace).Get(name, options)
 }
 
 // GetAlerts gets all alerts
-func GetAlerts(clientSet *alertclientset.Clientset) (*alertapi.AlertList, error) {
+func GetAlerts(clientSet alertclientset.Interface) (*alertapi.AlertList, error) {
 	return clientSet.SynopsysV1().Alerts(metav1.NamespaceAll).List(metav1.ListOptions{})
 }
 
 // UpdateAlert will update an Alert in the cluster
-func UpdateAlert(clientSet *alertclientset.Clientset, namespace string, alert *alertapi.Alert) (*alertapi.Alert, error) {
-	result := &alertapi.Alert{}
-	req := clientSet.SynopsysV1().RESTClient().Put().Resource("alerts").Body(alert).Name(alert.Name)
-
-	if len(alert.Namespace) > 0 {
-		req = req.Namespace(alert.Namespace)
-	}
-
-	err := req.Do().Into(result)
-	return result, err
+func UpdateAlert(clientSet alertclientset.Interface, namespace string, alert *alertapi.Alert) (*alertapi.Alert, error) {
+	return clientSet.SynopsysV1().Alerts(alert.Namespace).Update(alert)
 }
 
 // UpdateAlerts will update a set of Alerts in the cluster
-func UpdateAlerts(clientSet *alertclientset.Clientset, alertCRDs []alertapi.Alert) error {
+func UpdateAlerts(clientSet alertclientset.Interface, alertCRDs []alertapi.Alert) error {
 	for _, crd := range alertCRDs {
 		_, err := UpdateAlert(clientSet, crd.Spec.Namespace, &crd)
 		if err != nil {
@@ -967,17 +867,12 @@ func UpdateAlerts(clientSet *alertclientset.Clientset, alertCRDs []alertapi.Aler
 }
 
 // DeleteAlert will delete Alert in the cluster
-func DeleteAlert(clientSet *alertclientset.Clientset, name string, namespace string, options *metav1.DeleteOptions) error {
-	req := clientSet.SynopsysV1().RESTClient().Delete().Resource("alerts").Name(name).Body(options)
-
-	if len(namespace) > 0 {
-		req = req.Namespace(namespace)
-	}
-	return req.Do().Error()
+func DeleteAlert(clientSet alertclientset.Interface, name string, namespace string, options *metav1.DeleteOptions) error {
+	return clientSet.SynopsysV1().Alerts(namespace).Delete(name, options)
 }
 
 // ListHubPV will list all the persistent volumes attached to each hub in the cluster
-func ListHubPV(hubClientset *hubclientset.Clientset, namespace string) (map[string]string, error) {
+func ListHubPV(hubClientset hubclientset.Interface, namespace string) (map[string]string, error) {
 	var pvList map[string]string
 	pvList = make(map[string]string)
 	hubs, err := ListBlackduck(hubClientset, namespace, metav1.ListOptions{})
@@ -1004,22 +899,22 @@ func CreateServiceAccount(namespace string, name string) *components.ServiceAcco
 }
 
 // GetServiceAccount get a service account
-func GetServiceAccount(clientset *kubernetes.Clientset, namespace string, name string) (*corev1.ServiceAccount, error) {
+func GetServiceAccount(clientset kubernetes.Interface, namespace string, name string) (*corev1.ServiceAccount, error) {
 	return clientset.CoreV1().ServiceAccounts(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListServiceAccounts list a service account
-func ListServiceAccounts(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*corev1.ServiceAccountList, error) {
+func ListServiceAccounts(clientset kubernetes.Interface, namespace string, labelSelector string) (*corev1.ServiceAccountList, error) {
 	return clientset.CoreV1().ServiceAccounts(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateServiceAccount updates a service account
-func UpdateServiceAccount(clientset *kubernetes.Clientset, namespace string, serviceAccount *corev1.ServiceAccount) (*corev1.ServiceAccount, error) {
+func UpdateServiceAccount(clientset kubernetes.Interface, namespace string, serviceAccount *corev1.ServiceAccount) (*corev1.ServiceAccount, error) {
 	return clientset.CoreV1().ServiceAccounts(namespace).Update(serviceAccount)
 }
 
 // DeleteServiceAccount delete a service account
-func DeleteServiceAccount(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteServiceAccount(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.CoreV1().ServiceAccounts(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -1045,22 +940,22 @@ func CreateClusterRoleBinding(namespace string, name string, serviceAccountName
 }
 
 // GetClusterRoleBinding get a cluster role
-func GetClusterRoleBinding(clientset *kubernetes.Clientset, name string) (*rbacv1.ClusterRoleBinding, error) {
+func GetClusterRoleBinding(clientset kubernetes.Interface, name string) (*rbacv1.ClusterRoleBinding, error) {
 	return clientset.RbacV1().ClusterRoleBindings().Get(name, metav1.GetOptions{})
 }
 
 // ListClusterRoleBindings list a cluster role binding
-func ListClusterRoleBindings(clientset *kubernetes.Clientset, labelSelector string) (*rbacv1.ClusterRoleBindingList, error) {
+func ListClusterRoleBindings(clientset kubernetes.Interface, labelSelector string) (*rbacv1.ClusterRoleBindingList, error) {
 	return clientset.RbacV1().ClusterRoleBindings().List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateClusterRoleBinding updates the cluster role binding
-func UpdateClusterRoleBinding(clientset *kubernetes.Clientset, clusterRoleBinding *rbacv1.ClusterRoleBinding) (*rbacv1.ClusterRoleBinding, error) {
+func UpdateClusterRoleBinding(clientset kubernetes.Interface, clusterRoleBinding *rbacv1.ClusterRoleBinding) (*rbacv1.ClusterRoleBinding, error) {
 	return clientset.RbacV1().ClusterRoleBindings().Update(clusterRoleBinding)
 }
 
 // DeleteClusterRoleBinding delete a cluster role binding
-func DeleteClusterRoleBinding(clientset *kubernetes.Clientset, name string) error {
+func DeleteClusterRoleBinding(clientset kubernetes.Interface, name string) error {
 	return clientset.RbacV1().ClusterRoleBindings().Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -1103,22 +998,22 @@ func IsSubjectExist(subjects []rbacv1.Subject, namespace string, name string) bo
 }
 
 // GetClusterRole get a cluster role
-func GetClusterRole(clientset *kubernetes.Clientset, name string) (*rbacv1.ClusterRole, error) {
+func GetClusterRole(clientset kubernetes.Interface, name string) (*rbacv1.ClusterRole, error) {
 	return clientset.RbacV1().ClusterRoles().Get(name, metav1.GetOptions{})
 }
 
 // ListClusterRoles list a cluster role
-func ListClusterRoles(clientset *kubernetes.Clientset, labelSelector string) (*rbacv1.ClusterRoleList, error) {
+func ListClusterRoles(clientset kubernetes.Interface, labelSelector string) (*rbacv1.ClusterRoleList, error) {
 	return clientset.RbacV1().ClusterRoles().List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateClusterRole updates the cluster role
-func UpdateClusterRole(clientset *kubernetes.Clientset, clusterRole *rbacv1.ClusterRole) (*rbacv1.ClusterRole, error) {
+func UpdateClusterRole(clientset kubernetes.Interface, clusterRole *rbacv1.ClusterRole) (*rbacv1.ClusterRole, error) {
 	return clientset.RbacV1().ClusterRoles().Update(clusterRole)
 }
 
 // DeleteClusterRole delete a cluster role
-func DeleteClusterRole(clientset *kubernetes.Clientset, name string) error {
+func DeleteClusterRole(clientset kubernetes.Interface, name string) error {
 	return clientset.RbacV1().ClusterRoles().Delete(name, &metav1.DeleteOptions{})
 }
 
@@ -1133,48 +1028,48 @@ func IsClusterRoleRuleExist(oldRules []rbacv1.PolicyRule, newRule rbacv1.PolicyR
 }
 
 // GetRole get a role
-func GetRole(clientset *kubernetes.Clientset, namespace string, name string) (*rbacv1.Role, error) {
+func GetRole(clientset kubernetes.Interface, namespace string, name string) (*rbacv1.Role, error) {
 	return clientset.RbacV1().Roles(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListRoles list a role
-func ListRoles(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*rbacv1.RoleList, error) {
+func ListRoles(clientset kubernetes.Interface, namespace string, labelSelector string) (*rbacv1.RoleList, error) {
 	return clientset.RbacV1().Roles(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateRole updates the role
-func UpdateRole(clientset *kubernetes.Clientset, namespace string, role *rbacv1.Role) (*rbacv1.Role, error) {
+func UpdateRole(clientset kubernetes.Interface, namespace string, role *rbacv1.Role) (*rbacv1.Role, error) {
 	return clientset.RbacV1().Roles(namespace).Update(role)
 }
 
 // DeleteRole delete a role
-func DeleteRole(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteRole(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.RbacV1().Roles(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
 // GetRoleBinding get a role binding
-func GetRoleBinding(clientset *kubernetes.Clientset, namespace string, name string) (*rbacv1.RoleBinding, error) {
+func GetRoleBinding(clientset kubernetes.Interface, namespace string, name string) (*rbacv1.RoleBinding, error) {
 	return clientset.RbacV1().RoleBindings(namespace).Get(name, metav1.GetOptions{})
 }
 
 // ListRoleBindings list a role binding
-func ListRoleBindings(clientset *kubernetes.Clientset, namespace string, labelSelector string) (*rbacv1.RoleBindingList, error) {
+func ListRoleBindings(clientset kubernetes.Interface, namespace string, labelSelector string) (*rbacv1.RoleBindingList, error) {
 	return clientset.RbacV1().RoleBindings(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateRoleBinding updates the role binding
-func UpdateRoleBinding(clientset *kubernetes.Clientset, namespace string, role *rbacv1.RoleBinding) (*rbacv1.RoleBinding, error) {
+func UpdateRoleBinding(clientset kubernetes.Interface, namespace string, role *rbacv1.RoleBinding) (*rbacv1.RoleBinding, error) {
 	return clientset.RbacV1().RoleBindings(namespace).Update(role)
 }
 
 // DeleteRoleBinding delete a role binding
-func DeleteRoleBinding(clientset *kubernetes.Clientset, namespace string, name string) error {
+func DeleteRoleBinding(clientset kubernetes.Interface, namespace string, name string) error {
 	return clientset.RbacV1().RoleBindings(namespace).Delete(name, &metav1.DeleteOptions{})
 }
 
 // GetRouteClient attempts to get a Route Client. It returns nil if it
 // fails due to an error or due to being on kubernetes (doesn't support routes)
-func GetRouteClient(restConfig *rest.Config, clientset *kubernetes.Clientset, namespace string) *routeclient.RouteV1Client {
+func GetRouteClient(restConfig *rest.Config, clientset kubernetes.Interface, namespace string) *routeclient.RouteV1Client {
 	routeClient, err := routeclient.NewForConfig(restConfig)
 	if err != nil {
 		return nil
@@ -1269,7 +1164,7 @@ func UpdateOpenShiftSecurityConstraint(osSecurityClient *securityclient.Security
 }
 
 // EnsureFilterPodsByNamePrefixInNamespaceToZero filters the pods based on the prefix and make sure that it is zero
-func EnsureFilterPodsByNamePrefixInNamespaceToZero(clientset *kubernetes.Clientset, namespace string, prefix string) error {
+func EnsureFilterPodsByNamePrefixInNamespaceToZero(clientset kubernetes.Interface, namespace string, prefix string) error {
 	// timer starts the timer for timeoutInSeconds. If the task doesn't completed, return error
 	timeout := time.NewTimer(120 * time.Second)
 	// ticker starts and execute the task for every n intervals
@@ -1303,7 +1198,7 @@ func EnsureFilterPodsByNamePrefixInNamespaceToZero(clientset *kubernetes.Clients
 }
 
 // filterPodsByNamePrefixInNamespace will filter the pod based on pod name prefix from a list a pods in a given namespace
-func filterPodsByNamePrefixInNamespace(clientset *kubernetes.Clientset, namespace string, prefix string) ([]*corev1.Pod, error) {
+func filterPodsByNamePrefixInNamespace(clientset kubernetes.Interface, namespace string, prefix string) ([]*corev1.Pod, error) {
 	pods, err := ListPods(clientset, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("unable to list the pods in namespace %s due to %+v", namespace, err)
@@ -1328,7 +1223,7 @@ func filterPodsByNamePrefix(pods *corev1.PodList, prefix string) []*corev1.Pod {
 }
 
 // PatchReplicationControllerForReplicas patch a replication controller for replica update
-func PatchReplicationControllerForReplicas(clientset *kubernetes.Clientset, old *corev1.ReplicationController, replicas *int32) (*corev1.ReplicationController, error) {
+func PatchReplicationControllerForReplicas(clientset kubernetes.Interface, old *corev1.ReplicationController, replicas *int32) (*corev1.ReplicationController, error) {
 	oldData, err := json.Marshal(old)
 	if err != nil {
 		return nil, err
@@ -1352,7 +1247,7 @@ func PatchReplicationControllerForReplicas(clientset *kubernetes.Clientset, old
 }
 
 // PatchReplicationController patch a replication controller
-func PatchReplicationController(clientset *kubernetes.Clientset, old corev1.ReplicationController, new corev1.ReplicationController, isUpdateReplica bool) error {
+func PatchReplicationController(clientset kubernetes.Interface, old corev1.ReplicationController, new corev1.ReplicationController, isUpdateReplica bool) error {
 	oldData, err := json.Marshal(old)
 	if err != nil {
 		return err
@@ -1381,7 +1276,7 @@ func PatchReplicationController(clientset *kubernetes.Clientset, old corev1.Repl
 }
 
 // PatchDeploymentForReplicas patch a deployment for replica update
-func PatchDeploymentForReplicas(clientset *kubernetes.Clientset, old *appsv1.Deployment, replicas *int32) (*appsv1.Deployment, error) {
+func PatchDeploymentForReplicas(clientset kubernetes.Interface, old *appsv1.Deployment, replicas *int32) (*appsv1.Deployment, error) {
 	oldData, err := json.Marshal(old)
 	if err != nil {
 		return nil, err
@@ -1404,7 +1299,7 @@ func PatchDeploymentForReplicas(clientset *kubernetes.Clientset, old *appsv1.Dep
 }
 
 // PatchDeployment patch a deployment
-func PatchDeployment(clientset *kubernetes.Clientset, old appsv1.Deployment, new appsv1.Deployment) error {
+func PatchDeployment(clientset kubernetes.Interface, old appsv1.Deployment, new appsv1.Deployment) error {
 	oldData, err := json.Marshal(old)
 	if err != nil {
 		return err
@@ -1435,27 +1330,27 @@ func PatchDeployment(clientset *kubernetes.Clientset, old appsv1.Deployment, new
 }
 
 // GetCustomResourceDefinition get the custom resource defintion
-func GetCustomResourceDefinition(apiExtensionClient *apiextensionsclient.Clientset, name string) (*apiextensions.CustomResourceDefinition, error) {
+func GetCustomResourceDefinition(apiExtensionClient apiextensionsclient.Interface, name string) (*apiextensions.CustomResourceDefinition, error) {
 	return apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
 }
 
 // ListCustomResourceDefinitions list the custom resource defintions
-func ListCustomResourceDefinitions(apiExtensionClient *apiextensionsclient.Clientset, labelSelector string) (*apiextensions.CustomResourceDefinitionList, error) {
+func ListCustomResourceDefinitions(apiExtensionClient apiextensionsclient.Interface, labelSelector string) (*apiextensions.CustomResourceDefinitionList, error) {
 	return apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{LabelSelector: labelSelector})
 }
 
 // UpdateCustomResourceDefinition updates the custom resource defintion
-func UpdateCustomResourceDefinition(apiExtensionClient *apiextensionsclient.Clientset, crd *apiextensions.CustomResourceDefinition) (*apiextensions.CustomResourceDefinition, error) {
+func UpdateCustomResourceDefinition(apiExtensionClient apiextensionsclient.Interface, crd *apiextensions.CustomResourceDefinition) (*apiextensions.CustomResourceDefinition, error) {
 	return apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Update(crd)
 }
 
 // DeleteCustomResourceDefinition deletes the custom resource defintion
-func DeleteCustomResourceDefinition(apiExtensionClient *apiextensionsclient.Clientset, name string) error {
+func DeleteCustomResourceDefinition(apiExtensionClient apiextensionsclient.Interface, name string) error {
 	return apiExtensionClient.ApiextensionsV1beta1().CustomResourceDefinitions().Delete(name, &metav1.DeleteOptions{})
 }
 
 // WaitUntilPodsAreReady will wait for the pods to be ready
-func WaitUntilPodsAreReady(clientset *kubernetes.Clientset, namespace string, labelSelector string, timeoutInSeconds int64) error {
+func WaitUntilPodsAreReady(clientset kubernetes.Interface, namespace string, labelSelector string, timeoutInSeconds int64) error {
 	// timer starts the timer for timeoutInSeconds. If the task doesn't completed, return error
 	timeout := time.NewTimer(time.Duration(timeoutInSeconds) * time.Second)
 	// ticker starts and execute the task for every n intervals
@@ -1471,7 +1366,7 @@ func WaitUntilPodsAreReady(clientset *kubernetes.Clientset, namespace string, la
 			if err != nil {
 				return err
 			}
-			if podsAreReady == false {
+			if podsAreReady == true {
 				return nil
 			}
 			return fmt.Errorf("[NS: %s | Label: %s] the pods weren't ready - timing out after %d seconds", namespace, labelSelector, timeoutInSeconds)
@@ -1489,7 +1384,7 @@ func WaitUntilPodsAreReady(clientset *kubernetes.Clientset, namespace string, la
 }
 
 // ArePodsReady returns whether the pods are ready or not. Returns an error if pods will never become ready.
-func ArePodsReady(clientset *kubernetes.Clientset, namespace string, labelSelector string) (bool, error) {
+func ArePodsReady(clientset kubernetes.Interface, namespace string, labelSelector string) (bool, error) {
 	pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{
 		LabelSelector: labelSelector,
 	})
@@ -1515,7 +1410,7 @@ func ArePodsReady(clientset *kubernetes.Clientset, namespace string, labelSelect
 }
 
 // GetClusterScopeByName returns whether the CRD is cluster scope
-func GetClusterScopeByName(apiExtensionClient *apiextensionsclient.Clientset, name string) bool {
+func GetClusterScopeByName(apiExtensionClient apiextensionsclient.Interface, name string) bool {
 	cr, err := GetCustomResourceDefinition(apiExtensionClient, name)
 	if err == nil && strings.EqualFold("CLUSTER", string(cr.Spec.Scope)) {
 		return true
@@ -1524,7 +1419,7 @@ func GetClusterScopeByName(apiExtensionClient *apiextensionsclient.Clientset, na
 }
 
 // GetClusterScope returns whether any of the CRD is cluster scope
-func GetClusterScope(apiExtensionClient *apiextensionsclient.Clientset) bool {
+func GetClusterScope(apiExtensionClient apiextensionsclient.Interface) bool {
 	crds := []string{AlertCRDName, BlackDuckCRDName, OpsSightCRDName}
 	for _, crd := range crds {
 		cr, err := GetCustomResourceDefinition(apiExtensionClient, crd)
@@ -1539,7 +1434,7 @@ func GetClusterScope(apiExtensionClient *apiextensionsclient.Clientset) bool {
 // the provided namespace. In cluster scoped mode it will return the namespaces of all operators if provided
 // namespace=NamespaceAll. In namespace scoped mode it will return nil if there is no operator in
 // the namespace
-func GetOperatorNamespace(clientset *kubernetes.Clientset, namespace string) ([]string, error) {
+func GetOperatorNamespace(clientset kubernetes.Interface, namespace string) ([]string, error) {
 	namespaces := make(map[string]string, 0)
 	// check if operator is already installed
 	rcs, err := ListReplicationControllers(clientset, namespace, "app=synopsys-operator,component=operator")
@@ -1582,7 +1477,7 @@ func GetOperatorNamespace(clientset *kubernetes.Clientset, namespace string) ([]
 }
 
 // GetOperatorRoles returns the roles or the cluster role of the synopsys operator based on the labels
-func GetOperatorRoles(clientset *kubernetes.Clientset, namespace string) ([]string, []string, error) {
+func GetOperatorRoles(clientset kubernetes.Interface, namespace string) ([]string, []string, error) {
 	clusterRoles := []string{}
 	roles := []string{}
 
@@ -1629,7 +1524,7 @@ func GetOperatorRoles(clientset *kubernetes.Clientset, namespace string) ([]stri
 }
 
 // GetOperatorRoleBindings returns the cluster role bindings of the synopsys operator based on the labels
-func GetOperatorRoleBindings(clientset *kubernetes.Clientset, namespace string) ([]string, []string, error) {
+func GetOperatorRoleBindings(clientset kubernetes.Interface, namespace string) ([]string, []string, error) {
 	clusterRolebindings := []string{}
 	rolebindings := []string{}
 
@@ -1676,7 +1571,7 @@ func GetOperatorRoleBindings(clientset *kubernetes.Clientset, namespace string)
 }
 
 // GetKubernetesVersion will return the kubernetes version
-func GetKubernetesVersion(clientset *kubernetes.Clientset) (string, error) {
+func GetKubernetesVersion(clientset kubernetes.Interface) (string, error) {
 	k, err := clientset.Discovery().ServerVersion()
 	if k != nil {
 		return k.GitVersion, nil
@@ -1685,8 +1580,13 @@ func GetKubernetesVersion(clientset *kubernetes.Clientset) (string, error) {
 }
 
 // IsOpenshift will whether it is an openshift cluster
-func IsOpenshift(clientset *kubernetes.Clientset) bool {
-	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/").Do().Raw()
+func IsOpenshift(clientset kubernetes.Interface) bool {
+	// the discovery client of a fake clientset doesn't have a REST client
+	restClient := clientset.Discovery().RESTClient()
+	if restClient == nil {
+		return false
+	}
+	body, err := restClient.Get().AbsPath("/").Do().Raw()
 	if err != nil {
 		return false
 	}
@@ -1695,7 +1595,7 @@ func IsOpenshift(clientset *kubernetes.Clientset) bool {
 }
 
 // IsOperatorExist returns whether the operator exist or not
-func IsOperatorExist(clientset *kubernetes.Clientset, namespace string) bool {
+func IsOperatorExist(clientset kubernetes.Interface, namespace string) bool {
 	rcs, err := ListReplicationControllers(clientset, namespace, "app=synopsys-operator")
 	if err == nil && len(rcs.Items) > 0 {
 		return true
@@ -1724,7 +1624,7 @@ func isSynopsysResourceExist(namespace, label string) error {
 }
 
 // IsOwnerLabelExistInNamespace check for owner label exist in the namespace
-func IsOwnerLabelExistInNamespace(kubeClient *kubernetes.Clientset, namespace string) bool {
+func IsOwnerLabelExistInNamespace(kubeClient kubernetes.Interface, namespace string) bool {
 	// verify whether the namespace exist
 	ns, err := GetNamespace(kubeClient, namespace)
 	if err != nil {
@@ -1739,7 +1639,7 @@ func IsOwnerLabelExistInNamespace(kubeClient *kubernetes.Clientset, namespace st
 }
 
 // CheckResourceNamespace checks whether namespace is having any resource types
-func CheckResourceNamespace(kubeClient *kubernetes.Clientset, namespace string, label string, isOperator bool) (bool, error) {
+func CheckResourceNamespace(kubeClient kubernetes.Interface, namespace string, label string, isOperator bool) (bool, error) {
 	// verify whether the namespace exist
 	ns, err := GetNamespace(kubeClient, namespace)
 	if err != nil {
@@ -1769,7 +1669,7 @@ func CheckResourceNamespace(kubeClient *kubernetes.Clientset, namespace string,
 }
 
 // DeleteResourceNamespace deletes the namespace if none of the other resource types are running
-func DeleteResourceNamespace(kubeClient *kubernetes.Clientset, resourceName string, namespace string, name string, isOperator bool) error {
+func DeleteResourceNamespace(kubeClient kubernetes.Interface, resourceName string, namespace string, name string, isOperator bool) error {
 	isExist, err := CheckResourceNamespace(kubeClient, namespace, fmt.Sprintf("synopsys.com/%s.%s", resourceName, name), isOperator)
 	if isExist {
 		// if namespace exist and there is no error,
@@ -1791,7 +1691,7 @@ func DeleteResourceNamespace(kubeClient *kubernetes.Clientset, resourceName stri
 }
 
 // CheckAndUpdateNamespace will check whether the namespace is exist and if exist, update the version label in namespace of the updated/deleted resource
-func CheckAndUpdateNamespace(kubeClient *kubernetes.Clientset, resourceName string, namespace string, name string, version string, isDelete bool) (bool, error) {
+func CheckAndUpdateNamespace(kubeClient kubernetes.Interface, resourceName string, namespace string, name string, version string, isDelete bool) (bool, error) {
 	ns, err := GetNamespace(kubeClient, namespace)
 	if err == nil {
 		err = updateLabelsInNamespace(kubeClient, ns, resourceName, namespace, name, version, isDelete)
@@ -1804,7 +1704,7 @@ func CheckAndUpdateNamespace(kubeClient *kubernetes.Clientset, resourceName stri
 }
 
 // updateLabelsInNamespace will update the labels in the namespace
-func updateLabelsInNamespace(kubeClient *kubernetes.Clientset, ns *corev1.Namespace, resourceName string, namespace string, name string, version string, isDelete bool) error {
+func updateLabelsInNamespace(kubeClient kubernetes.Interface, ns *corev1.Namespace, resourceName string, namespace string, name string, version string, isDelete bool) error {
 	isLabelUpdated := false
 	ns.Labels = InitLabels(ns.Labels)
 	if isDelete {
@@ -1843,7 +1743,7 @@ func updateLabelsInNamespace(kubeClient *kubernetes.Clientset, ns *corev1.Namesp
 }
 
 // GetOperatorNamespaceByCRDScope get the operator namespace by CRD scope
-func GetOperatorNamespaceByCRDScope(kubeClient *kubernetes.Clientset, crdName string, scope apiextensions.ResourceScope, namespace string) (string, error) {
+func GetOperatorNamespaceByCRDScope(kubeClient kubernetes.Interface, crdName string, scope apiextensions.ResourceScope, namespace string) (string, error) {
 	operatorNamespace := namespace
 	if scope == apiextensions.ClusterScoped {
 		operatorNamespace = metav1.NamespaceAll
@@ -1873,7 +1773,7 @@ func GetOperatorNamespaceByCRDScope(kubeClient *kubernetes.Clientset, crdName st
 }
 
 // GetCRDNamesFromConfigMap get CRD names from the Synopsys Operator config map
-func GetCRDNamesFromConfigMap(kubeClient *kubernetes.Clientset, namespace string) (string, error) {
+func GetCRDNamesFromConfigMap(kubeClient kubernetes.Interface, namespace string) (string, error) {
 	cm, err := GetConfigMap(kubeClient, namespace, "synopsys-operator")
 	if err != nil {
 		return "", fmt.Errorf("error getting the Synopsys Operator config map due to %+v", err)
@@ -0,0 +1,73 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/describe"
+	"k8s.io/kubectl/pkg/describe/versioned"
+)
+
+// ObjectKey identifies an object in a namespace by its kind and name
+type ObjectKey struct {
+	Kind string
+	Name string
+}
+
+// GetPodObjectKeys returns the keys of a pod, of its owners, e.g. the ReplicaSet of a Deployment, and of its persistent
+// volume claims, e.g. the claims created from the volume claim templates of a StatefulSet
+func GetPodObjectKeys(pod corev1.Pod) []ObjectKey {
+	keys := []ObjectKey{{Kind: "Pod", Name: pod.Name}}
+	for _, owner := range pod.OwnerReferences {
+		keys = append(keys, ObjectKey{Kind: owner.Kind, Name: owner.Name})
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			keys = append(keys, ObjectKey{Kind: "PersistentVolumeClaim", Name: volume.PersistentVolumeClaim.ClaimName})
+		}
+	}
+	return keys
+}
+
+// FilterEvents returns the events whose involved object is one of the objects
+func FilterEvents(events []corev1.Event, objects map[ObjectKey]bool) []corev1.Event {
+	filteredEvents := []corev1.Event{}
+	for _, event := range events {
+		if objects[ObjectKey{Kind: event.InvolvedObject.Kind, Name: event.InvolvedObject.Name}] {
+			filteredEvents = append(filteredEvents, event)
+		}
+	}
+	return filteredEvents
+}
+
+// DescribeObject returns the kubectl describe output of an object in a namespace without its events. It returns false if
+// the kind has no describer. The describer of the secrets only prints the size of their data
+func DescribeObject(restConfig *rest.Config, namespace string, apiVersion string, kind string, name string) (string, bool, error) {
+	describer, ok := versioned.DescriberFor(schema.FromAPIVersionAndKind(apiVersion, kind).GroupKind(), restConfig)
+	if !ok {
+		return "", false, nil
+	}
+	out, err := describer.Describe(namespace, name, describe.DescriberSettings{ShowEvents: false})
+	return out, true, err
+}
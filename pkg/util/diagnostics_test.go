@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetPodObjectKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "bd-blackduck-webserver-5d9f-x2x4z",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "bd-blackduck-webserver-5d9f"}},
+		},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+			{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "bd-blackduck-webserver"}}},
+		}},
+	}
+	assert.Equal([]ObjectKey{
+		{Kind: "Pod", Name: "bd-blackduck-webserver-5d9f-x2x4z"},
+		{Kind: "ReplicaSet", Name: "bd-blackduck-webserver-5d9f"},
+		{Kind: "PersistentVolumeClaim", Name: "bd-blackduck-webserver"},
+	}, GetPodObjectKeys(pod))
+}
+
+func TestFilterEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	event := func(name string, kind string, objectName string) corev1.Event {
+		return corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: name}, InvolvedObject: corev1.ObjectReference{Kind: kind, Name: objectName}}
+	}
+	events := []corev1.Event{
+		event("a", "Deployment", "bd-blackduck-webserver"),
+		event("b", "Deployment", "other-webserver"),
+		event("c", "Pod", "bd-blackduck-webserver-5d9f-x2x4z"),
+		event("d", "Service", "bd-blackduck-webserver-5d9f-x2x4z"),
+	}
+	objects := map[ObjectKey]bool{
+		{Kind: "Deployment", Name: "bd-blackduck-webserver"}:     true,
+		{Kind: "Pod", Name: "bd-blackduck-webserver-5d9f-x2x4z"}: true,
+	}
+	assert.Equal([]corev1.Event{events[0], events[2]}, FilterEvents(events, objects))
+	assert.Equal([]corev1.Event{}, FilterEvents(events, map[ObjectKey]bool{}))
+}
+
+func TestDescribeObject(t *testing.T) {
+	assert := assert.New(t)
+
+	objects := map[string]interface{}{
+		"/api/v1/namespaces/bd/secrets/bd-blackduck-webserver-certificate": &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-blackduck-webserver-certificate", Namespace: "bd"},
+			Data:       map[string][]byte{"WEBSERVER_CUSTOM_KEY_FILE": []byte("private key")},
+		},
+		"/api/v1/namespaces/bd/configmaps/bd-blackduck-config": &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "bd-blackduck-config", Namespace: "bd"},
+			Data:       map[string]string{"HUB_VERSION": "2020.6.0"},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		object, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(&metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound})
+			return
+		}
+		json.NewEncoder(w).Encode(object)
+	}))
+	defer server.Close()
+	restConfig := &rest.Config{Host: server.URL}
+
+	// the secrets are described without their data
+	out, ok, err := DescribeObject(restConfig, "bd", "v1", "Secret", "bd-blackduck-webserver-certificate")
+	assert.Nil(err)
+	assert.True(ok)
+	assert.Contains(out, "bd-blackduck-webserver-certificate")
+	assert.Contains(out, "WEBSERVER_CUSTOM_KEY_FILE:  11 bytes")
+	assert.NotContains(out, "private key")
+
+	out, ok, err = DescribeObject(restConfig, "bd", "v1", "ConfigMap", "bd-blackduck-config")
+	assert.Nil(err)
+	assert.True(ok)
+	assert.Contains(out, "2020.6.0")
+
+	_, ok, err = DescribeObject(restConfig, "bd", "v1", "ConfigMap", "missing")
+	assert.NotNil(err)
+	assert.True(ok)
+
+	// the kinds without a describer are skipped
+	_, ok, err = DescribeObject(restConfig, "bd", "cert-manager.io/v1", "Certificate", "bd-blackduck-webserver")
+	assert.Nil(err)
+	assert.False(ok)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ANSI colors of the diff lines
+const (
+	diffColorReset  = "\x1b[0m"
+	diffColorRed    = "\x1b[31m"
+	diffColorGreen  = "\x1b[32m"
+	diffColorCyan   = "\x1b[36m"
+	diffColorYellow = "\x1b[33m"
+)
+
+// HelmValueChange is a Helm value that is different between two sets of Helm values.
+// The Key is the path of the value joined with '.', a nil OldValue means the value was added and a nil NewValue means it was removed
+type HelmValueChange struct {
+	Key      string
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// FlattenHelmValues returns the leaf values of the Helm values by their path joined with '.'
+// The values are converted to json first so that numbers of different types can be compared
+func FlattenHelmValues(vals map[string]interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	normalizedVals := make(map[string]interface{})
+	if err := json.Unmarshal(b, &normalizedVals); err != nil {
+		return nil, err
+	}
+	flatVals := make(map[string]interface{})
+	flattenHelmValue(flatVals, "", normalizedVals)
+	return flatVals, nil
+}
+
+func flattenHelmValue(flatVals map[string]interface{}, prefix string, value interface{}) {
+	valueMap, ok := value.(map[string]interface{})
+	if !ok || (len(valueMap) == 0 && len(prefix) > 0) {
+		flatVals[prefix] = value
+		return
+	}
+	for key, val := range valueMap {
+		if len(prefix) > 0 {
+			key = fmt.Sprintf("%s.%s", prefix, key)
+		}
+		flattenHelmValue(flatVals, key, val)
+	}
+}
+
+// DiffHelmValues returns the Helm values that were added, removed or changed between the old and the new values sorted by key
+func DiffHelmValues(oldVals map[string]interface{}, newVals map[string]interface{}) ([]HelmValueChange, error) {
+	oldFlatVals, err := FlattenHelmValues(oldVals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the old values: %+v", err)
+	}
+	newFlatVals, err := FlattenHelmValues(newVals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the new values: %+v", err)
+	}
+	changes := []HelmValueChange{}
+	for key, oldValue := range oldFlatVals {
+		newValue, ok := newFlatVals[key]
+		if !ok {
+			changes = append(changes, HelmValueChange{Key: key, OldValue: oldValue})
+		} else if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, HelmValueChange{Key: key, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for key, newValue := range newFlatVals {
+		if _, ok := oldFlatVals[key]; !ok {
+			changes = append(changes, HelmValueChange{Key: key, NewValue: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
+
+// DiffManifests returns a unified diff per Kubernetes object between the old and the new manifests, the lines are colored if color is true
+func DiffManifests(oldManifest string, newManifest string, color bool) (string, error) {
+	oldObjects, err := getManifestObjects(oldManifest)
+	if err != nil {
+		return "", err
+	}
+	newObjects, err := getManifestObjects(newManifest)
+	if err != nil {
+		return "", err
+	}
+	keys := []string{}
+	for key := range oldObjects {
+		keys = append(keys, key)
+	}
+	for key := range newObjects {
+		if _, ok := oldObjects[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var out bytes.Buffer
+	for _, key := range keys {
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(oldObjects[key]),
+			B:        difflib.SplitLines(newObjects[key]),
+			FromFile: fmt.Sprintf("deployed %s", key),
+			ToFile:   fmt.Sprintf("updated %s", key),
+			Context:  3,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to diff %s: %+v", key, err)
+		}
+		if len(diff) == 0 {
+			continue
+		}
+		if !color {
+			out.WriteString(diff)
+			continue
+		}
+		for _, line := range difflib.SplitLines(diff) {
+			switch {
+			case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+				out.WriteString(diffColorYellow + strings.TrimSuffix(line, "\n") + diffColorReset + "\n")
+			case strings.HasPrefix(line, "@@"):
+				out.WriteString(diffColorCyan + strings.TrimSuffix(line, "\n") + diffColorReset + "\n")
+			case strings.HasPrefix(line, "-"):
+				out.WriteString(diffColorRed + strings.TrimSuffix(line, "\n") + diffColorReset + "\n")
+			case strings.HasPrefix(line, "+"):
+				out.WriteString(diffColorGreen + strings.TrimSuffix(line, "\n") + diffColorReset + "\n")
+			default:
+				out.WriteString(line)
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+// getManifestObjects returns the yaml of each Kubernetes object in the manifest by 'Kind/name'
+func getManifestObjects(manifest string) (map[string]string, error) {
+	objects := make(map[string]string)
+	for _, m := range releaseutil.SplitManifests(manifest) {
+		object := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(m), &object); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %+v", err)
+		}
+		if _, ok := object["kind"]; !ok {
+			continue
+		}
+		// marshal the object again so that both manifests have the same key order and indentation
+		b, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the manifest to yaml: %+v", err)
+		}
+		objects[fmt.Sprintf("%v/%v", object["kind"], GetHelmValueFromMap(object, []string{"metadata", "name"}))] = string(b)
+	}
+	return objects, nil
+}
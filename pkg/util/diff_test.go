@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffHelmValues(t *testing.T) {
+	oldVals := map[string]interface{}{
+		"size":     "small",
+		"sealKey":  "abc",
+		"postgres": map[string]interface{}{"claimSize": "150Gi", "port": float64(5432)},
+	}
+	newVals := map[string]interface{}{
+		"size":     "medium",
+		"postgres": map[string]interface{}{"claimSize": "150Gi", "port": 5432},
+		"environs": map[string]interface{}{"HUB_MAX_MEMORY": "4096m"},
+	}
+	changes, err := DiffHelmValues(oldVals, newVals)
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Equal([]HelmValueChange{
+		{Key: "environs.HUB_MAX_MEMORY", NewValue: "4096m"},
+		{Key: "sealKey", OldValue: "abc"},
+		{Key: "size", OldValue: "small", NewValue: "medium"},
+	}, changes)
+}
+
+func TestDiffManifests(t *testing.T) {
+	oldManifest := `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bd-blackduck-config
+data:
+  HUB_VERSION: 2020.4.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-webserver
+`
+	newManifest := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-webserver
+---
+apiVersion: v1
+data:
+  HUB_VERSION: 2020.6.0
+kind: ConfigMap
+metadata:
+  name: bd-blackduck-config
+`
+	diff, err := DiffManifests(oldManifest, newManifest, false)
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.Contains(diff, "--- deployed ConfigMap/bd-blackduck-config")
+	assert.Contains(diff, "+++ updated ConfigMap/bd-blackduck-config")
+	assert.Contains(diff, "-  HUB_VERSION: 2020.4.0")
+	assert.Contains(diff, "+  HUB_VERSION: 2020.6.0")
+	assert.NotContains(diff, "Service/bd-blackduck-webserver")
+
+	coloredDiff, err := DiffManifests(oldManifest, newManifest, true)
+	assert.Nil(err)
+	assert.True(strings.Contains(coloredDiff, diffColorGreen+"+  HUB_VERSION: 2020.6.0"+diffColorReset))
+}
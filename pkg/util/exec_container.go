@@ -23,6 +23,7 @@ package util
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"strings"
 
@@ -35,7 +36,7 @@ import (
 )
 
 // CreateExecContainerRequest will create the request to exec into Kubernetes pod
-func CreateExecContainerRequest(clientset *kubernetes.Clientset, pod *corev1.Pod, command string) *rest.Request {
+func CreateExecContainerRequest(clientset kubernetes.Interface, pod *corev1.Pod, command ...string) *rest.Request {
 	return clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(pod.Name).
@@ -44,7 +45,7 @@ func CreateExecContainerRequest(clientset *kubernetes.Clientset, pod *corev1.Pod
 		Param("container", pod.Spec.Containers[0].Name).
 		VersionedParams(&corev1.PodExecOptions{
 			Container: pod.Spec.Containers[0].Name,
-			Command:   []string{command},
+			Command:   command,
 			Stdin:     true,
 			Stdout:    true,
 			Stderr:    true,
@@ -54,25 +55,35 @@ func CreateExecContainerRequest(clientset *kubernetes.Clientset, pod *corev1.Pod
 
 // ExecContainer will exec into the container and run the commands provided in the input
 func ExecContainer(kubeConfig *rest.Config, request *rest.Request, command []string) (string, error) {
-	var stdin io.Reader
-	stdin = NewStringReader(command)
-
 	log.Debugf("Request URL: %+v, request: %+v, command: %s", request.URL().String(), request, strings.Join(command, ""))
+	return ExecContainerWithStdin(kubeConfig, request, NewStringReader(command))
+}
+
+// ExecContainerWithStdin will exec into the container and stream the stdin reader to the command of the request
+func ExecContainerWithStdin(kubeConfig *rest.Config, request *rest.Request, stdin io.Reader) (string, error) {
+	var stdout bytes.Buffer
+	err := ExecContainerWithStreams(kubeConfig, request, stdin, &stdout)
+	return stdout.String(), err
+}
 
+// ExecContainerWithStreams will exec into the container, stream the stdin reader to the command of the request and
+// stream its output to the stdout writer. The output isn't logged because it can contain the data of the instance
+func ExecContainerWithStreams(kubeConfig *rest.Config, request *rest.Request, stdin io.Reader, stdout io.Writer) error {
 	exec, err := remotecommand.NewSPDYExecutor(kubeConfig, "POST", request.URL())
 	log.Debugf("exec: %+v, error: %+v", exec, err)
 	if err != nil {
-		log.Errorf("error while creating Executor: %v", err)
+		return fmt.Errorf("error while creating Executor: %v", err)
 	}
 
-	var stdout, stderr bytes.Buffer
+	var stderr bytes.Buffer
 	err = exec.Stream(remotecommand.StreamOptions{
 		Stdin:  stdin,
-		Stdout: &stdout,
+		Stdout: stdout,
 		Stderr: &stderr,
 		Tty:    false,
 	})
-
-	log.Debugf("stdout: %s, stderr: %s", stdout.String(), stderr.String())
-	return stdout.String(), err
+	if err != nil && stderr.Len() > 0 {
+		return fmt.Errorf("%+v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return err
 }
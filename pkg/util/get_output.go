@@ -0,0 +1,232 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// GetInstance is an instance of a Synopsys product shown by the get commands
+type GetInstance struct {
+	InventoryInstance
+	URL      string `json:"url"`
+	Revision int    `json:"revision,omitempty"`
+	Updated  string `json:"updated,omitempty"`
+	// Values are the Helm values of the release, or the spec of the custom resource
+	Values interface{} `json:"values,omitempty"`
+}
+
+// GetInstanceList is the output of a get command that lists instances
+type GetInstanceList struct {
+	Items []*GetInstance `json:"items"`
+}
+
+// getColumns are the columns of the default table output of the get commands
+var getColumns = []string{"NAME", "NAMESPACE", "VERSION", "STATE", "URL"}
+
+// getWideColumns are the additional columns of the wide output of the get commands
+var getWideColumns = []string{"SIZE", "EXPOSE", "DEPLOYMENT", "REVISION", "UPDATED"}
+
+// ValidateGetOutputFormat returns an error if the output format isn't one of table, wide, json, yaml, jsonpath=TEMPLATE or go-template=TEMPLATE
+func ValidateGetOutputFormat(format string) error {
+	switch {
+	case format == "table", format == "wide", format == "json", format == "yaml":
+		return nil
+	case strings.HasPrefix(format, "jsonpath="):
+		return jsonpath.New("get").Parse(strings.TrimPrefix(format, "jsonpath="))
+	case strings.HasPrefix(format, "go-template="):
+		_, err := template.New("get").Parse(strings.TrimPrefix(format, "go-template="))
+		return err
+	}
+	// the get commands used to run kubectl, whose other output formats aren't supported since they print the instances themselves
+	if format == "name" || strings.HasPrefix(format, "custom-columns") || strings.HasPrefix(format, "jsonpath-file=") || strings.HasPrefix(format, "go-template-file=") {
+		return fmt.Errorf("the '%s' output format is no longer supported, use jsonpath=... or go-template=... instead", format)
+	}
+	return fmt.Errorf("'%s' is an invalid output format, must be one of [table|wide|json|yaml|jsonpath=...|go-template=...]", format)
+}
+
+// PrintGetInstances prints the instances in the output format. A single instance that was requested by name is printed as an object,
+// otherwise the instances are printed as a list with an items field
+func PrintGetInstances(out io.Writer, instances []*GetInstance, format string, single bool) error {
+	if err := ValidateGetOutputFormat(format); err != nil {
+		return err
+	}
+	if format == "table" || format == "wide" {
+		printGetInstancesTable(out, instances, format == "wide")
+		return nil
+	}
+
+	var v interface{} = &GetInstanceList{Items: instances}
+	if single && len(instances) == 1 {
+		v = instances[0]
+	}
+	switch {
+	case format == "json":
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to convert the instances to json due to %+v", err)
+		}
+		fmt.Fprintln(out, string(b))
+	case format == "yaml":
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to convert the instances to yaml due to %+v", err)
+		}
+		fmt.Fprint(out, string(b))
+	default:
+		// templates are executed on the json representation so that they use the same field names as the json output
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to convert the instances to json due to %+v", err)
+		}
+		var data interface{}
+		if err := json.Unmarshal(b, &data); err != nil {
+			return fmt.Errorf("failed to convert the instances to json due to %+v", err)
+		}
+		if strings.HasPrefix(format, "jsonpath=") {
+			j := jsonpath.New("get").AllowMissingKeys(true)
+			if err := j.Parse(strings.TrimPrefix(format, "jsonpath=")); err != nil {
+				return err
+			}
+			return j.Execute(out, data)
+		}
+		t, err := template.New("get").Parse(strings.TrimPrefix(format, "go-template="))
+		if err != nil {
+			return err
+		}
+		return t.Execute(out, data)
+	}
+	return nil
+}
+
+// printGetInstancesTable prints the instances as a table, with the additional columns if wide is true
+func printGetInstancesTable(out io.Writer, instances []*GetInstance, wide bool) {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	columns := getColumns
+	if wide {
+		columns = append(append([]string{}, getColumns...), getWideColumns...)
+	}
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+	for _, instance := range instances {
+		row := []string{instance.Name, instance.Namespace, instance.Version, instance.State, instance.URL}
+		if wide {
+			revision := ""
+			if instance.Revision > 0 {
+				revision = strconv.Itoa(instance.Revision)
+			}
+			row = append(row, instance.Size, instance.ExposeType, instance.Deployment, revision, instance.Updated)
+		}
+		for i := range row {
+			if len(row[i]) == 0 {
+				row[i] = "<none>"
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// GetIngressURL returns the URL of the first host of an ingress, or of its load balancer if the rule has no host
+func GetIngressURL(ingress *networkingv1beta1.Ingress) string {
+	if len(ingress.Spec.Rules) == 0 {
+		return ""
+	}
+	host := ingress.Spec.Rules[0].Host
+	if len(host) == 0 {
+		for _, lbIngress := range ingress.Status.LoadBalancer.Ingress {
+			host = lbIngress.IP
+			if len(lbIngress.Hostname) > 0 {
+				host = lbIngress.Hostname
+			}
+			break
+		}
+	}
+	if len(host) == 0 {
+		return ""
+	}
+	scheme := "http"
+	if len(ingress.Spec.TLS) > 0 {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
+
+// GetRouteURL returns the URL of an OpenShift route
+func GetRouteURL(route *routev1.Route) string {
+	if len(route.Spec.Host) == 0 {
+		return ""
+	}
+	scheme := "http"
+	if route.Spec.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, route.Spec.Host, route.Spec.Path)
+}
+
+// GetServiceURL returns the URL of the first port of a LoadBalancer service, or of a NodePort service on the node address
+func GetServiceURL(service *corev1.Service, nodeAddress string) string {
+	if len(service.Spec.Ports) == 0 {
+		return ""
+	}
+	port := service.Spec.Ports[0]
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, lbIngress := range service.Status.LoadBalancer.Ingress {
+			host := lbIngress.IP
+			if len(lbIngress.Hostname) > 0 {
+				host = lbIngress.Hostname
+			}
+			return fmt.Sprintf("https://%s:%d", host, port.Port)
+		}
+	case corev1.ServiceTypeNodePort:
+		if len(nodeAddress) > 0 && port.NodePort > 0 {
+			return fmt.Sprintf("https://%s:%d", nodeAddress, port.NodePort)
+		}
+	}
+	return ""
+}
+
+// GetNodeAddress returns the external IP of the node, or its internal IP if it has none
+func GetNodeAddress(node *corev1.Node) string {
+	address := ""
+	for _, nodeAddress := range node.Status.Addresses {
+		switch nodeAddress.Type {
+		case corev1.NodeExternalIP:
+			return nodeAddress.Address
+		case corev1.NodeInternalIP:
+			address = nodeAddress.Address
+		}
+	}
+	return address
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+)
+
+func getTestGetInstances() []*GetInstance {
+	return []*GetInstance{
+		{
+			InventoryInstance: InventoryInstance{Product: BlackDuckName, Name: "bd", Namespace: "ns1", Version: "2020.4.0", Size: "small", ExposeType: "LOADBALANCER", Deployment: InventoryDeploymentHelm, State: "deployed"},
+			URL:               "https://10.0.0.1:443",
+			Revision:          2,
+			Values:            map[string]interface{}{"size": "small"},
+		},
+		{
+			InventoryInstance: InventoryInstance{Product: BlackDuckName, Name: "bd2", Namespace: "ns2", Version: "2020.4.0", Deployment: InventoryDeploymentHelm, State: "Stopped"},
+		},
+	}
+}
+
+func TestValidateGetOutputFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, format := range []string{"table", "wide", "json", "yaml", "jsonpath={.items[*].name}", "go-template={{.name}}"} {
+		assert.Nil(ValidateGetOutputFormat(format), format)
+	}
+	for _, format := range []string{"", "xml", "jsonpath={.items[", "go-template={{.name"} {
+		assert.NotNil(ValidateGetOutputFormat(format), format)
+	}
+	for _, format := range []string{"name", "custom-columns=NAME:.metadata.name", "custom-columns-file=columns.txt", "jsonpath-file=path.txt", "go-template-file=template.txt"} {
+		assert.Contains(ValidateGetOutputFormat(format).Error(), "no longer supported", format)
+	}
+}
+
+func TestPrintGetInstances(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.Nil(PrintGetInstances(&buf, getTestGetInstances(), "table", false))
+	expected := "NAME   NAMESPACE   VERSION    STATE      URL\n" +
+		"bd     ns1         2020.4.0   deployed   https://10.0.0.1:443\n" +
+		"bd2    ns2         2020.4.0   Stopped    <none>\n"
+	assert.Equal(expected, buf.String())
+
+	buf.Reset()
+	assert.Nil(PrintGetInstances(&buf, getTestGetInstances(), "wide", false))
+	assert.Contains(buf.String(), "SIZE     EXPOSE         DEPLOYMENT   REVISION   UPDATED")
+	assert.Contains(buf.String(), "small    LOADBALANCER   Helm         2          <none>")
+
+	buf.Reset()
+	assert.Nil(PrintGetInstances(&buf, getTestGetInstances(), "jsonpath={.items[*].name}", false))
+	assert.Equal("bd bd2", buf.String())
+
+	buf.Reset()
+	assert.Nil(PrintGetInstances(&buf, getTestGetInstances()[:1], "go-template={{.url}} {{.values.size}}", true))
+	assert.Equal("https://10.0.0.1:443 small", buf.String())
+
+	buf.Reset()
+	assert.Nil(PrintGetInstances(&buf, getTestGetInstances()[1:], "yaml", true))
+	assert.Equal("deployment: Helm\nexposeType: \"\"\nname: bd2\nnamespace: ns2\nproduct: blackduck\nsize: \"\"\nstate: Stopped\nurl: \"\"\nversion: 2020.4.0\n", buf.String())
+
+	assert.NotNil(PrintGetInstances(&buf, getTestGetInstances(), "xml", false))
+}
+
+func TestGetIngressURL(t *testing.T) {
+	assert := assert.New(t)
+
+	ingress := GetKubeIngress("ns", "bd", nil, IngressConfig{Host: "bd.example.com", TLSSecretName: "tls"}, "bd-webserver", 443)
+	assert.Equal("https://bd.example.com", GetIngressURL(ingress))
+
+	ingress = GetKubeIngress("ns", "bd", nil, IngressConfig{}, "bd-webserver", 443)
+	assert.Equal("", GetIngressURL(ingress))
+	ingress.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.2"}}
+	assert.Equal("http://10.0.0.2", GetIngressURL(ingress))
+
+	assert.Equal("", GetIngressURL(&networkingv1beta1.Ingress{}))
+}
+
+func TestGetServiceURL(t *testing.T) {
+	assert := assert.New(t)
+
+	service := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer, Ports: []corev1.ServicePort{{Port: 443, NodePort: 31443}}}}
+	assert.Equal("", GetServiceURL(service, ""))
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}}
+	assert.Equal("https://lb.example.com:443", GetServiceURL(service, ""))
+
+	service.Spec.Type = corev1.ServiceTypeNodePort
+	assert.Equal("https://192.168.0.1:31443", GetServiceURL(service, "192.168.0.1"))
+
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	assert.Equal("", GetServiceURL(service, "192.168.0.1"))
+}
@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -39,6 +40,7 @@ import (
 	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
@@ -49,22 +51,27 @@ import (
 var settings = cli.New()
 
 // CreateWithHelm3 uses the helm NewInstall action to create a resource in the cluster
+func CreateWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, kubeConfig string, dryRun bool, extraFiles ...string) error {
+	return newDefaultHelmClient(kubeConfig).Create(releaseName, namespace, chartURL, vals, dryRun, extraFiles...)
+}
+
+// Create uses the helm NewInstall action to create a resource in the cluster
 // Modified from https://github.com/openshift/console/blob/cdf6b189b71e488033ecaba7d90258d9f9453478/pkg/helm/actions/install_chart.go
 // Helm Actions: https://github.com/helm/helm/tree/9bc7934f350233fa72a11d2d29065aa78ab62792/pkg/action
-func CreateWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, kubeConfig string, dryRun bool, extraFiles ...string) error {
+func (c *HelmClient) Create(releaseName, namespace, chartURL string, vals map[string]interface{}, dryRun bool, extraFiles ...string) error {
 	// Check if resouce already exists
-	existingRelease, _ := GetWithHelm3(releaseName, namespace, kubeConfig)
+	existingRelease, _ := c.Get(releaseName, namespace)
 	if existingRelease != nil {
 		return fmt.Errorf("release '%+v' already exists in namespace '%+v'", existingRelease.Name, existingRelease.Namespace)
 	}
 
 	// Create the new Release
-	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
+	actionConfig, err := c.actionConfiguration(namespace)
 	if err != nil {
 		return err
 	}
 
-	chart, err := LoadChart(chartURL, actionConfig)
+	chart, err := c.loadChart(chartURL, actionConfig)
 	if err != nil {
 		return err
 	}
@@ -111,25 +118,34 @@ func CreateWithHelm3(releaseName, namespace, chartURL string, vals map[string]in
 	if err := mergeExtraFilesToConfig(chart, vals, extraFiles); err != nil {
 		return err
 	}
+	RemoveAutoscaledReplicasHelmValues(chart.Values, vals)
 
-	_, err = client.Run(chart, vals) // deploy the chart into the namespace from the actionConfig
+	helmRelease, err := client.Run(chart, vals) // deploy the chart into the namespace from the actionConfig
 	if err != nil {
 		return fmt.Errorf("failed to run install: %+v", err)
 	}
-	return nil
+	if dryRun {
+		return nil
+	}
+	return c.applySimulatedRelease(namespace, nil, helmRelease)
 }
 
 // UpdateWithHelm3 uses the helm NewUpgrade action to update a resource in the cluster
 func UpdateWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, kubeConfig string, extraFiles ...string) error {
-	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
+	return newDefaultHelmClient(kubeConfig).Update(releaseName, namespace, chartURL, vals, extraFiles...)
+}
+
+// Update uses the helm NewUpgrade action to update a resource in the cluster
+func (c *HelmClient) Update(releaseName, namespace, chartURL string, vals map[string]interface{}, extraFiles ...string) error {
+	actionConfig, err := c.actionConfiguration(namespace)
 	if err != nil {
 		return err
 	}
-	if releaseExists := ReleaseExists(releaseName, namespace, kubeConfig); !releaseExists {
+	if releaseExists := c.ReleaseExists(releaseName, namespace); !releaseExists {
 		return fmt.Errorf("release '%s' does not exist", releaseName)
 	}
 
-	chart, err := LoadChart(chartURL, actionConfig)
+	chart, err := c.loadChart(chartURL, actionConfig)
 	if err != nil {
 		return fmt.Errorf("failed to load release at '%s' for updating: %s", chartURL, err)
 	}
@@ -147,13 +163,43 @@ func UpdateWithHelm3(releaseName, namespace, chartURL string, vals map[string]in
 	if err := mergeExtraFilesToConfig(chart, vals, extraFiles); err != nil {
 		return err
 	}
+	RemoveAutoscaledReplicasHelmValues(chart.Values, vals)
 
-	client.ResetValues = true                     // rememeber the values that have been set previously
-	_, err = client.Run(releaseName, chart, vals) // updates the release in the namespace from the actionConfig
+	previousRelease := c.getSimulatedRelease(releaseName, namespace)
+	client.ResetValues = true                                // rememeber the values that have been set previously
+	helmRelease, err := client.Run(releaseName, chart, vals) // updates the release in the namespace from the actionConfig
 	if err != nil {
 		return fmt.Errorf("failed to run upgrade: %+v", err)
 	}
-	return nil
+	return c.applySimulatedRelease(namespace, previousRelease, helmRelease)
+}
+
+// UpdateValuesWithHelm3 uses the helm NewUpgrade action to update the values of a release with the chart it was installed with
+func UpdateValuesWithHelm3(releaseName, namespace string, vals map[string]interface{}, kubeConfig string) error {
+	return newDefaultHelmClient(kubeConfig).UpdateValues(releaseName, namespace, vals)
+}
+
+// UpdateValues uses the helm NewUpgrade action to update the values of a release with the chart it was installed with
+func (c *HelmClient) UpdateValues(releaseName, namespace string, vals map[string]interface{}) error {
+	actionConfig, err := c.actionConfiguration(namespace)
+	if err != nil {
+		return err
+	}
+	helmRelease, err := action.NewGet(actionConfig).Run(releaseName)
+	if err != nil {
+		return fmt.Errorf("release '%s' does not exist: %+v", releaseName, err)
+	}
+
+	RemoveAutoscaledReplicasHelmValues(helmRelease.Chart.Values, vals)
+
+	client := action.NewUpgrade(actionConfig)
+	client.Namespace = namespace
+	client.ResetValues = true
+	updatedRelease, err := client.Run(releaseName, helmRelease.Chart, vals)
+	if err != nil {
+		return fmt.Errorf("failed to run upgrade: %+v", err)
+	}
+	return c.applySimulatedRelease(namespace, helmRelease, updatedRelease)
 }
 
 // TemplateWithHelm3 prints the kube manifest files for a resource
@@ -256,27 +302,108 @@ func RenderManifests(releaseName, namespace string, chart *chart.Chart, vals map
 	return output.String(), nil
 }
 
+// RenderedRelease is a release rendered with RenderWithHelm3
+type RenderedRelease struct {
+	// Manifest is the rendered manifest of the release
+	Manifest string
+	// Values are the values merged with the extra files
+	Values map[string]interface{}
+	// ExtraFileValues are the values from the extra files only
+	ExtraFileValues map[string]interface{}
+}
+
+// RenderWithHelm3 renders the manifest of a release with the values merged with the extra files of the chart
+func RenderWithHelm3(releaseName, namespace, chartURL string, vals map[string]interface{}, kubeConfig string, extraFiles ...string) (*RenderedRelease, error) {
+	return newDefaultHelmClient(kubeConfig).Render(releaseName, namespace, chartURL, vals, extraFiles...)
+}
+
+// Render renders the manifest of a release with the values merged with the extra files of the chart
+func (c *HelmClient) Render(releaseName, namespace, chartURL string, vals map[string]interface{}, extraFiles ...string) (*RenderedRelease, error) {
+	actionConfig, err := c.actionConfiguration(namespace)
+	if err != nil {
+		return nil, err
+	}
+	chart, err := c.loadChart(chartURL, actionConfig)
+	if err != nil {
+		return nil, err
+	}
+	validInstallableChart, err := isChartInstallable(chart)
+	if !validInstallableChart {
+		return nil, err
+	}
+
+	rendered := &RenderedRelease{
+		Values:          CopyHelmValues(vals),
+		ExtraFileValues: make(map[string]interface{}),
+	}
+	if err := mergeExtraFilesToConfig(chart, rendered.ExtraFileValues, extraFiles); err != nil {
+		return nil, err
+	}
+	if err := mergeExtraFilesToConfig(chart, rendered.Values, extraFiles); err != nil {
+		return nil, err
+	}
+	rendered.Manifest, err = RenderManifests(releaseName, namespace, chart, rendered.Values, actionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render kube manifest files: %s", err)
+	}
+	return rendered, nil
+}
+
 // DeleteWithHelm3 uses the helm NewUninstall action to delete a resource from the cluster
 func DeleteWithHelm3(releaseName, namespace, kubeConfig string) error {
-	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
+	return newDefaultHelmClient(kubeConfig).Delete(releaseName, namespace)
+}
+
+// Delete uses the helm NewUninstall action to delete a resource from the cluster
+func (c *HelmClient) Delete(releaseName, namespace string) error {
+	actionConfig, err := c.actionConfiguration(namespace)
 	if err != nil {
 		return err
 	}
-	if releaseExists := ReleaseExists(releaseName, namespace, kubeConfig); !releaseExists {
+	if releaseExists := c.ReleaseExists(releaseName, namespace); !releaseExists {
 		return fmt.Errorf("release '%s' does not exist", releaseName)
 	}
 	client := action.NewUninstall(actionConfig)
-	_, err = client.Run(releaseName) // deletes the releaseName from the namespace in the actionConfig
+	response, err := client.Run(releaseName) // deletes the releaseName from the namespace in the actionConfig
 	if err != nil {
 		return fmt.Errorf("failed to run uninstall: %+v", err)
 	}
-	return nil
+	return c.applySimulatedRelease(namespace, response.Release, nil)
+}
+
+// RollbackWithHelm3 rolls back a release to the specified revision, if the revision is 0 it rolls back to the previous revision
+func RollbackWithHelm3(releaseName, namespace, kubeConfig string, revision int) error {
+	return newDefaultHelmClient(kubeConfig).Rollback(releaseName, namespace, revision)
+}
+
+// Rollback rolls back a release to the specified revision, if the revision is 0 it rolls back to the previous revision
+func (c *HelmClient) Rollback(releaseName, namespace string, revision int) error {
+	actionConfig, err := c.actionConfiguration(namespace)
+	if err != nil {
+		return err
+	}
+	if releaseExists := c.ReleaseExists(releaseName, namespace); !releaseExists {
+		return fmt.Errorf("release '%s' does not exist", releaseName)
+	}
+	previousRelease := c.getSimulatedRelease(releaseName, namespace)
+	client := action.NewRollback(actionConfig)
+	client.Version = revision
+	if err := client.Run(releaseName); err != nil { // rolls back the releaseName in the namespace from the actionConfig
+		return fmt.Errorf("failed to run rollback: %+v", err)
+	}
+	return c.applySimulatedRelease(namespace, previousRelease, c.getSimulatedRelease(releaseName, namespace))
 }
 
 // GetWithHelm3 uses the helm NewGet action to return a Release with information about
 // a resource from the cluster
 func GetWithHelm3(releaseName, namespace, kubeConfig string) (*release.Release, error) {
-	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
+	return newDefaultHelmClient(kubeConfig).Get(releaseName, namespace)
+}
+
+// Get uses the helm NewGet action to return a Release with information about
+// a resource from the cluster
+func (c *HelmClient) Get(releaseName, namespace string) (*release.Release, error) {
+	actionConfig, err := c.actionConfiguration(namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -294,11 +421,279 @@ func GetWithHelm3(releaseName, namespace, kubeConfig string) (*release.Release,
 	return nil, fmt.Errorf("unable to find instance '%+v' in namespace %+v", releaseName, namespace)
 }
 
+// ListWithHelm3 uses the helm NewList action to return the releases in the namespace
+func ListWithHelm3(namespace, kubeConfig string) ([]*release.Release, error) {
+	return newDefaultHelmClient(kubeConfig).List(namespace)
+}
+
+// List uses the helm NewList action to return the releases in the namespace
+func (c *HelmClient) List(namespace string) ([]*release.Release, error) {
+	actionConfig, err := c.actionConfiguration(namespace)
+	if err != nil {
+		return nil, err
+	}
+	aList := action.NewList(actionConfig)
+	releases, err := aList.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run list: %+v", err)
+	}
+	namespaceReleases := []*release.Release{}
+	for _, release := range releases {
+		if release.Namespace == namespace {
+			namespaceReleases = append(namespaceReleases, release)
+		}
+	}
+	return namespaceReleases, nil
+}
+
+// ListAllNamespacesWithHelm3 uses the helm NewList action to return the releases in all namespaces
+func ListAllNamespacesWithHelm3(kubeConfig string) ([]*release.Release, error) {
+	return newDefaultHelmClient(kubeConfig).ListAllNamespaces()
+}
+
+// ListAllNamespaces uses the helm NewList action to return the releases in all namespaces
+func (c *HelmClient) ListAllNamespaces() ([]*release.Release, error) {
+	actionConfig, err := c.actionConfiguration("")
+	if err != nil {
+		return nil, err
+	}
+	aList := action.NewList(actionConfig)
+	aList.AllNamespaces = true
+	releases, err := aList.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run list: %+v", err)
+	}
+	return releases, nil
+}
+
+// GetManifestResources returns the Kubernetes resources of a rendered manifest sorted by kind and name
+func GetManifestResources(manifest string) ([]map[string]interface{}, error) {
+	resources := []map[string]interface{}{}
+	for _, m := range releaseutil.SplitManifests(manifest) {
+		resource := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(m), &resource); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %+v", err)
+		}
+		if _, ok := resource["kind"]; !ok {
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		kindI, kindJ := fmt.Sprintf("%v", resources[i]["kind"]), fmt.Sprintf("%v", resources[j]["kind"])
+		if kindI != kindJ {
+			return kindI < kindJ
+		}
+		return fmt.Sprintf("%v", GetHelmValueFromMap(resources[i], []string{"metadata", "name"})) < fmt.Sprintf("%v", GetHelmValueFromMap(resources[j], []string{"metadata", "name"}))
+	})
+	return resources, nil
+}
+
+// GetHelmReleaseVersion returns the version of the product of a release. It is the image tag in the values of the
+// release, e.g. imageTag for Black Duck or alert.imageTag for Alert, and the app version of the chart otherwise
+func GetHelmReleaseVersion(helmRelease *release.Release) string {
+	for _, keyList := range [][]string{{"imageTag"}, {"alert", "imageTag"}} {
+		if version, ok := GetHelmValueFromMap(helmRelease.Config, keyList).(string); ok && len(version) > 0 {
+			return version
+		}
+		if helmRelease.Chart == nil {
+			continue
+		}
+		if version, ok := GetHelmValueFromMap(helmRelease.Chart.Values, keyList).(string); ok && len(version) > 0 {
+			return version
+		}
+	}
+	if helmRelease.Chart == nil || helmRelease.Chart.Metadata == nil {
+		return ""
+	}
+	if len(helmRelease.Chart.Metadata.AppVersion) > 0 {
+		return helmRelease.Chart.Metadata.AppVersion
+	}
+	return helmRelease.Chart.Metadata.Version
+}
+
+// GetWorkloadLabelSelector returns the label selector of the pods of a Deployment, StatefulSet or ReplicationController from a manifest
+func GetWorkloadLabelSelector(resource map[string]interface{}) string {
+	selector := GetHelmValueFromMap(resource, []string{"spec", "selector", "matchLabels"})
+	if resource["kind"] == "ReplicationController" {
+		selector = GetHelmValueFromMap(resource, []string{"spec", "selector"})
+	}
+	selectorMap, ok := selector.(map[string]interface{})
+	if !ok || len(selectorMap) == 0 {
+		return ""
+	}
+	labelSet := labels.Set{}
+	for key, value := range selectorMap {
+		labelSet[key] = fmt.Sprintf("%v", value)
+	}
+	return labels.SelectorFromSet(labelSet).String()
+}
+
+// RedactedValue replaces the sensitive values in the Helm values and manifests
+const RedactedValue = "<redacted>"
+
+// RedactHelmValues returns a copy of the Helm values where the values at the keyLists are replaced with RedactedValue
+func RedactHelmValues(vals map[string]interface{}, keyLists [][]string) map[string]interface{} {
+	redactedVals := CopyHelmValues(vals)
+	for _, keyList := range keyLists {
+		if GetHelmValueFromMap(redactedVals, keyList) != nil {
+			SetHelmValueInMap(redactedVals, keyList, RedactedValue)
+		}
+	}
+	return redactedVals
+}
+
+// CopyHelmValues returns a deep copy of the Helm values
+func CopyHelmValues(vals map[string]interface{}) map[string]interface{} {
+	return copyHelmValue(vals).(map[string]interface{})
+}
+
+// copyHelmValue returns a deep copy of a Helm value
+func copyHelmValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		valueCopy := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			valueCopy[key] = copyHelmValue(val)
+		}
+		return valueCopy
+	case []interface{}:
+		valueCopy := make([]interface{}, len(v))
+		for i, val := range v {
+			valueCopy[i] = copyHelmValue(val)
+		}
+		return valueCopy
+	default:
+		return v
+	}
+}
+
+// RedactManifestSecrets returns the manifest where the data of the secrets is replaced with RedactedValue
+func RedactManifestSecrets(manifest string) (string, error) {
+	splitManifests := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(splitManifests))
+	for key := range splitManifests {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var redactedManifest bytes.Buffer
+	for _, key := range keys {
+		m := splitManifests[key]
+		resource := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(m), &resource); err != nil {
+			return "", fmt.Errorf("failed to parse manifest: %+v", err)
+		}
+		if resource["kind"] == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := resource[field].(map[string]interface{}); ok {
+					for dataKey := range data {
+						data[dataKey] = RedactedValue
+					}
+				}
+			}
+			b, err := yaml.Marshal(resource)
+			if err != nil {
+				return "", fmt.Errorf("failed to convert secret to yaml: %+v", err)
+			}
+			m = string(b)
+		}
+		fmt.Fprintf(&redactedManifest, "---\n%s\n", strings.TrimSpace(m))
+	}
+	return redactedManifest.String(), nil
+}
+
+// KubeContext is the context of the kubeconfig that the Helm actions use. If it is empty, the current context is used
+var KubeContext string
+
+// HelmClient runs the Helm actions in the cluster of a context of a kubeconfig. Unlike the package functions it doesn't
+// depend on KubeContext, so clients for different clusters can be used at the same time
+type HelmClient struct {
+	// KubeConfig is the path of the kubeconfig, the default loading rules are used if it is empty
+	KubeConfig string
+	// KubeContext is the context of the kubeconfig, the current context is used if it is empty
+	KubeContext string
+	// InsecureSkipTLSVerify disables the verification of the certificate of the API server
+	InsecureSkipTLSVerify bool
+	// ChartCacheDirectory is the directory that the charts are cached in, the charts aren't cached if it is empty
+	ChartCacheDirectory string
+	// ChartKeyring is the keyring that the cached charts are verified with, the charts aren't verified if it is empty
+	ChartKeyring string
+	// Simulation is the simulated cluster that the Helm actions use instead of the cluster of the kubeconfig, if it is set
+	Simulation *SimulatedCluster
+}
+
+// NewHelmClient returns a HelmClient for the context of the kubeconfig
+func NewHelmClient(kubeConfig, kubeContext string) *HelmClient {
+	return &HelmClient{KubeConfig: kubeConfig, KubeContext: kubeContext}
+}
+
+// newDefaultHelmClient returns the HelmClient of the package functions, which runs in KubeContext or Simulation
+func newDefaultHelmClient(kubeConfig string) *HelmClient {
+	return &HelmClient{
+		KubeConfig:          kubeConfig,
+		KubeContext:         KubeContext,
+		ChartCacheDirectory: ChartCacheDirectory,
+		ChartKeyring:        ChartKeyring,
+		Simulation:          Simulation,
+	}
+}
+
+// actionConfiguration creates an action.Configuration that points to the namespace in the cluster of the client
+func (c *HelmClient) actionConfiguration(namespace string) (*action.Configuration, error) {
+	if c.Simulation != nil {
+		return c.Simulation.HelmActionConfiguration(namespace), nil
+	}
+	return createHelmActionConfiguration(c.KubeConfig, c.KubeContext, namespace, c.InsecureSkipTLSVerify)
+}
+
+// loadChart returns a chart from the specified chartURL through the chart cache of the client
+func (c *HelmClient) loadChart(chartURL string, actionConfig *action.Configuration) (*chart.Chart, error) {
+	return loadChart(chartURL, actionConfig, c.ChartCacheDirectory, c.ChartKeyring)
+}
+
+// getSimulatedRelease returns the deployed revision of a release in the simulated cluster of the client, or nil if the
+// client doesn't use a simulated cluster
+func (c *HelmClient) getSimulatedRelease(releaseName, namespace string) *release.Release {
+	if c.Simulation == nil {
+		return nil
+	}
+	helmRelease, err := action.NewGet(c.Simulation.HelmActionConfiguration(namespace)).Run(releaseName)
+	if err != nil {
+		return nil
+	}
+	return helmRelease
+}
+
+// applySimulatedRelease creates the objects of the current revision of a release in the simulated cluster of the client
+// and deletes the objects that were only in the previous revision
+func (c *HelmClient) applySimulatedRelease(namespace string, previousRelease, currentRelease *release.Release) error {
+	if c.Simulation == nil {
+		return nil
+	}
+	previousManifest, manifest := "", ""
+	if previousRelease != nil {
+		previousManifest = previousRelease.Manifest
+	}
+	if currentRelease != nil {
+		manifest = currentRelease.Manifest
+	}
+	return c.Simulation.ApplyReleaseManifests(namespace, previousManifest, manifest)
+}
+
 // CreateHelmActionConfiguration creates an action.Configuration that points to the specified cluster and namespace
 func CreateHelmActionConfiguration(kubeConfig, kubeContext, namespace string) (*action.Configuration, error) {
+	return createHelmActionConfiguration(kubeConfig, kubeContext, namespace, false)
+}
+
+// createHelmActionConfiguration creates an action.Configuration that points to the specified cluster and namespace and
+// optionally skips the verification of the certificate of the API server
+func createHelmActionConfiguration(kubeConfig, kubeContext, namespace string, insecureSkipTLSVerify bool) (*action.Configuration, error) {
 	// TODO: look into using GetActionConfigurations()
+	configFlags := kube.GetConfig(kubeConfig, kubeContext, namespace)
+	configFlags.Insecure = &insecureSkipTLSVerify
 	actionConfig := new(action.Configuration)
-	if err := actionConfig.Init(kube.GetConfig(kubeConfig, kubeContext, namespace), namespace, "secret", func(format string, v ...interface{}) {}); err != nil {
+	if err := actionConfig.Init(configFlags, namespace, "secret", func(format string, v ...interface{}) {}); err != nil {
 		return nil, err
 	}
 	return actionConfig, nil
@@ -337,6 +732,19 @@ func GetActionConfigurations(host, namespace, token string, transport *http.Roun
 // LoadChart returns a chart from the specified chartURL
 // Modified from https://github.com/openshift/console/blob/master/pkg/helm/actions/template_test.go
 func LoadChart(chartURL string, actionConfig *action.Configuration) (*chart.Chart, error) {
+	return loadChart(chartURL, actionConfig, ChartCacheDirectory, ChartKeyring)
+}
+
+// loadChart returns a chart from the specified chartURL, which is resolved through the chart cache in cacheDirectory
+// if it is set
+func loadChart(chartURL string, actionConfig *action.Configuration, cacheDirectory, keyring string) (*chart.Chart, error) {
+	if len(cacheDirectory) > 0 {
+		cachedChartPath, err := NewChartCache(cacheDirectory, keyring).Resolve(chartURL)
+		if err != nil {
+			return nil, err
+		}
+		chartURL = cachedChartPath
+	}
 	client := action.NewInstall(actionConfig)
 
 	// Get full path - checks local machine and chart repository
@@ -365,7 +773,12 @@ func isChartInstallable(ch *chart.Chart) (bool, error) {
 
 // ReleaseExists verifies that a resources is deployed in the cluster
 func ReleaseExists(releaseName, namespace, kubeConfig string) bool {
-	actionConfig, err := CreateHelmActionConfiguration(kubeConfig, "", namespace)
+	return newDefaultHelmClient(kubeConfig).ReleaseExists(releaseName, namespace)
+}
+
+// ReleaseExists verifies that a resources is deployed in the cluster
+func (c *HelmClient) ReleaseExists(releaseName, namespace string) bool {
+	actionConfig, err := c.actionConfiguration(namespace)
 	if err != nil {
 		return false
 	}
@@ -26,6 +26,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
 )
 
 func TestSetHelmValueInMap(t *testing.T) {
@@ -223,3 +225,117 @@ func TestGetHelmValueFromMap(t *testing.T) {
 		assert.Equal(tt.expectedValue, receivedValue, fmt.Sprintf("failed case: %s\nGot: %+v\nWanted: %+v", tt.testDesc, receivedValue, tt.expectedValue))
 	}
 }
+
+func TestGetManifestResources(t *testing.T) {
+	manifest := `---
+# Source: blackduck/templates/webserver.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: bd-blackduck-webserver
+---
+# Source: blackduck/templates/empty.yaml
+---
+# Source: blackduck/templates/postgres.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-postgres
+---
+# Source: blackduck/templates/authentication.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: bd-blackduck-authentication
+`
+	resources, err := GetManifestResources(manifest)
+	assert := assert.New(t)
+	assert.Nil(err)
+	names := []string{}
+	for _, resource := range resources {
+		names = append(names, fmt.Sprintf("%v/%v", resource["kind"], GetHelmValueFromMap(resource, []string{"metadata", "name"})))
+	}
+	assert.Equal([]string{"Deployment/bd-blackduck-authentication", "Deployment/bd-blackduck-postgres", "Service/bd-blackduck-webserver"}, names)
+}
+
+func TestRedactHelmValues(t *testing.T) {
+	vals := map[string]interface{}{
+		"sealKey": "abc",
+		"postgres": map[string]interface{}{
+			"adminPassword": "secret",
+			"adminUserName": "postgres",
+		},
+	}
+	redactedVals := RedactHelmValues(vals, [][]string{{"sealKey"}, {"postgres", "adminPassword"}, {"postgres", "userPassword"}})
+	assert := assert.New(t)
+	assert.Equal(map[string]interface{}{
+		"sealKey": RedactedValue,
+		"postgres": map[string]interface{}{
+			"adminPassword": RedactedValue,
+			"adminUserName": "postgres",
+		},
+	}, redactedVals)
+	// the original values must not be modified
+	assert.Equal("abc", vals["sealKey"])
+	assert.Equal("secret", GetHelmValueFromMap(vals, []string{"postgres", "adminPassword"}))
+}
+
+func TestRedactManifestSecrets(t *testing.T) {
+	manifest := `---
+# Source: blackduck/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bd-blackduck-db-creds
+data:
+  HUB_POSTGRES_ADMIN_PASSWORD_FILE: YmxhY2tkdWNr
+---
+# Source: blackduck/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bd-blackduck-config
+data:
+  HUB_VERSION: 2020.4.0
+`
+	redactedManifest, err := RedactManifestSecrets(manifest)
+	assert := assert.New(t)
+	assert.Nil(err)
+	assert.NotContains(redactedManifest, "YmxhY2tkdWNr")
+	assert.Contains(redactedManifest, "HUB_POSTGRES_ADMIN_PASSWORD_FILE: <redacted>")
+	assert.Contains(redactedManifest, "HUB_VERSION: 2020.4.0")
+}
+
+func TestGetWorkloadLabelSelector(t *testing.T) {
+	assert := assert.New(t)
+
+	deployment := map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "blackduck", "component": "webapp"}}},
+	}
+	assert.Equal("app=blackduck,component=webapp", GetWorkloadLabelSelector(deployment))
+
+	replicationController := map[string]interface{}{
+		"kind": "ReplicationController",
+		"spec": map[string]interface{}{"selector": map[string]interface{}{"app": "alert"}},
+	}
+	assert.Equal("app=alert", GetWorkloadLabelSelector(replicationController))
+
+	assert.Equal("", GetWorkloadLabelSelector(map[string]interface{}{"kind": "Deployment"}))
+}
+
+func TestGetHelmReleaseVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	blackDuckChart := &chart.Chart{Metadata: &chart.Metadata{Version: "2020.6.1"}, Values: map[string]interface{}{"imageTag": "2020.6.0"}}
+	assert.Equal("2020.6.0", GetHelmReleaseVersion(&release.Release{Chart: blackDuckChart}))
+	assert.Equal("2020.4.2", GetHelmReleaseVersion(&release.Release{Chart: blackDuckChart, Config: map[string]interface{}{"imageTag": "2020.4.2"}}))
+
+	alertChart := &chart.Chart{Metadata: &chart.Metadata{Version: "6.0.1"}, Values: map[string]interface{}{"alert": map[string]interface{}{"imageTag": "6.0.0"}}}
+	assert.Equal("6.0.0", GetHelmReleaseVersion(&release.Release{Chart: alertChart}))
+
+	bdbaChart := &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0", AppVersion: "2020.06"}}
+	assert.Equal("2020.06", GetHelmReleaseVersion(&release.Release{Chart: bdbaChart}))
+	assert.Equal("1.0.0", GetHelmReleaseVersion(&release.Release{Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0"}}}))
+	assert.Equal("", GetHelmReleaseVersion(&release.Release{}))
+}
@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// IngressClassAnnotation is the annotation that selects the ingress controller of an ingress
+const IngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// NginxBackendProtocolAnnotation is the annotation that makes the nginx ingress controller use HTTPS to the backend
+const NginxBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+
+// IngressConfig is the configuration of an ingress that exposes a service
+type IngressConfig struct {
+	Host          string
+	Class         string
+	TLSSecretName string
+	Annotations   map[string]string
+}
+
+// GetIngressConfigFromHelmValues returns the ingress configuration stored in the Helm values at the path
+func GetIngressConfigFromHelmValues(helmValues map[string]interface{}, keys ...string) IngressConfig {
+	config := IngressConfig{Annotations: map[string]string{}}
+	values, ok := GetHelmValueFromMap(helmValues, keys).(map[string]interface{})
+	if !ok {
+		return config
+	}
+	config.Host, _ = values["host"].(string)
+	config.Class, _ = values["class"].(string)
+	config.TLSSecretName, _ = values["tlsSecretName"].(string)
+	if annotations, ok := values["annotations"].(map[string]interface{}); ok {
+		for k, v := range annotations {
+			if value, ok := v.(string); ok {
+				config.Annotations[k] = value
+			}
+		}
+	}
+	return config
+}
+
+// GetKubeIngress returns an ingress that routes the host to the HTTPS port of the service
+func GetKubeIngress(namespace string, name string, labels map[string]string, config IngressConfig, serviceName string, servicePort int32) *networkingv1beta1.Ingress {
+	annotations := map[string]string{}
+	if len(config.Class) > 0 {
+		annotations[IngressClassAnnotation] = config.Class
+		if config.Class == "nginx" {
+			annotations[NginxBackendProtocolAnnotation] = "HTTPS"
+		}
+	}
+	for k, v := range config.Annotations {
+		annotations[k] = v
+	}
+
+	ingress := &networkingv1beta1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{
+				{
+					Host: config.Host,
+					IngressRuleValue: networkingv1beta1.IngressRuleValue{
+						HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+							Paths: []networkingv1beta1.HTTPIngressPath{
+								{
+									Path: "/",
+									Backend: networkingv1beta1.IngressBackend{
+										ServiceName: serviceName,
+										ServicePort: intstr.FromInt(int(servicePort)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if len(config.TLSSecretName) > 0 {
+		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{{Hosts: []string{config.Host}, SecretName: config.TLSSecretName}}
+	}
+	return ingress
+}
+
+// GetIngress will get the ingress
+func GetIngress(clientset kubernetes.Interface, namespace string, name string) (*networkingv1beta1.Ingress, error) {
+	return clientset.NetworkingV1beta1().Ingresses(namespace).Get(name, metav1.GetOptions{})
+}
+
+// CreateIngress will create the ingress
+func CreateIngress(clientset kubernetes.Interface, namespace string, ingress *networkingv1beta1.Ingress) (*networkingv1beta1.Ingress, error) {
+	return clientset.NetworkingV1beta1().Ingresses(namespace).Create(ingress)
+}
+
+// UpdateIngress will update the ingress
+func UpdateIngress(clientset kubernetes.Interface, namespace string, ingress *networkingv1beta1.Ingress) (*networkingv1beta1.Ingress, error) {
+	return clientset.NetworkingV1beta1().Ingresses(namespace).Update(ingress)
+}
+
+// DeleteIngress will delete the ingress
+func DeleteIngress(clientset kubernetes.Interface, namespace string, name string) error {
+	return clientset.NetworkingV1beta1().Ingresses(namespace).Delete(name, &metav1.DeleteOptions{})
+}
+
+// CreateOrUpdateIngress creates the ingress, or updates its annotations and spec if it already exists
+func CreateOrUpdateIngress(clientset kubernetes.Interface, namespace string, ingress *networkingv1beta1.Ingress) (*networkingv1beta1.Ingress, error) {
+	existing, err := GetIngress(clientset, namespace, ingress.Name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return CreateIngress(clientset, namespace, ingress)
+		}
+		return nil, err
+	}
+	existing.Labels = ingress.Labels
+	existing.Annotations = ingress.Annotations
+	existing.Spec = ingress.Spec
+	return UpdateIngress(clientset, namespace, existing)
+}
+
+// DeleteIngressIfExists deletes the ingress if it exists
+func DeleteIngressIfExists(clientset kubernetes.Interface, namespace string, name string) error {
+	if err := DeleteIngress(clientset, namespace, name); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIngressConfigFromHelmValues(t *testing.T) {
+	helmValues := map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"host":          "bd.example.com",
+			"class":         "nginx",
+			"tlsSecretName": "bd-tls",
+			"annotations": map[string]interface{}{
+				"nginx.ingress.kubernetes.io/proxy-body-size": "0",
+			},
+		},
+	}
+	config := GetIngressConfigFromHelmValues(helmValues, "ingress")
+	assert.Equal(t, IngressConfig{
+		Host:          "bd.example.com",
+		Class:         "nginx",
+		TLSSecretName: "bd-tls",
+		Annotations:   map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "0"},
+	}, config)
+
+	assert.Equal(t, IngressConfig{Annotations: map[string]string{}}, GetIngressConfigFromHelmValues(map[string]interface{}{}, "ingress"))
+}
+
+func TestGetKubeIngress(t *testing.T) {
+	config := IngressConfig{
+		Host:          "bd.example.com",
+		Class:         "nginx",
+		TLSSecretName: "bd-tls",
+		Annotations:   map[string]string{NginxBackendProtocolAnnotation: "HTTP", "nginx.ingress.kubernetes.io/proxy-body-size": "0"},
+	}
+	ingress := GetKubeIngress("ns", "bd-blackduck-webserver-ingress", map[string]string{"app": "blackduck"}, config, "bd-blackduck-webserver", 443)
+
+	assert.Equal(t, "ns", ingress.Namespace)
+	assert.Equal(t, map[string]string{
+		IngressClassAnnotation:                        "nginx",
+		NginxBackendProtocolAnnotation:                "HTTP",
+		"nginx.ingress.kubernetes.io/proxy-body-size": "0",
+	}, ingress.Annotations)
+	assert.Equal(t, "bd.example.com", ingress.Spec.Rules[0].Host)
+	assert.Equal(t, "bd-blackduck-webserver", ingress.Spec.Rules[0].HTTP.Paths[0].Backend.ServiceName)
+	assert.Equal(t, 443, ingress.Spec.Rules[0].HTTP.Paths[0].Backend.ServicePort.IntValue())
+	assert.Equal(t, []string{"bd.example.com"}, ingress.Spec.TLS[0].Hosts)
+	assert.Equal(t, "bd-tls", ingress.Spec.TLS[0].SecretName)
+
+	// without a class or a TLS secret
+	ingress = GetKubeIngress("ns", "bd-blackduck-webserver-ingress", nil, IngressConfig{Host: "bd.example.com"}, "bd-blackduck-webserver", 443)
+	assert.Empty(t, ingress.Annotations)
+	assert.Empty(t, ingress.Spec.TLS)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+
+	alertapi "github.com/blackducksoftware/synopsysctl/pkg/api/alert/v1"
+	blackduckapi "github.com/blackducksoftware/synopsysctl/pkg/api/blackduck/v1"
+	opssightapi "github.com/blackducksoftware/synopsysctl/pkg/api/opssight/v1"
+)
+
+const (
+	// InventoryDeploymentHelm is the deployment style of an instance that is managed by its Helm release
+	InventoryDeploymentHelm = "Helm"
+	// InventoryDeploymentMigrated is the deployment style of a Helm instance whose namespace is still owned by the operator
+	InventoryDeploymentMigrated = "Helm (migrated)"
+	// InventoryDeploymentOperator is the deployment style of an instance that is managed by a custom resource
+	InventoryDeploymentOperator = "Operator"
+)
+
+// InventoryStateUnknown is the state of an instance whose workloads couldn't be read
+const InventoryStateUnknown = "Unknown"
+
+// InventoryInstance describes an instance of a Synopsys product in the cluster
+type InventoryInstance struct {
+	Product           string `json:"product"`
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace"`
+	Version           string `json:"version"`
+	Size              string `json:"size"`
+	ExposeType        string `json:"exposeType"`
+	Deployment        string `json:"deployment"`
+	State             string `json:"state"`
+	OperatorNamespace string `json:"operatorNamespace,omitempty"`
+}
+
+// InventoryColumns are the column headers of the inventory table and CSV output
+var InventoryColumns = []string{"PRODUCT", "NAME", "NAMESPACE", "VERSION", "SIZE", "EXPOSE", "DEPLOYMENT", "STATE", "OPERATOR NAMESPACE"}
+
+// GetInventoryRow returns the fields of the instance in the order of InventoryColumns
+func GetInventoryRow(instance *InventoryInstance) []string {
+	return []string{instance.Product, instance.Name, instance.Namespace, instance.Version, instance.Size, instance.ExposeType, instance.Deployment, instance.State, instance.OperatorNamespace}
+}
+
+// SortInventoryInstances sorts the instances by namespace, product and name
+func SortInventoryInstances(instances []*InventoryInstance) {
+	sort.SliceStable(instances, func(i, j int) bool {
+		if instances[i].Namespace != instances[j].Namespace {
+			return instances[i].Namespace < instances[j].Namespace
+		}
+		if instances[i].Product != instances[j].Product {
+			return instances[i].Product < instances[j].Product
+		}
+		return instances[i].Name < instances[j].Name
+	})
+}
+
+// WriteInventoryCSV writes the instances as CSV with a header row
+func WriteInventoryCSV(w io.Writer, instances []*InventoryInstance) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(InventoryColumns); err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if err := csvWriter.Write(GetInventoryRow(instance)); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// GetInventoryExposeTypeFromHelmValues returns how the UI of a Helm instance is exposed based on the exposeui and exposedServiceType values
+func GetInventoryExposeTypeFromHelmValues(values map[string]interface{}) string {
+	if exposeUI, ok := GetHelmValueFromMap(values, []string{"exposeui"}).(bool); ok && !exposeUI {
+		return NONE
+	}
+	if exposeType, ok := GetHelmValueFromMap(values, []string{"exposedServiceType"}).(string); ok {
+		return exposeType
+	}
+	return ""
+}
+
+// GetInventoryStateFromHelmValues returns the state of a Helm instance, which is the status value set by the stop command
+// or else the status of its release
+func GetInventoryStateFromHelmValues(values map[string]interface{}, releaseStatus string) string {
+	if status, ok := GetHelmValueFromMap(values, []string{"status"}).(string); ok && len(status) > 0 {
+		return status
+	}
+	return releaseStatus
+}
+
+// GetInventoryInstanceFromBlackDuck returns the inventory of a Black Duck custom resource
+func GetInventoryInstanceFromBlackDuck(blackDuck *blackduckapi.Blackduck) *InventoryInstance {
+	return &InventoryInstance{
+		Product:    BlackDuckName,
+		Name:       blackDuck.Name,
+		Namespace:  blackDuck.Spec.Namespace,
+		Version:    blackDuck.Spec.Version,
+		Size:       blackDuck.Spec.Size,
+		ExposeType: blackDuck.Spec.ExposeService,
+		Deployment: InventoryDeploymentOperator,
+		State:      getCustomResourceState(blackDuck.Status.State, blackDuck.Spec.DesiredState),
+	}
+}
+
+// GetInventoryInstanceFromAlert returns the inventory of an Alert custom resource
+func GetInventoryInstanceFromAlert(alert *alertapi.Alert) *InventoryInstance {
+	return &InventoryInstance{
+		Product:    AlertName,
+		Name:       alert.Name,
+		Namespace:  alert.Spec.Namespace,
+		Version:    alert.Spec.Version,
+		ExposeType: alert.Spec.ExposeService,
+		Deployment: InventoryDeploymentOperator,
+		State:      getCustomResourceState(alert.Status.State, alert.Spec.DesiredState),
+	}
+}
+
+// GetInventoryInstanceFromOpsSight returns the inventory of an OpsSight custom resource
+func GetInventoryInstanceFromOpsSight(opsSight *opssightapi.OpsSight) *InventoryInstance {
+	instance := &InventoryInstance{
+		Product:    OpsSightName,
+		Name:       opsSight.Name,
+		Namespace:  opsSight.Spec.Namespace,
+		Deployment: InventoryDeploymentOperator,
+		State:      getCustomResourceState(opsSight.Status.State, opsSight.Spec.DesiredState),
+	}
+	if opsSight.Spec.Perceptor != nil {
+		instance.ExposeType = opsSight.Spec.Perceptor.Expose
+	}
+	return instance
+}
+
+// getCustomResourceState returns the state reported by the operator, or the desired state if the operator didn't report one yet
+func getCustomResourceState(state string, desiredState string) string {
+	if len(state) > 0 {
+		return state
+	}
+	return desiredState
+}
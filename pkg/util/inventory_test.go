@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	blackduckapi "github.com/blackducksoftware/synopsysctl/pkg/api/blackduck/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetInventoryExposeTypeFromHelmValues(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(NONE, GetInventoryExposeTypeFromHelmValues(map[string]interface{}{"exposeui": false, "exposedServiceType": "NodePort"}))
+	assert.Equal("LoadBalancer", GetInventoryExposeTypeFromHelmValues(map[string]interface{}{"exposeui": true, "exposedServiceType": "LoadBalancer"}))
+	assert.Equal("", GetInventoryExposeTypeFromHelmValues(map[string]interface{}{}))
+}
+
+func TestGetInventoryStateFromHelmValues(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("Stopped", GetInventoryStateFromHelmValues(map[string]interface{}{"status": "Stopped"}, "deployed"))
+	assert.Equal("deployed", GetInventoryStateFromHelmValues(map[string]interface{}{}, "deployed"))
+}
+
+func TestGetInventoryInstanceFromBlackDuck(t *testing.T) {
+	assert := assert.New(t)
+
+	blackDuck := &blackduckapi.Blackduck{
+		ObjectMeta: metav1.ObjectMeta{Name: "bd", Namespace: "bd"},
+		Spec:       blackduckapi.BlackduckSpec{Namespace: "bd", Version: "2020.4.0", Size: "small", ExposeService: "NODEPORT", DesiredState: "Running"},
+	}
+	expected := &InventoryInstance{Product: BlackDuckName, Name: "bd", Namespace: "bd", Version: "2020.4.0", Size: "small", ExposeType: "NODEPORT", Deployment: InventoryDeploymentOperator, State: "Running"}
+	assert.Equal(expected, GetInventoryInstanceFromBlackDuck(blackDuck))
+
+	blackDuck.Status.State = "Stopped"
+	assert.Equal("Stopped", GetInventoryInstanceFromBlackDuck(blackDuck).State)
+}
+
+func TestWriteInventoryCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	instances := []*InventoryInstance{
+		{Product: "polaris", Name: "polaris", Namespace: "b", Deployment: InventoryDeploymentHelm, State: "deployed"},
+		{Product: AlertName, Name: "al", Namespace: "a", Version: "5.3.0", ExposeType: "NONE", Deployment: InventoryDeploymentOperator, State: "Running", OperatorNamespace: "operator"},
+	}
+	SortInventoryInstances(instances)
+
+	var buf bytes.Buffer
+	assert.Nil(WriteInventoryCSV(&buf, instances))
+	expected := "PRODUCT,NAME,NAMESPACE,VERSION,SIZE,EXPOSE,DEPLOYMENT,STATE,OPERATOR NAMESPACE\n" +
+		"alert,al,a,5.3.0,,NONE,Operator,Running,operator\n" +
+		"polaris,polaris,b,,,,Helm,deployed,\n"
+	assert.Equal(expected, buf.String())
+}
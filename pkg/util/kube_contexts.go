@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// GetKubeConfigLoadingRules returns the rules that load the kubeconfig from the path, or from the KUBECONFIG environment
+// variable or the home directory if the path is empty. The rest config and the contexts of a command use the same rules
+func GetKubeConfigLoadingRules(kubeConfigPath string) *clientcmd.ClientConfigLoadingRules {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeConfigPath
+	return loadingRules
+}
+
+// GetKubeContexts returns the sorted names of the contexts in the kubeconfig.
+// If the path is empty, the kubeconfig is loaded from the KUBECONFIG environment variable or the home directory
+func GetKubeContexts(kubeConfigPath string) ([]string, error) {
+	loadingRules := GetKubeConfigLoadingRules(kubeConfigPath)
+	config, err := loadingRules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubeconfig due to %+v", err)
+	}
+	contexts := []string{}
+	for name := range config.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, nil
+}
+
+// SelectKubeContexts returns the selected contexts, or all the contexts if none are selected.
+// It returns an error if a selected context is not in the kubeconfig
+func SelectKubeContexts(contexts []string, selected []string) ([]string, error) {
+	if len(selected) == 0 {
+		return contexts, nil
+	}
+	for _, context := range selected {
+		if !IsExistInStringSlice(contexts, context) {
+			return nil, fmt.Errorf("context '%s' is not in the kubeconfig, must be one of [%s]", context, strings.Join(contexts, "|"))
+		}
+	}
+	return selected, nil
+}
+
+// GetKubeContextArgs returns the command line arguments that run a command in a single context.
+// The context flags are removed from the arguments and replaced by --context
+func GetKubeContextArgs(args []string, kubeContext string) []string {
+	contextArgs := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--all-contexts" || strings.HasPrefix(arg, "--all-contexts="):
+			continue
+		case arg == "--contexts" || arg == "--context":
+			i++ // skip the value
+			continue
+		case strings.HasPrefix(arg, "--contexts=") || strings.HasPrefix(arg, "--context="):
+			continue
+		}
+		contextArgs = append(contextArgs, arg)
+	}
+	return append(contextArgs, fmt.Sprintf("--context=%s", kubeContext))
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster:
+    server: https://east.example.com
+- name: west
+  cluster:
+    server: https://west.example.com
+contexts:
+- name: west
+  context:
+    cluster: west
+- name: east
+  context:
+    cluster: east
+current-context: east
+`
+
+func TestGetKubeContexts(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	kubeConfigPath := filepath.Join(dir, "config")
+	assert.Nil(ioutil.WriteFile(kubeConfigPath, []byte(testKubeConfig), 0600))
+
+	contexts, err := GetKubeContexts(kubeConfigPath)
+	assert.Nil(err)
+	assert.Equal([]string{"east", "west"}, contexts)
+
+	_, err = GetKubeContexts(filepath.Join(dir, "missing"))
+	assert.NotNil(err)
+}
+
+func TestGetKubeConfigLoadingRules(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	kubeConfigPath := filepath.Join(dir, "config")
+	assert.Nil(ioutil.WriteFile(kubeConfigPath, []byte(testKubeConfig), 0600))
+
+	kubeConfigEnv, kubeConfigEnvSet := os.LookupEnv("KUBECONFIG")
+	defer func() {
+		if kubeConfigEnvSet {
+			os.Setenv("KUBECONFIG", kubeConfigEnv)
+		} else {
+			os.Unsetenv("KUBECONFIG")
+		}
+	}()
+	os.Setenv("KUBECONFIG", kubeConfigPath)
+
+	// without a path the kubeconfig of the KUBECONFIG environment variable is loaded
+	config, err := GetKubeConfigLoadingRules("").Load()
+	assert.Nil(err)
+	assert.Len(config.Contexts, 2)
+
+	// the path takes precedence over the KUBECONFIG environment variable
+	_, err = GetKubeConfigLoadingRules(filepath.Join(dir, "missing")).Load()
+	assert.NotNil(err)
+}
+
+func TestSelectKubeContexts(t *testing.T) {
+	assert := assert.New(t)
+
+	contexts := []string{"east", "west"}
+	selected, err := SelectKubeContexts(contexts, nil)
+	assert.Nil(err)
+	assert.Equal(contexts, selected)
+
+	selected, err = SelectKubeContexts(contexts, []string{"west"})
+	assert.Nil(err)
+	assert.Equal([]string{"west"}, selected)
+
+	_, err = SelectKubeContexts(contexts, []string{"north"})
+	assert.NotNil(err)
+}
+
+func TestGetKubeContextArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"status", "blackduck", "-n", "bd", "--context=west"},
+		GetKubeContextArgs([]string{"status", "blackduck", "--all-contexts", "-n", "bd", "--context", "east"}, "west"))
+	assert.Equal([]string{"get", "blackduck", "--context=east"},
+		GetKubeContextArgs([]string{"get", "blackduck", "--contexts", "east,west"}, "east"))
+	assert.Equal([]string{"update", "bdba", "--context=west"},
+		GetKubeContextArgs([]string{"update", "bdba", "--contexts=west", "--all-contexts=true"}, "west"))
+}
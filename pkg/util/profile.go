@@ -0,0 +1,49 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+// Profile holds the default flag values of a profile in the synopsysctl config file
+type Profile struct {
+	// Flags are the default flag values of every command
+	Flags map[string]interface{} `mapstructure:"flags"`
+	// Products are the default flag values of the commands of a product, e.g. blackduck
+	Products map[string]map[string]interface{} `mapstructure:"products"`
+	// Commands are the default flag values of a command path, e.g. "create blackduck"
+	Commands map[string]map[string]interface{} `mapstructure:"commands"`
+}
+
+// GetSettings returns the default flag values of a command of a product. The values of the command override the ones
+// of the product, which override the ones of every command. The product is empty for the commands without a product
+func (p *Profile) GetSettings(product string, commandPath string) map[string]interface{} {
+	settings := make(map[string]interface{})
+	layers := []map[string]interface{}{p.Flags}
+	if len(product) > 0 {
+		layers = append(layers, p.Products[product])
+	}
+	layers = append(layers, p.Commands[commandPath])
+	for _, layer := range layers {
+		for flagName, value := range layer {
+			settings[flagName] = value
+		}
+	}
+	return settings
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileGetSettings(t *testing.T) {
+	assert := assert.New(t)
+
+	profile := Profile{
+		Flags: map[string]interface{}{"registry": "global", "size": "small", "pvc-storage-class": "standard"},
+		Products: map[string]map[string]interface{}{
+			"blackduck": {"size": "medium", "pvc-storage-class": "ssd"},
+			"alert":     {"size": "large"},
+		},
+		Commands: map[string]map[string]interface{}{
+			"create blackduck": {"size": "large"},
+		},
+	}
+
+	// the command overrides the product, which overrides every command
+	assert.Equal(map[string]interface{}{"registry": "global", "size": "large", "pvc-storage-class": "ssd"}, profile.GetSettings("blackduck", "create blackduck"))
+	assert.Equal(map[string]interface{}{"registry": "global", "size": "medium", "pvc-storage-class": "ssd"}, profile.GetSettings("blackduck", "images list"))
+	assert.Equal(map[string]interface{}{"registry": "global", "size": "large", "pvc-storage-class": "standard"}, profile.GetSettings("alert", "create alert"))
+	assert.Equal(map[string]interface{}{"registry": "global", "size": "small", "pvc-storage-class": "standard"}, profile.GetSettings("", "images list"))
+
+	// the profile can be empty
+	assert.Equal(map[string]interface{}{}, (&Profile{}).GetSettings("blackduck", "create blackduck"))
+}
@@ -0,0 +1,605 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/ghodss/yaml"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DockerManifestMediaType is the media type of a Docker image manifest
+	DockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	// DockerManifestListMediaType is the media type of a Docker manifest list
+	DockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	// OCIManifestMediaType is the media type of an OCI image manifest
+	OCIManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// OCIIndexMediaType is the media type of an OCI image index
+	OCIIndexMediaType = "application/vnd.oci.image.index.v1+json"
+
+	defaultDockerRegistry = "registry-1.docker.io"
+)
+
+// ImageReference is a parsed image reference
+type ImageReference struct {
+	// Registry is the host of the registry, i.e. docker.io is registry-1.docker.io
+	Registry string
+	// Repository is the repository in the registry, i.e. blackducksoftware/blackduck-webapp
+	Repository string
+	// Reference is the tag or the digest of the image
+	Reference string
+}
+
+// ParseImageReference parses an image such as docker.io/blackducksoftware/blackduck-webapp:2020.4.0
+func ParseImageReference(image string) (*ImageReference, error) {
+	if len(image) == 0 {
+		return nil, fmt.Errorf("image can't be empty")
+	}
+	ref := &ImageReference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Reference = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+		name, ref.Reference = name[:i], name[i+1:]
+	} else {
+		ref.Reference = "latest"
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry, ref.Repository = parts[0], parts[1]
+	} else {
+		ref.Registry, ref.Repository = defaultDockerRegistry, name
+	}
+	if ref.Registry == "docker.io" || ref.Registry == "index.docker.io" {
+		ref.Registry = defaultDockerRegistry
+	}
+	if ref.Registry == defaultDockerRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = fmt.Sprintf("library/%s", ref.Repository)
+	}
+	if len(ref.Repository) == 0 || len(ref.Reference) == 0 {
+		return nil, fmt.Errorf("invalid image '%s'", image)
+	}
+	return ref, nil
+}
+
+// String returns the image reference in the registry/repository:tag or registry/repository@digest format
+func (r *ImageReference) String() string {
+	if strings.Contains(r.Reference, ":") {
+		return fmt.Sprintf("%s/%s@%s", r.Registry, r.Repository, r.Reference)
+	}
+	return fmt.Sprintf("%s/%s:%s", r.Registry, r.Repository, r.Reference)
+}
+
+// GetRegistryImage returns the image with its registry and repository path replaced by the registry
+func GetRegistryImage(image string, registry string) (string, error) {
+	return generateNewImage(image, strings.TrimSuffix(registry, "/"))
+}
+
+// GetPinnedImage returns the image pinned to the digest of its manifest, e.g. registry:5000/repository@sha256:...
+// The tag or the digest of the image is replaced by the digest
+func GetPinnedImage(image string, digest string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image '%s' due to %+v", image, err)
+	}
+	pinned, err := reference.ParseNormalizedNamed(fmt.Sprintf("%s@%s", reference.TrimNamed(named).String(), digest))
+	if err != nil {
+		return "", fmt.Errorf("invalid digest '%s' of image '%s' due to %+v", digest, image, err)
+	}
+	return pinned.String(), nil
+}
+
+// GetManifestImages returns the sorted unique images of the containers and init containers in the manifest
+func GetManifestImages(manifest string) ([]string, error) {
+	images := map[string]bool{}
+	for _, m := range releaseutil.SplitManifests(manifest) {
+		resource := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(m), &resource); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %+v", err)
+		}
+		findContainerImages(resource, images)
+	}
+	sortedImages := []string{}
+	for image := range images {
+		sortedImages = append(sortedImages, image)
+	}
+	sort.Strings(sortedImages)
+	return sortedImages, nil
+}
+
+// findContainerImages walks the resource and adds the image of every entry of a containers or initContainers list
+func findContainerImages(value interface{}, images map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if key == "containers" || key == "initContainers" {
+				if containers, ok := child.([]interface{}); ok {
+					for _, c := range containers {
+						if container, ok := c.(map[string]interface{}); ok {
+							if image, ok := container["image"].(string); ok && len(image) > 0 {
+								images[image] = true
+							}
+						}
+					}
+				}
+			}
+			findContainerImages(child, images)
+		}
+	case []interface{}:
+		for _, child := range v {
+			findContainerImages(child, images)
+		}
+	}
+}
+
+// GetImagePullSecret returns a docker config secret that authenticates with the registry, e.g. registry.example.com/blackducksoftware
+func GetImagePullSecret(namespace string, name string, registry string, username string, password string) (*corev1.Secret, error) {
+	host := strings.SplitN(registry, "/", 2)[0]
+	dockerConfig := map[string]interface{}{
+		"auths": map[string]interface{}{
+			host: map[string]string{
+				"username": username,
+				"password": password,
+				"auth":     base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password))),
+			},
+		},
+	}
+	dockerConfigBytes, err := json.Marshal(dockerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerConfigBytes,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}, nil
+}
+
+// RegistryClient pulls and pushes images with the Docker Registry HTTP API V2
+type RegistryClient struct {
+	client   *http.Client
+	username string
+	password string
+	scheme   string
+	tokens   map[string]string
+}
+
+// NewRegistryClient returns a registry client that authenticates with the username and password if they are set.
+// If plainHTTP is true, the registry is accessed with http. If skipTLSVerify is true, the certificate of the registry
+// isn't verified
+func NewRegistryClient(username string, password string, plainHTTP bool, skipTLSVerify bool) *RegistryClient {
+	client := &http.Client{}
+	scheme := "https"
+	if plainHTTP {
+		scheme = "http"
+	}
+	if skipTLSVerify {
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	return &RegistryClient{
+		client:   client,
+		username: username,
+		password: password,
+		scheme:   scheme,
+		tokens:   map[string]string{},
+	}
+}
+
+// SavedImage is an image saved by PullImage
+type SavedImage struct {
+	// Image is the pulled image
+	Image string `json:"image"`
+	// ManifestDigest is the digest of the image manifest
+	ManifestDigest string `json:"manifestDigest"`
+	// MediaType is the media type of the image manifest
+	MediaType string `json:"mediaType"`
+	// ManifestPath is the path of the image manifest relative to the directory
+	ManifestPath string `json:"manifestPath"`
+	// Blobs are the digests of the config and the layers
+	Blobs []string `json:"blobs"`
+}
+
+type registryDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+type registryManifest struct {
+	MediaType string               `json:"mediaType"`
+	Config    registryDescriptor   `json:"config"`
+	Layers    []registryDescriptor `json:"layers"`
+	Manifests []registryDescriptor `json:"manifests"`
+}
+
+// PullImage saves the manifest and the blobs of the image to the directory. If the image is a manifest list,
+// the linux/amd64 image is saved. The blobs are stored as blobs/sha256/<digest> so that images share their layers
+func (c *RegistryClient) PullImage(image string, dir string) (*SavedImage, error) {
+	ref, err := ParseImageReference(image)
+	if err != nil {
+		return nil, err
+	}
+	manifestBytes, mediaType, err := c.getManifest(ref, ref.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the manifest of image '%s' due to %+v", image, err)
+	}
+	manifest := &registryManifest{}
+	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse the manifest of image '%s' due to %+v", image, err)
+	}
+
+	if mediaType == DockerManifestListMediaType || mediaType == OCIIndexMediaType {
+		digest := ""
+		for _, m := range manifest.Manifests {
+			if m.Platform != nil && m.Platform.OS == "linux" && m.Platform.Architecture == "amd64" {
+				digest = m.Digest
+				break
+			}
+		}
+		if len(digest) == 0 {
+			return nil, fmt.Errorf("image '%s' doesn't have a linux/amd64 manifest", image)
+		}
+		if manifestBytes, mediaType, err = c.getManifest(ref, digest); err != nil {
+			return nil, fmt.Errorf("failed to get the linux/amd64 manifest of image '%s' due to %+v", image, err)
+		}
+		manifest = &registryManifest{}
+		if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse the manifest of image '%s' due to %+v", image, err)
+		}
+	}
+	if mediaType != DockerManifestMediaType && mediaType != OCIManifestMediaType {
+		return nil, fmt.Errorf("image '%s' has an unsupported manifest type '%s'", image, mediaType)
+	}
+
+	manifestDigest := sha256Digest(manifestBytes)
+	saved := &SavedImage{
+		Image:          image,
+		ManifestDigest: manifestDigest,
+		MediaType:      mediaType,
+		ManifestPath:   blobPath(manifestDigest),
+	}
+	if err := writeFile(filepath.Join(dir, saved.ManifestPath), manifestBytes); err != nil {
+		return nil, err
+	}
+	for _, blob := range append([]registryDescriptor{manifest.Config}, manifest.Layers...) {
+		if err := c.downloadBlob(ref, blob.Digest, filepath.Join(dir, blobPath(blob.Digest))); err != nil {
+			return nil, fmt.Errorf("failed to download blob '%s' of image '%s' due to %+v", blob.Digest, image, err)
+		}
+		saved.Blobs = append(saved.Blobs, blob.Digest)
+	}
+	return saved, nil
+}
+
+// PushImage pushes an image saved by PullImage in the directory to the target image
+func (c *RegistryClient) PushImage(saved *SavedImage, dir string, targetImage string) error {
+	ref, err := ParseImageReference(targetImage)
+	if err != nil {
+		return err
+	}
+	for _, digest := range saved.Blobs {
+		if err := c.uploadBlob(ref, digest, filepath.Join(dir, blobPath(digest))); err != nil {
+			return fmt.Errorf("failed to upload blob '%s' of image '%s' due to %+v", digest, targetImage, err)
+		}
+	}
+	manifestBytes, err := ioutil.ReadFile(filepath.Join(dir, saved.ManifestPath))
+	if err != nil {
+		return fmt.Errorf("failed to read the manifest of image '%s' due to %+v", saved.Image, err)
+	}
+	req, err := http.NewRequest(http.MethodPut, c.registryURL(ref, "manifests", ref.Reference), bytes.NewReader(manifestBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", saved.MediaType)
+	resp, err := c.do(ref, req, "push,pull")
+	if err != nil {
+		return fmt.Errorf("failed to push the manifest of image '%s' due to %+v", targetImage, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to push the manifest of image '%s' | %s", targetImage, resp.Status)
+	}
+	return nil
+}
+
+// CopyImage copies an image from one registry to another through the directory
+func (c *RegistryClient) CopyImage(image string, targetImage string, dir string) error {
+	saved, err := c.PullImage(image, dir)
+	if err != nil {
+		return err
+	}
+	return c.PushImage(saved, dir, targetImage)
+}
+
+func (c *RegistryClient) getManifest(ref *ImageReference, reference string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, c.registryURL(ref, "manifests", reference), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", strings.Join([]string{DockerManifestMediaType, DockerManifestListMediaType, OCIManifestMediaType, OCIIndexMediaType}, ", "))
+	resp, err := c.do(ref, req, "pull")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch %s | %s", req.URL.String(), resp.Status)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if strings.HasPrefix(reference, "sha256:") && sha256Digest(content) != reference {
+		return nil, "", fmt.Errorf("manifest digest doesn't match '%s'", reference)
+	}
+	mediaType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if m := (&registryManifest{}); json.Unmarshal(content, m) == nil && len(m.MediaType) > 0 {
+		mediaType = m.MediaType
+	}
+	return content, mediaType, nil
+}
+
+func (c *RegistryClient) downloadBlob(ref *ImageReference, digest string, path string) error {
+	// the blobs are content addressable so a blob that was already downloaded doesn't change
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodGet, c.registryURL(ref, "blobs", digest), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ref, req, "pull")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s | %s", req.URL.String(), resp.Status)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	tmpPath := path + ".partial"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), resp.Body)
+	f.Close()
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if fmt.Sprintf("sha256:%s", hex.EncodeToString(hash.Sum(nil))) != digest {
+		os.Remove(tmpPath)
+		return fmt.Errorf("blob digest doesn't match '%s'", digest)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func (c *RegistryClient) uploadBlob(ref *ImageReference, digest string, path string) error {
+	req, err := http.NewRequest(http.MethodHead, c.registryURL(ref, "blobs", digest), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ref, req, "push,pull")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	req, err = http.NewRequest(http.MethodPost, c.registryURL(ref, "blobs", "uploads/"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err = c.do(ref, req, "push,pull")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to start the upload to %s | %s", req.URL.String(), resp.Status)
+	}
+	location, err := req.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location '%s' due to %+v", resp.Header.Get("Location"), err)
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	req, err = http.NewRequest(http.MethodPut, location.String(), f)
+	if err != nil {
+		return err
+	}
+	// the client closes the file after the request, so a retry after authentication opens it again
+	req.GetBody = func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err = c.do(ref, req, "push,pull")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to upload to %s | %s", location.String(), resp.Status)
+	}
+	return nil
+}
+
+func (c *RegistryClient) registryURL(ref *ImageReference, kind string, reference string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", c.scheme, ref.Registry, ref.Repository, kind, reference)
+}
+
+// do sends the request and authenticates with the registry if it responds with a challenge
+func (c *RegistryClient) do(ref *ImageReference, req *http.Request, actions string) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:%s", ref.Repository, actions)
+	if token, ok := c.tokens[scope]; ok {
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	token, err := c.authenticate(resp.Header.Get("WWW-Authenticate"), scope)
+	if err != nil {
+		return nil, err
+	}
+	c.tokens[scope] = token
+	if req.Body != nil {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("the body of %s %s can't be sent again after authentication", req.Method, req.URL.String())
+		}
+		if req.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("Authorization", token)
+	return c.client.Do(req)
+}
+
+var challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate returns the Authorization header for the challenge of the registry
+func (c *RegistryClient) authenticate(challenge string, scope string) (string, error) {
+	if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+		if len(c.username) == 0 {
+			return "", fmt.Errorf("the registry requires a username and password")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(c.username, c.password)
+		return req.Header.Get("Authorization"), nil
+	}
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer") {
+		return "", fmt.Errorf("unsupported authentication challenge '%s'", challenge)
+	}
+
+	params := map[string]string{}
+	for _, match := range challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil || len(params["realm"]) == 0 {
+		return "", fmt.Errorf("invalid authentication realm in challenge '%s'", challenge)
+	}
+	query := tokenURL.Query()
+	if len(params["service"]) > 0 {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get a registry token due to %+v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get a registry token from %s | %s", tokenURL.String(), resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse the registry token due to %+v", err)
+	}
+	if len(token.Token) == 0 {
+		token.Token = token.AccessToken
+	}
+	return fmt.Sprintf("Bearer %s", token.Token), nil
+}
+
+func sha256Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("sha256:%s", hex.EncodeToString(sum[:]))
+}
+
+func blobPath(digest string) string {
+	return filepath.Join("blobs", strings.Replace(digest, ":", string(os.PathSeparator), 1))
+}
+
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the directory for '%s' due to %+v", path, err)
+	}
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write '%s' due to %+v", path, err)
+	}
+	return nil
+}
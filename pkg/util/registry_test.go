@@ -0,0 +1,298 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// testRegistry is an in-memory stand-in for a Docker Registry HTTP API V2
+type testRegistry struct {
+	lock      sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	types     map[string]string
+	// uploadAuth makes the blob uploads require basic authentication
+	uploadAuth bool
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}, types: map[string]string{}}
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case strings.Contains(path, "/manifests/"):
+		parts := strings.SplitN(path, "/manifests/", 2)
+		key := parts[0] + ":" + parts[1]
+		switch req.Method {
+		case http.MethodGet:
+			content, ok := r.manifests[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", r.types[key])
+			w.Write(content)
+		case http.MethodPut:
+			content, _ := ioutil.ReadAll(req.Body)
+			r.manifests[key] = content
+			r.manifests[parts[0]+":"+sha256Digest(content)] = content
+			r.types[key] = req.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusCreated)
+		}
+	case strings.HasSuffix(path, "/blobs/uploads/"):
+		w.Header().Set("Location", fmt.Sprintf("/v2/%suuid", path))
+		w.WriteHeader(http.StatusAccepted)
+	case strings.Contains(path, "/blobs/uploads/"):
+		content, _ := ioutil.ReadAll(req.Body)
+		if _, _, ok := req.BasicAuth(); r.uploadAuth && !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		digest := req.URL.Query().Get("digest")
+		if sha256Digest(content) != digest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = content
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		content, ok := r.blobs[path[strings.LastIndex(path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.Method == http.MethodGet {
+			w.Write(content)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *testRegistry) addImage(repository string, tag string, layers ...string) {
+	config := []byte(`{"architecture":"amd64","os":"linux"}`)
+	r.blobs[sha256Digest(config)] = config
+	manifest := registryManifest{
+		MediaType: DockerManifestMediaType,
+		Config:    registryDescriptor{MediaType: "application/vnd.docker.container.image.v1+json", Digest: sha256Digest(config), Size: int64(len(config))},
+	}
+	for _, layer := range layers {
+		r.blobs[sha256Digest([]byte(layer))] = []byte(layer)
+		manifest.Layers = append(manifest.Layers, registryDescriptor{MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Digest: sha256Digest([]byte(layer)), Size: int64(len(layer))})
+	}
+	content, _ := json.Marshal(manifest)
+	r.manifests[repository+":"+tag] = content
+	r.types[repository+":"+tag] = DockerManifestMediaType
+}
+
+func TestParseImageReference(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		image    string
+		expected *ImageReference
+	}{
+		{image: "docker.io/blackducksoftware/blackduck-webapp:2020.4.0", expected: &ImageReference{Registry: "registry-1.docker.io", Repository: "blackducksoftware/blackduck-webapp", Reference: "2020.4.0"}},
+		{image: "postgres:9.6", expected: &ImageReference{Registry: "registry-1.docker.io", Repository: "library/postgres", Reference: "9.6"}},
+		{image: "gcr.io/project/image", expected: &ImageReference{Registry: "gcr.io", Repository: "project/image", Reference: "latest"}},
+		{image: "localhost:5000/image@sha256:abc", expected: &ImageReference{Registry: "localhost:5000", Repository: "image", Reference: "sha256:abc"}},
+	}
+	for _, test := range tests {
+		ref, err := ParseImageReference(test.image)
+		assert.Nil(err)
+		assert.Equal(test.expected, ref)
+	}
+
+	_, err := ParseImageReference("")
+	assert.NotNil(err)
+}
+
+func TestGetRegistryImage(t *testing.T) {
+	image, err := GetRegistryImage("docker.io/blackducksoftware/blackduck-webapp:2020.4.0", "registry.example.com/mirror/")
+	assert.Nil(t, err)
+	assert.Equal(t, "registry.example.com/mirror/blackduck-webapp:2020.4.0", image)
+}
+
+func TestGetManifestImages(t *testing.T) {
+	manifest := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: webapp
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: docker.io/blackducksoftware/blackduck-init:1.0.0
+      containers:
+      - name: webapp
+        image: docker.io/blackducksoftware/blackduck-webapp:2020.4.0
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: job
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: job
+            image: docker.io/blackducksoftware/blackduck-webapp:2020.4.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: webapp
+`
+	images, err := GetManifestImages(manifest)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"docker.io/blackducksoftware/blackduck-init:1.0.0", "docker.io/blackducksoftware/blackduck-webapp:2020.4.0"}, images)
+}
+
+func TestGetPinnedImage(t *testing.T) {
+	assert := assert.New(t)
+
+	digest := sha256Digest([]byte("manifest"))
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "registry.example.com/blackducksoftware/blackduck-webapp:2020.4.0", expected: "registry.example.com/blackducksoftware/blackduck-webapp@" + digest},
+		{image: "registry.example.com:5000/blackduck-webapp", expected: "registry.example.com:5000/blackduck-webapp@" + digest},
+		{image: "registry.example.com:5000/blackduck-webapp@" + sha256Digest([]byte("other")), expected: "registry.example.com:5000/blackduck-webapp@" + digest},
+	}
+	for _, test := range tests {
+		pinned, err := GetPinnedImage(test.image, digest)
+		assert.Nil(err)
+		assert.Equal(test.expected, pinned)
+	}
+	_, err := GetPinnedImage("registry.example.com/blackduck-webapp:2020.4.0", "invalid")
+	assert.NotNil(err)
+}
+
+func TestRegistryClientPullAndPushImage(t *testing.T) {
+	assert := assert.New(t)
+
+	source := newTestRegistry()
+	source.addImage("blackducksoftware/blackduck-webapp", "2020.4.0", "layer1", "layer2")
+	sourceServer := httptest.NewServer(source)
+	defer sourceServer.Close()
+
+	target := newTestRegistry()
+	targetServer := httptest.NewServer(target)
+	defer targetServer.Close()
+
+	dir, err := ioutil.TempDir("", "synopsysctl-registry")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	client := NewRegistryClient("", "", true, false)
+	image := fmt.Sprintf("%s/blackducksoftware/blackduck-webapp:2020.4.0", strings.TrimPrefix(sourceServer.URL, "http://"))
+	saved, err := client.PullImage(image, dir)
+	assert.Nil(err)
+	assert.Equal(DockerManifestMediaType, saved.MediaType)
+	assert.Equal(3, len(saved.Blobs))
+
+	targetImage, err := GetRegistryImage(image, fmt.Sprintf("%s/mirror", strings.TrimPrefix(targetServer.URL, "http://")))
+	assert.Nil(err)
+	assert.Nil(client.PushImage(saved, dir, targetImage))
+	assert.Equal(source.manifests["blackducksoftware/blackduck-webapp:2020.4.0"], target.manifests["mirror/blackduck-webapp:2020.4.0"])
+	assert.Equal(3, len(target.blobs))
+
+	_, err = client.PullImage(fmt.Sprintf("%s/blackducksoftware/missing:1.0.0", strings.TrimPrefix(sourceServer.URL, "http://")), dir)
+	assert.NotNil(err)
+}
+
+func TestRegistryClientPushImageWithAuthentication(t *testing.T) {
+	assert := assert.New(t)
+
+	source := newTestRegistry()
+	source.addImage("blackducksoftware/blackduck-webapp", "2020.4.0", "layer1")
+	sourceServer := httptest.NewServer(source)
+	defer sourceServer.Close()
+
+	target := newTestRegistry()
+	target.uploadAuth = true
+	targetServer := httptest.NewServer(target)
+	defer targetServer.Close()
+
+	dir, err := ioutil.TempDir("", "synopsysctl-registry")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+
+	image := fmt.Sprintf("%s/blackducksoftware/blackduck-webapp:2020.4.0", strings.TrimPrefix(sourceServer.URL, "http://"))
+	saved, err := NewRegistryClient("", "", true, false).PullImage(image, dir)
+	assert.Nil(err)
+
+	// the upload of the layer is sent again with the credentials after the registry asks for them
+	targetImage, err := GetRegistryImage(image, strings.TrimPrefix(targetServer.URL, "http://"))
+	assert.Nil(err)
+	assert.Nil(NewRegistryClient("user", "password", true, false).PushImage(saved, dir, targetImage))
+	assert.Equal(2, len(target.blobs))
+}
+
+func TestRegistryClientCorruptedBlob(t *testing.T) {
+	source := newTestRegistry()
+	source.addImage("blackducksoftware/blackduck-webapp", "2020.4.0", "layer1")
+	source.blobs[sha256Digest([]byte("layer1"))] = []byte("corrupted")
+	server := httptest.NewServer(source)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "synopsysctl-registry")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewRegistryClient("", "", true, false).PullImage(fmt.Sprintf("%s/blackducksoftware/blackduck-webapp:2020.4.0", strings.TrimPrefix(server.URL, "http://")), dir)
+	assert.NotNil(t, err)
+}
+
+func TestGetImagePullSecret(t *testing.T) {
+	assert := assert.New(t)
+
+	secret, err := GetImagePullSecret("default", "mirror", "registry.example.com/blackducksoftware", "user", "password")
+	assert.Nil(err)
+	assert.Equal("mirror", secret.Name)
+	assert.Equal("default", secret.Namespace)
+	assert.Equal(corev1.SecretTypeDockerConfigJson, secret.Type)
+	assert.Equal(`{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNzd29yZA==","password":"password","username":"user"}}}`, string(secret.Data[corev1.DockerConfigJsonKey]))
+}
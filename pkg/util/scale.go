@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComponentWorkload is a Deployment or StatefulSet that runs a component of an instance
+type ComponentWorkload struct {
+	Kind string
+	Name string
+	// Replicas are the replicas of the workload in the manifest
+	Replicas int32
+}
+
+// GetComponentWorkloads returns the Deployments and StatefulSets of the manifest resources of a release keyed by component.
+// The component is the component label of the workload, or its name without the release name prefix
+func GetComponentWorkloads(resources []map[string]interface{}, releaseName string) map[string][]ComponentWorkload {
+	components := map[string][]ComponentWorkload{}
+	for _, resource := range resources {
+		kind := fmt.Sprintf("%v", resource["kind"])
+		if kind != "Deployment" && kind != "StatefulSet" {
+			continue
+		}
+		name := fmt.Sprintf("%v", GetHelmValueFromMap(resource, []string{"metadata", "name"}))
+		component, ok := GetHelmValueFromMap(resource, []string{"metadata", "labels", "component"}).(string)
+		if !ok || len(component) == 0 {
+			component = strings.TrimPrefix(name, fmt.Sprintf("%s-", releaseName))
+		}
+		components[component] = append(components[component], ComponentWorkload{Kind: kind, Name: name, Replicas: getManifestReplicas(resource)})
+	}
+	return components
+}
+
+// getManifestReplicas returns the replicas of a Deployment or StatefulSet from a manifest, which default to 1
+func getManifestReplicas(resource map[string]interface{}) int32 {
+	switch replicas := GetHelmValueFromMap(resource, []string{"spec", "replicas"}).(type) {
+	case float64:
+		return int32(replicas)
+	case int64:
+		return int32(replicas)
+	case int:
+		return int32(replicas)
+	}
+	return 1
+}
+
+// GetComponentReplicasValuePath returns the path of the replicas of the component in the Helm values of a chart, or nil if the chart doesn't parameterize them
+func GetComponentReplicasValuePath(chartValues map[string]interface{}, releaseValues map[string]interface{}, component string) []string {
+	path := []string{component, "replicas"}
+	if GetHelmValueFromMap(chartValues, path) != nil || GetHelmValueFromMap(releaseValues, path) != nil {
+		return path
+	}
+	return nil
+}
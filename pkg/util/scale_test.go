@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetComponentWorkloads(t *testing.T) {
+	assert := assert.New(t)
+
+	resources := []map[string]interface{}{
+		{"kind": "Deployment", "metadata": map[string]interface{}{"name": "bd-blackduck-jobrunner", "labels": map[string]interface{}{"component": "jobrunner"}}, "spec": map[string]interface{}{"replicas": float64(2)}},
+		{"kind": "StatefulSet", "metadata": map[string]interface{}{"name": "bdba-postgresql"}},
+		{"kind": "Service", "metadata": map[string]interface{}{"name": "bdba-webapp"}},
+	}
+	components := GetComponentWorkloads(resources, "bdba")
+	assert.Equal(map[string][]ComponentWorkload{
+		"jobrunner":  {{Kind: "Deployment", Name: "bd-blackduck-jobrunner", Replicas: 2}},
+		"postgresql": {{Kind: "StatefulSet", Name: "bdba-postgresql", Replicas: 1}},
+	}, components)
+}
+
+func TestGetComponentReplicasValuePath(t *testing.T) {
+	assert := assert.New(t)
+
+	chartValues := map[string]interface{}{"worker": map[string]interface{}{"replicas": 1}}
+	releaseValues := map[string]interface{}{"jobrunner": map[string]interface{}{"replicas": 2}}
+	assert.Equal([]string{"worker", "replicas"}, GetComponentReplicasValuePath(chartValues, releaseValues, "worker"))
+	assert.Equal([]string{"jobrunner", "replicas"}, GetComponentReplicasValuePath(chartValues, releaseValues, "jobrunner"))
+	assert.Nil(GetComponentReplicasValuePath(chartValues, releaseValues, "webapp"))
+}
@@ -0,0 +1,287 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StoppedReplicasAnnotation records the replicas of a Deployment or StatefulSet before it was stopped
+const StoppedReplicasAnnotation = "synopsys.com/stoppedReplicas"
+
+// SetStoppedReplicasAnnotation records the replicas in the annotations, defaulting to 1 as Kubernetes does
+func SetStoppedReplicasAnnotation(annotations map[string]string, replicas *int32) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	stoppedReplicas := int32(1)
+	if replicas != nil {
+		stoppedReplicas = *replicas
+	}
+	annotations[StoppedReplicasAnnotation] = strconv.Itoa(int(stoppedReplicas))
+	return annotations
+}
+
+// GetStoppedReplicas returns the replicas recorded by SetStoppedReplicasAnnotation and whether they were found
+func GetStoppedReplicas(annotations map[string]string) (*int32, bool, error) {
+	value, ok := annotations[StoppedReplicasAnnotation]
+	if !ok {
+		return nil, false, nil
+	}
+	replicas, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid %s annotation '%s'", StoppedReplicasAnnotation, value)
+	}
+	return IntToInt32(replicas), true, nil
+}
+
+// ScheduleReleaseLabel is the label of the resources of a start/stop schedule whose value is the Helm release name
+const ScheduleReleaseLabel = "synopsys.com/schedule-release"
+
+// ScheduleActionLabel is the label of a schedule CronJob whose value is either stop or start
+const ScheduleActionLabel = "synopsys.com/schedule-action"
+
+var cronFieldRegexp = regexp.MustCompile(`^(\*|[0-9A-Za-z]+(-[0-9A-Za-z]+)?)(/[0-9]+)?(,(\*|[0-9A-Za-z]+(-[0-9A-Za-z]+)?)(/[0-9]+)?)*$`)
+
+var cronMacros = []string{"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+// ValidateCronSchedule checks that the schedule is a five field cron expression or one of the cron macros
+func ValidateCronSchedule(schedule string) error {
+	if strings.HasPrefix(schedule, "@") {
+		for _, macro := range cronMacros {
+			if schedule == macro {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid schedule '%s': unknown macro", schedule)
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return fmt.Errorf("invalid schedule '%s': expected 5 fields (minute hour day-of-month month day-of-week) but got %d", schedule, len(fields))
+	}
+	for _, field := range fields {
+		if !cronFieldRegexp.MatchString(field) {
+			return fmt.Errorf("invalid schedule '%s': invalid field '%s'", schedule, field)
+		}
+	}
+	return nil
+}
+
+// GetScheduleCommand returns the synopsysctl command that stops or starts the instance, so that a schedule
+// uses the same mechanism as the stop and start commands and finds the workloads when it runs
+func GetScheduleCommand(action string, product string, name string, namespace string) []string {
+	command := []string{"synopsysctl", action, product}
+	if len(name) > 0 {
+		command = append(command, name)
+	}
+	return append(command, "-n", namespace, "--disable-chart-cache")
+}
+
+// GetScheduleServiceAccount returns the service account the schedule CronJobs run as
+func GetScheduleServiceAccount(namespace string, name string, labels map[string]string) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+	}
+}
+
+// GetScheduleRole returns the role of the schedule service account. The scheduled stop and start upgrade the Helm release,
+// which stores its revisions in secrets and scales the deployments and stateful sets. Helm reads the other resources of the
+// manifest of the release to compare them with the chart, so they can only be read
+func GetScheduleRole(namespace string, name string, labels map[string]string, resources []map[string]interface{}) *rbacv1.Role {
+	rules := []rbacv1.PolicyRule{
+		// the names of the release secrets can't be restricted since Helm lists them by label and creates one per revision
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list", "create", "update", "delete"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets"}, Verbs: []string{"get", "update", "patch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale", "statefulsets/scale"}, Verbs: []string{"get", "update", "patch"}},
+	}
+	groupResources := map[string][]string{}
+	groups := []string{}
+	seen := map[schema.GroupResource]bool{{Group: "", Resource: "secrets"}: true, {Group: "apps", Resource: "deployments"}: true, {Group: "apps", Resource: "statefulsets"}: true}
+	for _, resource := range resources {
+		gvk := schema.FromAPIVersionAndKind(fmt.Sprintf("%v", resource["apiVersion"]), fmt.Sprintf("%v", resource["kind"]))
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		if seen[gvr.GroupResource()] {
+			continue
+		}
+		seen[gvr.GroupResource()] = true
+		if _, ok := groupResources[gvr.Group]; !ok {
+			groups = append(groups, gvr.Group)
+		}
+		groupResources[gvr.Group] = append(groupResources[gvr.Group], gvr.Resource)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		sort.Strings(groupResources[group])
+		rules = append(rules, rbacv1.PolicyRule{APIGroups: []string{group}, Resources: groupResources[group], Verbs: []string{"get"}})
+	}
+	return &rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{Kind: "Role", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Rules:      rules,
+	}
+}
+
+// GetScheduleRoleBinding returns the role binding of the schedule role to the schedule service account
+func GetScheduleRoleBinding(namespace string, name string, labels map[string]string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta:   metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: name, Namespace: namespace}},
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: name},
+	}
+}
+
+// GetScheduleCronJob returns the CronJob that runs the command in the synopsysctl image on the schedule
+func GetScheduleCronJob(namespace string, name string, labels map[string]string, schedule string, image string, serviceAccountName string, command []string) *batchv1beta1.CronJob {
+	successfulJobsHistoryLimit := int32(1)
+	failedJobsHistoryLimit := int32(3)
+	backoffLimit := int32(2)
+	return &batchv1beta1.CronJob{
+		TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   schedule,
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &successfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     &failedJobsHistoryLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							ServiceAccountName: serviceAccountName,
+							RestartPolicy:      corev1.RestartPolicyNever,
+							Containers: []corev1.Container{
+								{
+									Name:    "synopsysctl",
+									Image:   image,
+									Command: command,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// CreateOrUpdateScheduleServiceAccount creates the service account if it doesn't exist
+func CreateOrUpdateScheduleServiceAccount(clientset kubernetes.Interface, serviceAccount *corev1.ServiceAccount) error {
+	_, err := clientset.CoreV1().ServiceAccounts(serviceAccount.Namespace).Create(serviceAccount)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// CreateOrUpdateRole creates the role, or updates its rules if it already exists
+func CreateOrUpdateRole(clientset kubernetes.Interface, role *rbacv1.Role) error {
+	existing, err := GetRole(clientset, role.Namespace, role.Name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			_, err = clientset.RbacV1().Roles(role.Namespace).Create(role)
+		}
+		return err
+	}
+	existing.Labels = role.Labels
+	existing.Rules = role.Rules
+	_, err = UpdateRole(clientset, role.Namespace, existing)
+	return err
+}
+
+// CreateOrUpdateRoleBinding creates the role binding, or replaces it if it refers to another role
+func CreateOrUpdateRoleBinding(clientset kubernetes.Interface, roleBinding *rbacv1.RoleBinding) error {
+	existing, err := clientset.RbacV1().RoleBindings(roleBinding.Namespace).Get(roleBinding.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			_, err = clientset.RbacV1().RoleBindings(roleBinding.Namespace).Create(roleBinding)
+		}
+		return err
+	}
+	if existing.RoleRef == roleBinding.RoleRef {
+		return nil
+	}
+	// the role of a role binding can't be updated
+	if err := DeleteRoleBinding(clientset, roleBinding.Namespace, roleBinding.Name); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	_, err = clientset.RbacV1().RoleBindings(roleBinding.Namespace).Create(roleBinding)
+	return err
+}
+
+// ListCronJobs will get all the CronJobs corresponding to a namespace
+func ListCronJobs(clientset kubernetes.Interface, namespace string, labelSelector string) (*batchv1beta1.CronJobList, error) {
+	return clientset.BatchV1beta1().CronJobs(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+}
+
+// CreateOrUpdateCronJob creates the CronJob, or updates its spec if it already exists
+func CreateOrUpdateCronJob(clientset kubernetes.Interface, cronJob *batchv1beta1.CronJob) error {
+	existing, err := clientset.BatchV1beta1().CronJobs(cronJob.Namespace).Get(cronJob.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			_, err = clientset.BatchV1beta1().CronJobs(cronJob.Namespace).Create(cronJob)
+		}
+		return err
+	}
+	existing.Labels = cronJob.Labels
+	existing.Spec = cronJob.Spec
+	_, err = clientset.BatchV1beta1().CronJobs(cronJob.Namespace).Update(existing)
+	return err
+}
+
+// DeleteCronJobIfExists deletes the CronJob and its jobs if it exists
+func DeleteCronJobIfExists(clientset kubernetes.Interface, namespace string, name string) error {
+	propagationPolicy := metav1.DeletePropagationBackground
+	err := clientset.BatchV1beta1().CronJobs(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteScheduleRBAC deletes the service account, role and role binding of a schedule if they exist
+func DeleteScheduleRBAC(clientset kubernetes.Interface, namespace string, name string) error {
+	for _, deleteFunc := range []func(kubernetes.Interface, string, string) error{DeleteRoleBinding, DeleteRole, DeleteServiceAccount} {
+		if err := deleteFunc(clientset, namespace, name); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
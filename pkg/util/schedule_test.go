@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateCronSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, schedule := range []string{"0 20 * * 1-5", "*/15 7-19 * * mon-fri", "0 0 1,15 * *", "@daily"} {
+		assert.Nil(ValidateCronSchedule(schedule), schedule)
+	}
+	for _, schedule := range []string{"", "0 20 * *", "0 20 * * 1-5 2020", "0 20 * * ?", "@sometimes"} {
+		assert.NotNil(ValidateCronSchedule(schedule), schedule)
+	}
+}
+
+func TestGetScheduleCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"synopsysctl", "stop", "blackduck", "bd", "-n", "bd-ns", "--disable-chart-cache"}, GetScheduleCommand("stop", "blackduck", "bd", "bd-ns"))
+	assert.Equal([]string{"synopsysctl", "start", "bdba", "-n", "bdba", "--disable-chart-cache"}, GetScheduleCommand("start", "bdba", "", "bdba"))
+}
+
+func TestGetScheduleRole(t *testing.T) {
+	assert := assert.New(t)
+
+	resources := []map[string]interface{}{
+		{"apiVersion": "apps/v1", "kind": "Deployment"},
+		{"apiVersion": "apps/v1", "kind": "StatefulSet"},
+		{"apiVersion": "v1", "kind": "Service"},
+		{"apiVersion": "v1", "kind": "ConfigMap"},
+		{"apiVersion": "v1", "kind": "Service"},
+		{"apiVersion": "v1", "kind": "Secret"},
+		{"apiVersion": "networking.k8s.io/v1beta1", "kind": "Ingress"},
+	}
+	role := GetScheduleRole("bdba", "bdba-schedule", nil, resources)
+	assert.Equal([]rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list", "create", "update", "delete"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets"}, Verbs: []string{"get", "update", "patch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments/scale", "statefulsets/scale"}, Verbs: []string{"get", "update", "patch"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "services"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses"}, Verbs: []string{"get"}},
+	}, role.Rules)
+
+	// the rules of an existing role are replaced
+	clientset := fake.NewSimpleClientset(&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "bdba-schedule", Namespace: "bdba"}})
+	assert.Nil(CreateOrUpdateRole(clientset, role))
+	existing, err := clientset.RbacV1().Roles("bdba").Get("bdba-schedule", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(role.Rules, existing.Rules)
+}
+
+func TestCreateOrUpdateRoleBinding(t *testing.T) {
+	assert := assert.New(t)
+
+	clientset := fake.NewSimpleClientset(&rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "bdba-schedule", Namespace: "bdba"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "admin"},
+	})
+	assert.Nil(CreateOrUpdateRoleBinding(clientset, GetScheduleRoleBinding("bdba", "bdba-schedule", nil)))
+	roleBinding, err := clientset.RbacV1().RoleBindings("bdba").Get("bdba-schedule", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "bdba-schedule"}, roleBinding.RoleRef)
+	assert.Nil(CreateOrUpdateRoleBinding(clientset, GetScheduleRoleBinding("bdba", "bdba-schedule", nil)))
+}
+
+func TestSetStoppedReplicasAnnotation(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(map[string]string{StoppedReplicasAnnotation: "1"}, SetStoppedReplicasAnnotation(nil, nil))
+	assert.Equal(map[string]string{"app": "blackduck", StoppedReplicasAnnotation: "3"}, SetStoppedReplicasAnnotation(map[string]string{"app": "blackduck"}, IntToInt32(3)))
+	assert.Equal(map[string]string{StoppedReplicasAnnotation: "0"}, SetStoppedReplicasAnnotation(map[string]string{StoppedReplicasAnnotation: "2"}, IntToInt32(0)))
+}
+
+func TestGetStoppedReplicas(t *testing.T) {
+	assert := assert.New(t)
+
+	replicas, found, err := GetStoppedReplicas(nil)
+	assert.Nil(err)
+	assert.False(found)
+	assert.Nil(replicas)
+
+	replicas, found, err = GetStoppedReplicas(map[string]string{"app": "blackduck"})
+	assert.Nil(err)
+	assert.False(found)
+	assert.Nil(replicas)
+
+	replicas, found, err = GetStoppedReplicas(SetStoppedReplicasAnnotation(nil, IntToInt32(2)))
+	assert.Nil(err)
+	assert.True(found)
+	assert.Equal(int32(2), *replicas)
+
+	_, found, err = GetStoppedReplicas(map[string]string{StoppedReplicasAnnotation: "two"})
+	assert.NotNil(err)
+	assert.False(found)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// SecretFlagAnnotation marks the flags whose values are secrets
+const SecretFlagAnnotation = "synopsysctl_secret"
+
+// SecretFlagNeededAnnotation marks the secret flags that a command needs although they aren't required, because the
+// command only needs them for some values of its other flags and reports them as missing itself
+const SecretFlagNeededAnnotation = "synopsysctl_secret_needed"
+
+// secretFlagUsage is appended to the usage of the secret flags
+const secretFlagUsage = " (use @FILE to read it from a file, - to read it from stdin, env:VAR to read it from an environment variable, or vault:PATH#KEY or k8s:NAMESPACE/NAME#KEY to read it from a secret store)"
+
+// MarkFlagSecret marks the flag as a secret so that ResolveSecretFlags reads its value from a file, stdin, an
+// environment variable or a secret provider and the value is masked in the logs. The usage of the flag documents
+// these sources; the suffix goes before a trailing new line so that the flag groups of the help stay separated
+func MarkFlagSecret(flagset *pflag.FlagSet, name string) {
+	flag := flagset.Lookup(name)
+	if flag == nil {
+		return
+	}
+	flagset.SetAnnotation(name, SecretFlagAnnotation, []string{"true"})
+	usage := strings.TrimSuffix(flag.Usage, "\n")
+	if !strings.HasSuffix(usage, secretFlagUsage) {
+		flag.Usage = usage + secretFlagUsage + flag.Usage[len(usage):]
+	}
+}
+
+// MarkSecretFlagNeeded marks the secret flag as needed if each condition FLAG=VALUE matches the value of another flag,
+// e.g. "enable-offline-mode=false". Without conditions the flag is always needed. ResolveSecretFlags asks for the
+// needed secret flags that weren't set like it does for the required ones
+func MarkSecretFlagNeeded(flagset *pflag.FlagSet, name string, conditions ...string) {
+	if flagset.Lookup(name) == nil {
+		return
+	}
+	flagset.SetAnnotation(name, SecretFlagNeededAnnotation, conditions)
+}
+
+// IsSecretFlag returns true if the flag was marked as a secret
+func IsSecretFlag(flag *pflag.Flag) bool {
+	annotation, ok := flag.Annotations[SecretFlagAnnotation]
+	return ok && len(annotation) > 0 && annotation[0] == "true"
+}
+
+// ReadSecretValue returns the secret of a flag value. @FILE reads the secret from the file, - reads it from stdin,
+// env:VAR reads it from the environment variable and SCHEME:PATH#KEY reads it from the secret provider of the scheme.
+// Other values are the secret. The trailing new line of a file or stdin is removed
+func ReadSecretValue(value string, stdin io.Reader) (string, error) {
+	if secret, ok, err := ResolveSecretReference(value); ok {
+		return secret, err
+	}
+	switch {
+	case value == "-":
+		content, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the secret from stdin due to %+v", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case strings.HasPrefix(value, "@"):
+		content, err := ioutil.ReadFile(value[1:])
+		if err != nil {
+			return "", fmt.Errorf("failed to read the secret from file '%s' due to %+v", value[1:], err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case strings.HasPrefix(value, "env:"):
+		secret, ok := os.LookupEnv(value[len("env:"):])
+		if !ok {
+			return "", fmt.Errorf("environment variable '%s' isn't set", value[len("env:"):])
+		}
+		return secret, nil
+	}
+	return value, nil
+}
+
+// SecretPrompt asks the user for the value of a secret flag
+type SecretPrompt func(flag *pflag.Flag) (string, error)
+
+// ResolveSecretFlags replaces the values of the secret flags with their secrets and registers the secrets to be masked in the logs.
+// If prompt isn't nil, it is used to ask for the required and needed secret flags that weren't set
+func ResolveSecretFlags(flagset *pflag.FlagSet, stdin io.Reader, prompt SecretPrompt) error {
+	flags := []*pflag.Flag{}
+	flagset.VisitAll(func(flag *pflag.Flag) {
+		if IsSecretFlag(flag) {
+			flags = append(flags, flag)
+		}
+	})
+
+	stdinFlag := ""
+	for _, flag := range flags {
+		var secret string
+		var err error
+		switch {
+		case flag.Changed:
+			if flag.Value.String() == "-" {
+				if len(stdinFlag) > 0 {
+					return fmt.Errorf("flags '%s' and '%s' can't both be read from stdin", stdinFlag, flag.Name)
+				}
+				stdinFlag = flag.Name
+			}
+			secret, err = ReadSecretValue(flag.Value.String(), stdin)
+		case (isRequiredFlag(flag) || isNeededSecretFlag(flagset, flag)) && prompt != nil:
+			secret, err = prompt(flag)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value for flag '%s': %+v", flag.Name, err)
+		}
+		if err := flagset.Set(flag.Name, secret); err != nil {
+			return err
+		}
+		RegisterSecret(secret)
+	}
+	return nil
+}
+
+func isRequiredFlag(flag *pflag.Flag) bool {
+	required, ok := flag.Annotations[cobra.BashCompOneRequiredFlag]
+	return ok && len(required) > 0 && required[0] == "true"
+}
+
+// isNeededSecretFlag returns true if the flag was marked as needed and the values of the other flags match its conditions
+func isNeededSecretFlag(flagset *pflag.FlagSet, flag *pflag.Flag) bool {
+	conditions, ok := flag.Annotations[SecretFlagNeededAnnotation]
+	if !ok {
+		return false
+	}
+	for _, condition := range conditions {
+		name, value := condition, ""
+		if i := strings.Index(condition, "="); i >= 0 {
+			name, value = condition[:i], condition[i+1:]
+		}
+		other := flagset.Lookup(name)
+		if other == nil || other.Value.String() != value {
+			return false
+		}
+	}
+	return true
+}
+
+// NewTerminalSecretPrompt returns a prompt that reads the secrets without echo from the terminal, or nil if stdin isn't a terminal
+func NewTerminalSecretPrompt() SecretPrompt {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	return func(flag *pflag.Flag) (string, error) {
+		fmt.Fprintf(os.Stderr, "Enter %s: ", flag.Name)
+		secret, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		return string(secret), nil
+	}
+}
+
+// secrets are the values that are masked in the logs
+var secrets = struct {
+	sync.RWMutex
+	values map[string]bool
+}{values: map[string]bool{}}
+
+// RegisterSecret masks the value in the logs. Secrets of any length are masked, even if a short one makes the logs
+// harder to read, and only the empty value is skipped
+func RegisterSecret(value string) {
+	if len(value) == 0 {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	secrets.values[value] = true
+}
+
+// MaskSecrets replaces the registered secrets in the text with RedactedValue
+func MaskSecrets(text string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	// replace the longest secrets first in case a secret contains another one
+	values := []string{}
+	for value := range secrets.values {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		text = strings.Replace(text, value, RedactedValue, -1)
+	}
+	return text
+}
+
+// SecretMaskingHook is a logrus hook that masks the registered secrets in the log messages and fields
+type SecretMaskingHook struct{}
+
+// Levels returns all the log levels
+func (h *SecretMaskingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire masks the secrets of the log entry
+func (h *SecretMaskingHook) Fire(entry *logrus.Entry) error {
+	entry.Message = MaskSecrets(entry.Message)
+	for key, value := range entry.Data {
+		if text := fmt.Sprintf("%v", value); MaskSecrets(text) != text {
+			entry.Data[key] = MaskSecrets(text)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSecretValue(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "synopsysctl-secret")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	secretFile := filepath.Join(dir, "secret")
+	assert.Nil(ioutil.WriteFile(secretFile, []byte("fromfile\n"), 0600))
+	os.Setenv("SYNOPSYSCTL_TEST_SECRET", "fromenv")
+	defer os.Unsetenv("SYNOPSYSCTL_TEST_SECRET")
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "plain", expected: "plain"},
+		{value: "@" + secretFile, expected: "fromfile"},
+		{value: "-", expected: "fromstdin"},
+		{value: "env:SYNOPSYSCTL_TEST_SECRET", expected: "fromenv"},
+	}
+	for _, test := range tests {
+		secret, err := ReadSecretValue(test.value, strings.NewReader("fromstdin\n"))
+		assert.Nil(err)
+		assert.Equal(test.expected, secret)
+	}
+
+	_, err = ReadSecretValue("@"+filepath.Join(dir, "missing"), nil)
+	assert.NotNil(err)
+	_, err = ReadSecretValue("env:SYNOPSYSCTL_TEST_MISSING", nil)
+	assert.NotNil(err)
+}
+
+func TestResolveSecretFlags(t *testing.T) {
+	assert := assert.New(t)
+
+	newFlagSet := func() *pflag.FlagSet {
+		flagset := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flagset.String("admin-password", "", "")
+		flagset.String("user-password", "", "")
+		flagset.String("seal-key", "", "Seal key\n")
+		flagset.String("name", "", "")
+		MarkFlagSecret(flagset, "admin-password")
+		MarkFlagSecret(flagset, "user-password")
+		MarkFlagSecret(flagset, "seal-key")
+		cobra.MarkFlagRequired(flagset, "seal-key")
+		return flagset
+	}
+
+	flagset := newFlagSet()
+	assert.Nil(flagset.Parse([]string{"--admin-password", "-", "--name", "-"}))
+	prompted := []string{}
+	err := ResolveSecretFlags(flagset, strings.NewReader("secret1\n"), func(flag *pflag.Flag) (string, error) {
+		prompted = append(prompted, flag.Name)
+		return "secret2", nil
+	})
+	assert.Nil(err)
+	assert.Equal("secret1", flagset.Lookup("admin-password").Value.String())
+	assert.Equal("", flagset.Lookup("user-password").Value.String())
+	assert.Equal("secret2", flagset.Lookup("seal-key").Value.String())
+	assert.Equal("-", flagset.Lookup("name").Value.String())
+	assert.Equal([]string{"seal-key"}, prompted)
+	assert.True(strings.HasSuffix(flagset.Lookup("admin-password").Usage, secretFlagUsage))
+	assert.Equal("Seal key"+secretFlagUsage+"\n", flagset.Lookup("seal-key").Usage)
+
+	// the needed secret flags are asked for if the other flags match their conditions
+	newNeededFlagSet := func() *pflag.FlagSet {
+		flagset := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flagset.Bool("enable-offline-mode", false, "")
+		flagset.Bool("external-postgres", false, "")
+		flagset.String("postgres-secret", "", "")
+		flagset.String("license-password", "", "")
+		flagset.String("postgres-password", "default", "")
+		MarkFlagSecret(flagset, "license-password")
+		MarkFlagSecret(flagset, "postgres-password")
+		MarkSecretFlagNeeded(flagset, "license-password", "enable-offline-mode=false")
+		MarkSecretFlagNeeded(flagset, "postgres-password", "external-postgres=false", "postgres-secret=")
+		MarkSecretFlagNeeded(flagset, "missing")
+		return flagset
+	}
+	for _, test := range []struct {
+		args     []string
+		expected []string
+	}{
+		{args: []string{}, expected: []string{"license-password", "postgres-password"}},
+		{args: []string{"--enable-offline-mode", "--postgres-secret", "postgres"}, expected: []string{}},
+		{args: []string{"--license-password", "license", "--external-postgres"}, expected: []string{}},
+	} {
+		flagset = newNeededFlagSet()
+		assert.Nil(flagset.Parse(test.args))
+		prompted = []string{}
+		assert.Nil(ResolveSecretFlags(flagset, strings.NewReader(""), func(flag *pflag.Flag) (string, error) {
+			prompted = append(prompted, flag.Name)
+			return "secret", nil
+		}))
+		assert.Equal(test.expected, prompted, test.args)
+	}
+	// without a prompt the needed secret flags are left to the command
+	flagset = newNeededFlagSet()
+	assert.Nil(ResolveSecretFlags(flagset, strings.NewReader(""), nil))
+	assert.Equal("", flagset.Lookup("license-password").Value.String())
+
+	// only one flag can be read from stdin
+	flagset = newFlagSet()
+	assert.Nil(flagset.Parse([]string{"--admin-password", "-", "--user-password", "-"}))
+	assert.NotNil(ResolveSecretFlags(flagset, strings.NewReader("secret\n"), nil))
+}
+
+func TestSecretMaskingHook(t *testing.T) {
+	assert := assert.New(t)
+
+	RegisterSecret("supersecret")
+	var output bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &output
+	logger.AddHook(&SecretMaskingHook{})
+	logger.WithField("password", "supersecret").Infof("using password '%s'", "supersecret")
+
+	assert.NotContains(output.String(), "supersecret")
+	assert.Contains(output.String(), RedactedValue)
+
+	// short secrets are masked too, but not the empty value
+	RegisterSecret("xq")
+	RegisterSecret("")
+	assert.Equal("a "+RedactedValue+" flag", MaskSecrets("a xq flag"))
+}
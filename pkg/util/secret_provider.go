@@ -0,0 +1,249 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SecretProvider reads secrets from a secret store
+type SecretProvider interface {
+	// GetSecret returns the value of the key of the secret at the path
+	GetSecret(path string, key string) (string, error)
+}
+
+// secretProviders are the secret providers by the scheme of their references
+var secretProviders = struct {
+	sync.RWMutex
+	providers map[string]SecretProvider
+}{providers: map[string]SecretProvider{}}
+
+// secretProviderSchemes are the schemes of the secret providers that synopsysctl registers. A reference of one of these schemes is never
+// used as the secret, even if its provider isn't registered, e.g. k8s references of commands that don't access the cluster
+var secretProviderSchemes = []string{"vault", "k8s"}
+
+// RegisterSecretProvider resolves the secret references of the scheme, e.g. vault:secret/data/bd#adminPassword, with the provider
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProviders.Lock()
+	defer secretProviders.Unlock()
+	secretProviders.providers[scheme] = provider
+}
+
+// getSecretProvider returns the provider of a reference SCHEME:PATH#KEY and the path and key of the secret. The fourth return
+// value is false if the value isn't a reference of a secret provider
+func getSecretProvider(reference string) (SecretProvider, string, string, bool, error) {
+	i := strings.Index(reference, ":")
+	if i < 0 {
+		return nil, "", "", false, nil
+	}
+	scheme := reference[:i]
+	secretProviders.RLock()
+	provider, ok := secretProviders.providers[scheme]
+	secretProviders.RUnlock()
+	if !ok {
+		for _, s := range secretProviderSchemes {
+			if s == scheme {
+				return nil, "", "", true, fmt.Errorf("secret reference '%s' can't be resolved because the '%s' secret provider isn't available for this command", reference, scheme)
+			}
+		}
+		return nil, "", "", false, nil
+	}
+	path, key := reference[i+1:], ""
+	if j := strings.LastIndex(path, "#"); j >= 0 {
+		path, key = path[:j], path[j+1:]
+	}
+	return provider, path, key, true, nil
+}
+
+// ResolveSecretReference returns the secret of a reference such as vault:secret/data/bd#adminPassword. The second return
+// value is false if the value isn't a reference of a secret provider
+func ResolveSecretReference(reference string) (string, bool, error) {
+	provider, path, key, ok, err := getSecretProvider(reference)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+	if len(path) == 0 || len(key) == 0 {
+		return "", true, fmt.Errorf("secret reference '%s' must have the format SCHEME:PATH#KEY", reference)
+	}
+	secret, err := provider.GetSecret(path, key)
+	if err != nil {
+		return "", true, fmt.Errorf("failed to get key '%s' of secret '%s' due to %+v", key, path, err)
+	}
+	return secret, true, nil
+}
+
+// VaultSecretProvider reads secrets from a HashiCorp Vault KV secrets engine, e.g. vault:secret/data/bd#adminPassword
+type VaultSecretProvider struct {
+	client *http.Client
+	// Address is the address of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token is the token to authenticate with Vault
+	Token string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+	// CACert is the path of a PEM file with the CA certificates that the certificate of the Vault server is verified with, if any
+	CACert string
+	// SkipVerify is true if the certificate of the Vault server isn't verified
+	SkipVerify bool
+}
+
+// NewVaultSecretProviderFromEnv returns a Vault secret provider configured with the VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE,
+// VAULT_CACERT and VAULT_SKIP_VERIFY environment variables
+func NewVaultSecretProviderFromEnv() *VaultSecretProvider {
+	// like the Vault CLI, any value of VAULT_SKIP_VERIFY other than a false boolean skips the verification
+	skipVerify := false
+	if value := os.Getenv("VAULT_SKIP_VERIFY"); len(value) > 0 {
+		if parsed, err := strconv.ParseBool(value); err != nil || parsed {
+			skipVerify = true
+		}
+	}
+	return &VaultSecretProvider{
+		Address:    os.Getenv("VAULT_ADDR"),
+		Token:      os.Getenv("VAULT_TOKEN"),
+		Namespace:  os.Getenv("VAULT_NAMESPACE"),
+		CACert:     os.Getenv("VAULT_CACERT"),
+		SkipVerify: skipVerify,
+	}
+}
+
+// getClient returns the HTTP client that trusts the CA certificates of the provider
+func (v *VaultSecretProvider) getClient() (*http.Client, error) {
+	if v.client != nil {
+		return v.client, nil
+	}
+	if len(v.CACert) == 0 && !v.SkipVerify {
+		return http.DefaultClient, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: v.SkipVerify}
+	if len(v.CACert) > 0 {
+		caCert, err := ioutil.ReadFile(v.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the Vault CA certificate '%s' due to %+v", v.CACert, err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("the Vault CA certificate '%s' doesn't have a PEM certificate", v.CACert)
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	v.client = &http.Client{Transport: transport}
+	return v.client, nil
+}
+
+// GetSecret returns the value of the key of the secret at the path. The path of a KV version 2 secret includes the data
+// prefix, e.g. secret/data/bd
+func (v *VaultSecretProvider) GetSecret(path string, key string) (string, error) {
+	if len(v.Address) == 0 {
+		return "", fmt.Errorf("the address of Vault isn't set, set the VAULT_ADDR environment variable")
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(v.Address, "/"), strings.TrimPrefix(path, "/")), nil)
+	if err != nil {
+		return "", err
+	}
+	if len(v.Token) > 0 {
+		req.Header.Set("X-Vault-Token", v.Token)
+	}
+	if len(v.Namespace) > 0 {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+	client, err := v.getClient()
+	if err != nil {
+		return "", err
+	}
+	response, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read secret '%s' from Vault | %s", path, response.Status)
+	}
+
+	// KV version 2 nests the secret in data.data, KV version 1 returns it in data
+	secret := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to parse secret '%s' from Vault due to %+v", path, err)
+	}
+	data := secret.Data
+	if nested, ok := secret.Data["data"].(map[string]interface{}); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			data = nested
+		}
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("secret '%s' doesn't have key '%s'", path, key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+// KubernetesSecretProvider reads secrets from Kubernetes secrets, e.g. k8s:namespace/name#key
+type KubernetesSecretProvider struct {
+	clientset kubernetes.Interface
+	// defaultNamespace is the namespace of the secrets whose path doesn't have a namespace
+	defaultNamespace string
+}
+
+// NewKubernetesSecretProvider returns a Kubernetes secret provider
+func NewKubernetesSecretProvider(clientset kubernetes.Interface, defaultNamespace string) *KubernetesSecretProvider {
+	return &KubernetesSecretProvider{clientset: clientset, defaultNamespace: defaultNamespace}
+}
+
+// GetSecret returns the value of the key of the secret at the path NAMESPACE/NAME or NAME
+func (k *KubernetesSecretProvider) GetSecret(path string, key string) (string, error) {
+	namespace, name := k.defaultNamespace, path
+	if i := strings.Index(path, "/"); i >= 0 {
+		namespace, name = path[:i], path[i+1:]
+	}
+	if len(namespace) == 0 {
+		return "", fmt.Errorf("secret '%s' must have a namespace", path)
+	}
+	secret, err := k.clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if value, ok := secret.Data[key]; ok {
+		return string(value), nil
+	}
+	if value, ok := secret.StringData[key]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("secret '%s' in namespace '%s' doesn't have key '%s'", name, namespace, key)
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestVault is an HTTP stand-in for a Vault server
+func newTestVault(token string) *httptest.Server {
+	return httptest.NewServer(newTestVaultHandler(token))
+}
+
+// newTestVaultHandler serves a KV version 2 engine at secret/ and a KV version 1 engine at kv/
+func newTestVaultHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/bd":
+			w.Write([]byte(`{"data":{"data":{"adminPassword":"admin-secret","port":5432},"metadata":{"version":1}}}`))
+		case "/v1/kv/bd":
+			w.Write([]byte(`{"data":{"adminPassword":"kv1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newTestVault("token")
+	defer server.Close()
+	provider := &VaultSecretProvider{Address: server.URL, Token: "token"}
+
+	secret, err := provider.GetSecret("secret/data/bd", "adminPassword")
+	assert.Nil(err)
+	assert.Equal("admin-secret", secret)
+	secret, err = provider.GetSecret("secret/data/bd", "port")
+	assert.Nil(err)
+	assert.Equal("5432", secret)
+	secret, err = provider.GetSecret("kv/bd", "adminPassword")
+	assert.Nil(err)
+	assert.Equal("kv1-secret", secret)
+
+	_, err = provider.GetSecret("secret/data/bd", "missing")
+	assert.NotNil(err)
+	_, err = provider.GetSecret("secret/data/missing", "adminPassword")
+	assert.NotNil(err)
+	_, err = (&VaultSecretProvider{Address: server.URL, Token: "wrong"}).GetSecret("secret/data/bd", "adminPassword")
+	assert.NotNil(err)
+}
+
+func TestKubernetesSecretProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "bd-credentials", Namespace: "bd"},
+		Data:       map[string][]byte{"adminPassword": []byte("k8s-secret")},
+	})
+	provider := NewKubernetesSecretProvider(clientset, "bd")
+
+	secret, err := provider.GetSecret("bd/bd-credentials", "adminPassword")
+	assert.Nil(err)
+	assert.Equal("k8s-secret", secret)
+	secret, err = provider.GetSecret("bd-credentials", "adminPassword")
+	assert.Nil(err)
+	assert.Equal("k8s-secret", secret)
+
+	_, err = provider.GetSecret("bd/bd-credentials", "missing")
+	assert.NotNil(err)
+	_, err = provider.GetSecret("other/bd-credentials", "adminPassword")
+	assert.NotNil(err)
+}
+
+func TestReadSecretValueFromProvider(t *testing.T) {
+	assert := assert.New(t)
+
+	server := newTestVault("token")
+	defer server.Close()
+	RegisterSecretProvider("vault", &VaultSecretProvider{Address: server.URL, Token: "token"})
+
+	secret, err := ReadSecretValue("vault:secret/data/bd#adminPassword", nil)
+	assert.Nil(err)
+	assert.Equal("admin-secret", secret)
+
+	_, err = ReadSecretValue("vault:secret/data/bd", nil)
+	assert.NotNil(err)
+
+	// values of unknown schemes are secrets
+	secret, err = ReadSecretValue("unknown:secret/data/bd#adminPassword", nil)
+	assert.Nil(err)
+	assert.Equal("unknown:secret/data/bd#adminPassword", secret)
+
+	// references of known schemes whose provider isn't registered aren't used as the secret
+	_, err = ReadSecretValue("k8s:bd/bd-credentials#adminPassword", nil)
+	assert.NotNil(err)
+}
+
+func TestVaultSecretProviderTLS(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewTLSServer(newTestVaultHandler("token"))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "synopsysctl-vault")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	caCert := filepath.Join(dir, "ca.crt")
+	assert.Nil(ioutil.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	os.Setenv("VAULT_ADDR", server.URL)
+	os.Setenv("VAULT_TOKEN", "token")
+	defer os.Unsetenv("VAULT_ADDR")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	// the certificate of the server isn't trusted by default
+	_, err = NewVaultSecretProviderFromEnv().GetSecret("secret/data/bd", "adminPassword")
+	assert.NotNil(err)
+
+	os.Setenv("VAULT_CACERT", caCert)
+	secret, err := NewVaultSecretProviderFromEnv().GetSecret("secret/data/bd", "adminPassword")
+	assert.Nil(err)
+	assert.Equal("admin-secret", secret)
+	os.Unsetenv("VAULT_CACERT")
+
+	os.Setenv("VAULT_SKIP_VERIFY", "true")
+	defer os.Unsetenv("VAULT_SKIP_VERIFY")
+	secret, err = NewVaultSecretProviderFromEnv().GetSecret("secret/data/bd", "adminPassword")
+	assert.Nil(err)
+	assert.Equal("admin-secret", secret)
+}
@@ -0,0 +1,439 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	alertfake "github.com/blackducksoftware/synopsysctl/pkg/alert/client/clientset/versioned/fake"
+	alertscheme "github.com/blackducksoftware/synopsysctl/pkg/alert/client/clientset/versioned/scheme"
+	blackduckfake "github.com/blackducksoftware/synopsysctl/pkg/blackduck/client/clientset/versioned/fake"
+	blackduckscheme "github.com/blackducksoftware/synopsysctl/pkg/blackduck/client/clientset/versioned/scheme"
+	opssightfake "github.com/blackducksoftware/synopsysctl/pkg/opssight/client/clientset/versioned/fake"
+	opssightscheme "github.com/blackducksoftware/synopsysctl/pkg/opssight/client/clientset/versioned/scheme"
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	helmkubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apiextensionsscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// SimulatedClusterHost is the host of the rest config of a simulated cluster. It never resolves, so the calls that
+// don't go through the fake clientsets fail instead of reaching a real cluster
+const SimulatedClusterHost = "https://simulated-cluster.invalid"
+
+// Simulation is the simulated cluster that the Helm actions of the package functions use instead of the cluster of the
+// kubeconfig. If it is nil, the cluster of the kubeconfig is used
+var Simulation *SimulatedCluster
+
+// SimulatedCluster is an in-memory cluster that the commands can run against instead of a real one. The Kubernetes and
+// custom resources are kept in fake clientsets and the Helm releases in Helm's in-memory storage driver. Nothing runs in
+// the cluster, so the objects of a release are only created from its manifest and its pods never start
+type SimulatedCluster struct {
+	// Path is the file that the state of the cluster is loaded from and saved to
+	Path               string
+	KubeClient         *kubefake.Clientset
+	APIExtensionClient *apiextensionsfake.Clientset
+	AlertClient        *alertfake.Clientset
+	BlackDuckClient    *blackduckfake.Clientset
+	OpsSightClient     *opssightfake.Clientset
+	objectStores       []*simulatedObjectStore
+	// releases holds the releases of all the namespaces. It reads and writes the releases of the namespace that was
+	// set last, so it's only used through a simulatedReleaseDriver or while releasesLock is held
+	releases     *driver.Memory
+	releasesLock sync.Mutex
+}
+
+// simulatedClusterState is the content of the file of a simulated cluster
+type simulatedClusterState struct {
+	Releases []*release.Release `json:"releases"`
+	Objects  []json.RawMessage  `json:"objects"`
+}
+
+// simulatedObjectStore is the object tracker of a fake clientset and the scheme of the objects it accepts
+type simulatedObjectStore struct {
+	scheme  *runtime.Scheme
+	tracker k8stesting.ObjectTracker
+}
+
+// simulatedClusterScopedKinds are the kinds in a manifest that don't get the namespace of the release
+var simulatedClusterScopedKinds = map[string]bool{
+	"ClusterRole":              true,
+	"ClusterRoleBinding":       true,
+	"CustomResourceDefinition": true,
+	"Namespace":                true,
+	"PersistentVolume":         true,
+	"StorageClass":             true,
+}
+
+// NewSimulatedCluster returns the simulated cluster saved in the file, or an empty one if the file doesn't exist
+func NewSimulatedCluster(path string) (*SimulatedCluster, error) {
+	s := &SimulatedCluster{
+		Path:               path,
+		KubeClient:         kubefake.NewSimpleClientset(),
+		APIExtensionClient: apiextensionsfake.NewSimpleClientset(),
+		AlertClient:        alertfake.NewSimpleClientset(),
+		BlackDuckClient:    blackduckfake.NewSimpleClientset(),
+		OpsSightClient:     opssightfake.NewSimpleClientset(),
+		releases:           driver.NewMemory(),
+	}
+	s.objectStores = []*simulatedObjectStore{
+		newSimulatedObjectStore(kubescheme.Scheme, &s.KubeClient.Fake),
+		newSimulatedObjectStore(apiextensionsscheme.Scheme, &s.APIExtensionClient.Fake),
+		newSimulatedObjectStore(alertscheme.Scheme, &s.AlertClient.Fake),
+		newSimulatedObjectStore(blackduckscheme.Scheme, &s.BlackDuckClient.Fake),
+		newSimulatedObjectStore(opssightscheme.Scheme, &s.OpsSightClient.Fake),
+	}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the simulated cluster from '%s' due to %+v", path, err)
+	}
+	state := &simulatedClusterState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse the simulated cluster in '%s' due to %+v", path, err)
+	}
+	for _, helmRelease := range state.Releases {
+		key := fmt.Sprintf("sh.helm.release.v1.%s.v%d", helmRelease.Name, helmRelease.Version)
+		if err := s.releases.Create(key, helmRelease); err != nil {
+			return nil, fmt.Errorf("failed to load revision %d of release '%s' of the simulated cluster due to %+v", helmRelease.Version, helmRelease.Name, err)
+		}
+	}
+	for _, raw := range state.Objects {
+		store, obj, gvk, err := s.decodeObject(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load an object of the simulated cluster due to %+v", err)
+		}
+		if store == nil {
+			return nil, fmt.Errorf("failed to load an object of the simulated cluster due to the unknown kind %s", gvk)
+		}
+		if err := store.apply(obj, gvk); err != nil {
+			return nil, fmt.Errorf("failed to load %s of the simulated cluster due to %+v", gvk, err)
+		}
+	}
+	return s, nil
+}
+
+// Save writes the releases and objects of the simulated cluster to its file
+func (s *SimulatedCluster) Save() error {
+	releases, err := (&simulatedReleaseDriver{cluster: s}).List(func(*release.Release) bool { return true })
+	if err != nil {
+		return fmt.Errorf("failed to list the releases of the simulated cluster due to %+v", err)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Namespace != releases[j].Namespace {
+			return releases[i].Namespace < releases[j].Namespace
+		}
+		if releases[i].Name != releases[j].Name {
+			return releases[i].Name < releases[j].Name
+		}
+		return releases[i].Version < releases[j].Version
+	})
+	state := &simulatedClusterState{Releases: []*release.Release{}, Objects: []json.RawMessage{}}
+	state.Releases = append(state.Releases, releases...)
+	for _, store := range s.objectStores {
+		objects, err := store.list()
+		if err != nil {
+			return fmt.Errorf("failed to list the objects of the simulated cluster due to %+v", err)
+		}
+		state.Objects = append(state.Objects, objects...)
+	}
+
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to convert the simulated cluster to json due to %+v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of '%s' due to %+v", s.Path, err)
+	}
+	if err := ioutil.WriteFile(s.Path, content, 0600); err != nil {
+		return fmt.Errorf("failed to save the simulated cluster to '%s' due to %+v", s.Path, err)
+	}
+	return nil
+}
+
+// HelmActionConfiguration returns an action.Configuration that keeps the releases of the namespace in the memory of the
+// simulated cluster. The Kubernetes client of the configuration doesn't do anything, ApplyReleaseManifests creates the
+// objects of a release
+func (s *SimulatedCluster) HelmActionConfiguration(namespace string) *action.Configuration {
+	return &action.Configuration{
+		Releases:     storage.Init(&simulatedReleaseDriver{cluster: s, namespace: namespace}),
+		KubeClient:   &helmkubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(format string, v ...interface{}) { log.Tracef(format, v...) },
+	}
+}
+
+// simulatedReleaseDriver is the Helm storage driver of the releases of a namespace of a simulated cluster. Every call
+// selects the namespace in the in-memory driver of the cluster while holding its lock, so the action configurations of
+// different namespaces can be used at the same time. The releases of all the namespaces are listed if namespace is empty
+type simulatedReleaseDriver struct {
+	cluster   *SimulatedCluster
+	namespace string
+}
+
+// memory locks the in-memory driver of the cluster and selects the namespace of the driver. The returned function
+// releases the lock
+func (d *simulatedReleaseDriver) memory() (*driver.Memory, func()) {
+	d.cluster.releasesLock.Lock()
+	d.cluster.releases.SetNamespace(d.namespace)
+	return d.cluster.releases, d.cluster.releasesLock.Unlock
+}
+
+// Name returns the name of the in-memory driver
+func (d *simulatedReleaseDriver) Name() string {
+	return d.cluster.releases.Name()
+}
+
+// Get returns the release of the key in the namespace
+func (d *simulatedReleaseDriver) Get(key string) (*release.Release, error) {
+	memory, unlock := d.memory()
+	defer unlock()
+	return memory.Get(key)
+}
+
+// List returns the releases in the namespace that pass the filter
+func (d *simulatedReleaseDriver) List(filter func(*release.Release) bool) ([]*release.Release, error) {
+	memory, unlock := d.memory()
+	defer unlock()
+	return memory.List(filter)
+}
+
+// Query returns the releases in the namespace that match the labels
+func (d *simulatedReleaseDriver) Query(labels map[string]string) ([]*release.Release, error) {
+	memory, unlock := d.memory()
+	defer unlock()
+	return memory.Query(labels)
+}
+
+// Create stores a new release
+func (d *simulatedReleaseDriver) Create(key string, rls *release.Release) error {
+	memory, unlock := d.memory()
+	defer unlock()
+	return memory.Create(key, rls)
+}
+
+// Update replaces a stored release
+func (d *simulatedReleaseDriver) Update(key string, rls *release.Release) error {
+	memory, unlock := d.memory()
+	defer unlock()
+	return memory.Update(key, rls)
+}
+
+// Delete removes the release of the key in the namespace
+func (d *simulatedReleaseDriver) Delete(key string) (*release.Release, error) {
+	memory, unlock := d.memory()
+	defer unlock()
+	return memory.Delete(key)
+}
+
+// ApplyReleaseManifests updates the objects of the simulated cluster from the manifest of the previous revision of a
+// release to the manifest of the current one. The objects that are only in the previous manifest are deleted, and the
+// objects of kinds that the fake clientsets don't know about, e.g. routes, are skipped
+func (s *SimulatedCluster) ApplyReleaseManifests(namespace string, previousManifest string, manifest string) error {
+	current := make(map[string]bool)
+	for _, m := range releaseutil.SplitManifests(manifest) {
+		store, obj, gvk, err := s.decodeManifestObject(namespace, m)
+		if err != nil {
+			return err
+		}
+		if store == nil {
+			continue
+		}
+		current[getSimulatedObjectKey(obj, gvk)] = true
+		if err := store.apply(obj, gvk); err != nil {
+			return fmt.Errorf("failed to apply %s to the simulated cluster due to %+v", gvk, err)
+		}
+	}
+	for _, m := range releaseutil.SplitManifests(previousManifest) {
+		store, obj, gvk, err := s.decodeManifestObject(namespace, m)
+		if err != nil {
+			return err
+		}
+		if store == nil || current[getSimulatedObjectKey(obj, gvk)] {
+			continue
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		if err := store.tracker.Delete(getSimulatedResource(gvk), accessor.GetNamespace(), accessor.GetName()); err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete %s '%s' from the simulated cluster due to %+v", gvk.Kind, accessor.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// newSimulatedObjectStore returns an object store for the objects of the scheme and makes the fake clientset use it
+func newSimulatedObjectStore(scheme *runtime.Scheme, fake *k8stesting.Fake) *simulatedObjectStore {
+	tracker := k8stesting.NewObjectTracker(scheme, serializer.NewCodecFactory(scheme).UniversalDecoder())
+	fake.PrependReactor("*", "*", k8stesting.ObjectReaction(tracker))
+	fake.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := tracker.Watch(action.GetResource(), action.GetNamespace())
+		if err != nil {
+			return false, nil, err
+		}
+		return true, w, nil
+	})
+	return &simulatedObjectStore{scheme: scheme, tracker: tracker}
+}
+
+// decodeObject decodes a json or yaml object with the scheme of the fake clientset that accepts its kind. The store is
+// nil if none of the fake clientsets accepts it
+func (s *SimulatedCluster) decodeObject(raw []byte) (*simulatedObjectStore, runtime.Object, schema.GroupVersionKind, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, nil, schema.GroupVersionKind{}, err
+	}
+	gvk := schema.FromAPIVersionAndKind(typeMeta.APIVersion, typeMeta.Kind)
+	for _, store := range s.objectStores {
+		if !store.scheme.Recognizes(gvk) {
+			continue
+		}
+		obj, _, err := serializer.NewCodecFactory(store.scheme).UniversalDeserializer().Decode(raw, nil, nil)
+		if err != nil {
+			return nil, nil, gvk, err
+		}
+		return store, obj, gvk, nil
+	}
+	return nil, nil, gvk, nil
+}
+
+// decodeManifestObject decodes an object of the manifest of a release and sets the namespace of the release if it's a
+// namespaced object without a namespace
+func (s *SimulatedCluster) decodeManifestObject(namespace string, manifest string) (*simulatedObjectStore, runtime.Object, schema.GroupVersionKind, error) {
+	store, obj, gvk, err := s.decodeObject([]byte(manifest))
+	if err != nil {
+		return nil, nil, gvk, fmt.Errorf("failed to parse the manifest due to %+v", err)
+	}
+	if store == nil {
+		if len(gvk.Kind) > 0 {
+			log.Debugf("skipping %s in the simulated cluster", gvk)
+		}
+		return nil, nil, gvk, nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, gvk, err
+	}
+	if len(accessor.GetNamespace()) == 0 && !simulatedClusterScopedKinds[gvk.Kind] {
+		accessor.SetNamespace(namespace)
+	}
+	return store, obj, gvk, nil
+}
+
+// apply creates the object in the object store, or updates it if it already exists
+func (store *simulatedObjectStore) apply(obj runtime.Object, gvk schema.GroupVersionKind) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	gvr := getSimulatedResource(gvk)
+	err = store.tracker.Create(gvr, obj, accessor.GetNamespace())
+	if k8serrors.IsAlreadyExists(err) {
+		err = store.tracker.Update(gvr, obj, accessor.GetNamespace())
+	}
+	return err
+}
+
+// list returns the objects in the object store as json with their apiVersion and kind, sorted by kind, namespace and name
+func (store *simulatedObjectStore) list() ([]json.RawMessage, error) {
+	gvks := []schema.GroupVersionKind{}
+	for gvk := range store.scheme.AllKnownTypes() {
+		if gvk.Version == runtime.APIVersionInternal || strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+		if list, err := store.scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List")); err != nil || !meta.IsListType(list) {
+			continue
+		}
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+
+	objects := []json.RawMessage{}
+	for _, gvk := range gvks {
+		list, err := store.tracker.List(getSimulatedResource(gvk), gvk, metav1.NamespaceAll)
+		if err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return getSimulatedObjectKey(items[i], gvk) < getSimulatedObjectKey(items[j], gvk)
+		})
+		for _, item := range items {
+			item = item.DeepCopyObject()
+			item.GetObjectKind().SetGroupVersionKind(gvk)
+			raw, err := json.Marshal(item)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, raw)
+		}
+	}
+	return objects, nil
+}
+
+// getSimulatedResource returns the resource that the fake clientsets keep the objects of a kind in
+func getSimulatedResource(gvk schema.GroupVersionKind) schema.GroupVersionResource {
+	if gvk.Kind == "Endpoints" {
+		return gvk.GroupVersion().WithResource("endpoints")
+	}
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+	return gvr
+}
+
+// getSimulatedObjectKey returns a key that identifies an object in the simulated cluster
+func getSimulatedObjectKey(obj runtime.Object, gvk schema.GroupVersionKind) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return gvk.String()
+	}
+	return fmt.Sprintf("%s/%s/%s", gvk, accessor.GetNamespace(), accessor.GetName())
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const simulatedManifest = `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bd-config
+data:
+  KEY: value
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: bd-secret
+`
+
+func TestSimulatedClusterApplyReleaseManifests(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewSimulatedCluster(filepath.Join(os.TempDir(), "synopsysctl-simulate-missing", "simulate.json"))
+	assert.Nil(err)
+
+	assert.Nil(s.ApplyReleaseManifests("ns", "", simulatedManifest))
+	cm, err := s.KubeClient.CoreV1().ConfigMaps("ns").Get("bd-config", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal("value", cm.Data["KEY"])
+	_, err = s.KubeClient.CoreV1().Secrets("ns").Get("bd-secret", metav1.GetOptions{})
+	assert.Nil(err)
+
+	// the secret isn't in the new manifest, so it's deleted
+	manifest := "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: bd-config\ndata:\n  KEY: updated\n"
+	assert.Nil(s.ApplyReleaseManifests("ns", simulatedManifest, manifest))
+	cm, err = s.KubeClient.CoreV1().ConfigMaps("ns").Get("bd-config", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal("updated", cm.Data["KEY"])
+	_, err = s.KubeClient.CoreV1().Secrets("ns").Get("bd-secret", metav1.GetOptions{})
+	assert.NotNil(err)
+}
+
+func TestSimulatedClusterSave(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "synopsysctl-simulate")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state", "simulate.json")
+
+	s, err := NewSimulatedCluster(path)
+	assert.Nil(err)
+	assert.Nil(s.ApplyReleaseManifests("ns", "", simulatedManifest))
+	helmRelease := &release.Release{Name: "bd", Namespace: "ns", Version: 1, Info: &release.Info{Status: release.StatusDeployed}, Manifest: simulatedManifest}
+	assert.Nil(s.HelmActionConfiguration("ns").Releases.Create(helmRelease))
+	assert.Nil(s.Save())
+
+	loaded, err := NewSimulatedCluster(path)
+	assert.Nil(err)
+	got, err := loaded.HelmActionConfiguration("ns").Releases.Get("bd", 1)
+	assert.Nil(err)
+	assert.Equal(release.StatusDeployed, got.Info.Status)
+	cm, err := loaded.KubeClient.CoreV1().ConfigMaps("ns").Get("bd-config", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal("value", cm.Data["KEY"])
+}
+
+func TestSimulatedClusterConcurrentNamespaces(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewSimulatedCluster(filepath.Join(os.TempDir(), "synopsysctl-simulate-missing", "simulate.json"))
+	assert.Nil(err)
+
+	namespaces := []string{"ns1", "ns2", "ns3", "ns4"}
+	var wg sync.WaitGroup
+	for _, namespace := range namespaces {
+		wg.Add(1)
+		go func(namespace string) {
+			defer wg.Done()
+			actionConfig := s.HelmActionConfiguration(namespace)
+			for version := 1; version <= 20; version++ {
+				helmRelease := &release.Release{Name: "bd", Namespace: namespace, Version: version, Info: &release.Info{Status: release.StatusDeployed}}
+				assert.Nil(actionConfig.Releases.Create(helmRelease))
+				_, err := actionConfig.Releases.Get("bd", version)
+				assert.Nil(err)
+			}
+		}(namespace)
+	}
+	wg.Wait()
+
+	for _, namespace := range namespaces {
+		history, err := s.HelmActionConfiguration(namespace).Releases.History("bd")
+		assert.Nil(err)
+		assert.Equal(20, len(history))
+		for _, helmRelease := range history {
+			assert.Equal(namespace, helmRelease.Namespace)
+		}
+	}
+	all, err := s.HelmActionConfiguration("").Releases.ListReleases()
+	assert.Nil(err)
+	assert.Equal(80, len(all))
+}
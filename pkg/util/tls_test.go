@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParsePemCertificates(t *testing.T) {
+	assert := assert.New(t)
+
+	cert, key, err := GeneratePemSelfSignedCertificateAndKey(pkix.Name{CommonName: "blackduck.example.com"})
+	assert.Nil(err)
+	caCert, _, err := GeneratePemSelfSignedCertificateAndKey(pkix.Name{CommonName: "ca.example.com"})
+	assert.Nil(err)
+
+	// the key before the certificate is skipped
+	certificates, err := ParsePemCertificates([]byte(key + cert))
+	assert.Nil(err)
+	assert.Equal(1, len(certificates))
+	assert.Equal("blackduck.example.com", certificates[0].Subject.CommonName)
+
+	// every certificate of a bundle is returned
+	certificates, err = ParsePemCertificates([]byte(cert + caCert))
+	assert.Nil(err)
+	assert.Equal(2, len(certificates))
+	assert.Equal("ca.example.com", certificates[1].Subject.CommonName)
+
+	_, err = ParsePemCertificates([]byte(key))
+	assert.NotNil(err)
+	_, err = ParsePemCertificates([]byte(cert + "-----BEGIN CERTIFICATE-----\naW52YWxpZA==\n-----END CERTIFICATE-----\n"))
+	assert.NotNil(err)
+}
+
+func TestPodSpecReferencesSecret(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "certificate", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "webserver-certificate"}}},
+		},
+		Containers: []corev1.Container{
+			{
+				Name:    "webapp",
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "proxy-certificate"}}}},
+				Env: []corev1.EnvVar{
+					{Name: "AUTH_CUSTOM_CA", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "auth-custom-ca"}, Key: "AUTH_CUSTOM_CA"}}},
+				},
+			},
+		},
+	}
+	assert.True(PodSpecReferencesSecret(spec, "webserver-certificate"))
+	assert.True(PodSpecReferencesSecret(spec, "proxy-certificate"))
+	assert.True(PodSpecReferencesSecret(spec, "auth-custom-ca"))
+	assert.False(PodSpecReferencesSecret(spec, "other"))
+}
@@ -0,0 +1,607 @@
+/*
+Copyright (C) 2020 Synopsys, Inc.
+
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements. See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership. The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied. See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// waitLogLines is the number of log lines of each container of a failing pod that are shown when a wait fails
+const waitLogLines = 20
+
+// waitEvents is the number of events of a failing pod that are shown when a wait fails
+const waitEvents = 5
+
+// WaitResource is a Deployment, StatefulSet, ReplicationController, Job or PersistentVolumeClaim of an instance
+type WaitResource struct {
+	Kind string
+	Name string
+}
+
+// WaitTarget describes the instance to wait for
+type WaitTarget struct {
+	// Description is shown in the progress, e.g. "Black Duck 'bd' in namespace 'bd'"
+	Description string
+	Namespace   string
+	// Resources are the resources of the instance. If empty, the resources are the ones that match the Selector
+	Resources []WaitResource
+	Selector  labels.Selector
+	// Stopped waits for the pods of the workloads to be removed instead of being ready
+	Stopped bool
+}
+
+// WaitComponentStatus is the progress of a resource of the instance
+type WaitComponentStatus struct {
+	Kind    string
+	Name    string
+	Ready   int32
+	Desired int32
+	Message string
+	Done    bool
+	Failed  bool
+	// Pods are the names of the pods of a workload that aren't ready, or that are still running when stopping
+	Pods []string
+}
+
+// waitObjects are the cached objects of the namespace of the instance
+type waitObjects struct {
+	deployments  []*appsv1.Deployment
+	statefulSets []*appsv1.StatefulSet
+	rcs          []*corev1.ReplicationController
+	jobs         []*batchv1.Job
+	pvcs         []*corev1.PersistentVolumeClaim
+	pods         []*corev1.Pod
+}
+
+// GetWaitResources returns the resources to wait for from the manifest resources of a release
+func GetWaitResources(resources []map[string]interface{}) []WaitResource {
+	waitResources := []WaitResource{}
+	for _, resource := range resources {
+		kind := fmt.Sprintf("%v", resource["kind"])
+		switch kind {
+		case "Deployment", "StatefulSet", "ReplicationController", "Job", "PersistentVolumeClaim":
+			waitResources = append(waitResources, WaitResource{Kind: kind, Name: fmt.Sprintf("%v", GetHelmValueFromMap(resource, []string{"metadata", "name"}))})
+		}
+	}
+	return waitResources
+}
+
+// getWaitComponentStatuses returns the progress of the resources of the target
+func getWaitComponentStatuses(target *WaitTarget, objects *waitObjects) []WaitComponentStatus {
+	resources := target.Resources
+	if len(resources) == 0 {
+		resources = getSelectedWaitResources(target.Selector, objects)
+	}
+	statuses := []WaitComponentStatus{}
+	for _, resource := range resources {
+		var status WaitComponentStatus
+		switch resource.Kind {
+		case "Deployment", "StatefulSet", "ReplicationController":
+			status = getWorkloadWaitStatus(resource, objects, target.Stopped)
+		case "Job":
+			if target.Stopped {
+				continue
+			}
+			status = getJobWaitStatus(resource, objects.jobs)
+		case "PersistentVolumeClaim":
+			if target.Stopped {
+				continue
+			}
+			status = getPVCWaitStatus(resource, objects.pvcs)
+		default:
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// getSelectedWaitResources returns the resources that match the selector
+func getSelectedWaitResources(selector labels.Selector, objects *waitObjects) []WaitResource {
+	resources := []WaitResource{}
+	if selector == nil {
+		return resources
+	}
+	for _, deployment := range objects.deployments {
+		if selector.Matches(labels.Set(deployment.Labels)) {
+			resources = append(resources, WaitResource{Kind: "Deployment", Name: deployment.Name})
+		}
+	}
+	for _, statefulSet := range objects.statefulSets {
+		if selector.Matches(labels.Set(statefulSet.Labels)) {
+			resources = append(resources, WaitResource{Kind: "StatefulSet", Name: statefulSet.Name})
+		}
+	}
+	for _, rc := range objects.rcs {
+		if selector.Matches(labels.Set(rc.Labels)) {
+			resources = append(resources, WaitResource{Kind: "ReplicationController", Name: rc.Name})
+		}
+	}
+	for _, job := range objects.jobs {
+		if selector.Matches(labels.Set(job.Labels)) {
+			resources = append(resources, WaitResource{Kind: "Job", Name: job.Name})
+		}
+	}
+	for _, pvc := range objects.pvcs {
+		if selector.Matches(labels.Set(pvc.Labels)) {
+			resources = append(resources, WaitResource{Kind: "PersistentVolumeClaim", Name: pvc.Name})
+		}
+	}
+	return resources
+}
+
+// getWorkloadWaitStatus returns the progress of a Deployment, StatefulSet or ReplicationController based on its pods
+func getWorkloadWaitStatus(resource WaitResource, objects *waitObjects, stopped bool) WaitComponentStatus {
+	status := WaitComponentStatus{Kind: resource.Kind, Name: resource.Name}
+	var replicas *int32
+	var selector labels.Selector
+	rolledOut := false
+	found := false
+	switch resource.Kind {
+	case "Deployment":
+		for _, deployment := range objects.deployments {
+			if deployment.Name == resource.Name {
+				found = true
+				replicas = deployment.Spec.Replicas
+				selector, _ = metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+				desired := getDesiredReplicas(replicas)
+				rolledOut = deployment.Status.ObservedGeneration >= deployment.Generation && deployment.Status.UpdatedReplicas == desired &&
+					deployment.Status.Replicas == desired && deployment.Status.ReadyReplicas == desired
+			}
+		}
+	case "StatefulSet":
+		for _, statefulSet := range objects.statefulSets {
+			if statefulSet.Name == resource.Name {
+				found = true
+				replicas = statefulSet.Spec.Replicas
+				selector, _ = metav1.LabelSelectorAsSelector(statefulSet.Spec.Selector)
+				desired := getDesiredReplicas(replicas)
+				rolledOut = statefulSet.Status.ObservedGeneration >= statefulSet.Generation && statefulSet.Status.Replicas == desired &&
+					statefulSet.Status.ReadyReplicas == desired && (statefulSet.Status.UpdatedReplicas == desired || statefulSet.Status.CurrentRevision == statefulSet.Status.UpdateRevision)
+			}
+		}
+	case "ReplicationController":
+		for _, rc := range objects.rcs {
+			if rc.Name == resource.Name {
+				found = true
+				replicas = rc.Spec.Replicas
+				selector = labels.SelectorFromSet(rc.Spec.Selector)
+				desired := getDesiredReplicas(replicas)
+				rolledOut = rc.Status.ObservedGeneration >= rc.Generation && rc.Status.Replicas == desired && rc.Status.ReadyReplicas == desired
+			}
+		}
+	}
+
+	if !found {
+		// a workload that doesn't exist has no pods, which is what a stop waits for
+		status.Done = stopped
+		status.Message = "Missing"
+		if stopped {
+			status.Message = "Stopped"
+		}
+		return status
+	}
+
+	pods := []*corev1.Pod{}
+	if selector != nil {
+		for _, pod := range objects.pods {
+			if selector.Matches(labels.Set(pod.Labels)) {
+				pods = append(pods, pod)
+			}
+		}
+	}
+
+	if stopped {
+		status.Ready = int32(len(pods))
+		for _, pod := range pods {
+			status.Pods = append(status.Pods, pod.Name)
+		}
+		status.Done = len(pods) == 0
+		status.Message = "Stopping"
+		if status.Done {
+			status.Message = "Stopped"
+		}
+		return status
+	}
+
+	status.Desired = getDesiredReplicas(replicas)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if isPodReady(pod) {
+			status.Ready++
+			continue
+		}
+		status.Pods = append(status.Pods, pod.Name)
+		if len(status.Message) == 0 {
+			status.Message = getPodWaitingReason(pod)
+		}
+	}
+	status.Done = rolledOut && status.Ready >= status.Desired
+	if status.Done {
+		status.Message = "Ready"
+	} else if len(status.Message) == 0 {
+		status.Message = "Progressing"
+	}
+	return status
+}
+
+// getDesiredReplicas returns the replicas of a workload, which default to 1
+func getDesiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// isPodReady returns true if the pod is running and its Ready condition is true
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// getPodWaitingReason returns why a pod isn't ready, e.g. ImagePullBackOff or CrashLoopBackOff, or its phase
+func getPodWaitingReason(pod *corev1.Pod) string {
+	containerStatuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, containerStatus := range containerStatuses {
+		if containerStatus.State.Waiting != nil && len(containerStatus.State.Waiting.Reason) > 0 {
+			return containerStatus.State.Waiting.Reason
+		}
+		if containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode != 0 {
+			return containerStatus.State.Terminated.Reason
+		}
+	}
+	return string(pod.Status.Phase)
+}
+
+// getJobWaitStatus returns the progress of a Job based on its succeeded pods
+func getJobWaitStatus(resource WaitResource, jobs []*batchv1.Job) WaitComponentStatus {
+	status := WaitComponentStatus{Kind: resource.Kind, Name: resource.Name, Desired: 1, Message: "Missing"}
+	for _, job := range jobs {
+		if job.Name != resource.Name {
+			continue
+		}
+		if job.Spec.Completions != nil {
+			status.Desired = *job.Spec.Completions
+		}
+		status.Ready = job.Status.Succeeded
+		status.Message = "Running"
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case batchv1.JobComplete:
+				status.Done = true
+				status.Message = "Complete"
+			case batchv1.JobFailed:
+				status.Failed = true
+				status.Message = fmt.Sprintf("Failed: %s", condition.Reason)
+			}
+		}
+	}
+	return status
+}
+
+// getPVCWaitStatus returns the progress of a PersistentVolumeClaim, which is done when it's bound
+func getPVCWaitStatus(resource WaitResource, pvcs []*corev1.PersistentVolumeClaim) WaitComponentStatus {
+	status := WaitComponentStatus{Kind: resource.Kind, Name: resource.Name, Desired: 1, Message: "Missing"}
+	for _, pvc := range pvcs {
+		if pvc.Name != resource.Name {
+			continue
+		}
+		status.Message = string(pvc.Status.Phase)
+		if pvc.Status.Phase == corev1.ClaimBound {
+			status.Ready = 1
+			status.Done = true
+		}
+	}
+	return status
+}
+
+// isWaitComplete returns true if all the resources are done. An instance selected by labels is only complete once
+// at least one of its workloads exists, unless it's being stopped
+func isWaitComplete(target *WaitTarget, statuses []WaitComponentStatus) bool {
+	if len(statuses) == 0 {
+		return target.Stopped
+	}
+	for _, status := range statuses {
+		if !status.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitProgress shows the progress of a wait
+type WaitProgress interface {
+	Update(elapsed time.Duration, statuses []WaitComponentStatus)
+}
+
+// NewWaitProgress returns the progress of a wait for a terminal, which redraws the status of every resource,
+// or for a log, which prints a line whenever a resource changes
+func NewWaitProgress(out io.Writer, description string, isTerminal bool) WaitProgress {
+	if isTerminal {
+		return &terminalWaitProgress{out: out, description: description}
+	}
+	return &lineWaitProgress{out: out, description: description, last: map[string]string{}}
+}
+
+// terminalWaitProgress redraws the status of every resource in place
+type terminalWaitProgress struct {
+	out         io.Writer
+	description string
+	lines       int
+}
+
+// Update redraws the statuses over the previous ones
+func (p *terminalWaitProgress) Update(elapsed time.Duration, statuses []WaitComponentStatus) {
+	if p.lines > 0 {
+		// move the cursor to the beginning of the previous progress
+		fmt.Fprintf(p.out, "\033[%dA", p.lines)
+	}
+	done := 0
+	for _, status := range statuses {
+		if status.Done {
+			done++
+		}
+	}
+	fmt.Fprintf(p.out, "\033[2KWaiting for %s: %d/%d ready (%s)\n", p.description, done, len(statuses), elapsed.Round(time.Second))
+	for _, status := range statuses {
+		mark := " "
+		if status.Done {
+			mark = "✓"
+		} else if status.Failed {
+			mark = "✗"
+		}
+		fmt.Fprintf(p.out, "\033[2K  %s %s %s\n", mark, getWaitComponentName(status), getWaitComponentProgress(status))
+	}
+	// clear the lines of resources that were removed since the previous update
+	for i := len(statuses) + 1; i < p.lines; i++ {
+		fmt.Fprint(p.out, "\033[2K\n")
+	}
+	if p.lines > len(statuses)+1 {
+		fmt.Fprintf(p.out, "\033[%dA", p.lines-len(statuses)-1)
+	}
+	p.lines = len(statuses) + 1
+}
+
+// lineWaitProgress prints a line whenever a resource changes, for logs that don't support redrawing
+type lineWaitProgress struct {
+	out         io.Writer
+	description string
+	last        map[string]string
+	started     bool
+}
+
+// Update prints the statuses that changed since the previous update
+func (p *lineWaitProgress) Update(elapsed time.Duration, statuses []WaitComponentStatus) {
+	if !p.started {
+		fmt.Fprintf(p.out, "waiting for %s\n", p.description)
+		p.started = true
+	}
+	for _, status := range statuses {
+		name := getWaitComponentName(status)
+		progress := getWaitComponentProgress(status)
+		if p.last[name] == progress {
+			continue
+		}
+		p.last[name] = progress
+		fmt.Fprintf(p.out, "[%s] %s: %s\n", elapsed.Round(time.Second), name, progress)
+	}
+}
+
+// getWaitComponentName returns the kind and name of a resource, e.g. Deployment/blackduck-webapp
+func getWaitComponentName(status WaitComponentStatus) string {
+	return fmt.Sprintf("%s/%s", status.Kind, status.Name)
+}
+
+// getWaitComponentProgress returns the progress of a resource, e.g. 1/2 ready (ContainerCreating)
+func getWaitComponentProgress(status WaitComponentStatus) string {
+	switch status.Kind {
+	case "Job":
+		return fmt.Sprintf("%d/%d succeeded (%s)", status.Ready, status.Desired, status.Message)
+	case "PersistentVolumeClaim":
+		return status.Message
+	}
+	if len(status.Pods) > 0 && status.Desired == 0 {
+		return fmt.Sprintf("%d pod(s) remaining (%s)", status.Ready, status.Message)
+	}
+	return fmt.Sprintf("%d/%d ready (%s)", status.Ready, status.Desired, status.Message)
+}
+
+// WaitForInstance watches the pods, workloads, jobs and persistent volume claims of the namespace of the target with informers
+// until all of its resources are ready, or stopped. It returns an error with the events and logs of the failing pods if a job
+// fails or if the timeout is reached
+func WaitForInstance(clientset kubernetes.Interface, target *WaitTarget, timeout time.Duration, progress WaitProgress) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(target.Namespace))
+	podLister := factory.Core().V1().Pods().Lister()
+	pvcLister := factory.Core().V1().PersistentVolumeClaims().Lister()
+	rcLister := factory.Core().V1().ReplicationControllers().Lister()
+	deploymentLister := factory.Apps().V1().Deployments().Lister()
+	statefulSetLister := factory.Apps().V1().StatefulSets().Lister()
+	jobLister := factory.Batch().V1().Jobs().Lister()
+
+	// changed is signaled whenever an object of the namespace changes; a single pending signal is enough to recompute the progress
+	changed := make(chan struct{}, 1)
+	signal := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { signal() },
+		UpdateFunc: func(oldObj, newObj interface{}) { signal() },
+		DeleteFunc: func(obj interface{}) { signal() },
+	}
+	factory.Core().V1().Pods().Informer().AddEventHandler(handler)
+	factory.Core().V1().PersistentVolumeClaims().Informer().AddEventHandler(handler)
+	factory.Core().V1().ReplicationControllers().Informer().AddEventHandler(handler)
+	factory.Apps().V1().Deployments().Informer().AddEventHandler(handler)
+	factory.Apps().V1().StatefulSets().Informer().AddEventHandler(handler)
+	factory.Batch().V1().Jobs().Informer().AddEventHandler(handler)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	for informerType, synced := range factory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return fmt.Errorf("failed to watch %v in namespace '%s'", informerType, target.Namespace)
+		}
+	}
+
+	start := time.Now()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	// the ticker refreshes the elapsed time even if nothing changes
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var statuses []WaitComponentStatus
+	for {
+		objects := &waitObjects{}
+		var err error
+		if objects.pods, err = podLister.Pods(target.Namespace).List(labels.Everything()); err != nil {
+			return err
+		}
+		if objects.pvcs, err = pvcLister.PersistentVolumeClaims(target.Namespace).List(labels.Everything()); err != nil {
+			return err
+		}
+		if objects.rcs, err = rcLister.ReplicationControllers(target.Namespace).List(labels.Everything()); err != nil {
+			return err
+		}
+		if objects.deployments, err = deploymentLister.Deployments(target.Namespace).List(labels.Everything()); err != nil {
+			return err
+		}
+		if objects.statefulSets, err = statefulSetLister.StatefulSets(target.Namespace).List(labels.Everything()); err != nil {
+			return err
+		}
+		if objects.jobs, err = jobLister.Jobs(target.Namespace).List(labels.Everything()); err != nil {
+			return err
+		}
+		statuses = getWaitComponentStatuses(target, objects)
+		progress.Update(time.Since(start), statuses)
+
+		if isWaitComplete(target, statuses) {
+			return nil
+		}
+		for _, status := range statuses {
+			if status.Failed {
+				return fmt.Errorf("%s %s failed while waiting for %s%s", status.Kind, status.Name, target.Description, getWaitFailureDetails(clientset, target.Namespace, statuses))
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+		case <-timer.C:
+			notReady := 0
+			for _, status := range statuses {
+				if !status.Done {
+					notReady++
+				}
+			}
+			return fmt.Errorf("timed out after %s waiting for %s, %d of %d resource(s) are not ready%s", timeout, target.Description, notReady, len(statuses), getWaitFailureDetails(clientset, target.Namespace, statuses))
+		}
+	}
+}
+
+// getWaitFailureDetails returns the last events and log lines of the pods of the resources that aren't done
+func getWaitFailureDetails(clientset kubernetes.Interface, namespace string, statuses []WaitComponentStatus) string {
+	var details strings.Builder
+	for _, status := range statuses {
+		if status.Done {
+			continue
+		}
+		fmt.Fprintf(&details, "\n%s: %s", getWaitComponentName(status), getWaitComponentProgress(status))
+		involvedObjects := status.Pods
+		if len(involvedObjects) == 0 {
+			// resources without pods, e.g. persistent volume claims, have their own events
+			involvedObjects = []string{status.Name}
+		}
+		for _, name := range involvedObjects {
+			if name != status.Name {
+				fmt.Fprintf(&details, "\n  pod %s:", name)
+			}
+			for _, event := range getLastEvents(clientset, namespace, name, waitEvents) {
+				fmt.Fprintf(&details, "\n    event: %s %s: %s", event.Type, event.Reason, strings.TrimSpace(event.Message))
+			}
+			if name == status.Name {
+				continue
+			}
+			pod, err := GetPod(clientset, namespace, name)
+			if err != nil {
+				continue
+			}
+			for _, container := range append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+				tailLines := int64(waitLogLines)
+				logs, err := clientset.CoreV1().Pods(namespace).GetLogs(name, &corev1.PodLogOptions{Container: container.Name, TailLines: &tailLines}).Do().Raw()
+				if err != nil || len(strings.TrimSpace(string(logs))) == 0 {
+					continue
+				}
+				fmt.Fprintf(&details, "\n    logs of container %s:", container.Name)
+				for _, line := range strings.Split(strings.TrimRight(string(logs), "\n"), "\n") {
+					fmt.Fprintf(&details, "\n      %s", line)
+				}
+			}
+		}
+	}
+	return details.String()
+}
+
+// getLastEvents returns the last events of an object in the namespace, oldest first
+func getLastEvents(clientset kubernetes.Interface, namespace string, name string, count int) []corev1.Event {
+	events, err := clientset.CoreV1().Events(namespace).List(metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("involvedObject.name", name).String()})
+	if err != nil {
+		return nil
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return events.Items[i].LastTimestamp.Before(&events.Items[j].LastTimestamp)
+	})
+	if len(events.Items) > count {
+		return events.Items[len(events.Items)-count:]
+	}
+	return events.Items
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2020 Synopsys, Inc.
+ *
+ *  Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements. See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership. The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ *  with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied. See the License for the
+ * specific language governing permissions and limitations
+ *  under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func getTestWaitDeployment(name string, replicas int32, readyReplicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"app": "test"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"component": name}},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: readyReplicas, UpdatedReplicas: readyReplicas, ReadyReplicas: readyReplicas},
+	}
+}
+
+func getTestWaitPod(name string, component string, ready bool, waitingReason string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"component": component}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	if ready {
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	if len(waitingReason) > 0 {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "c", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waitingReason}}}}
+	}
+	return pod
+}
+
+func TestGetWaitResources(t *testing.T) {
+	assert := assert.New(t)
+
+	resources := []map[string]interface{}{
+		{"kind": "Deployment", "metadata": map[string]interface{}{"name": "webapp"}},
+		{"kind": "Service", "metadata": map[string]interface{}{"name": "webapp"}},
+		{"kind": "PersistentVolumeClaim", "metadata": map[string]interface{}{"name": "data"}},
+		{"kind": "Job", "metadata": map[string]interface{}{"name": "migrate"}},
+	}
+	expected := []WaitResource{{Kind: "Deployment", Name: "webapp"}, {Kind: "PersistentVolumeClaim", Name: "data"}, {Kind: "Job", Name: "migrate"}}
+	assert.Equal(expected, GetWaitResources(resources))
+}
+
+func TestGetWaitComponentStatuses(t *testing.T) {
+	assert := assert.New(t)
+
+	completions := int32(1)
+	objects := &waitObjects{
+		deployments: []*appsv1.Deployment{getTestWaitDeployment("webapp", 2, 1), getTestWaitDeployment("jobrunner", 1, 1)},
+		pods: []*corev1.Pod{
+			getTestWaitPod("webapp-1", "webapp", true, ""),
+			getTestWaitPod("webapp-2", "webapp", false, "CrashLoopBackOff"),
+			getTestWaitPod("jobrunner-1", "jobrunner", true, ""),
+		},
+		jobs: []*batchv1.Job{{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrate"},
+			Spec:       batchv1.JobSpec{Completions: &completions},
+			Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}}},
+		}},
+		pvcs: []*corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}}},
+	}
+	target := &WaitTarget{Resources: []WaitResource{
+		{Kind: "Deployment", Name: "webapp"},
+		{Kind: "Deployment", Name: "jobrunner"},
+		{Kind: "StatefulSet", Name: "postgres"},
+		{Kind: "Job", Name: "migrate"},
+		{Kind: "PersistentVolumeClaim", Name: "data"},
+	}}
+
+	statuses := getWaitComponentStatuses(target, objects)
+	assert.Equal(5, len(statuses))
+	assert.Equal(WaitComponentStatus{Kind: "Deployment", Name: "webapp", Ready: 1, Desired: 2, Message: "CrashLoopBackOff", Pods: []string{"webapp-2"}}, statuses[0])
+	assert.Equal(WaitComponentStatus{Kind: "Deployment", Name: "jobrunner", Ready: 1, Desired: 1, Message: "Ready", Done: true}, statuses[1])
+	assert.Equal(WaitComponentStatus{Kind: "StatefulSet", Name: "postgres", Message: "Missing"}, statuses[2])
+	assert.True(statuses[3].Failed)
+	assert.Equal("Failed: BackoffLimitExceeded", statuses[3].Message)
+	assert.True(statuses[4].Done)
+	assert.False(isWaitComplete(target, statuses))
+
+	// stopping only waits for the pods of the workloads to be removed
+	target.Stopped = true
+	statuses = getWaitComponentStatuses(target, objects)
+	assert.Equal(3, len(statuses))
+	assert.Equal([]string{"webapp-1", "webapp-2"}, statuses[0].Pods)
+	assert.False(statuses[0].Done)
+	assert.True(statuses[2].Done)
+
+	objects.pods = nil
+	assert.True(isWaitComplete(target, getWaitComponentStatuses(target, objects)))
+}
+
+func TestGetWaitComponentStatusesWithSelector(t *testing.T) {
+	assert := assert.New(t)
+
+	objects := &waitObjects{
+		deployments: []*appsv1.Deployment{getTestWaitDeployment("webapp", 1, 1)},
+		pods:        []*corev1.Pod{getTestWaitPod("webapp-1", "webapp", true, "")},
+	}
+	target := &WaitTarget{Selector: labels.SelectorFromSet(map[string]string{"app": "other"})}
+	statuses := getWaitComponentStatuses(target, objects)
+	assert.Equal(0, len(statuses))
+	// nothing to wait for yet, e.g. the operator didn't create the resources of the instance
+	assert.False(isWaitComplete(target, statuses))
+
+	target.Selector = labels.SelectorFromSet(map[string]string{"app": "test"})
+	statuses = getWaitComponentStatuses(target, objects)
+	assert.Equal(1, len(statuses))
+	assert.True(isWaitComplete(target, statuses))
+}
+
+func TestLineWaitProgress(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	progress := NewWaitProgress(&buf, "test", false)
+	progress.Update(time.Second, []WaitComponentStatus{{Kind: "Deployment", Name: "webapp", Desired: 1, Message: "Pending"}})
+	progress.Update(2*time.Second, []WaitComponentStatus{{Kind: "Deployment", Name: "webapp", Desired: 1, Message: "Pending"}})
+	progress.Update(3*time.Second, []WaitComponentStatus{{Kind: "Deployment", Name: "webapp", Ready: 1, Desired: 1, Message: "Ready", Done: true}})
+	expected := "waiting for test\n" +
+		"[1s] Deployment/webapp: 0/1 ready (Pending)\n" +
+		"[3s] Deployment/webapp: 1/1 ready (Ready)\n"
+	assert.Equal(expected, buf.String())
+}